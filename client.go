@@ -1,20 +1,28 @@
 package loop
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/psbt"
 	"github.com/lightninglabs/lndclient"
 	"github.com/lightninglabs/loop/loopdb"
 	"github.com/lightninglabs/loop/lsat"
 	"github.com/lightninglabs/loop/swap"
 	"github.com/lightninglabs/loop/sweep"
+	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 )
 
 var (
@@ -68,6 +76,13 @@ type Client struct {
 	resumeReady chan struct{}
 	wg          sync.WaitGroup
 
+	// externalHtlcSwaps contains the loop in swaps with an external htlc
+	// that were handed to the executor, so that their htlc tx can be
+	// published from a psbt. Swaps that finished executing are removed
+	// whenever a new swap is added.
+	externalHtlcSwaps map[lntypes.Hash]*loopInSwap
+	externalHtlcLock  sync.Mutex
+
 	clientConfig
 }
 
@@ -103,15 +118,130 @@ type ClientConfig struct {
 	// for a loop out swap. When greater than one, a multi-part payment may
 	// be attempted.
 	LoopOutMaxParts uint32
+
+	// BatchSweeps enables sweeping the htlcs of multiple loop out swaps
+	// in a single transaction.
+	BatchSweeps bool
+
+	// LoopOutMaxSweepFeeMultiplier is the factor by which the max miner
+	// fee of a loop out swap is raised at most when the fee of its sweep is
+	// bumped after the preimage was revealed. If zero,
+	// DefaultMaxSweepFeeMultiplier is used. A factor of one never exceeds
+	// the max miner fee.
+	LoopOutMaxSweepFeeMultiplier float64
+
+	// LoopInTimeoutMaxFeeRate is the fee rate that the timeout tx of an
+	// expired loop in swap is bumped to at most. If zero,
+	// DefaultTimeoutMaxFeeRate is used.
+	LoopInTimeoutMaxFeeRate chainfee.SatPerKWeight
+
+	// ReorgSafetyDepth is the number of confirmations that the tx
+	// spending a swap htlc is tracked for after the swap reached its
+	// outcome. If the tx is removed from the chain by a reorg before it
+	// reaches this depth, the swap reverts to its previous state. A depth
+	// of one or less disables reorg tracking.
+	ReorgSafetyDepth uint32
+
+	// BackupFile is the path of the encrypted backup of pending swaps. If
+	// empty, the backup is kept in the database directory.
+	BackupFile string
+
+	// RestoreBackup is the path of a swap backup that swaps that are
+	// missing from the database are restored from on startup.
+	RestoreBackup string
+
+	// DatabaseBackend is the database backend that swaps are stored in. If
+	// empty, swaps are stored in bbolt.
+	DatabaseBackend loopdb.Backend
+}
+
+// DeriveBackupKey derives the key that swap backups are encrypted with. It is
+// the shared secret of a key of lnd's wallet and a point with unknown
+// discrete logarithm, so that only the owner of the lnd seed can derive it.
+func DeriveBackupKey(ctx context.Context, lnd *lndclient.LndServices) (
+	*loopdb.BackupKey, error) {
+
+	numsKey, err := swap.NUMSKey()
+	if err != nil {
+		return nil, err
+	}
+
+	sharedKey, err := lnd.Signer.DeriveSharedKey(
+		ctx, numsKey, &keychain.KeyLocator{
+			Family: keychain.KeyFamily(swap.BackupKeyFamily),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to derive backup key: %v", err)
+	}
+
+	key := loopdb.BackupKey(sharedKey)
+	return &key, nil
+}
+
+// newBackupStore restores swaps from a backup if requested and wraps the
+// store provided so that a backup of its pending swaps is kept.
+func newBackupStore(store loopdb.SwapStore, dbDir string,
+	cfg *ClientConfig) (loopdb.SwapStore, error) {
+
+	key, err := DeriveBackupKey(context.Background(), cfg.Lnd)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.RestoreBackup != "" {
+		restored, err := loopdb.RestoreBackup(
+			store, cfg.RestoreBackup, key, cfg.Lnd.ChainParams,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("unable to restore swap backup: "+
+				"%v", err)
+		}
+
+		log.Infof("Restored %v swaps from backup %v", restored,
+			cfg.RestoreBackup)
+	}
+
+	backupFile := cfg.BackupFile
+	if backupFile == "" {
+		backupFile = filepath.Join(dbDir, loopdb.BackupFileName)
+	}
+
+	return loopdb.NewBackupSwapStore(
+		store, backupFile, key, cfg.Lnd.ChainParams,
+	)
+}
+
+// newLsatStore returns the store that LSAT tokens are kept in. The tokens are
+// stored in the swap database if it is a bbolt database, and in token files
+// otherwise.
+func newLsatStore(swapStore loopdb.SwapStore, backend loopdb.Backend,
+	dbDir string) (lsat.Store, error) {
+
+	if backend == loopdb.BackendBolt {
+		return loopdb.NewBoltLsatStore(swapStore, dbDir)
+	}
+
+	return lsat.NewFileStore(dbDir)
 }
 
 // NewClient returns a new instance to initiate swaps with.
 func NewClient(dbDir string, cfg *ClientConfig) (*Client, func(), error) {
-	store, err := loopdb.NewBoltSwapStore(dbDir, cfg.Lnd.ChainParams)
+	backend := cfg.DatabaseBackend
+	if backend == "" {
+		backend = loopdb.BackendBolt
+	}
+	swapStore, err := loopdb.NewSwapStore(
+		backend, dbDir, cfg.Lnd.ChainParams,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	store, err := newBackupStore(swapStore, dbDir, cfg)
 	if err != nil {
 		return nil, nil, err
 	}
-	lsatStore, err := lsat.NewFileStore(dbDir)
+	lsatStore, err := newLsatStore(swapStore, backend, dbDir)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -135,12 +265,26 @@ func NewClient(dbDir string, cfg *ClientConfig) (*Client, func(), error) {
 		Lnd: cfg.Lnd,
 	}
 
+	maxSweepFeeMultiplier := cfg.LoopOutMaxSweepFeeMultiplier
+	if maxSweepFeeMultiplier == 0 {
+		maxSweepFeeMultiplier = DefaultMaxSweepFeeMultiplier
+	}
+
+	timeoutMaxFeeRate := cfg.LoopInTimeoutMaxFeeRate
+	if timeoutMaxFeeRate == 0 {
+		timeoutMaxFeeRate = DefaultTimeoutMaxFeeRate
+	}
+
 	executor := newExecutor(&executorConfig{
-		lnd:               cfg.Lnd,
-		store:             store,
-		sweeper:           sweeper,
-		createExpiryTimer: config.CreateExpiryTimer,
-		loopOutMaxParts:   cfg.LoopOutMaxParts,
+		lnd:                   cfg.Lnd,
+		store:                 store,
+		sweeper:               sweeper,
+		createExpiryTimer:     config.CreateExpiryTimer,
+		loopOutMaxParts:       cfg.LoopOutMaxParts,
+		batchSweeps:           cfg.BatchSweeps,
+		maxSweepFeeMultiplier: maxSweepFeeMultiplier,
+		timeoutMaxFeeRate:     timeoutMaxFeeRate,
+		reorgSafetyDepth:      cfg.ReorgSafetyDepth,
 	})
 
 	client := &Client{
@@ -150,6 +294,8 @@ func NewClient(dbDir string, cfg *ClientConfig) (*Client, func(), error) {
 		sweeper:      sweeper,
 		executor:     executor,
 		resumeReady:  make(chan struct{}),
+
+		externalHtlcSwaps: make(map[lntypes.Hash]*loopInSwap),
 	}
 
 	cleanup := func() {
@@ -171,6 +317,120 @@ func (s *Client) FetchSwaps() ([]*SwapInfo, error) {
 		return nil, err
 	}
 
+	return s.swapInfos(loopOutSwaps, loopInSwaps)
+}
+
+// ListSwaps returns the page of loop in and out swaps that the query provided
+// selects, ordered by their initiation time and hash. The cursor of the next
+// page is returned if more swaps match the query.
+func (s *Client) ListSwaps(query *loopdb.SwapQuery) ([]*SwapInfo,
+	*loopdb.SwapCursor, error) {
+
+	page, err := s.Store.QuerySwaps(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	swaps, err := s.swapInfos(page.LoopOuts, page.LoopIns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sort.Slice(swaps, func(i, j int) bool {
+		ti := swaps[i].InitiationTime.UnixNano()
+		tj := swaps[j].InitiationTime.UnixNano()
+		if ti != tj {
+			return ti < tj
+		}
+
+		return bytes.Compare(
+			swaps[i].SwapHash[:], swaps[j].SwapHash[:],
+		) < 0
+	})
+
+	return swaps, page.NextCursor, nil
+}
+
+// SwapEvents returns the persisted state transitions of the swap with the
+// hash provided, in the order that they happened.
+func (s *Client) SwapEvents(hash lntypes.Hash) ([]*loopdb.LoopEvent, error) {
+	page, err := s.Store.QuerySwaps(&loopdb.SwapQuery{
+		Hash:            &hash,
+		IncludeArchived: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(page.LoopOuts) != 0:
+		return page.LoopOuts[0].Events, nil
+
+	case len(page.LoopIns) != 0:
+		return page.LoopIns[0].Events, nil
+
+	default:
+		return nil, fmt.Errorf("swap %v not found", hash)
+	}
+}
+
+// BackupDatabase writes a consistent snapshot of the swap database to a new
+// file at the path provided and verifies the snapshot.
+func (s *Client) BackupDatabase(path string) (*loopdb.SnapshotInfo, error) {
+	if err := s.Store.Snapshot(path); err != nil {
+		return nil, err
+	}
+
+	return s.VerifyDatabaseBackup(path)
+}
+
+// VerifyDatabaseBackup checks that the swap database snapshot at the path
+// provided can be read by this client.
+func (s *Client) VerifyDatabaseBackup(path string) (*loopdb.SnapshotInfo,
+	error) {
+
+	return loopdb.VerifySnapshot(path, s.lndServices.ChainParams)
+}
+
+// ArchiveSwaps archives the completed swaps that had their last update before
+// the time provided, so that they are no longer read on startup and by the
+// liquidity manager. The hashes of the archived swaps are returned.
+func (s *Client) ArchiveSwaps(before time.Time) ([]lntypes.Hash, error) {
+	// Only the contracts and last updates of swaps are read to find the
+	// candidates, so that we don't deserialize the history of all swaps.
+	page, err := s.Store.QuerySwaps(&loopdb.SwapQuery{
+		StateTypes: []loopdb.SwapStateType{
+			loopdb.StateTypeSuccess, loopdb.StateTypeFail,
+		},
+		UpdatedBefore: before,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var hashes []lntypes.Hash
+	for _, swp := range page.LoopOuts {
+		hashes = append(hashes, swp.Hash)
+	}
+	for _, swp := range page.LoopIns {
+		hashes = append(hashes, swp.Hash)
+	}
+
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	if err := s.Store.ArchiveSwaps(hashes); err != nil {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+// swapInfos returns the swap info of the loop out and loop in swaps provided.
+func (s *Client) swapInfos(loopOutSwaps []*loopdb.LoopOut,
+	loopInSwaps []*loopdb.LoopIn) ([]*SwapInfo, error) {
+
 	swaps := make([]*SwapInfo, 0, len(loopInSwaps)+len(loopOutSwaps))
 
 	for _, swp := range loopOutSwaps {
@@ -191,6 +451,7 @@ func (s *Client) FetchSwaps() ([]*SwapInfo, error) {
 			SwapHash:         swp.Hash,
 			LastUpdate:       swp.LastUpdateTime(),
 			HtlcAddressP2WSH: htlc.Address,
+			Archived:         swp.Archived,
 		})
 	}
 
@@ -223,6 +484,7 @@ func (s *Client) FetchSwaps() ([]*SwapInfo, error) {
 			LastUpdate:        swp.LastUpdateTime(),
 			HtlcAddressP2WSH:  htlcP2WSH.Address,
 			HtlcAddressNP2WSH: htlcNP2WSH.Address,
+			Archived:          swp.Archived,
 		})
 	}
 
@@ -310,6 +572,7 @@ func (s *Client) resumeSwaps(ctx context.Context,
 
 	swapCfg := newSwapConfig(s.lndServices, s.Store, s.Server)
 
+	batchFees := batchSweepFees(loopOutSwaps)
 	for _, pend := range loopOutSwaps {
 		if pend.State().State.Type() != loopdb.StateTypePending {
 			continue
@@ -320,6 +583,10 @@ func (s *Client) resumeSwaps(ctx context.Context,
 			continue
 		}
 
+		if swap.sweepTxHash != nil {
+			swap.batchFee = batchFees[*swap.sweepTxHash]
+		}
+
 		s.executor.initiateSwap(ctx, swap)
 	}
 
@@ -333,6 +600,10 @@ func (s *Client) resumeSwaps(ctx context.Context,
 			continue
 		}
 
+		if swap.ExternalHtlc {
+			s.addExternalHtlcSwap(swap)
+		}
+
 		s.executor.initiateSwap(ctx, swap)
 	}
 }
@@ -349,6 +620,15 @@ func (s *Client) resumeSwaps(ctx context.Context,
 func (s *Client) LoopOut(globalCtx context.Context,
 	request *OutRequest) (*LoopOutSwapInfo, error) {
 
+	if len(request.SweepDestinations) > 0 {
+		err := validateSweepDestinations(request)
+		if err != nil {
+			return nil, err
+		}
+
+		request.DestAddr = request.SweepDestinations[0].Addr
+	}
+
 	log.Infof("LoopOut %v to %v (channels: %v)",
 		request.Amount, request.DestAddr, request.OutgoingChanSet,
 	)
@@ -460,7 +740,8 @@ func (s *Client) LoopOutQuote(ctx context.Context,
 
 	minerFee, err := s.sweeper.GetSweepFee(
 		ctx, swap.QuoteHtlc.AddSuccessToEstimator,
-		p2wshAddress, request.SweepConfTarget,
+		[]loopdb.SweepDestination{{Addr: p2wshAddress, Weight: 1}},
+		request.SweepConfTarget,
 	)
 	if err != nil {
 		return nil, err
@@ -522,6 +803,10 @@ func (s *Client) LoopIn(globalCtx context.Context,
 	}
 	swap := initResult.swap
 
+	if request.ExternalHtlc {
+		s.addExternalHtlcSwap(swap)
+	}
+
 	// Post swap to the main loop.
 	s.executor.initiateSwap(globalCtx, swap)
 
@@ -533,9 +818,166 @@ func (s *Client) LoopIn(globalCtx context.Context,
 		HtlcAddressNP2WSH: swap.htlcNP2WSH.Address,
 		ServerMessage:     initResult.serverMessage,
 	}
+
+	// Provide a psbt template for wallets that fund the external htlc.
+	if request.ExternalHtlc {
+		swapInfo.HtlcPsbt, err = newHtlcPsbt(
+			swap.htlcP2WSH, request.Amount,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return swapInfo, nil
 }
 
+// LoopInBatch initiates a batch of loop in swaps and publishes all of their
+// htlcs in a single transaction.
+func (s *Client) LoopInBatch(globalCtx context.Context,
+	request *LoopInBatchRequest) (*LoopInBatchInfo, error) {
+
+	log.Infof("Loop in batch of %v swaps", len(request.Requests))
+
+	if len(request.Requests) == 0 {
+		return nil, errors.New("no swaps in batch")
+	}
+
+	for _, req := range request.Requests {
+		if req.ExternalHtlc {
+			return nil, errors.New("external htlcs cannot be batched")
+		}
+
+		if len(req.HtlcFundingOutpoints) > 0 {
+			return nil, errors.New("htlc funding outpoints cannot " +
+				"be set for batched swaps")
+		}
+	}
+
+	if err := s.waitForInitialized(globalCtx); err != nil {
+		return nil, err
+	}
+
+	// Create a new swap object for every swap of the batch.
+	initiationHeight := s.executor.height()
+	swapCfg := newSwapConfig(s.lndServices, s.Store, s.Server)
+
+	var (
+		swaps       = make([]*loopInSwap, 0, len(request.Requests))
+		initResults = make([]*loopInInitResult, 0, len(request.Requests))
+	)
+	for _, req := range request.Requests {
+		req := *req
+		req.HtlcConfTarget = request.HtlcConfTarget
+
+		initResult, err := newLoopInSwap(
+			globalCtx, swapCfg, initiationHeight, &req,
+		)
+		if err != nil {
+			// The swaps that we already created are known to the
+			// server and stored, so they would be resumed after a
+			// restart anyway. Execute them individually.
+			if len(swaps) > 0 {
+				log.Warnf("Executing %v swaps of failed batch "+
+					"individually", len(swaps))
+			}
+			for _, swap := range swaps {
+				s.executor.initiateSwap(globalCtx, swap)
+			}
+
+			return nil, fmt.Errorf("cannot initiate swap %v of "+
+				"batch: %v", len(swaps), err)
+		}
+
+		swaps = append(swaps, initResult.swap)
+		initResults = append(initResults, initResult)
+	}
+
+	// Publish all htlcs in a single tx before the swaps are executed, so
+	// that they don't publish their htlcs on their own.
+	htlcTxHash, err := publishOnChainHtlcBatch(
+		globalCtx, s.lndServices, swaps, s.executor.height(),
+		request.HtlcConfTarget,
+	)
+	if err != nil {
+		// The swaps are known to the server and stored, so we execute
+		// them anyway instead of leaving them behind until a restart.
+		// Depending on how far the batch got, they announce their
+		// failure, republish the recorded batch tx or publish their
+		// htlcs individually.
+		log.Warnf("Executing %v swaps of failed batch individually",
+			len(swaps))
+
+		for _, swap := range swaps {
+			s.executor.initiateSwap(globalCtx, swap)
+		}
+
+		return nil, err
+	}
+
+	// Post swaps to the main loop and return their hashes so that the
+	// caller can identify them in the updates stream.
+	info := &LoopInBatchInfo{
+		Swaps:      make([]*LoopInSwapInfo, len(swaps)),
+		HtlcTxHash: *htlcTxHash,
+	}
+	for i, swap := range swaps {
+		s.executor.initiateSwap(globalCtx, swap)
+
+		info.Swaps[i] = &LoopInSwapInfo{
+			SwapHash:          swap.hash,
+			HtlcAddressP2WSH:  swap.htlcP2WSH.Address,
+			HtlcAddressNP2WSH: swap.htlcNP2WSH.Address,
+			ServerMessage:     initResults[i].serverMessage,
+		}
+	}
+
+	return info, nil
+}
+
+// PublishLoopInPsbt publishes the htlc tx of a loop in swap with an external
+// htlc from a signed psbt. The tx must pay exactly the swap amount to the
+// swap's htlc. The psbt is handed to the swap, which records the tx hash
+// before the tx is published and then only follows this particular htlc.
+func (s *Client) PublishLoopInPsbt(ctx context.Context, hash lntypes.Hash,
+	packet *psbt.Packet) (*chainhash.Hash, error) {
+
+	log.Infof("Publish psbt for loop in %v", hash)
+
+	if err := s.waitForInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	s.externalHtlcLock.Lock()
+	swap, ok := s.externalHtlcSwaps[hash]
+	s.externalHtlcLock.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no pending loop in swap %v with an "+
+			"external htlc", hash)
+	}
+
+	return swap.publishHtlcPsbt(ctx, packet)
+}
+
+// addExternalHtlcSwap keeps track of a loop in swap with an external htlc, so
+// that its htlc tx can be published from a psbt. It also removes all swaps
+// that finished executing.
+func (s *Client) addExternalHtlcSwap(swap *loopInSwap) {
+	s.externalHtlcLock.Lock()
+	defer s.externalHtlcLock.Unlock()
+
+	for hash, other := range s.externalHtlcSwaps {
+		select {
+		case <-other.done:
+			delete(s.externalHtlcSwaps, hash)
+		default:
+		}
+	}
+
+	s.externalHtlcSwaps[swap.hash] = swap
+}
+
 // LoopInQuote takes an amount and returns a break down of estimated
 // costs for the client. Both the swap server and the on-chain fee estimator are
 // queried to get to build the quote response.
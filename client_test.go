@@ -9,10 +9,13 @@ import (
 
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
 	"github.com/lightninglabs/lndclient"
 	"github.com/lightninglabs/loop/loopdb"
 	"github.com/lightninglabs/loop/swap"
+	"github.com/lightninglabs/loop/sweep"
 	"github.com/lightninglabs/loop/test"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lntypes"
@@ -69,11 +72,68 @@ func TestSuccess(t *testing.T) {
 	confIntent := ctx.AssertRegisterConf(false, req.HtlcConfirmations)
 
 	testSuccess(ctx, testRequest.Amount, info.SwapHash,
-		signalPrepaymentResult, signalSwapPaymentResult, false,
+		signalPrepaymentResult, signalSwapPaymentResult,
 		confIntent, swap.HtlcV2,
 	)
 }
 
+// TestSuccessSweepDestinations tests that the swept value of a loop out swap is
+// split across multiple destinations according to their weights.
+func TestSuccessSweepDestinations(t *testing.T) {
+	defer test.Guard(t)()
+
+	ctx := createClientTestContext(t, nil)
+
+	req := *testRequest
+	req.DestAddr = nil
+	req.SweepDestinations = []loopdb.SweepDestination{
+		{Addr: test.GetDestAddr(t, 0), Weight: 3},
+		{Addr: test.GetDestAddr(t, 1), Weight: 1},
+	}
+
+	// A destination address can't be combined with sweep destinations.
+	invalidReq := req
+	invalidReq.DestAddr = testAddr
+	_, err := ctx.swapClient.LoopOut(context.Background(), &invalidReq)
+	require.Error(t, err)
+
+	info, err := ctx.swapClient.LoopOut(context.Background(), &req)
+	require.NoError(t, err)
+
+	ctx.assertStored()
+	ctx.assertStatus(loopdb.StateInitiated)
+
+	signalSwapPaymentResult := ctx.AssertPaid(swapInvoiceDesc)
+	signalPrepaymentResult := ctx.AssertPaid(prepayInvoiceDesc)
+
+	confIntent := ctx.AssertRegisterConf(false, defaultConfirmations)
+
+	sweepTx := testSuccess(ctx, req.Amount, info.SwapHash,
+		signalPrepaymentResult, signalSwapPaymentResult,
+		confIntent, swap.HtlcV2,
+	)
+
+	// Every destination receives its share of the swept value.
+	require.Len(t, sweepTx.TxOut, len(req.SweepDestinations))
+
+	swept := btcutil.Amount(sweepTx.TxOut[0].Value + sweepTx.TxOut[1].Value)
+	values := sweep.SplitValue(swept, req.SweepDestinations)
+
+	for i, dest := range req.SweepDestinations {
+		pkScript, err := txscript.PayToAddrScript(dest.Addr)
+		require.NoError(t, err)
+
+		require.Equal(t, pkScript, sweepTx.TxOut[i].PkScript)
+		require.Equal(t, int64(values[i]), sweepTx.TxOut[i].Value)
+	}
+
+	// Only the fee of the sweep is counted as on-chain cost, not the
+	// value that is sent to any of the destinations.
+	updates := ctx.store.loopOutUpdates[info.SwapHash]
+	finalState := updates[len(updates)-1]
+	require.Equal(t, req.Amount-swept, finalState.Cost.Onchain)
+}
+
 // TestFailOffchain tests the handling of swap for which the server failed the
 // payments.
 func TestFailOffchain(t *testing.T) {
@@ -302,15 +362,14 @@ func testResume(t *testing.T, confs uint32, expired, preimageRevealed,
 	testSuccess(ctx, amt, hash,
 		func(r error) {},
 		func(r error) {},
-		preimageRevealed,
 		confIntent, scriptVersion,
 	)
 }
 
 func testSuccess(ctx *testContext, amt btcutil.Amount, hash lntypes.Hash,
 	signalPrepaymentResult, signalSwapPaymentResult func(error),
-	preimageRevealed bool, confIntent *test.ConfRegistration,
-	scriptVersion swap.ScriptVersion) {
+	confIntent *test.ConfRegistration,
+	scriptVersion swap.ScriptVersion) *wire.MsgTx {
 
 	htlcOutpoint := ctx.publishHtlc(confIntent.PkScript, amt)
 
@@ -328,10 +387,11 @@ func testSuccess(ctx *testContext, amt btcutil.Amount, hash lntypes.Hash,
 	// Expect a signing request.
 	<-ctx.Lnd.SignOutputRawChannel
 
-	if !preimageRevealed {
-		ctx.assertStatus(loopdb.StatePreimageRevealed)
-		ctx.assertStorePreimageReveal()
-	}
+	// Our sweep is recorded before it is published. If we had already
+	// revealed our preimage before resuming, this update only adds the
+	// sweep tx to the swap's history.
+	ctx.assertStatus(loopdb.StatePreimageRevealed)
+	ctx.assertStorePreimageReveal()
 
 	// Expect client on-chain sweep of HTLC.
 	sweepTx := ctx.ReceiveTx()
@@ -370,4 +430,6 @@ func testSuccess(ctx *testContext, amt btcutil.Amount, hash lntypes.Hash,
 	ctx.assertStoreFinished(loopdb.StateSuccess)
 
 	ctx.finish()
+
+	return sweepTx
 }
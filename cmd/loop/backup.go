@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/lightninglabs/loop/looprpc"
+	"github.com/urfave/cli"
+)
+
+var backupDBCommand = cli.Command{
+	Name:      "backupdb",
+	Usage:     "write a snapshot of the swap database",
+	ArgsUsage: "path",
+	Description: "Writes a consistent snapshot of the swap database to a " +
+		"new file on the loopd host, while swaps continue to run. " +
+		"The snapshot is verified after it was written. With " +
+		"--verify_only, an existing snapshot is verified instead.",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name: "verify_only",
+			Usage: "only verify the existing snapshot at path " +
+				"rather than writing a new one",
+		},
+	},
+	Action: backupDB,
+}
+
+func backupDB(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return cli.ShowCommandHelp(ctx, "backupdb")
+	}
+
+	// Relative paths are resolved against the current directory, which
+	// assumes that loopd runs on the same host.
+	path, err := filepath.Abs(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+
+	client, cleanup, err := getClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resp, err := client.BackupDatabase(
+		context.Background(), &looprpc.BackupDatabaseRequest{
+			Path:       path,
+			VerifyOnly: ctx.Bool("verify_only"),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+	return nil
+}
@@ -2,12 +2,15 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 
 	"github.com/btcsuite/btcutil"
 	"github.com/lightninglabs/loop"
 	"github.com/lightninglabs/loop/labels"
 	"github.com/lightninglabs/loop/looprpc"
+	"github.com/lightningnetwork/lnd/lntypes"
 	"github.com/lightningnetwork/lnd/routing/route"
 	"github.com/urfave/cli"
 )
@@ -48,6 +51,11 @@ var (
 		The external flag can be set to publish the on chain htlc 
 		independently. Note that this flag cannot be set with the 
 		conf_target flag.
+
+		The utxo flag can be set one or more times to fund the on-chain 
+		htlc from specific wallet outputs. Any change is paid to the 
+		address set with the change_addr flag, or to a new wallet 
+		address.
 		`,
 		Flags: []cli.Flag{
 			cli.Uint64Flag{
@@ -61,9 +69,47 @@ var (
 			confTargetFlag,
 			lastHopFlag,
 			labelFlag,
+			cli.StringSliceFlag{
+				Name: "utxo",
+				Usage: "a wallet outpoint in the format " +
+					"txid:index to fund the htlc with, " +
+					"may be set multiple times",
+			},
+			cli.StringFlag{
+				Name: "change_addr",
+				Usage: "the address that receives the change " +
+					"of the htlc tx if utxo is set",
+			},
 		},
 		Action: loopIn,
 	}
+
+	batchInCommand = cli.Command{
+		Name:      "batchin",
+		Usage:     "perform multiple loop in swaps with a single htlc tx",
+		ArgsUsage: "amt [amt...]",
+		Description: `
+		Send the amounts in satoshis specified by the amt arguments 
+		off-chain, each in its own loop in swap. The on-chain htlcs of 
+		all swaps are published in a single transaction. The fee 
+		priority of this transaction can optionally be set using the 
+		conf_target flag.
+
+		The last_hop flag can be set once to use the same last hop for 
+		all swaps, or once for every amount to set the last hop of 
+		every swap individually.
+		`,
+		Flags: []cli.Flag{
+			confTargetFlag,
+			cli.StringSliceFlag{
+				Name: "last_hop",
+				Usage: "the pubkey of the last hop to use for " +
+					"the swaps, may be set for every swap",
+			},
+			labelFlag,
+		},
+		Action: batchIn,
+	}
 )
 
 func loopIn(ctx *cli.Context) error {
@@ -103,6 +149,18 @@ func loopIn(ctx *cli.Context) error {
 		return fmt.Errorf("external and conf_target both set")
 	}
 
+	// The htlc can only be funded from specific outputs if we publish it
+	// ourselves.
+	utxos := ctx.StringSlice("utxo")
+	if external && len(utxos) > 0 {
+		return fmt.Errorf("external and utxo both set")
+	}
+
+	changeAddr := ctx.String("change_addr")
+	if changeAddr != "" && len(utxos) == 0 {
+		return fmt.Errorf("change_addr can only be set with utxo")
+	}
+
 	// Validate our label early so that we can fail before getting a quote.
 	label := ctx.String(labelFlag.Name)
 	if err := labels.Validate(label); err != nil {
@@ -151,6 +209,8 @@ func loopIn(ctx *cli.Context) error {
 		HtlcConfTarget: htlcConfTarget,
 		Label:          label,
 		Initiator:      defaultInitiator,
+		Outpoints:      utxos,
+		ChangeAddress:  changeAddr,
 	}
 
 	if ctx.IsSet(lastHopFlag.Name) {
@@ -178,6 +238,192 @@ func loopIn(ctx *cli.Context) error {
 	if resp.ServerMessage != "" {
 		fmt.Printf("Server message: %v\n", resp.ServerMessage)
 	}
+	if len(resp.HtlcPsbt) > 0 {
+		fmt.Println()
+		fmt.Printf("HTLC psbt: %v\n",
+			base64.StdEncoding.EncodeToString(resp.HtlcPsbt))
+		fmt.Println()
+		fmt.Printf("Fund and sign the psbt with your wallet and run " +
+			"`loop publishpsbt` to publish the HTLC.\n")
+	}
+	fmt.Println()
+	fmt.Printf("Run `loop monitor` to monitor progress.\n")
+
+	return nil
+}
+
+var publishPsbtCommand = cli.Command{
+	Name:      "publishpsbt",
+	Usage:     "publish the htlc of an external loop in from a psbt",
+	ArgsUsage: "id psbt",
+	Description: `
+		Publishes the htlc tx of a loop in swap that was initiated with 
+		the external flag. The psbt must be funded and signed and pay 
+		exactly the swap amount to the htlc. It is passed base64 
+		encoded, as it is returned by loop in.
+		`,
+	Action: publishPsbt,
+}
+
+func publishPsbt(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 2 {
+		return cli.ShowCommandHelp(ctx, "publishpsbt")
+	}
+
+	id := args[0]
+	if len(id) != hex.EncodedLen(lntypes.HashSize) {
+		return fmt.Errorf("invalid swap ID")
+	}
+	idBytes, err := hex.DecodeString(id)
+	if err != nil {
+		return fmt.Errorf("cannot hex decode id: %v", err)
+	}
+
+	signedPsbt, err := base64.StdEncoding.DecodeString(args[1])
+	if err != nil {
+		return fmt.Errorf("cannot base64 decode psbt: %v", err)
+	}
+
+	client, cleanup, err := getClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resp, err := client.LoopInPublishPsbt(
+		context.Background(), &looprpc.LoopInPublishPsbtRequest{
+			Id:         idBytes,
+			SignedPsbt: signedPsbt,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("HTLC published\n")
+	fmt.Printf("HTLC txid: %v\n", resp.HtlcTxid)
+
+	return nil
+}
+
+func batchIn(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) == 0 {
+		return cli.ShowCommandHelp(ctx, "batchin")
+	}
+
+	amts := make([]btcutil.Amount, len(args))
+	for i, arg := range args {
+		amt, err := parseAmt(arg)
+		if err != nil {
+			return err
+		}
+		amts[i] = amt
+	}
+
+	lastHops := ctx.StringSlice(lastHopFlag.Name)
+	if len(lastHops) > 1 && len(lastHops) != len(amts) {
+		return fmt.Errorf("last_hop must be set once or once for " +
+			"every swap")
+	}
+
+	// Validate our label early so that we can fail before getting a quote.
+	label := ctx.String(labelFlag.Name)
+	if err := labels.Validate(label); err != nil {
+		return err
+	}
+
+	client, cleanup, err := getClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	htlcConfTarget := int32(ctx.Uint64(confTargetFlag.Name))
+
+	batchReq := &looprpc.LoopInBatchRequest{
+		Requests:       make([]*looprpc.LoopInRequest, len(amts)),
+		HtlcConfTarget: htlcConfTarget,
+	}
+	for i, amt := range amts {
+		quote, err := client.GetLoopInQuote(
+			context.Background(),
+			&looprpc.QuoteRequest{
+				Amt:        int64(amt),
+				ConfTarget: htlcConfTarget,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		// The quote is based on an individual htlc tx, so it is an
+		// upper bound for the share of the batch tx fee.
+		if quote.HtlcPublishFeeSat ==
+			int64(loop.MinerFeeEstimationFailed) {
+
+			return fmt.Errorf("miner fee estimation not " +
+				"possible, lnd has insufficient funds to " +
+				"create a sample transaction for selected " +
+				"amount")
+		}
+
+		limits := getInLimits(quote)
+		err = displayInLimits(
+			amt, btcutil.Amount(quote.HtlcPublishFeeSat), limits,
+			false,
+		)
+		if err != nil {
+			return err
+		}
+
+		req := &looprpc.LoopInRequest{
+			Amt:         int64(amt),
+			MaxMinerFee: int64(limits.maxMinerFee),
+			MaxSwapFee:  int64(limits.maxSwapFee),
+			Label:       label,
+			Initiator:   defaultInitiator,
+		}
+
+		var lastHopStr string
+		switch len(lastHops) {
+		case 0:
+		case 1:
+			lastHopStr = lastHops[0]
+		default:
+			lastHopStr = lastHops[i]
+		}
+
+		if lastHopStr != "" {
+			lastHop, err := route.NewVertexFromStr(lastHopStr)
+			if err != nil {
+				return err
+			}
+
+			req.LastHop = lastHop[:]
+		}
+
+		batchReq.Requests[i] = req
+	}
+
+	resp, err := client.LoopInBatch(context.Background(), batchReq)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Swaps initiated\n")
+	fmt.Printf("HTLC txid: %v\n", resp.HtlcTxid)
+	for _, swapResp := range resp.Swaps {
+		fmt.Println()
+		fmt.Printf("ID:           %v\n", swapResp.Id)
+		fmt.Printf("HTLC address (P2WSH): %v\n",
+			swapResp.HtlcAddressP2Wsh)
+		if swapResp.ServerMessage != "" {
+			fmt.Printf("Server message: %v\n",
+				swapResp.ServerMessage)
+		}
+	}
 	fmt.Println()
 	fmt.Printf("Run `loop monitor` to monitor progress.\n")
 
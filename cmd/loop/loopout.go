@@ -12,6 +12,7 @@ import (
 	"github.com/lightninglabs/loop/labels"
 	"github.com/lightninglabs/loop/loopdb"
 	"github.com/lightninglabs/loop/looprpc"
+	"github.com/lightningnetwork/lnd/routing/route"
 	"github.com/urfave/cli"
 )
 
@@ -33,12 +34,24 @@ var loopOutCommand = cli.Command{
 			Usage: "the comma-separated list of short " +
 				"channel IDs of the channels to loop out",
 		},
+		cli.StringFlag{
+			Name: "peer",
+			Usage: "the pubkey of a peer to loop out from, all " +
+				"active channels with this peer may be used",
+		},
 		cli.StringFlag{
 			Name: "addr",
 			Usage: "the optional address that the looped out funds " +
 				"should be sent to, if let blank the funds " +
 				"will go to lnd's wallet",
 		},
+		cli.StringSliceFlag{
+			Name: "sweep_dest",
+			Usage: "a destination in the format address:weight " +
+				"that receives a share of the looped out " +
+				"funds according to its weight, may be set " +
+				"multiple times, cannot be combined with addr",
+		},
 		cli.Uint64Flag{
 			Name:  "amt",
 			Usage: "the amount in satoshis to loop out",
@@ -73,6 +86,17 @@ var loopOutCommand = cli.Command{
 				"Not setting this flag therefore might " +
 				"result in a lower swap fee.",
 		},
+		cli.StringFlag{
+			Name: "last_hop",
+			Usage: "the optional pubkey of the last hop that the " +
+				"off-chain swap payment must be routed through",
+		},
+		cli.Uint64Flag{
+			Name: "cltv_limit",
+			Usage: "the optional maximum total time lock of the " +
+				"off-chain payment routes, if not specified " +
+				"lnd's default limit will be used",
+		},
 		labelFlag,
 	},
 	Action: loopOut,
@@ -114,6 +138,20 @@ func loopOut(ctx *cli.Context) error {
 		}
 	}
 
+	var outgoingPeer []byte
+	if ctx.IsSet("peer") {
+		if ctx.IsSet("channel") {
+			return fmt.Errorf("channel and peer cannot both be set")
+		}
+
+		peer, err := route.NewVertexFromStr(ctx.String("peer"))
+		if err != nil {
+			return err
+		}
+
+		outgoingPeer = peer[:]
+	}
+
 	// Validate our label early so that we can fail before getting a quote.
 	label := ctx.String(labelFlag.Name)
 	if err := labels.Validate(label); err != nil {
@@ -128,6 +166,20 @@ func loopOut(ctx *cli.Context) error {
 		destAddr = args.First()
 	}
 
+	var sweepDests []*looprpc.SweepDestination
+	for _, destStr := range ctx.StringSlice("sweep_dest") {
+		if destAddr != "" {
+			return fmt.Errorf("addr and sweep_dest cannot both be " +
+				"set")
+		}
+
+		dest, err := parseSweepDestination(destStr)
+		if err != nil {
+			return err
+		}
+		sweepDests = append(sweepDests, dest)
+	}
+
 	client, cleanup, err := getClient(ctx)
 	if err != nil {
 		return err
@@ -182,7 +234,7 @@ func loopOut(ctx *cli.Context) error {
 		return err
 	}
 
-	resp, err := client.LoopOut(context.Background(), &looprpc.LoopOutRequest{
+	req := &looprpc.LoopOutRequest{
 		Amt:                     int64(amt),
 		Dest:                    destAddr,
 		MaxMinerFee:             int64(limits.maxMinerFee),
@@ -191,12 +243,26 @@ func loopOut(ctx *cli.Context) error {
 		MaxPrepayRoutingFee:     int64(limits.maxPrepayRoutingFee),
 		MaxSwapRoutingFee:       int64(limits.maxSwapRoutingFee),
 		OutgoingChanSet:         outgoingChanSet,
+		OutgoingPeer:            outgoingPeer,
 		SweepConfTarget:         sweepConfTarget,
 		HtlcConfirmations:       htlcConfs,
 		SwapPublicationDeadline: uint64(swapDeadline.Unix()),
 		Label:                   label,
 		Initiator:               defaultInitiator,
-	})
+		PaymentCltvLimit:        int32(ctx.Uint64("cltv_limit")),
+		SweepDestinations:       sweepDests,
+	}
+
+	if ctx.IsSet("last_hop") {
+		lastHop, err := route.NewVertexFromStr(ctx.String("last_hop"))
+		if err != nil {
+			return err
+		}
+
+		req.LastHop = lastHop[:]
+	}
+
+	resp, err := client.LoopOut(context.Background(), req)
 	if err != nil {
 		return err
 	}
@@ -212,3 +278,26 @@ func loopOut(ctx *cli.Context) error {
 
 	return nil
 }
+
+// parseSweepDestination parses a sweep destination in the format
+// address:weight.
+func parseSweepDestination(destStr string) (*looprpc.SweepDestination,
+	error) {
+
+	sep := strings.LastIndex(destStr, ":")
+	if sep < 0 {
+		return nil, fmt.Errorf("sweep destination %v not in format "+
+			"address:weight", destStr)
+	}
+
+	weight, err := strconv.ParseUint(destStr[sep+1:], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing weight of sweep "+
+			"destination %v: %v", destStr, err)
+	}
+
+	return &looprpc.SweepDestination{
+		Address: destStr[:sep],
+		Weight:  uint32(weight),
+	}, nil
+}
@@ -130,10 +130,11 @@ func main() {
 		macaroonPathFlag,
 	}
 	app.Commands = []cli.Command{
-		loopOutCommand, loopInCommand, termsCommand,
-		monitorCommand, quoteCommand, listAuthCommand,
+		loopOutCommand, loopInCommand, batchInCommand, publishPsbtCommand,
+		termsCommand, monitorCommand, quoteCommand, listAuthCommand,
 		listSwapsCommand, swapInfoCommand, getLiquidityParamsCommand,
 		setLiquidityRuleCommand, suggestSwapCommand, setParamsCommand,
+		backupDBCommand,
 	}
 
 	err := app.Run(os.Args)
@@ -349,6 +350,12 @@ func logSwap(swap *looprpc.SwapStatus) {
 		swapState = fmt.Sprintf("%v (%v)", swapState, swap.FailureReason)
 	}
 
+	// Point out that the swap went back to an earlier state because of a
+	// reorg.
+	if swap.Reorged {
+		swapState = fmt.Sprintf("%v (reorged)", swapState)
+	}
+
 	if swap.Type == looprpc.SwapType_LOOP_OUT {
 		fmt.Printf("%v %v %v %v - %v",
 			time.Unix(0, swap.LastUpdateTime).Format(time.RFC3339),
@@ -375,6 +382,8 @@ func logSwap(swap *looprpc.SwapStatus) {
 		fmt.Printf(" (cost: server %v, onchain %v, offchain %v)",
 			swap.CostServer, swap.CostOnchain, swap.CostOffchain,
 		)
+	} else if swap.SweepFee != 0 {
+		fmt.Printf(" (sweep fee %v)", btcutil.Amount(swap.SweepFee))
 	}
 
 	fmt.Println()
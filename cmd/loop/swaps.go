@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"math"
+	"time"
 
 	"github.com/lightninglabs/loop/looprpc"
 	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/routing/route"
 	"github.com/urfave/cli"
 )
 
@@ -14,11 +17,126 @@ var listSwapsCommand = cli.Command{
 	Name:  "listswaps",
 	Usage: "list all swaps in the local database",
 	Description: "Allows the user to get a list of all swaps that are " +
-		"currently stored in the database",
+		"currently stored in the database, optionally filtered " +
+		"and split into pages",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "loop_out_only",
+			Usage: "only list loop out swaps",
+		},
+		cli.BoolFlag{
+			Name:  "loop_in_only",
+			Usage: "only list loop in swaps",
+		},
+		cli.StringSliceFlag{
+			Name: "state",
+			Usage: "only list swaps that are in a state of this " +
+				"type, one of pending, succeeded or failed, " +
+				"may be set multiple times",
+		},
+		cli.Int64Flag{
+			Name: "start_time",
+			Usage: "only list swaps that were initiated at or " +
+				"after this unix timestamp",
+		},
+		cli.Int64Flag{
+			Name: "end_time",
+			Usage: "only list swaps that were initiated before " +
+				"this unix timestamp",
+		},
+		cli.StringFlag{
+			Name:  "label",
+			Usage: "only list swaps with this label",
+		},
+		cli.Uint64Flag{
+			Name: "channel",
+			Usage: "only list loop out swaps that may use this " +
+				"channel for their swap payment",
+		},
+		cli.StringFlag{
+			Name:  "last_hop",
+			Usage: "only list swaps with this last hop pubkey",
+		},
+		cli.StringFlag{
+			Name:  "initiator",
+			Usage: "only list swaps with this initiator",
+		},
+		cli.Uint64Flag{
+			Name: "max_swaps",
+			Usage: "the maximum number of swaps to list, if set " +
+				"the response contains a cursor for the next " +
+				"page if there are more swaps",
+		},
+		cli.StringFlag{
+			Name: "cursor",
+			Usage: "the next_cursor of a previous response to " +
+				"continue listing from",
+		},
+		cli.BoolFlag{
+			Name: "include_archived",
+			Usage: "also list completed swaps that have been " +
+				"archived",
+		},
+	},
 	Action: listSwaps,
 }
 
 func listSwaps(ctx *cli.Context) error {
+	if ctx.Bool("loop_out_only") && ctx.Bool("loop_in_only") {
+		return fmt.Errorf("only one of loop_out_only and " +
+			"loop_in_only can be set")
+	}
+
+	filter := &looprpc.ListSwapsFilter{
+		StartTimeNs:    ctx.Int64("start_time") * int64(time.Second),
+		EndTimeNs:      ctx.Int64("end_time") * int64(time.Second),
+		Label:          ctx.String("label"),
+		OutgoingChanId: ctx.Uint64("channel"),
+		Initiator:      ctx.String("initiator"),
+	}
+
+	switch {
+	case ctx.Bool("loop_out_only"):
+		filter.SwapType = looprpc.ListSwapsFilter_LOOP_OUT
+
+	case ctx.Bool("loop_in_only"):
+		filter.SwapType = looprpc.ListSwapsFilter_LOOP_IN
+	}
+
+	for _, state := range ctx.StringSlice("state") {
+		var stateType looprpc.ListSwapsFilter_StateTypeFilter
+		switch state {
+		case "pending":
+			stateType = looprpc.ListSwapsFilter_PENDING
+
+		case "succeeded":
+			stateType = looprpc.ListSwapsFilter_SUCCEEDED
+
+		case "failed":
+			stateType = looprpc.ListSwapsFilter_FAILED
+
+		default:
+			return fmt.Errorf("unknown state type: %v", state)
+		}
+
+		filter.StateTypes = append(filter.StateTypes, stateType)
+	}
+
+	if ctx.IsSet("last_hop") {
+		lastHop, err := route.NewVertexFromStr(ctx.String("last_hop"))
+		if err != nil {
+			return err
+		}
+
+		filter.LastHop = lastHop[:]
+	}
+
+	maxSwaps := ctx.Uint64("max_swaps")
+	if maxSwaps > math.MaxUint32 {
+		return fmt.Errorf("max_swaps must be at most %v",
+			uint32(math.MaxUint32))
+	}
+
 	client, cleanup, err := getClient(ctx)
 	if err != nil {
 		return err
@@ -26,7 +144,12 @@ func listSwaps(ctx *cli.Context) error {
 	defer cleanup()
 
 	resp, err := client.ListSwaps(
-		context.Background(), &looprpc.ListSwapsRequest{},
+		context.Background(), &looprpc.ListSwapsRequest{
+			Filter:          filter,
+			Cursor:          ctx.String("cursor"),
+			MaxSwaps:        uint32(maxSwaps),
+			IncludeArchived: ctx.Bool("include_archived"),
+		},
 	)
 	if err != nil {
 		return err
@@ -41,7 +164,8 @@ var swapInfoCommand = cli.Command{
 	Usage:     "show the status of a swap",
 	ArgsUsage: "id",
 	Description: "Allows the user to get the status of a single swap " +
-		"currently stored in the database",
+		"currently stored in the database, along with the " +
+		"history of its state transitions",
 	Flags: []cli.Flag{
 		cli.Uint64Flag{
 			Name:  "id",
@@ -10,6 +10,7 @@ import (
 	"github.com/lightninglabs/lndclient"
 	"github.com/lightninglabs/loop/loopdb"
 	"github.com/lightninglabs/loop/sweep"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 	"github.com/lightningnetwork/lnd/queue"
 )
 
@@ -24,6 +25,21 @@ type executorConfig struct {
 	createExpiryTimer func(expiry time.Duration) <-chan time.Time
 
 	loopOutMaxParts uint32
+
+	// batchSweeps indicates whether loop out htlcs are swept in batches.
+	batchSweeps bool
+
+	// maxSweepFeeMultiplier is the factor by which the max miner fee of
+	// a loop out swap is raised at most once its preimage was revealed.
+	maxSweepFeeMultiplier float64
+
+	// timeoutMaxFeeRate is the fee rate that loop in timeout txes are
+	// bumped to at most.
+	timeoutMaxFeeRate chainfee.SatPerKWeight
+
+	// reorgSafetyDepth is the number of confirmations that htlc spends
+	// are tracked for to detect reorgs.
+	reorgSafetyDepth uint32
 }
 
 // executor is responsible for executing swaps.
@@ -34,16 +50,26 @@ type executor struct {
 	newSwaps      chan genericSwap
 	currentHeight uint32
 	ready         chan struct{}
+	batcher       *sweepBatcher
 
 	executorConfig
 }
 
 // newExecutor returns a new swap executor instance.
 func newExecutor(cfg *executorConfig) *executor {
+	var batcher *sweepBatcher
+	if cfg.batchSweeps {
+		batcher = newSweepBatcher(
+			cfg.lnd, cfg.store, cfg.sweeper,
+			cfg.createExpiryTimer,
+		)
+	}
+
 	return &executor{
 		executorConfig: *cfg,
 		newSwaps:       make(chan genericSwap),
 		ready:          make(chan struct{}),
+		batcher:        batcher,
 	}
 }
 
@@ -81,6 +107,19 @@ func (s *executor) run(mainCtx context.Context,
 	// Start main event loop.
 	log.Infof("Starting event loop at height %v", height)
 
+	// Start the sweep batcher if sweep batching is enabled.
+	if s.batcher != nil {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+
+			err := s.batcher.run(mainCtx)
+			if err != nil && err != context.Canceled {
+				log.Errorf("Sweep batcher terminated: %v", err)
+			}
+		}()
+	}
+
 	// Signal that executor being ready with an up to date block height.
 	close(s.ready)
 
@@ -111,11 +150,15 @@ func (s *executor) run(mainCtx context.Context,
 				defer s.wg.Done()
 
 				newSwap.execute(mainCtx, &executeConfig{
-					statusChan:      statusChan,
-					sweeper:         s.sweeper,
-					blockEpochChan:  queue.ChanOut(),
-					timerFactory:    s.executorConfig.createExpiryTimer,
-					loopOutMaxParts: s.executorConfig.loopOutMaxParts,
+					statusChan:            statusChan,
+					sweeper:               s.sweeper,
+					batcher:               s.batcher,
+					blockEpochChan:        queue.ChanOut(),
+					timerFactory:          s.executorConfig.createExpiryTimer,
+					loopOutMaxParts:       s.executorConfig.loopOutMaxParts,
+					maxSweepFeeMultiplier: s.executorConfig.maxSweepFeeMultiplier,
+					timeoutMaxFeeRate:     s.executorConfig.timeoutMaxFeeRate,
+					reorgSafetyDepth:      s.executorConfig.reorgSafetyDepth,
 				}, height)
 
 				select {
@@ -4,6 +4,7 @@ require (
 	github.com/btcsuite/btcd v0.21.0-beta.0.20201208033208-6bd4c64a54fa
 	github.com/btcsuite/btclog v0.0.0-20170628155309-84c8d2346e9f
 	github.com/btcsuite/btcutil v1.0.2
+	github.com/btcsuite/btcutil/psbt v1.0.3-0.20200826194809-5f93e33af2b0
 	github.com/btcsuite/btcwallet/wtxmgr v1.2.0
 	github.com/coreos/bbolt v1.3.3
 	github.com/fortytw2/leaktest v1.3.0
@@ -19,11 +20,13 @@ require (
 	github.com/lightningnetwork/lnd/ticker v1.0.0
 	github.com/stretchr/testify v1.5.1
 	github.com/urfave/cli v1.20.0
-	golang.org/x/net v0.0.0-20191002035440-2ec189313ef0
+	golang.org/x/crypto v0.0.0-20200709230013-948cd5f35899
+	golang.org/x/net v0.0.0-20201021035429-f5854403a974
 	google.golang.org/genproto v0.0.0-20190927181202-20e1ac93f88c
 	google.golang.org/grpc v1.24.0
 	gopkg.in/macaroon-bakery.v2 v2.0.1
 	gopkg.in/macaroon.v2 v2.1.0
+	modernc.org/sqlite v1.10.6
 )
 
 go 1.13
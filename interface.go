@@ -3,7 +3,10 @@ package loop
 import (
 	"time"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/psbt"
 	"github.com/lightninglabs/loop/loopdb"
 	"github.com/lightninglabs/loop/swap"
 	"github.com/lightningnetwork/lnd/lntypes"
@@ -19,6 +22,13 @@ type OutRequest struct {
 	// Destination address for the swap.
 	DestAddr btcutil.Address
 
+	// SweepDestinations optionally splits the swept value across multiple
+	// addresses according to their weights. The sweep fee is deducted from
+	// the destinations in proportion to their weights. If set, DestAddr
+	// must not be set, the first destination is used as the destination
+	// address of the swap instead.
+	SweepDestinations []loopdb.SweepDestination
+
 	// MaxSwapRoutingFee is the maximum off-chain fee in msat that may be
 	// paid for payment to the server. This limit is applied during path
 	// finding. Typically this value is taken from the response of the
@@ -72,6 +82,14 @@ type OutRequest struct {
 	// channels that may be used to loop out.
 	OutgoingChanSet loopdb.ChannelSet
 
+	// LastHop optionally specifies the last hop that the swap payment must
+	// be routed through.
+	LastHop *route.Vertex
+
+	// PaymentCltvLimit optionally limits the total time lock of the swap
+	// and prepay payment routes. If zero, no limit is applied.
+	PaymentCltvLimit int32
+
 	// SwapPublicationDeadline can be set by the client to allow the server
 	// delaying publication of the swap HTLC to save on chain fees.
 	SwapPublicationDeadline time.Time
@@ -206,6 +224,30 @@ type LoopInRequest struct {
 	// initiated the swap (loop CLI, autolooper, LiT UI and so on) and is
 	// appended to the user agent string.
 	Initiator string
+
+	// HtlcFundingOutpoints optionally specifies the wallet outputs that
+	// fund the htlc. If empty, the wallet selects the outputs. Explicit
+	// outputs cannot be used for external htlcs or batches.
+	HtlcFundingOutpoints []wire.OutPoint
+
+	// ChangeAddr optionally specifies the address that receives the
+	// change of the htlc funding tx if HtlcFundingOutpoints is set. If
+	// nil, a new wallet address is used.
+	ChangeAddr btcutil.Address
+}
+
+// LoopInBatchRequest contains the parameters for a batch of loop in swaps
+// whose htlcs are funded in a single transaction.
+type LoopInBatchRequest struct {
+	// Requests contains the parameters of the individual swaps. External
+	// htlcs are not supported in a batch. The HtlcConfTarget of the
+	// individual requests is overridden by the HtlcConfTarget of the
+	// batch.
+	Requests []*LoopInRequest
+
+	// HtlcConfTarget specifies the targeted confirmation target for the
+	// tx that funds all htlcs.
+	HtlcConfTarget int32
 }
 
 // LoopInTerms are the server terms on which it executes loop in swaps.
@@ -277,6 +319,22 @@ type LoopInSwapInfo struct { // nolint
 	// ServerMessages is the human-readable message received from the loop
 	// server.
 	ServerMessage string
+
+	// HtlcPsbt is an unsigned psbt that pays the swap amount to the native
+	// segwit htlc. It is only set for swaps with an external htlc.
+	HtlcPsbt *psbt.Packet
+}
+
+// LoopInBatchInfo contains essential information of a batch of loop-in swaps
+// after their htlcs have been published.
+type LoopInBatchInfo struct {
+	// Swaps contains the information of the individual swaps, in the
+	// order of the batch requests.
+	Swaps []*LoopInSwapInfo
+
+	// HtlcTxHash is the hash of the tx that funds the htlcs of all
+	// swaps.
+	HtlcTxHash chainhash.Hash
 }
 
 // LoopOutSwapInfo contains essential information of a loop-out swap after the
@@ -329,6 +387,15 @@ type SwapInfo struct {
 
 	// ExternalHtlc is set to true for external loop-in swaps.
 	ExternalHtlc bool
+
+	// Reorged is set on the update that is sent when a transaction of the
+	// swap was removed from the chain by a reorg and the swap reverted to
+	// an earlier state.
+	Reorged bool
+
+	// Archived is set for completed swaps that have been moved to the
+	// archive of the swap store.
+	Archived bool
 }
 
 // LastUpdate returns the last update time of the swap
@@ -7,13 +7,25 @@ const (
 	// transactions in the lnd backend.
 	loopdLabelPattern = "loopd -- %s(swap=%s)"
 
+	// loopdBatchLabelPattern is the pattern that loop uses to label
+	// on-chain transactions that belong to multiple swaps.
+	loopdBatchLabelPattern = "loopd -- %s(swaps=%d)"
+
 	// loopOutSweepSuccess is the label used for loop out swaps to sweep
 	// the HTLC in the success case.
 	loopOutSweepSuccess = "OutSweepSuccess"
 
+	// loopOutBatchSweepSuccess is the label used for loop out swaps that
+	// sweep their HTLCs in a single batch tx in the success case.
+	loopOutBatchSweepSuccess = "OutBatchSweepSuccess"
+
 	// loopInHtlc is the label used for loop in swaps to publish an HTLC.
 	loopInHtlc = "InHtlc"
 
+	// loopInBatchHtlc is the label used for loop in swaps that publish
+	// their HTLCs in a single batch tx.
+	loopInBatchHtlc = "InBatchHtlc"
+
 	// loopInTimeout is the label used for loop in swaps to sweep an HTLC
 	// that has timed out.
 	loopInSweepTimeout = "InSweepTimeout"
@@ -25,11 +37,25 @@ func LoopOutSweepSuccess(swapHash string) string {
 	return fmt.Sprintf(loopdLabelPattern, loopOutSweepSuccess, swapHash)
 }
 
+// LoopOutBatchSweepSuccess returns the label used for loop out swaps that
+// sweep their HTLCs in a single batch tx in the success case.
+func LoopOutBatchSweepSuccess(numSwaps int) string {
+	return fmt.Sprintf(
+		loopdBatchLabelPattern, loopOutBatchSweepSuccess, numSwaps,
+	)
+}
+
 // LoopInHtlcLabel returns the label used for loop in swaps to publish an HTLC.
 func LoopInHtlcLabel(swapHash string) string {
 	return fmt.Sprintf(loopdLabelPattern, loopInHtlc, swapHash)
 }
 
+// LoopInBatchHtlcLabel returns the label used for loop in swaps that publish
+// their HTLCs in a single batch tx.
+func LoopInBatchHtlcLabel(numSwaps int) string {
+	return fmt.Sprintf(loopdBatchLabelPattern, loopInBatchHtlc, numSwaps)
+}
+
 // LoopInSweepTimeout returns the label used for loop in swaps to sweep an HTLC
 // that has timed out.
 func LoopInSweepTimeout(swapHash string) string {
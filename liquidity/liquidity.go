@@ -547,6 +547,22 @@ func (m *Manager) GetParameters() Parameters {
 	return cloneParameters(m.params)
 }
 
+// ArchiveCutoff returns the time from which completed swaps are still used to
+// suggest swaps. Swaps that completed before this time no longer affect our
+// failure backoff or, when autoloop is enabled, our autoloop budget, so they
+// may be archived.
+func (m *Manager) ArchiveCutoff() time.Time {
+	m.paramsLock.Lock()
+	defer m.paramsLock.Unlock()
+
+	cutoff := m.cfg.Clock.Now().Add(m.params.FailureBackOff * -1)
+	if m.params.Autoloop && m.params.AutoFeeStartDate.Before(cutoff) {
+		cutoff = m.params.AutoFeeStartDate
+	}
+
+	return cutoff
+}
+
 // SetParameters updates our current set of parameters if the new parameters
 // provided are valid.
 func (m *Manager) SetParameters(ctx context.Context, params Parameters) error {
@@ -198,6 +198,32 @@ func TestParameters(t *testing.T) {
 	require.Equal(t, ErrZeroChannelID, err)
 }
 
+// TestArchiveCutoff tests that swaps are only archived once they no longer
+// affect our failure backoff or autoloop budget.
+func TestArchiveCutoff(t *testing.T) {
+	cfg, _ := newTestConfig()
+	manager := NewManager(cfg)
+
+	backoffCutoff := testTime.Add(defaultFailureBackoff * -1)
+	require.Equal(t, backoffCutoff, manager.ArchiveCutoff())
+
+	// Our budget start date doesn't matter while autoloop is disabled.
+	manager.params.AutoFeeStartDate = backoffCutoff.Add(time.Hour * -1)
+	require.Equal(t, backoffCutoff, manager.ArchiveCutoff())
+
+	// Once autoloop is enabled, swaps that count towards our budget are
+	// kept.
+	manager.params.Autoloop = true
+	require.Equal(
+		t, manager.params.AutoFeeStartDate, manager.ArchiveCutoff(),
+	)
+
+	// A budget that started after our backoff cutoff doesn't need any
+	// additional swaps.
+	manager.params.AutoFeeStartDate = backoffCutoff.Add(time.Hour)
+	require.Equal(t, backoffCutoff, manager.ArchiveCutoff())
+}
+
 // TestValidateRestrictions tests validating client restrictions against a set
 // of server restrictions.
 func TestValidateRestrictions(t *testing.T) {
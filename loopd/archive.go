@@ -0,0 +1,59 @@
+package loopd
+
+import (
+	"context"
+	"time"
+)
+
+// archiveInterval is the interval at which completed swaps are checked for
+// archival.
+const archiveInterval = time.Hour
+
+// archiveSwaps periodically archives the completed swaps that are older than
+// the configured archive age until the context is canceled.
+func (d *Daemon) archiveSwaps(ctx context.Context) {
+	archiveTicker := time.NewTicker(archiveInterval)
+	defer archiveTicker.Stop()
+
+	for {
+		d.archiveCompletedSwaps()
+
+		select {
+		case <-archiveTicker.C:
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// archiveCompletedSwaps archives the completed swaps that are older than the
+// configured archive age and no longer needed by the liquidity manager, and
+// removes them from the swap cache of the rpc server.
+func (d *Daemon) archiveCompletedSwaps() {
+	cutoff := time.Now().Add(d.cfg.ArchiveAge * -1)
+
+	liquidityCutoff := d.liquidityMgr.ArchiveCutoff()
+	if liquidityCutoff.Before(cutoff) {
+		cutoff = liquidityCutoff
+	}
+
+	archived, err := d.impl.ArchiveSwaps(cutoff)
+	if err != nil {
+		log.Errorf("Unable to archive swaps: %v", err)
+		return
+	}
+
+	if len(archived) == 0 {
+		return
+	}
+
+	d.swapsLock.Lock()
+	for _, hash := range archived {
+		delete(d.swaps, hash)
+	}
+	d.swapsLock.Unlock()
+
+	log.Infof("Archived %v swaps that completed before %v", len(archived),
+		cutoff)
+}
@@ -0,0 +1,48 @@
+package loopd
+
+import (
+	"fmt"
+
+	"github.com/lightninglabs/lndclient"
+	"github.com/lightninglabs/loop/loopdb"
+)
+
+// checkDB checks all swaps in the database in the data directory and prints
+// the problems that are found. An error is returned if any problem remains
+// that wasn't repaired.
+func checkDB(config *Config) error {
+	network := lndclient.Network(config.Network)
+
+	chainParams, err := network.ChainParams()
+	if err != nil {
+		return err
+	}
+
+	report, err := loopdb.CheckDatabase(
+		loopdb.Backend(config.DatabaseBackend), config.DataDir,
+		chainParams, config.CheckDB.Repair,
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Checked %v loop out and %v loop in swaps in %v database "+
+		"version %v\n", report.LoopOuts, report.LoopIns,
+		report.Backend, report.Version)
+
+	var unrepaired int
+	for _, problem := range report.Problems {
+		fmt.Println(problem)
+
+		if !problem.Repaired {
+			unrepaired++
+		}
+	}
+
+	if unrepaired == 0 {
+		fmt.Println("No problems remaining")
+		return nil
+	}
+
+	return fmt.Errorf("%v problems found", unrepaired)
+}
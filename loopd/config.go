@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop"
+	"github.com/lightninglabs/loop/loopdb"
 	"github.com/lightninglabs/loop/lsat"
 	"github.com/lightningnetwork/lnd/cert"
 	"github.com/lightningnetwork/lnd/lncfg"
@@ -37,6 +39,24 @@ var (
 	defaultMaxLogFileSize  = 10
 	defaultLoopOutMaxParts = uint32(5)
 
+	// defaultLoopInTimeoutMaxFeeRate is the default fee rate in sat/vbyte
+	// that loop in timeout txes are bumped to at most.
+	defaultLoopInTimeoutMaxFeeRate = uint64(100)
+
+	// defaultReorgSafetyDepth is the default number of confirmations that
+	// htlc spends are tracked for to detect reorgs.
+	defaultReorgSafetyDepth = uint32(3)
+
+	// defaultRecoverTimeout is the default time that the recover command
+	// waits for the htlc of a swap to be found on chain.
+	defaultRecoverTimeout = time.Minute
+
+	// minArchiveAge is the minimum age of completed swaps that may be
+	// archived. It leaves enough time for the txes of a swap to be tracked
+	// for reorgs after the swap completed, as archived swaps can't be
+	// updated anymore.
+	minArchiveAge = time.Hour * 24
+
 	// DefaultTLSCertFilename is the default file name for the autogenerated
 	// TLS certificate.
 	DefaultTLSCertFilename = "tls.cert"
@@ -101,6 +121,23 @@ type loopServerConfig struct {
 
 type viewParameters struct{}
 
+type migrateDBParameters struct{}
+
+type removePendingTokenParameters struct{}
+
+type checkDBParameters struct {
+	Repair bool `long:"repair" description:"Move swaps that can't be read to a quarantine bucket, so that loopd can load the remaining swaps again. Only supported for bbolt databases."`
+}
+
+type recoverParameters struct {
+	SwapHash   string        `long:"swaphash" description:"The hash of the swap to recover." required:"true"`
+	Backup     string        `long:"backup" description:"Read the swap from this encrypted swap backup instead of the database."`
+	Addr       string        `long:"addr" description:"The address to sweep the htlc to. Defaults to the destination of a loop out and a new wallet address for a loop in."`
+	ConfTarget int32         `long:"conftarget" description:"The confirmation target that the sweep fee is estimated for."`
+	Publish    bool          `long:"publish" description:"Publish the sweep transaction. If not set, the signed transaction is only printed."`
+	Timeout    time.Duration `long:"timeout" description:"The maximum time to wait for the htlc to be found on chain."`
+}
+
 type Config struct {
 	ShowVersion bool   `long:"version" description:"Display version information and exit"`
 	Network     string `long:"network" description:"network to run on" choice:"regtest" choice:"testnet" choice:"mainnet" choice:"simnet"`
@@ -131,11 +168,36 @@ type Config struct {
 
 	LoopOutMaxParts uint32 `long:"loopoutmaxparts" description:"The maximum number of payment parts that may be used for a loop out swap."`
 
+	BatchSweeps bool `long:"batchsweeps" description:"Sweep the htlcs of multiple loop out swaps in a single transaction. Swaps that are close to their expiry are always swept individually."`
+
+	LoopOutMaxSweepFeeMultiplier float64 `long:"loopoutmaxsweepfeemultiplier" description:"The factor by which the max miner fee of a loop out swap may be exceeded at most when the fee of its sweep is bumped as the htlc expiry approaches, after the preimage was revealed. Set to 1 to never exceed the max miner fee."`
+
+	LoopInTimeoutMaxFeeRate uint64 `long:"loopintimeoutmaxfeerate" description:"The maximum fee rate in sat/vbyte that the timeout tx of an expired loop in swap is bumped to while it doesn't confirm."`
+
+	ReorgSafetyDepth uint32 `long:"reorgsafetydepth" description:"The number of confirmations that the tx spending a swap htlc is tracked for after the swap reached its outcome. If the tx is reorged out before reaching this depth, the swap reverts to its previous state and the tx is republished. Set to 1 to disable reorg tracking."`
+
+	BackupFile    string `long:"backupfile" description:"Path of the encrypted backup of pending swaps that is updated on every swap change. Defaults to a file in the data directory."`
+	RestoreBackup string `long:"restorebackup" description:"Path of a swap backup to restore swaps from on startup. Swaps that already exist in the database are skipped."`
+
+	ArchiveAge time.Duration `long:"archiveage" description:"Archive completed swaps once their last update is older than this duration, so that they are no longer read on startup and by autoloop. Archived swaps can still be listed with listswaps --include_archived. Swaps that still count towards the autoloop budget or failure backoff are kept. Set to 0 to disable archiving."`
+
+	DatabaseBackend string `long:"databasebackend" description:"The database backend that swaps are stored in. An existing bbolt database must be migrated with the migratedb command before switching to sqlite." choice:"bbolt" choice:"sqlite"`
+
+	DryRunMigrations bool `long:"dry-run-migrations" description:"Apply the migrations that the database is missing to a copy of it, report the result and exit without starting loopd. The database itself is left untouched. Before the database is migrated on startup, a copy of it is always written to the data directory."`
+
 	Lnd *lndConfig `group:"lnd" namespace:"lnd"`
 
 	Server *loopServerConfig `group:"server" namespace:"server"`
 
 	View viewParameters `command:"view" alias:"v" description:"View all swaps in the database. This command can only be executed when loopd is not running."`
+
+	MigrateDB migrateDBParameters `command:"migratedb" description:"Copy all swaps from the bbolt database to a new sqlite database, which is used once loopd is started with databasebackend=sqlite. The bbolt database is left untouched. This command can only be executed when loopd is not running."`
+
+	CheckDB checkDBParameters `command:"check-db" description:"Check that all swaps in the database, including archived swaps, can be read and are consistent, and report the problems that are found. This command can only be executed when loopd is not running."`
+
+	RemovePendingToken removePendingTokenParameters `command:"removependingtoken" description:"Remove the LSAT token from the database, or its file with the sqlite backend, if its payment is still pending, so that a new token is paid for. Use this if the payment of the token failed. This command can only be executed when loopd is not running."`
+
+	Recover recoverParameters `command:"recover" description:"Sweep the htlc of a swap without the swap server, using the preimage of a loop out or the timeout path of an expired loop in. This command can only be executed when loopd is not running."`
 }
 
 const (
@@ -152,19 +214,27 @@ func DefaultConfig() Config {
 		Server: &loopServerConfig{
 			NoTLS: false,
 		},
-		LoopDir:         LoopDirBase,
-		ConfigFile:      defaultConfigFile,
-		DataDir:         LoopDirBase,
-		LogDir:          defaultLogDir,
-		MaxLogFiles:     defaultMaxLogFiles,
-		MaxLogFileSize:  defaultMaxLogFileSize,
-		DebugLevel:      defaultLogLevel,
-		TLSCertPath:     DefaultTLSCertPath,
-		TLSKeyPath:      DefaultTLSKeyPath,
-		MacaroonPath:    DefaultMacaroonPath,
-		MaxLSATCost:     lsat.DefaultMaxCostSats,
-		MaxLSATFee:      lsat.DefaultMaxRoutingFeeSats,
-		LoopOutMaxParts: defaultLoopOutMaxParts,
+		LoopDir:                      LoopDirBase,
+		ConfigFile:                   defaultConfigFile,
+		DataDir:                      LoopDirBase,
+		LogDir:                       defaultLogDir,
+		MaxLogFiles:                  defaultMaxLogFiles,
+		MaxLogFileSize:               defaultMaxLogFileSize,
+		DebugLevel:                   defaultLogLevel,
+		TLSCertPath:                  DefaultTLSCertPath,
+		TLSKeyPath:                   DefaultTLSKeyPath,
+		MacaroonPath:                 DefaultMacaroonPath,
+		MaxLSATCost:                  lsat.DefaultMaxCostSats,
+		MaxLSATFee:                   lsat.DefaultMaxRoutingFeeSats,
+		LoopOutMaxParts:              defaultLoopOutMaxParts,
+		LoopOutMaxSweepFeeMultiplier: loop.DefaultMaxSweepFeeMultiplier,
+		LoopInTimeoutMaxFeeRate:      defaultLoopInTimeoutMaxFeeRate,
+		ReorgSafetyDepth:             defaultReorgSafetyDepth,
+		DatabaseBackend:              string(loopdb.BackendBolt),
+		Recover: recoverParameters{
+			ConfTarget: loop.DefaultSweepConfTarget,
+			Timeout:    defaultRecoverTimeout,
+		},
 		Lnd: &lndConfig{
 			Host: "localhost:10009",
 			MacaroonPath: filepath.Join(
@@ -185,6 +255,9 @@ func Validate(cfg *Config) error {
 	cfg.TLSCertPath = lncfg.CleanAndExpandPath(cfg.TLSCertPath)
 	cfg.TLSKeyPath = lncfg.CleanAndExpandPath(cfg.TLSKeyPath)
 	cfg.MacaroonPath = lncfg.CleanAndExpandPath(cfg.MacaroonPath)
+	cfg.BackupFile = lncfg.CleanAndExpandPath(cfg.BackupFile)
+	cfg.RestoreBackup = lncfg.CleanAndExpandPath(cfg.RestoreBackup)
+	cfg.Recover.Backup = lncfg.CleanAndExpandPath(cfg.Recover.Backup)
 
 	// Since our loop directory overrides our log/data dir values, make sure
 	// that they are not set when loop dir is set. We hard here rather than
@@ -280,6 +353,16 @@ func Validate(cfg *Config) error {
 		return fmt.Errorf("must specify --lnd.macaroonpath")
 	}
 
+	if cfg.LoopOutMaxSweepFeeMultiplier < 1 {
+		return fmt.Errorf("loopoutmaxsweepfeemultiplier must be at " +
+			"least 1")
+	}
+
+	if cfg.ArchiveAge != 0 && cfg.ArchiveAge < minArchiveAge {
+		return fmt.Errorf("archiveage must be at least %v",
+			minArchiveAge)
+	}
+
 	return nil
 }
 
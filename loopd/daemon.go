@@ -425,6 +425,17 @@ func (d *Daemon) initialize() error {
 		log.Info("Liquidity manager stopped")
 	}()
 
+	if d.cfg.ArchiveAge != 0 {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+
+			log.Infof("Archiving swaps older than %v",
+				d.cfg.ArchiveAge)
+			d.archiveSwaps(d.mainCtx)
+		}()
+	}
+
 	// Last, start our internal error handler. This will return exactly one
 	// error or nil on the main error channel to inform the caller that
 	// something went wrong or that shutdown is complete. We don't add to
@@ -0,0 +1,42 @@
+package loopd
+
+import (
+	"fmt"
+
+	"github.com/lightninglabs/lndclient"
+	"github.com/lightninglabs/loop/loopdb"
+)
+
+// dryRunMigrations migrates a copy of the database in the data directory to
+// the latest version and prints the result.
+func dryRunMigrations(config *Config) error {
+	network := lndclient.Network(config.Network)
+
+	chainParams, err := network.ChainParams()
+	if err != nil {
+		return err
+	}
+
+	report, err := loopdb.DryRunMigrations(
+		loopdb.Backend(config.DatabaseBackend), config.DataDir,
+		chainParams,
+	)
+	if err != nil {
+		return err
+	}
+
+	if report.FromVersion == report.ToVersion {
+		fmt.Printf("The %v database already has the latest version "+
+			"%v, no migrations are needed\n", report.Backend,
+			report.ToVersion)
+	} else {
+		fmt.Printf("Migrated a copy of the %v database from version "+
+			"%v to %v\n", report.Backend, report.FromVersion,
+			report.ToVersion)
+	}
+
+	fmt.Printf("Read %v loop out and %v loop in swaps from the migrated "+
+		"database\n", report.LoopOuts, report.LoopIns)
+
+	return nil
+}
@@ -41,6 +41,20 @@ var (
 			Entity: "loop",
 			Action: "in",
 		}},
+		"/looprpc.SwapClient/LoopInBatch": {{
+			Entity: "swap",
+			Action: "execute",
+		}, {
+			Entity: "loop",
+			Action: "in",
+		}},
+		"/looprpc.SwapClient/LoopInPublishPsbt": {{
+			Entity: "swap",
+			Action: "execute",
+		}, {
+			Entity: "loop",
+			Action: "in",
+		}},
 		"/looprpc.SwapClient/Monitor": {{
 			Entity: "swap",
 			Action: "read",
@@ -97,6 +111,13 @@ var (
 			Entity: "suggestions",
 			Action: "write",
 		}},
+		"/looprpc.SwapClient/BackupDatabase": {{
+			Entity: "swap",
+			Action: "read",
+		}, {
+			Entity: "swap",
+			Action: "execute",
+		}},
 	}
 
 	// allPermissions is the list of all existing permissions that exist
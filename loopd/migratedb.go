@@ -0,0 +1,30 @@
+package loopd
+
+import (
+	"fmt"
+
+	"github.com/lightninglabs/lndclient"
+	"github.com/lightninglabs/loop/loopdb"
+)
+
+// migrateDB copies all swaps from the bbolt database in the data directory
+// to a new sqlite database.
+func migrateDB(config *Config) error {
+	network := lndclient.Network(config.Network)
+
+	chainParams, err := network.ChainParams()
+	if err != nil {
+		return err
+	}
+
+	migrated, err := loopdb.MigrateBoltToSqlite(config.DataDir, chainParams)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Migrated %v swaps to %v, start loopd with "+
+		"databasebackend=%v to use it\n", migrated,
+		loopdb.SqliteFileName, loopdb.BackendSqlite)
+
+	return nil
+}
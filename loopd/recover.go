@@ -0,0 +1,132 @@
+package loopd
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/lightninglabs/lndclient"
+	"github.com/lightninglabs/loop"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+// recoverSwap sweeps the htlc of a single swap without the swap server. The
+// swap is read from the database or from a swap backup.
+func recoverSwap(config *Config, lisCfg *listenerCfg) error {
+	params := config.Recover
+
+	hash, err := lntypes.MakeHashFromStr(params.SwapHash)
+	if err != nil {
+		return err
+	}
+
+	network := lndclient.Network(config.Network)
+
+	lnd, err := lisCfg.getLnd(network, config.Lnd)
+	if err != nil {
+		return err
+	}
+	defer lnd.Close()
+
+	chainParams, err := network.ChainParams()
+	if err != nil {
+		return err
+	}
+
+	req := &loop.RecoveryRequest{
+		SweepConfTarget: params.ConfTarget,
+		Publish:         params.Publish,
+	}
+	if params.Addr != "" {
+		req.DestAddr, err = swap.DecodeAddress(params.Addr, chainParams)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), params.Timeout)
+	defer cancel()
+
+	loopOuts, loopIns, err := recoverySwaps(
+		ctx, config, &lnd.LndServices, chainParams,
+	)
+	if err != nil {
+		return err
+	}
+
+	var result *loop.RecoveryResult
+	for _, s := range loopOuts {
+		if s.Hash == hash {
+			result, err = loop.RecoverLoopOut(
+				ctx, &lnd.LndServices, s, req,
+			)
+		}
+	}
+	for _, s := range loopIns {
+		if s.Hash == hash {
+			result, err = loop.RecoverLoopIn(
+				ctx, &lnd.LndServices, s, req,
+			)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return fmt.Errorf("swap %v not found", hash)
+	}
+
+	var b bytes.Buffer
+	if err := result.SweepTx.Serialize(&b); err != nil {
+		return err
+	}
+
+	fmt.Printf("Htlc: %v (%v)\n", result.HtlcOutpoint, result.HtlcValue)
+	fmt.Printf("Sweep tx: %v\n", result.SweepTx.TxHash())
+	fmt.Printf("Fee: %v\n", result.Fee)
+	fmt.Printf("Published: %v\n", result.Published)
+	fmt.Printf("Raw tx: %v\n", hex.EncodeToString(b.Bytes()))
+
+	return nil
+}
+
+// recoverySwaps returns the swaps that can be recovered, either from the
+// backup that is set in the recover parameters or from the database.
+func recoverySwaps(ctx context.Context, config *Config,
+	lnd *lndclient.LndServices, chainParams *chaincfg.Params) (
+	[]*loopdb.LoopOut, []*loopdb.LoopIn, error) {
+
+	if config.Recover.Backup != "" {
+		key, err := loop.DeriveBackupKey(ctx, lnd)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return loopdb.ReadBackup(config.Recover.Backup, key, chainParams)
+	}
+
+	store, err := loopdb.NewSwapStore(
+		loopdb.Backend(config.DatabaseBackend), config.DataDir,
+		chainParams,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer store.Close()
+
+	loopOuts, err := store.FetchLoopOutSwaps()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	loopIns, err := store.FetchLoopInSwaps()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return loopOuts, loopIns, nil
+}
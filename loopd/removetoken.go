@@ -0,0 +1,66 @@
+package loopd
+
+import (
+	"fmt"
+
+	"github.com/lightninglabs/lndclient"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightninglabs/loop/lsat"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+// removePendingToken removes the pending LSAT token from the store, so that a
+// new token is paid for once loopd is started again.
+func removePendingToken(config *Config) error {
+	var (
+		hash lntypes.Hash
+		err  error
+	)
+	if loopdb.Backend(config.DatabaseBackend) == loopdb.BackendBolt {
+		hash, err = removePendingBoltToken(config)
+	} else {
+		hash, err = removePendingFileToken(config)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed pending LSAT token %v\n", hash)
+
+	return nil
+}
+
+// removePendingBoltToken removes the pending LSAT token from the bbolt swap
+// database.
+func removePendingBoltToken(config *Config) (lntypes.Hash, error) {
+	network := lndclient.Network(config.Network)
+
+	chainParams, err := network.ChainParams()
+	if err != nil {
+		return lntypes.Hash{}, err
+	}
+
+	swapStore, err := loopdb.NewBoltSwapStore(config.DataDir, chainParams)
+	if err != nil {
+		return lntypes.Hash{}, err
+	}
+	defer swapStore.Close()
+
+	lsatStore, err := loopdb.NewBoltLsatStore(swapStore, config.DataDir)
+	if err != nil {
+		return lntypes.Hash{}, err
+	}
+
+	return lsatStore.RemovePendingToken()
+}
+
+// removePendingFileToken removes the pending LSAT token file that is used
+// next to databases that can't hold tokens.
+func removePendingFileToken(config *Config) (lntypes.Hash, error) {
+	fileStore, err := lsat.NewFileStore(config.DataDir)
+	if err != nil {
+		return lntypes.Hash{}, err
+	}
+
+	return fileStore.RemovePendingToken()
+}
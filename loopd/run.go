@@ -214,6 +214,10 @@ func Run(rpcCfg RPCConfig) error {
 	}
 
 	// Execute command.
+	if parser.Active == nil && config.DryRunMigrations {
+		return dryRunMigrations(&config)
+	}
+
 	if parser.Active == nil {
 		daemon := New(&config, lisCfg)
 		if err := daemon.Start(); err != nil {
@@ -239,6 +243,22 @@ func Run(rpcCfg RPCConfig) error {
 		return view(&config, lisCfg)
 	}
 
+	if parser.Active.Name == "migratedb" {
+		return migrateDB(&config)
+	}
+
+	if parser.Active.Name == "check-db" {
+		return checkDB(&config)
+	}
+
+	if parser.Active.Name == "removependingtoken" {
+		return removePendingToken(&config)
+	}
+
+	if parser.Active.Name == "recover" {
+		return recoverSwap(&config, lisCfg)
+	}
+
 	return fmt.Errorf("unimplemented command %v", parser.Active.Name)
 }
 
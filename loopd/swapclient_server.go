@@ -1,14 +1,22 @@
 package loopd
 
 import (
+	"bytes"
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/psbt"
 	"github.com/lightninglabs/lndclient"
 	"github.com/lightninglabs/loop"
 	"github.com/lightninglabs/loop/labels"
@@ -58,6 +66,15 @@ func (s *swapClientServer) LoopOut(ctx context.Context,
 
 	log.Infof("Loop out request received")
 
+	// The SendPayment api of the lnd version that we build against has no
+	// option to exclude nodes from the payment route, so we refuse the
+	// request rather than ignoring the restriction.
+	if len(in.ExcludedNodes) > 0 {
+		return nil, status.Error(codes.Unimplemented, "excluded_nodes "+
+			"is not supported by the lnd version loop is built "+
+			"against")
+	}
+
 	sweepConfTarget, err := validateConfTarget(
 		in.SweepConfTarget, loop.DefaultSweepConfTarget,
 	)
@@ -65,17 +82,44 @@ func (s *swapClientServer) LoopOut(ctx context.Context,
 		return nil, err
 	}
 
-	var sweepAddr btcutil.Address
-	if in.Dest == "" {
+	var (
+		sweepAddr         btcutil.Address
+		sweepDestinations []loopdb.SweepDestination
+	)
+	for _, dest := range in.SweepDestinations {
+		addr, err := swap.DecodeAddress(dest.Address, s.lnd.ChainParams)
+		if err != nil {
+			return nil, fmt.Errorf("decode sweep destination: %v",
+				err)
+		}
+
+		sweepDestinations = append(
+			sweepDestinations, loopdb.SweepDestination{
+				Addr:   addr,
+				Weight: dest.Weight,
+			},
+		)
+	}
+
+	switch {
+	case len(sweepDestinations) > 0 && in.Dest != "":
+		return nil, errors.New("dest and sweep_destinations cannot " +
+			"both be set")
+
+	// The sweep destinations take the place of the destination address.
+	case len(sweepDestinations) > 0:
+
+	case in.Dest == "":
 		// Generate sweep address if none specified.
 		var err error
 		sweepAddr, err = s.lnd.WalletKit.NextAddr(context.Background())
 		if err != nil {
 			return nil, fmt.Errorf("NextAddr error: %v", err)
 		}
-	} else {
+
+	default:
 		var err error
-		sweepAddr, err = btcutil.DecodeAddress(
+		sweepAddr, err = swap.DecodeAddress(
 			in.Dest, s.lnd.ChainParams,
 		)
 		if err != nil {
@@ -101,8 +145,23 @@ func (s *swapClientServer) LoopOut(ctx context.Context,
 		SwapPublicationDeadline: time.Unix(
 			int64(in.SwapPublicationDeadline), 0,
 		),
-		Label:     in.Label,
-		Initiator: in.Initiator,
+		Label:             in.Label,
+		Initiator:         in.Initiator,
+		PaymentCltvLimit:  in.PaymentCltvLimit,
+		SweepDestinations: sweepDestinations,
+	}
+
+	if in.PaymentCltvLimit < 0 {
+		return nil, errors.New("payment cltv limit must not be " +
+			"negative")
+	}
+
+	if in.LastHop != nil {
+		lastHop, err := route.NewVertexFromBytes(in.LastHop)
+		if err != nil {
+			return nil, err
+		}
+		req.LastHop = &lastHop
 	}
 
 	switch {
@@ -110,9 +169,34 @@ func (s *swapClientServer) LoopOut(ctx context.Context,
 		return nil, errors.New("loop_out_channel and outgoing_" +
 			"chan_ids are mutually exclusive")
 
+	case in.OutgoingPeer != nil && (in.LoopOutChannel != 0 ||
+		len(in.OutgoingChanSet) > 0):
+
+		return nil, errors.New("outgoing_peer cannot be combined " +
+			"with outgoing channels")
+
 	case in.LoopOutChannel != 0:
 		req.OutgoingChanSet = loopdb.ChannelSet{in.LoopOutChannel}
 
+	case in.OutgoingPeer != nil:
+		peer, err := route.NewVertexFromBytes(in.OutgoingPeer)
+		if err != nil {
+			return nil, err
+		}
+
+		channels, err := s.lnd.Client.ListChannels(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		req.OutgoingChanSet, err = peerChannelSet(channels, peer)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Infof("Resolved outgoing peer %v to channels %v", peer,
+			req.OutgoingChanSet)
+
 	default:
 		req.OutgoingChanSet = in.OutgoingChanSet
 	}
@@ -135,6 +219,89 @@ func (s *swapClientServer) LoopOut(ctx context.Context,
 func (s *swapClientServer) marshallSwap(loopSwap *loop.SwapInfo) (
 	*looprpc.SwapStatus, error) {
 
+	state, failureReason, err := marshallSwapState(loopSwap.State)
+	if err != nil {
+		return nil, err
+	}
+
+	var swapType looprpc.SwapType
+	var htlcAddress, htlcAddressP2WSH, htlcAddressNP2WSH string
+
+	switch loopSwap.SwapType {
+	case swap.TypeIn:
+		swapType = looprpc.SwapType_LOOP_IN
+		htlcAddressP2WSH = loopSwap.HtlcAddressP2WSH.EncodeAddress()
+
+		if loopSwap.ExternalHtlc {
+			htlcAddressNP2WSH = loopSwap.HtlcAddressNP2WSH.EncodeAddress()
+			htlcAddress = htlcAddressNP2WSH
+		} else {
+			htlcAddress = htlcAddressP2WSH
+		}
+
+	case swap.TypeOut:
+		swapType = looprpc.SwapType_LOOP_OUT
+		htlcAddressP2WSH = loopSwap.HtlcAddressP2WSH.EncodeAddress()
+		htlcAddress = htlcAddressP2WSH
+
+	default:
+		return nil, errors.New("unknown swap type")
+	}
+
+	return &looprpc.SwapStatus{
+		Amt:               int64(loopSwap.AmountRequested),
+		Id:                loopSwap.SwapHash.String(),
+		IdBytes:           loopSwap.SwapHash[:],
+		State:             state,
+		FailureReason:     failureReason,
+		InitiationTime:    loopSwap.InitiationTime.UnixNano(),
+		LastUpdateTime:    loopSwap.LastUpdate.UnixNano(),
+		HtlcAddress:       htlcAddress,
+		HtlcAddressP2Wsh:  htlcAddressP2WSH,
+		HtlcAddressNp2Wsh: htlcAddressNP2WSH,
+		Type:              swapType,
+		CostServer:        int64(loopSwap.Cost.Server),
+		CostOnchain:       int64(loopSwap.Cost.Onchain),
+		CostOffchain:      int64(loopSwap.Cost.Offchain),
+		Label:             loopSwap.Label,
+		SweepFee:          int64(loopSwap.SweepFee),
+		Reorged:           loopSwap.Reorged,
+		Initiator:         loopSwap.Initiator,
+		HtlcTxid:          marshallTxid(loopSwap.HtlcTxHash),
+		HtlcOutpoint:      marshallOutpoint(loopSwap.HtlcOutpoint),
+		HtlcConfHeight:    loopSwap.HtlcConfHeight,
+		SweepTxid:         marshallTxid(loopSwap.SweepTxHash),
+		SpendTxid:         marshallTxid(loopSwap.SpendTxHash),
+		SpendConfHeight:   loopSwap.SpendConfHeight,
+		Archived:          loopSwap.Archived,
+	}, nil
+}
+
+// marshallTxid returns the string representation of an optional txid, or an
+// empty string if it isn't set.
+func marshallTxid(txid *chainhash.Hash) string {
+	if txid == nil {
+		return ""
+	}
+
+	return txid.String()
+}
+
+// marshallOutpoint returns the txid:index representation of an optional
+// outpoint, or an empty string if it isn't set.
+func marshallOutpoint(outpoint *wire.OutPoint) string {
+	if outpoint == nil {
+		return ""
+	}
+
+	return outpoint.String()
+}
+
+// marshallSwapState converts a swap state into the rpc swap state and failure
+// reason.
+func marshallSwapState(swapState loopdb.SwapState) (looprpc.SwapState,
+	looprpc.FailureReason, error) {
+
 	var (
 		state         looprpc.SwapState
 		failureReason = looprpc.FailureReason_FAILURE_REASON_NONE
@@ -145,7 +312,7 @@ func (s *swapClientServer) marshallSwap(loopSwap *loop.SwapInfo) (
 	// previous versions where we squashed all failure reasons to a single
 	// failure state, we set a failure reason for all our different failure
 	// states, and set our failed state for all of them.
-	switch loopSwap.State {
+	switch swapState {
 	case loopdb.StateInitiated:
 		state = looprpc.SwapState_INITIATED
 
@@ -180,7 +347,7 @@ func (s *swapClientServer) marshallSwap(loopSwap *loop.SwapInfo) (
 		failureReason = looprpc.FailureReason_FAILURE_REASON_INCORRECT_AMOUNT
 
 	default:
-		return nil, fmt.Errorf("unknown swap state: %v", loopSwap.State)
+		return 0, 0, fmt.Errorf("unknown swap state: %v", swapState)
 	}
 
 	// If we have a failure reason, we have a failure state, so should use
@@ -189,47 +356,39 @@ func (s *swapClientServer) marshallSwap(loopSwap *loop.SwapInfo) (
 		state = looprpc.SwapState_FAILED
 	}
 
-	var swapType looprpc.SwapType
-	var htlcAddress, htlcAddressP2WSH, htlcAddressNP2WSH string
+	return state, failureReason, nil
+}
 
-	switch loopSwap.SwapType {
-	case swap.TypeIn:
-		swapType = looprpc.SwapType_LOOP_IN
-		htlcAddressP2WSH = loopSwap.HtlcAddressP2WSH.EncodeAddress()
+// marshallSwapEvents converts the persisted state transitions of a swap into
+// rpc swap events.
+func marshallSwapEvents(events []*loopdb.LoopEvent) ([]*looprpc.SwapEvent,
+	error) {
 
-		if loopSwap.ExternalHtlc {
-			htlcAddressNP2WSH = loopSwap.HtlcAddressNP2WSH.EncodeAddress()
-			htlcAddress = htlcAddressNP2WSH
-		} else {
-			htlcAddress = htlcAddressP2WSH
+	rpcEvents := make([]*looprpc.SwapEvent, 0, len(events))
+	for _, event := range events {
+		state, failureReason, err := marshallSwapState(event.State)
+		if err != nil {
+			return nil, err
 		}
 
-	case swap.TypeOut:
-		swapType = looprpc.SwapType_LOOP_OUT
-		htlcAddressP2WSH = loopSwap.HtlcAddressP2WSH.EncodeAddress()
-		htlcAddress = htlcAddressP2WSH
-
-	default:
-		return nil, errors.New("unknown swap type")
+		rpcEvents = append(rpcEvents, &looprpc.SwapEvent{
+			State:           state,
+			FailureReason:   failureReason,
+			Time:            event.Time.UnixNano(),
+			CostServer:      int64(event.Cost.Server),
+			CostOnchain:     int64(event.Cost.Onchain),
+			CostOffchain:    int64(event.Cost.Offchain),
+			HtlcTxid:        marshallTxid(event.HtlcTxHash),
+			SweepTxid:       marshallTxid(event.SweepTxHash),
+			SweepFee:        int64(event.SweepFee),
+			HtlcOutpoint:    marshallOutpoint(event.HtlcOutpoint),
+			HtlcConfHeight:  event.HtlcConfHeight,
+			SpendTxid:       marshallTxid(event.SpendTxHash),
+			SpendConfHeight: event.SpendConfHeight,
+		})
 	}
 
-	return &looprpc.SwapStatus{
-		Amt:               int64(loopSwap.AmountRequested),
-		Id:                loopSwap.SwapHash.String(),
-		IdBytes:           loopSwap.SwapHash[:],
-		State:             state,
-		FailureReason:     failureReason,
-		InitiationTime:    loopSwap.InitiationTime.UnixNano(),
-		LastUpdateTime:    loopSwap.LastUpdate.UnixNano(),
-		HtlcAddress:       htlcAddress,
-		HtlcAddressP2Wsh:  htlcAddressP2WSH,
-		HtlcAddressNp2Wsh: htlcAddressNP2WSH,
-		Type:              swapType,
-		CostServer:        int64(loopSwap.Cost.Server),
-		CostOnchain:       int64(loopSwap.Cost.Onchain),
-		CostOffchain:      int64(loopSwap.Cost.Offchain),
-		Label:             loopSwap.Label,
-	}, nil
+	return rpcEvents, nil
 }
 
 // Monitor will return a stream of swap updates for currently active swaps.
@@ -332,33 +491,136 @@ func (s *swapClientServer) Monitor(in *looprpc.MonitorRequest,
 	}
 }
 
-// ListSwaps returns a list of all currently known swaps and their current
-// status.
+// ListSwaps returns the page of swaps that matches the request and their
+// current status.
 func (s *swapClientServer) ListSwaps(_ context.Context,
-	_ *looprpc.ListSwapsRequest) (*looprpc.ListSwapsResponse, error) {
+	req *looprpc.ListSwapsRequest) (*looprpc.ListSwapsResponse, error) {
 
-	var (
-		rpcSwaps = make([]*looprpc.SwapStatus, len(s.swaps))
-		idx      = 0
-		err      error
-	)
+	query, err := unmarshallSwapQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	swaps, nextCursor, err := s.impl.ListSwaps(query)
+	if err != nil {
+		return nil, err
+	}
 
 	s.swapsLock.Lock()
 	defer s.swapsLock.Unlock()
 
-	// We can just use the server's in-memory cache as that contains the
-	// most up-to-date state including temporary failures which aren't
-	// persisted to disk. The swaps field is a map, that's why we need an
-	// additional index.
-	for _, swp := range s.swaps {
-		swp := swp
-		rpcSwaps[idx], err = s.marshallSwap(&swp)
+	rpcSwaps := make([]*looprpc.SwapStatus, 0, len(swaps))
+	for _, swp := range swaps {
+		// We use the server's in-memory cache for the swaps that it
+		// contains, as that contains the most up-to-date state
+		// including temporary failures which aren't persisted to
+		// disk.
+		info, ok := s.swaps[swp.SwapHash]
+		if !ok {
+			info = *swp
+		}
+
+		rpcSwap, err := s.marshallSwap(&info)
 		if err != nil {
 			return nil, err
 		}
-		idx++
+		rpcSwaps = append(rpcSwaps, rpcSwap)
+	}
+
+	resp := &looprpc.ListSwapsResponse{Swaps: rpcSwaps}
+	if nextCursor != nil {
+		resp.NextCursor = hex.EncodeToString(nextCursor.Serialize())
+	}
+
+	return resp, nil
+}
+
+// unmarshallSwapQuery converts a list swaps request into a swap query.
+func unmarshallSwapQuery(req *looprpc.ListSwapsRequest) (*loopdb.SwapQuery,
+	error) {
+
+	query := &loopdb.SwapQuery{
+		IncludeArchived: req.IncludeArchived,
+		Limit:           req.MaxSwaps,
+	}
+
+	if req.Cursor != "" {
+		cursorBytes, err := hex.DecodeString(req.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %v", err)
+		}
+
+		query.After, err = loopdb.DeserializeSwapCursor(cursorBytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %v", err)
+		}
+	}
+
+	filter := req.Filter
+	if filter == nil {
+		return query, nil
+	}
+
+	switch filter.SwapType {
+	case looprpc.ListSwapsFilter_ANY:
+
+	case looprpc.ListSwapsFilter_LOOP_OUT:
+		swapType := swap.TypeOut
+		query.SwapType = &swapType
+
+	case looprpc.ListSwapsFilter_LOOP_IN:
+		swapType := swap.TypeIn
+		query.SwapType = &swapType
+
+	default:
+		return nil, fmt.Errorf("unknown swap type filter: %v",
+			filter.SwapType)
+	}
+
+	for _, stateType := range filter.StateTypes {
+		switch stateType {
+		case looprpc.ListSwapsFilter_PENDING:
+			query.StateTypes = append(
+				query.StateTypes, loopdb.StateTypePending,
+			)
+
+		case looprpc.ListSwapsFilter_SUCCEEDED:
+			query.StateTypes = append(
+				query.StateTypes, loopdb.StateTypeSuccess,
+			)
+
+		case looprpc.ListSwapsFilter_FAILED:
+			query.StateTypes = append(
+				query.StateTypes, loopdb.StateTypeFail,
+			)
+
+		default:
+			return nil, fmt.Errorf("unknown state type filter: %v",
+				stateType)
+		}
+	}
+
+	if filter.StartTimeNs != 0 {
+		query.StartTime = time.Unix(0, filter.StartTimeNs)
+	}
+
+	if filter.EndTimeNs != 0 {
+		query.EndTime = time.Unix(0, filter.EndTimeNs)
+	}
+
+	query.Label = filter.Label
+	query.OutgoingChanID = filter.OutgoingChanId
+	query.Initiator = filter.Initiator
+
+	if len(filter.LastHop) != 0 {
+		lastHop, err := route.NewVertexFromBytes(filter.LastHop)
+		if err != nil {
+			return nil, err
+		}
+		query.LastHop = &lastHop
 	}
-	return &looprpc.ListSwapsResponse{Swaps: rpcSwaps}, nil
+
+	return query, nil
 }
 
 // SwapInfo returns all known details about a single swap.
@@ -372,11 +634,45 @@ func (s *swapClientServer) SwapInfo(_ context.Context,
 
 	// Just return the server's in-memory cache here too as we also want to
 	// return temporary failures to the client.
+	s.swapsLock.Lock()
 	swp, ok := s.swaps[swapHash]
+	s.swapsLock.Unlock()
+
+	// Archived swaps are no longer cached, so we read them from the
+	// store.
 	if !ok {
-		return nil, fmt.Errorf("swap with hash %s not found", req.Id)
+		swaps, _, err := s.impl.ListSwaps(&loopdb.SwapQuery{
+			Hash:            &swapHash,
+			IncludeArchived: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(swaps) == 0 {
+			return nil, fmt.Errorf("swap with hash %s not found",
+				req.Id)
+		}
+
+		swp = *swaps[0]
+	}
+
+	rpcSwap, err := s.marshallSwap(&swp)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.impl.SwapEvents(swapHash)
+	if err != nil {
+		return nil, err
 	}
-	return s.marshallSwap(&swp)
+
+	rpcSwap.Events, err = marshallSwapEvents(events)
+	if err != nil {
+		return nil, err
+	}
+
+	return rpcSwap, nil
 }
 
 // LoopOutTerms returns the terms that the server enforces for loop out swaps.
@@ -508,6 +804,33 @@ func (s *swapClientServer) LoopIn(ctx context.Context,
 		}
 		req.LastHop = &lastHop
 	}
+
+	for _, outpointStr := range in.Outpoints {
+		outpoint, err := parseOutpoint(outpointStr)
+		if err != nil {
+			return nil, err
+		}
+
+		req.HtlcFundingOutpoints = append(
+			req.HtlcFundingOutpoints, outpoint,
+		)
+	}
+
+	if in.ChangeAddress != "" {
+		if len(req.HtlcFundingOutpoints) == 0 {
+			return nil, errors.New("change address can only be " +
+				"set with outpoints")
+		}
+
+		req.ChangeAddr, err = btcutil.DecodeAddress(
+			in.ChangeAddress, s.lnd.ChainParams,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("decode change address: %v",
+				err)
+		}
+	}
+
 	swapInfo, err := s.impl.LoopIn(ctx, req)
 	if err != nil {
 		log.Errorf("Loop in: %v", err)
@@ -528,9 +851,123 @@ func (s *swapClientServer) LoopIn(ctx context.Context,
 		response.HtlcAddress = response.HtlcAddressP2Wsh
 	}
 
+	if swapInfo.HtlcPsbt != nil {
+		var b bytes.Buffer
+		if err := swapInfo.HtlcPsbt.Serialize(&b); err != nil {
+			return nil, err
+		}
+		response.HtlcPsbt = b.Bytes()
+	}
+
+	return response, nil
+}
+
+// LoopInBatch initiates a batch of loop in swaps whose htlcs are published in
+// a single transaction.
+func (s *swapClientServer) LoopInBatch(ctx context.Context,
+	in *looprpc.LoopInBatchRequest) (*looprpc.LoopInBatchResponse, error) {
+
+	log.Infof("Loop in batch request received")
+
+	htlcConfTarget, err := validateLoopInRequest(in.HtlcConfTarget, false)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &loop.LoopInBatchRequest{
+		Requests:       make([]*loop.LoopInRequest, len(in.Requests)),
+		HtlcConfTarget: htlcConfTarget,
+	}
+	for i, swapReq := range in.Requests {
+		if swapReq.ExternalHtlc {
+			return nil, errors.New("external htlcs cannot be " +
+				"batched")
+		}
+
+		if len(swapReq.Outpoints) > 0 {
+			return nil, errors.New("outpoints cannot be set for " +
+				"batched swaps")
+		}
+
+		// Check that the label is valid.
+		if err := labels.Validate(swapReq.Label); err != nil {
+			return nil, err
+		}
+
+		req.Requests[i] = &loop.LoopInRequest{
+			Amount:      btcutil.Amount(swapReq.Amt),
+			MaxMinerFee: btcutil.Amount(swapReq.MaxMinerFee),
+			MaxSwapFee:  btcutil.Amount(swapReq.MaxSwapFee),
+			Label:       swapReq.Label,
+			Initiator:   swapReq.Initiator,
+		}
+		if swapReq.LastHop != nil {
+			lastHop, err := route.NewVertexFromBytes(
+				swapReq.LastHop,
+			)
+			if err != nil {
+				return nil, err
+			}
+			req.Requests[i].LastHop = &lastHop
+		}
+	}
+
+	batchInfo, err := s.impl.LoopInBatch(ctx, req)
+	if err != nil {
+		log.Errorf("Loop in batch: %v", err)
+		return nil, err
+	}
+
+	response := &looprpc.LoopInBatchResponse{
+		Swaps:    make([]*looprpc.SwapResponse, len(batchInfo.Swaps)),
+		HtlcTxid: batchInfo.HtlcTxHash.String(),
+	}
+	for i, swapInfo := range batchInfo.Swaps {
+		htlcAddress := swapInfo.HtlcAddressP2WSH.String()
+
+		response.Swaps[i] = &looprpc.SwapResponse{
+			Id:               swapInfo.SwapHash.String(),
+			IdBytes:          swapInfo.SwapHash[:],
+			HtlcAddress:      htlcAddress,
+			HtlcAddressP2Wsh: htlcAddress,
+			ServerMessage:    swapInfo.ServerMessage,
+		}
+	}
+
 	return response, nil
 }
 
+// LoopInPublishPsbt publishes the htlc tx of a loop in swap with an external
+// htlc from a signed psbt.
+func (s *swapClientServer) LoopInPublishPsbt(ctx context.Context,
+	in *looprpc.LoopInPublishPsbtRequest) (
+	*looprpc.LoopInPublishPsbtResponse, error) {
+
+	log.Infof("Loop in publish psbt request received")
+
+	swapHash, err := lntypes.MakeHash(in.Id)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing swap hash: %v", err)
+	}
+
+	packet, err := psbt.NewFromRawBytes(
+		bytes.NewReader(in.SignedPsbt), false,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing psbt: %v", err)
+	}
+
+	txHash, err := s.impl.PublishLoopInPsbt(ctx, swapHash, packet)
+	if err != nil {
+		log.Errorf("Loop in publish psbt: %v", err)
+		return nil, err
+	}
+
+	return &looprpc.LoopInPublishPsbtResponse{
+		HtlcTxid: txHash.String(),
+	}, nil
+}
+
 // GetLsatTokens returns all tokens that are contained in the LSAT token store.
 func (s *swapClientServer) GetLsatTokens(ctx context.Context,
 	_ *looprpc.TokensRequest) (*looprpc.TokensResponse, error) {
@@ -808,6 +1245,42 @@ func (s *swapClientServer) SuggestSwaps(ctx context.Context,
 	}, nil
 }
 
+// BackupDatabase writes a consistent snapshot of the swap database to a new
+// file, or verifies an existing snapshot.
+func (s *swapClientServer) BackupDatabase(_ context.Context,
+	req *looprpc.BackupDatabaseRequest) (*looprpc.BackupDatabaseResponse,
+	error) {
+
+	if !filepath.IsAbs(req.Path) {
+		return nil, status.Error(
+			codes.InvalidArgument, "snapshot path must be absolute",
+		)
+	}
+
+	var (
+		info *loopdb.SnapshotInfo
+		err  error
+	)
+	if req.VerifyOnly {
+		log.Infof("Verifying database snapshot %v", req.Path)
+		info, err = s.impl.VerifyDatabaseBackup(req.Path)
+	} else {
+		log.Infof("Writing database snapshot to %v", req.Path)
+		info, err = s.impl.BackupDatabase(req.Path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &looprpc.BackupDatabaseResponse{
+		Path:         req.Path,
+		Backend:      string(info.Backend),
+		DbVersion:    info.Version,
+		LoopOutSwaps: uint32(info.LoopOuts),
+		LoopInSwaps:  uint32(info.LoopIns),
+	}, nil
+}
+
 func rpcAutoloopReason(reason liquidity.Reason) (looprpc.AutoReason, error) {
 	switch reason {
 	case liquidity.ReasonNone:
@@ -902,6 +1375,51 @@ func validateConfTarget(target, defaultTarget int32) (int32, error) {
 	}
 }
 
+// parseOutpoint parses an outpoint in the format txid:index.
+func parseOutpoint(outpointStr string) (wire.OutPoint, error) {
+	parts := strings.Split(outpointStr, ":")
+	if len(parts) != 2 {
+		return wire.OutPoint{}, fmt.Errorf("outpoint %v not in format "+
+			"txid:index", outpointStr)
+	}
+
+	hash, err := chainhash.NewHashFromStr(parts[0])
+	if err != nil {
+		return wire.OutPoint{}, err
+	}
+
+	index, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return wire.OutPoint{}, err
+	}
+
+	return wire.OutPoint{
+		Hash:  *hash,
+		Index: uint32(index),
+	}, nil
+}
+
+// peerChannelSet returns the set of active channels that we have with the
+// peer provided. It fails if there are no such channels.
+func peerChannelSet(channels []lndclient.ChannelInfo,
+	peer route.Vertex) (loopdb.ChannelSet, error) {
+
+	var chanIDs []uint64
+	for _, channel := range channels {
+		if channel.PubKeyBytes != peer || !channel.Active {
+			continue
+		}
+
+		chanIDs = append(chanIDs, channel.ChannelID)
+	}
+
+	if len(chanIDs) == 0 {
+		return nil, fmt.Errorf("no active channels with peer %v", peer)
+	}
+
+	return loopdb.NewChannelSet(chanIDs)
+}
+
 // validateLoopInRequest fails if the mutually exclusive conf target and
 // external parameters are both set.
 func validateLoopInRequest(htlcConfTarget int32, external bool) (int32, error) {
@@ -1,9 +1,23 @@
 package loopd
 
 import (
+	"context"
+	"encoding/hex"
 	"testing"
+	"time"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/lndclient"
 	"github.com/lightninglabs/loop"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightninglabs/loop/looprpc"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // TestValidateConfTarget tests all failure and success cases for our conf
@@ -143,3 +157,266 @@ func TestValidateLoopInRequest(t *testing.T) {
 		})
 	}
 }
+
+// TestParseOutpoint tests parsing of outpoints in the format txid:index.
+func TestParseOutpoint(t *testing.T) {
+	const txid = "3f4e1d2b6d8a3c9b7f0e5a4d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b"
+
+	hash, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		outpoint  string
+		expected  wire.OutPoint
+		expectErr bool
+	}{
+		{
+			name:     "valid outpoint",
+			outpoint: txid + ":2",
+			expected: wire.OutPoint{
+				Hash:  *hash,
+				Index: 2,
+			},
+		},
+		{
+			name:      "no index",
+			outpoint:  txid,
+			expectErr: true,
+		},
+		{
+			name:      "invalid txid",
+			outpoint:  "txid:2",
+			expectErr: true,
+		},
+		{
+			name:      "invalid index",
+			outpoint:  txid + ":-1",
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			outpoint, err := parseOutpoint(test.outpoint)
+
+			haveErr := err != nil
+			if haveErr != test.expectErr {
+				t.Fatalf("expected err: %v, got: %v",
+					test.expectErr, err)
+			}
+
+			if outpoint != test.expected {
+				t.Fatalf("expected: %v, got: %v",
+					test.expected, outpoint)
+			}
+		})
+	}
+}
+
+// TestPeerChannelSet tests resolving a peer to its set of active channels.
+func TestPeerChannelSet(t *testing.T) {
+	var (
+		peer1 = route.Vertex{1}
+		peer2 = route.Vertex{2}
+	)
+
+	channels := []lndclient.ChannelInfo{
+		{ChannelID: 1, PubKeyBytes: peer1, Active: true},
+		{ChannelID: 2, PubKeyBytes: peer2, Active: true},
+		{ChannelID: 3, PubKeyBytes: peer1, Active: false},
+		{ChannelID: 4, PubKeyBytes: peer1, Active: true},
+		{ChannelID: 5, PubKeyBytes: peer2, Active: false},
+	}
+
+	chanSet, err := peerChannelSet(channels, peer1)
+	require.NoError(t, err)
+	require.Equal(t, loopdb.ChannelSet{1, 4}, chanSet)
+
+	// A peer without any active channels can't be looped out from.
+	_, err = peerChannelSet(channels[2:3], peer1)
+	require.Error(t, err)
+
+	_, err = peerChannelSet(channels, route.Vertex{3})
+	require.Error(t, err)
+}
+
+// TestLoopOutExcludedNodes tests that loop outs that exclude nodes from the
+// swap payment route are rejected instead of ignoring the exclusion.
+func TestLoopOutExcludedNodes(t *testing.T) {
+	server := &swapClientServer{}
+
+	_, err := server.LoopOut(context.Background(), &looprpc.LoopOutRequest{
+		Amt:           100000,
+		ExcludedNodes: [][]byte{{2, 3}},
+	})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+// TestUnmarshallSwapQuery tests conversion of list swaps requests into swap
+// queries.
+func TestUnmarshallSwapQuery(t *testing.T) {
+	lastHop := route.Vertex{1, 2, 3}
+	loopIn := swap.TypeIn
+	cursor := &loopdb.SwapCursor{
+		InitiationTime: time.Unix(0, 1000),
+		Hash:           lntypes.Hash{4},
+	}
+
+	tests := []struct {
+		name      string
+		req       *looprpc.ListSwapsRequest
+		expected  *loopdb.SwapQuery
+		expectErr bool
+	}{
+		{
+			name:     "no filter",
+			req:      &looprpc.ListSwapsRequest{},
+			expected: &loopdb.SwapQuery{},
+		},
+		{
+			name: "all filters",
+			req: &looprpc.ListSwapsRequest{
+				Filter: &looprpc.ListSwapsFilter{
+					SwapType: looprpc.ListSwapsFilter_LOOP_IN,
+					StateTypes: []looprpc.ListSwapsFilter_StateTypeFilter{
+						looprpc.ListSwapsFilter_PENDING,
+						looprpc.ListSwapsFilter_FAILED,
+					},
+					StartTimeNs:    100,
+					EndTimeNs:      200,
+					Label:          "label",
+					OutgoingChanId: 5,
+					LastHop:        lastHop[:],
+					Initiator:      "autoloop",
+				},
+				Cursor: hex.EncodeToString(
+					cursor.Serialize(),
+				),
+				MaxSwaps:        10,
+				IncludeArchived: true,
+			},
+			expected: &loopdb.SwapQuery{
+				SwapType: &loopIn,
+				StateTypes: []loopdb.SwapStateType{
+					loopdb.StateTypePending,
+					loopdb.StateTypeFail,
+				},
+				StartTime:       time.Unix(0, 100),
+				EndTime:         time.Unix(0, 200),
+				Label:           "label",
+				OutgoingChanID:  5,
+				LastHop:         &lastHop,
+				Initiator:       "autoloop",
+				After:           cursor,
+				Limit:           10,
+				IncludeArchived: true,
+			},
+		},
+		{
+			name: "invalid cursor",
+			req: &looprpc.ListSwapsRequest{
+				Cursor: "0102",
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid last hop",
+			req: &looprpc.ListSwapsRequest{
+				Filter: &looprpc.ListSwapsFilter{
+					LastHop: []byte{1},
+				},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			query, err := unmarshallSwapQuery(test.req)
+			if test.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expected, query)
+		})
+	}
+}
+
+// TestMarshallSwapEvents tests conversion of the persisted state transitions
+// of a swap into rpc swap events.
+func TestMarshallSwapEvents(t *testing.T) {
+	sweepTxHash := chainhash.Hash{1}
+	htlcOutpoint := wire.OutPoint{Hash: chainhash.Hash{2}, Index: 1}
+
+	events := []*loopdb.LoopEvent{
+		{
+			SwapStateData: loopdb.SwapStateData{
+				State: loopdb.StatePreimageRevealed,
+				Cost: loopdb.SwapCost{
+					Server: 10,
+				},
+				SweepTxHash:    &sweepTxHash,
+				SweepFee:       5,
+				HtlcOutpoint:   &htlcOutpoint,
+				HtlcConfHeight: 600,
+			},
+			Time: time.Unix(0, 100),
+		},
+		{
+			SwapStateData: loopdb.SwapStateData{
+				State: loopdb.StateFailSweepTimeout,
+				Cost: loopdb.SwapCost{
+					Server:   10,
+					Onchain:  20,
+					Offchain: 30,
+				},
+				SpendTxHash:     &sweepTxHash,
+				SpendConfHeight: 610,
+			},
+			Time: time.Unix(0, 200),
+		},
+	}
+
+	rpcEvents, err := marshallSwapEvents(events)
+	require.NoError(t, err)
+	require.Equal(t, []*looprpc.SwapEvent{
+		{
+			State:          looprpc.SwapState_PREIMAGE_REVEALED,
+			Time:           100,
+			CostServer:     10,
+			SweepTxid:      sweepTxHash.String(),
+			SweepFee:       5,
+			HtlcOutpoint:   htlcOutpoint.String(),
+			HtlcConfHeight: 600,
+		},
+		{
+			State:           looprpc.SwapState_FAILED,
+			FailureReason:   looprpc.FailureReason_FAILURE_REASON_SWEEP_TIMEOUT,
+			Time:            200,
+			CostServer:      10,
+			CostOnchain:     20,
+			CostOffchain:    30,
+			SpendTxid:       sweepTxHash.String(),
+			SpendConfHeight: 610,
+		},
+	}, rpcEvents)
+
+	// Unknown states can't be converted.
+	_, err = marshallSwapEvents([]*loopdb.LoopEvent{
+		{
+			SwapStateData: loopdb.SwapStateData{
+				State: loopdb.SwapState(255),
+			},
+		},
+	})
+	require.Error(t, err)
+}
@@ -7,8 +7,10 @@ import (
 	"github.com/lightninglabs/lndclient"
 	"github.com/lightninglabs/loop"
 	"github.com/lightninglabs/loop/liquidity"
+	"github.com/lightninglabs/loop/loopdb"
 	"github.com/lightninglabs/loop/swap"
 	"github.com/lightningnetwork/lnd/clock"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 	"github.com/lightningnetwork/lnd/ticker"
 )
 
@@ -17,14 +19,23 @@ func getClient(config *Config, lnd *lndclient.LndServices) (*loop.Client,
 	func(), error) {
 
 	clientConfig := &loop.ClientConfig{
-		ServerAddress:   config.Server.Host,
-		ProxyAddress:    config.Server.Proxy,
-		SwapServerNoTLS: config.Server.NoTLS,
-		TLSPathServer:   config.Server.TLSPath,
-		Lnd:             lnd,
-		MaxLsatCost:     btcutil.Amount(config.MaxLSATCost),
-		MaxLsatFee:      btcutil.Amount(config.MaxLSATFee),
-		LoopOutMaxParts: config.LoopOutMaxParts,
+		ServerAddress:                config.Server.Host,
+		ProxyAddress:                 config.Server.Proxy,
+		SwapServerNoTLS:              config.Server.NoTLS,
+		TLSPathServer:                config.Server.TLSPath,
+		Lnd:                          lnd,
+		MaxLsatCost:                  btcutil.Amount(config.MaxLSATCost),
+		MaxLsatFee:                   btcutil.Amount(config.MaxLSATFee),
+		LoopOutMaxParts:              config.LoopOutMaxParts,
+		BatchSweeps:                  config.BatchSweeps,
+		LoopOutMaxSweepFeeMultiplier: config.LoopOutMaxSweepFeeMultiplier,
+		LoopInTimeoutMaxFeeRate: chainfee.SatPerKVByte(
+			config.LoopInTimeoutMaxFeeRate * 1000,
+		).FeePerKWeight(),
+		ReorgSafetyDepth: config.ReorgSafetyDepth,
+		BackupFile:       config.BackupFile,
+		RestoreBackup:    config.RestoreBackup,
+		DatabaseBackend:  loopdb.Backend(config.DatabaseBackend),
 	}
 
 	swapClient, cleanUp, err := loop.NewClient(config.DataDir, clientConfig)
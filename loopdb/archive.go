@@ -0,0 +1,154 @@
+package loopdb
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/coreos/bbolt"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+// ErrArchivePendingSwap is returned when a swap that hasn't completed yet is
+// archived.
+var ErrArchivePendingSwap = errors.New("only completed swaps can be archived")
+
+// ArchiveSwaps moves the completed swaps with the hashes provided from their
+// root bucket to the archive bucket of their swap type. Swaps that are already
+// archived are skipped. No swap is archived if any of them is pending or
+// unknown.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) ArchiveSwaps(hashes []lntypes.Hash) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		for _, hash := range hashes {
+			if err := archiveSwap(tx, hash); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// archiveSwap moves the swap with the hash provided to the archive bucket of
+// its swap type.
+func archiveSwap(tx *bbolt.Tx, hash lntypes.Hash) error {
+	for _, swapType := range []swap.Type{swap.TypeOut, swap.TypeIn} {
+		archiveBucket, err := tx.CreateBucketIfNotExists(
+			archiveBucketKey(swapType),
+		)
+		if err != nil {
+			return err
+		}
+
+		if archiveBucket.Bucket(hash[:]) != nil {
+			return nil
+		}
+
+		rootBucket := tx.Bucket(swapTypeBucketKey(swapType))
+		if rootBucket == nil {
+			return errors.New("bucket does not exist")
+		}
+
+		swapBucket := rootBucket.Bucket(hash[:])
+		if swapBucket == nil {
+			continue
+		}
+
+		state, err := lastState(swapBucket)
+		if err != nil {
+			return err
+		}
+
+		if state.Type() == StateTypePending {
+			return fmt.Errorf("swap %v in state %v: %w", hash,
+				state, ErrArchivePendingSwap)
+		}
+
+		archivedBucket, err := archiveBucket.CreateBucket(hash[:])
+		if err != nil {
+			return err
+		}
+
+		if err := copyBucket(archivedBucket, swapBucket); err != nil {
+			return err
+		}
+
+		return rootBucket.DeleteBucket(hash[:])
+	}
+
+	return fmt.Errorf("swap %v not found", hash)
+}
+
+// copyBucket recursively copies all keys and nested buckets of a bucket to
+// another, empty bucket. The sequence numbers of all buckets are copied as
+// well, so that swap updates keep being numbered in the same way.
+func copyBucket(to, from *bbolt.Bucket) error {
+	if err := to.SetSequence(from.Sequence()); err != nil {
+		return err
+	}
+
+	return from.ForEach(func(k, v []byte) error {
+		if v != nil {
+			return to.Put(k, v)
+		}
+
+		nestedBucket, err := to.CreateBucket(k)
+		if err != nil {
+			return err
+		}
+
+		return copyBucket(nestedBucket, from.Bucket(k))
+	})
+}
+
+// ArchiveSwaps marks the completed swaps with the hashes provided as archived.
+// Swaps that are already archived are skipped. No swap is archived if any of
+// them is pending or unknown.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *sqliteSwapStore) ArchiveSwaps(hashes []lntypes.Hash) error {
+	return s.update(func(tx *sql.Tx) error {
+		for _, hash := range hashes {
+			var (
+				id    int64
+				state SwapState
+			)
+
+			// The state of a swap is the state of its last
+			// update, or initiated if it has no updates.
+			err := tx.QueryRow(`
+				SELECT s.id, COALESCE((
+					SELECT u.state FROM swap_updates u
+					WHERE u.swap_id = s.id
+					ORDER BY u.id DESC LIMIT 1
+				), ?)
+				FROM swaps s WHERE s.swap_hash = ?`,
+				uint8(StateInitiated), hash[:],
+			).Scan(&id, &state)
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("swap %v not found", hash)
+			}
+			if err != nil {
+				return err
+			}
+
+			if state.Type() == StateTypePending {
+				return fmt.Errorf("swap %v in state %v: %w",
+					hash, state, ErrArchivePendingSwap)
+			}
+
+			_, err = tx.Exec(
+				"UPDATE swaps SET archived = 1 WHERE id = ?",
+				id,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,177 @@
+package loopdb
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/lightninglabs/loop/test"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/stretchr/testify/require"
+)
+
+// TestArchiveSwaps tests archiving completed swaps in all swap store backends.
+func TestArchiveSwaps(t *testing.T) {
+	for _, backend := range testBackends {
+		backend := backend
+
+		t.Run(string(backend), func(t *testing.T) {
+			testArchiveSwaps(t, backend)
+		})
+	}
+}
+
+// createArchiveTestSwaps adds a completed loop out and a pending loop in swap
+// to the store provided and returns their hashes.
+func createArchiveTestSwaps(t *testing.T, store SwapStore) (lntypes.Hash,
+	lntypes.Hash) {
+
+	loopOut := &LoopOutContract{
+		SwapContract: SwapContract{
+			Preimage:       testPreimage,
+			SenderKey:      senderKey,
+			ReceiverKey:    receiverKey,
+			InitiationTime: time.Unix(0, testTime.UnixNano()),
+		},
+		DestAddr: test.GetDestAddr(t, 0),
+	}
+	loopOutHash := testPreimage.Hash()
+	require.NoError(t, store.CreateLoopOut(loopOutHash, loopOut))
+
+	for _, state := range []SwapState{
+		StatePreimageRevealed, StateSuccess,
+	} {
+		require.NoError(t, store.UpdateLoopOut(
+			loopOutHash, testTime, SwapStateData{State: state},
+		))
+	}
+
+	loopInPreimage := lntypes.Preimage{5}
+	loopIn := &LoopInContract{
+		SwapContract: SwapContract{
+			Preimage:       loopInPreimage,
+			SenderKey:      senderKey,
+			ReceiverKey:    receiverKey,
+			InitiationTime: time.Unix(0, testTime.UnixNano()),
+		},
+	}
+	loopInHash := loopInPreimage.Hash()
+	require.NoError(t, store.CreateLoopIn(loopInHash, loopIn))
+
+	return loopOutHash, loopInHash
+}
+
+func testArchiveSwaps(t *testing.T, backend Backend) {
+	tempDirName, err := ioutil.TempDir("", "clientstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDirName)
+
+	store, err := NewSwapStore(
+		backend, tempDirName, &chaincfg.MainNetParams,
+	)
+	require.NoError(t, err)
+	defer store.Close()
+
+	loopOutHash, loopInHash := createArchiveTestSwaps(t, store)
+
+	loopOuts, err := store.FetchLoopOutSwaps()
+	require.NoError(t, err)
+	require.Len(t, loopOuts, 1)
+
+	// Pending swaps can't be archived. Archiving fails as a whole, so the
+	// completed swap isn't archived either.
+	err = store.ArchiveSwaps([]lntypes.Hash{loopOutHash, loopInHash})
+	require.True(t, errors.Is(err, ErrArchivePendingSwap))
+
+	// Unknown swaps can't be archived.
+	require.Error(t, store.ArchiveSwaps([]lntypes.Hash{{9}}))
+
+	fetchedOuts, err := store.FetchLoopOutSwaps()
+	require.NoError(t, err)
+	require.Equal(t, loopOuts, fetchedOuts)
+
+	require.NoError(t, store.ArchiveSwaps([]lntypes.Hash{loopOutHash}))
+
+	// Archiving a swap again is a no-op.
+	require.NoError(t, store.ArchiveSwaps([]lntypes.Hash{loopOutHash}))
+
+	// The archived swap is no longer fetched, but the pending swap is.
+	fetchedOuts, err = store.FetchLoopOutSwaps()
+	require.NoError(t, err)
+	require.Empty(t, fetchedOuts)
+
+	fetchedIns, err := store.FetchLoopInSwaps()
+	require.NoError(t, err)
+	require.Len(t, fetchedIns, 1)
+
+	// Archived swaps are only queried when they are included.
+	page, err := store.QuerySwaps(&SwapQuery{})
+	require.NoError(t, err)
+	require.Empty(t, page.LoopOuts)
+	require.Len(t, page.LoopIns, 1)
+	require.False(t, page.LoopIns[0].Archived)
+
+	page, err = store.QuerySwaps(&SwapQuery{IncludeArchived: true})
+	require.NoError(t, err)
+	require.Len(t, page.LoopIns, 1)
+	require.Len(t, page.LoopOuts, 1)
+
+	loopOuts[0].Archived = true
+	require.Equal(t, loopOuts[0], page.LoopOuts[0])
+
+	// Archived swaps can't be updated or created again.
+	err = store.UpdateLoopOut(
+		loopOutHash, testTime, SwapStateData{State: StateSuccess},
+	)
+	require.Error(t, err)
+
+	err = store.CreateLoopOut(loopOutHash, &LoopOutContract{
+		SwapContract: SwapContract{Preimage: testPreimage},
+		DestAddr:     test.GetDestAddr(t, 0),
+	})
+	require.Error(t, err)
+}
+
+// TestMigrateArchivedSwaps tests that archived swaps are migrated to another
+// store and stay archived.
+func TestMigrateArchivedSwaps(t *testing.T) {
+	boltDir, err := ioutil.TempDir("", "clientstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(boltDir)
+
+	sqliteDir, err := ioutil.TempDir("", "clientstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(sqliteDir)
+
+	params := &chaincfg.MainNetParams
+	boltStore, err := NewBoltSwapStore(boltDir, params)
+	require.NoError(t, err)
+	defer boltStore.Close()
+
+	loopOutHash, _ := createArchiveTestSwaps(t, boltStore)
+	require.NoError(t, boltStore.ArchiveSwaps([]lntypes.Hash{loopOutHash}))
+
+	sqliteStore, err := NewSqliteSwapStore(sqliteDir, params)
+	require.NoError(t, err)
+	defer sqliteStore.Close()
+
+	migrated, err := MigrateSwaps(boltStore, sqliteStore)
+	require.NoError(t, err)
+	require.Equal(t, 2, migrated)
+
+	loopOuts, err := sqliteStore.FetchLoopOutSwaps()
+	require.NoError(t, err)
+	require.Empty(t, loopOuts)
+
+	boltPage, err := boltStore.QuerySwaps(&SwapQuery{IncludeArchived: true})
+	require.NoError(t, err)
+
+	sqlitePage, err := sqliteStore.QuerySwaps(
+		&SwapQuery{IncludeArchived: true},
+	)
+	require.NoError(t, err)
+	require.Equal(t, boltPage, sqlitePage)
+}
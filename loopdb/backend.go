@@ -0,0 +1,320 @@
+package loopdb
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+// Backend is the database backend that swaps are stored in.
+type Backend string
+
+const (
+	// BackendBolt stores swaps in a bbolt database.
+	BackendBolt Backend = "bbolt"
+
+	// BackendSqlite stores swaps in a sqlite database.
+	BackendSqlite Backend = "sqlite"
+)
+
+var (
+	// ErrMigrationTargetNotEmpty is returned when swaps are migrated to a
+	// store that already contains swaps.
+	ErrMigrationTargetNotEmpty = errors.New("migration target store is " +
+		"not empty")
+)
+
+// NewSwapStore opens the swap store of the backend provided in the directory
+// provided. A sqlite store is only created if there is no bbolt database in
+// the directory, because its swaps would otherwise silently be ignored. They
+// need to be migrated with MigrateBoltToSqlite first.
+func NewSwapStore(backend Backend, dbPath string,
+	chainParams *chaincfg.Params) (SwapStore, error) {
+
+	switch backend {
+	case BackendBolt:
+		return NewBoltSwapStore(dbPath, chainParams)
+
+	case BackendSqlite:
+		boltExists := fileExists(filepath.Join(dbPath, dbFileName))
+		sqliteExists := fileExists(filepath.Join(dbPath, SqliteFileName))
+		if boltExists && !sqliteExists {
+			return nil, fmt.Errorf("%v exists in %v, migrate it to "+
+				"sqlite before using the sqlite backend",
+				dbFileName, dbPath)
+		}
+
+		return NewSqliteSwapStore(dbPath, chainParams)
+
+	default:
+		return nil, fmt.Errorf("unknown database backend: %v", backend)
+	}
+}
+
+// MigrateBoltToSqlite copies all swaps and their updates from the bbolt
+// database in the directory provided to a new sqlite database in the same
+// directory. The sqlite database is built in a temporary directory and only
+// moved into place once all swaps were copied and checked, so that a failed
+// migration leaves no partial database behind. The current LSAT token is
+// written to the token files that are used with the sqlite backend. The bbolt
+// database is left untouched. The number of migrated swaps is returned.
+func MigrateBoltToSqlite(dbPath string, chainParams *chaincfg.Params) (int,
+	error) {
+
+	if !fileExists(filepath.Join(dbPath, dbFileName)) {
+		return 0, fmt.Errorf("no %v found in %v", dbFileName, dbPath)
+	}
+
+	// An existing sqlite database is only replaced if it has no swaps.
+	sqlitePath := filepath.Join(dbPath, SqliteFileName)
+	if fileExists(sqlitePath) {
+		if err := checkEmptySqliteStore(dbPath, chainParams); err != nil {
+			return 0, err
+		}
+	}
+
+	boltStore, err := NewBoltSwapStore(dbPath, chainParams)
+	if err != nil {
+		return 0, err
+	}
+	defer boltStore.Close()
+
+	// The temporary directory is kept next to the database, so that the
+	// new database can be renamed into place.
+	tempDir, err := ioutil.TempDir(dbPath, "sqlite-migration")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	sqliteStore, err := openSqliteSwapStore(tempDir, chainParams, false)
+	if err != nil {
+		return 0, err
+	}
+
+	migrated, err := MigrateSwaps(boltStore, sqliteStore)
+	if err != nil {
+		_ = sqliteStore.Close()
+		return migrated, err
+	}
+
+	if err := sqliteStore.Close(); err != nil {
+		return migrated, err
+	}
+
+	err = os.Rename(filepath.Join(tempDir, SqliteFileName), sqlitePath)
+	if err != nil {
+		return migrated, err
+	}
+
+	// LSAT tokens are only stored in bbolt databases, the sqlite backend
+	// keeps them in files.
+	return migrated, exportLsatToken(boltStore, dbPath)
+}
+
+// checkEmptySqliteStore returns ErrMigrationTargetNotEmpty if the sqlite store
+// in the directory provided contains any swaps.
+func checkEmptySqliteStore(dbPath string, chainParams *chaincfg.Params) error {
+	store, err := NewSqliteSwapStore(dbPath, chainParams)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	loopOuts, loopIns, err := fetchAllSwaps(store)
+	if err != nil {
+		return err
+	}
+
+	if len(loopOuts) != 0 || len(loopIns) != 0 {
+		return ErrMigrationTargetNotEmpty
+	}
+
+	return nil
+}
+
+// MigrateSwaps copies all swaps and their updates from one store to another,
+// empty store. Archived swaps are archived in the target store as well. After
+// copying, the swaps of both stores are compared. The number of migrated swaps
+// is returned.
+func MigrateSwaps(from, to SwapStore) (int, error) {
+	loopOuts, loopIns, err := fetchAllSwaps(from)
+	if err != nil {
+		return 0, err
+	}
+
+	existingOuts, existingIns, err := fetchAllSwaps(to)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(existingOuts) != 0 || len(existingIns) != 0 {
+		return 0, ErrMigrationTargetNotEmpty
+	}
+
+	log.Infof("Migrating %v loop out and %v loop in swaps", len(loopOuts),
+		len(loopIns))
+
+	migrated, err := addSwaps(to, loopOuts, loopIns)
+	if err != nil {
+		return migrated, err
+	}
+
+	if err := compareSwaps(to, loopOuts, loopIns); err != nil {
+		return migrated, fmt.Errorf("migration check failed: %v", err)
+	}
+
+	return migrated, nil
+}
+
+// fetchAllSwaps returns all swaps in the store, including archived swaps.
+func fetchAllSwaps(store SwapStore) ([]*LoopOut, []*LoopIn, error) {
+	page, err := store.QuerySwaps(&SwapQuery{IncludeArchived: true})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return page.LoopOuts, page.LoopIns, nil
+}
+
+// addSwaps creates the swaps provided in the store and applies their updates.
+// Swaps that are marked as archived are archived once they have been added.
+// The number of added swaps is returned.
+func addSwaps(store SwapStore, loopOuts []*LoopOut, loopIns []*LoopIn) (int,
+	error) {
+
+	var (
+		added    int
+		archived []lntypes.Hash
+	)
+	for _, swap := range loopOuts {
+		err := store.CreateLoopOut(swap.Hash, swap.Contract)
+		if err != nil {
+			return added, err
+		}
+
+		for _, event := range swap.Events {
+			err := store.UpdateLoopOut(
+				swap.Hash, event.Time, event.SwapStateData,
+			)
+			if err != nil {
+				return added, err
+			}
+		}
+
+		if swap.Archived {
+			archived = append(archived, swap.Hash)
+		}
+
+		log.Infof("Added loop out swap %v", swap.Hash)
+		added++
+	}
+
+	for _, swap := range loopIns {
+		err := store.CreateLoopIn(swap.Hash, swap.Contract)
+		if err != nil {
+			return added, err
+		}
+
+		for _, event := range swap.Events {
+			err := store.UpdateLoopIn(
+				swap.Hash, event.Time, event.SwapStateData,
+			)
+			if err != nil {
+				return added, err
+			}
+		}
+
+		if swap.Archived {
+			archived = append(archived, swap.Hash)
+		}
+
+		log.Infof("Added loop in swap %v", swap.Hash)
+		added++
+	}
+
+	if len(archived) == 0 {
+		return added, nil
+	}
+
+	if err := store.ArchiveSwaps(archived); err != nil {
+		return added, err
+	}
+
+	return added, nil
+}
+
+// compareSwaps checks that the store contains exactly the swaps provided,
+// along with the same updates and archive status.
+func compareSwaps(store SwapStore, loopOuts []*LoopOut,
+	loopIns []*LoopIn) error {
+
+	storedOuts, storedIns, err := fetchAllSwaps(store)
+	if err != nil {
+		return err
+	}
+
+	if len(storedOuts) != len(loopOuts) || len(storedIns) != len(loopIns) {
+		return fmt.Errorf("expected %v loop out and %v loop in swaps, "+
+			"got %v and %v", len(loopOuts), len(loopIns),
+			len(storedOuts), len(storedIns))
+	}
+
+	swaps := make(map[lntypes.Hash]*Loop)
+	for _, swap := range loopOuts {
+		swaps[swap.Hash] = &swap.Loop
+	}
+	for _, swap := range loopIns {
+		swaps[swap.Hash] = &swap.Loop
+	}
+
+	check := func(swap *Loop) error {
+		expected, ok := swaps[swap.Hash]
+		if !ok {
+			return fmt.Errorf("unexpected swap %v", swap.Hash)
+		}
+
+		count := len(expected.Events)
+		if count != len(swap.Events) {
+			return fmt.Errorf("expected %v updates for swap %v, "+
+				"got %v", count, swap.Hash, len(swap.Events))
+		}
+
+		for i, event := range swap.Events {
+			expectedEvent := expected.Events[i]
+			if !event.Time.Equal(expectedEvent.Time) ||
+				!reflect.DeepEqual(event.SwapStateData,
+					expectedEvent.SwapStateData) {
+
+				return fmt.Errorf("update %v of swap %v does "+
+					"not match", i, swap.Hash)
+			}
+		}
+
+		if expected.Archived != swap.Archived {
+			return fmt.Errorf("expected archived %v for swap %v",
+				expected.Archived, swap.Hash)
+		}
+
+		return nil
+	}
+
+	for _, swap := range storedOuts {
+		if err := check(&swap.Loop); err != nil {
+			return err
+		}
+	}
+	for _, swap := range storedIns {
+		if err := check(&swap.Loop); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
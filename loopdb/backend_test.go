@@ -0,0 +1,181 @@
+package loopdb
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/test"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMigrateBoltToSqlite tests that all swaps and their updates are copied
+// from a bbolt database to a sqlite database.
+func TestMigrateBoltToSqlite(t *testing.T) {
+	tempDirName, err := ioutil.TempDir("", "clientstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDirName)
+
+	params := &chaincfg.MainNetParams
+
+	// Migrating without a bbolt database fails.
+	_, err = MigrateBoltToSqlite(tempDirName, params)
+	require.Error(t, err)
+
+	boltStore, err := NewBoltSwapStore(tempDirName, params)
+	require.NoError(t, err)
+
+	contract := SwapContract{
+		AmountRequested:  100,
+		Preimage:         testPreimage,
+		CltvExpiry:       144,
+		SenderKey:        senderKey,
+		ReceiverKey:      receiverKey,
+		MaxMinerFee:      10,
+		MaxSwapFee:       20,
+		InitiationHeight: 99,
+		InitiationTime:   time.Unix(0, testTime.UnixNano()),
+		Label:            "migrated",
+		ProtocolVersion:  ProtocolVersionHtlcV2,
+	}
+
+	lastHop := route.Vertex{1, 2, 3}
+	loopOut := &LoopOutContract{
+		SwapContract:      contract,
+		DestAddr:          test.GetDestAddr(t, 0),
+		SwapInvoice:       "swapinvoice",
+		PrepayInvoice:     "prepayinvoice",
+		MaxSwapRoutingFee: 30,
+		SweepConfTarget:   2,
+		HtlcConfirmations: 3,
+		OutgoingChanSet:   ChannelSet{1, 1 << 63},
+		LastHop:           &lastHop,
+		PaymentCltvLimit:  500,
+		SweepDestinations: []SweepDestination{
+			{Addr: test.GetDestAddr(t, 1), Weight: 2},
+			{Addr: test.GetDestAddr(t, 2), Weight: 1},
+		},
+		SwapPublicationDeadline: time.Unix(0, testTime.UnixNano()),
+	}
+	loopOutHash := testPreimage.Hash()
+	require.NoError(t, boltStore.CreateLoopOut(loopOutHash, loopOut))
+
+	for i, state := range []SwapState{
+		StatePreimageRevealed, StateSuccess,
+	} {
+		require.NoError(t, boltStore.UpdateLoopOut(
+			loopOutHash, testTime.Add(time.Duration(i)*time.Hour),
+			SwapStateData{
+				State: state,
+				Cost: SwapCost{
+					Server:   1,
+					Onchain:  2,
+					Offchain: 3,
+				},
+				HtlcTxHash:  &chainhash.Hash{1},
+				SweepTxHash: &chainhash.Hash{2},
+				SweepFee:    300,
+				HtlcOutpoint: wire.NewOutPoint(
+					&chainhash.Hash{1}, 1,
+				),
+				HtlcConfHeight:  100,
+				SpendTxHash:     &chainhash.Hash{2},
+				SpendConfHeight: 101,
+			},
+		))
+	}
+
+	changeAddr, err := btcutil.NewAddressWitnessPubKeyHash(
+		make([]byte, 20), params,
+	)
+	require.NoError(t, err)
+
+	htlcTx := wire.NewMsgTx(2)
+	htlcTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: chainhash.Hash{5}},
+		SignatureScript:  []byte{},
+		Witness:          wire.TxWitness{{1, 2}},
+	})
+	htlcTx.AddTxOut(&wire.TxOut{PkScript: []byte{3}, Value: 100})
+	htlcTxHash := htlcTx.TxHash()
+
+	loopInPreimage := lntypes.Preimage{5}
+	loopIn := &LoopInContract{
+		SwapContract:   contract,
+		HtlcConfTarget: 2,
+		LastHop:        &lastHop,
+		HtlcFundingOutpoints: []wire.OutPoint{
+			{Hash: chainhash.Hash{3}, Index: 1},
+			{Hash: chainhash.Hash{4}, Index: 0},
+		},
+		ChangeAddr: changeAddr,
+		Label:      "loop in",
+	}
+	loopIn.Preimage = loopInPreimage
+	loopIn.SwapContract.Label = ""
+	loopInHash := loopInPreimage.Hash()
+	require.NoError(t, boltStore.CreateLoopIn(loopInHash, loopIn))
+	require.NoError(t, boltStore.UpdateLoopIn(
+		loopInHash, testTime, SwapStateData{
+			State:      StateHtlcPublished,
+			HtlcTxHash: &htlcTxHash,
+			HtlcTx:     htlcTx,
+		},
+	))
+
+	loopOuts, err := boltStore.FetchLoopOutSwaps()
+	require.NoError(t, err)
+	loopIns, err := boltStore.FetchLoopInSwaps()
+	require.NoError(t, err)
+	require.NoError(t, boltStore.Close())
+
+	// The sqlite backend may not be used before the bbolt database is
+	// migrated.
+	_, err = NewSwapStore(BackendSqlite, tempDirName, params)
+	require.Error(t, err)
+
+	// An empty sqlite database is replaced by the migration.
+	emptyStore, err := NewSqliteSwapStore(tempDirName, params)
+	require.NoError(t, err)
+	require.NoError(t, emptyStore.Close())
+
+	migrated, err := MigrateBoltToSqlite(tempDirName, params)
+	require.NoError(t, err)
+	require.Equal(t, 2, migrated)
+
+	// The temporary directory of the migration is removed.
+	files, err := ioutil.ReadDir(tempDirName)
+	require.NoError(t, err)
+	for _, file := range files {
+		require.False(t, file.IsDir(), file.Name())
+	}
+
+	// The migration can't be repeated once the sqlite store has swaps.
+	_, err = MigrateBoltToSqlite(tempDirName, params)
+	require.Equal(t, ErrMigrationTargetNotEmpty, err)
+
+	sqliteStore, err := NewSwapStore(BackendSqlite, tempDirName, params)
+	require.NoError(t, err)
+	defer sqliteStore.Close()
+
+	sqliteOuts, err := sqliteStore.FetchLoopOutSwaps()
+	require.NoError(t, err)
+	require.Equal(t, loopOuts, sqliteOuts)
+
+	sqliteIns, err := sqliteStore.FetchLoopInSwaps()
+	require.NoError(t, err)
+	require.Equal(t, loopIns, sqliteIns)
+
+	// The migration check detects updates that differ in their content.
+	require.NoError(t, compareSwaps(sqliteStore, loopOuts, loopIns))
+
+	loopOuts[0].Events[1].SweepFee++
+	require.Error(t, compareSwaps(sqliteStore, loopOuts, loopIns))
+}
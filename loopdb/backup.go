@@ -0,0 +1,774 @@
+package loopdb
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	// BackupFileName is the default file name of the swap backup.
+	BackupFileName = "swaps.backup"
+
+	// backupVersion is the version of the plaintext backup encoding.
+	backupVersion = 0
+
+	// maxBackupValueSize is the maximum size of a single value in the
+	// backup. It protects against allocating huge buffers when reading a
+	// corrupt backup.
+	maxBackupValueSize = 1 << 20
+)
+
+var (
+	// ErrBackupUnknownVersion is returned when a backup was created with
+	// an encoding version that we don't know.
+	ErrBackupUnknownVersion = errors.New("unknown swap backup version")
+)
+
+// BackupKey is the symmetric key that swap backups are encrypted with.
+type BackupKey [chacha20poly1305.KeySize]byte
+
+// kvMap is an in-memory key-value bucket that allows swaps to be serialized
+// with the same encoding that is used in the database.
+type kvMap map[string][]byte
+
+// Get returns the value stored under the key provided, or nil if there is no
+// such value.
+func (m kvMap) Get(key []byte) []byte {
+	return m[string(key)]
+}
+
+// Put stores a copy of the value provided under the key provided.
+func (m kvMap) Put(key, value []byte) error {
+	m[string(key)] = append([]byte{}, value...)
+	return nil
+}
+
+// serialize writes the map to the writer provided, sorted by key.
+func (m kvMap) serialize(w io.Writer) error {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if err := binary.Write(w, byteOrder, uint32(len(keys))); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := wire.WriteVarBytes(w, 0, []byte(key)); err != nil {
+			return err
+		}
+
+		if err := wire.WriteVarBytes(w, 0, m[key]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deserializeKVMap reads a map that was written by serialize.
+func deserializeKVMap(r io.Reader) (kvMap, error) {
+	var count uint32
+	if err := binary.Read(r, byteOrder, &count); err != nil {
+		return nil, err
+	}
+
+	m := make(kvMap)
+	for i := uint32(0); i < count; i++ {
+		key, err := wire.ReadVarBytes(r, 0, maxBackupValueSize, "key")
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := wire.ReadVarBytes(
+			r, 0, maxBackupValueSize, "value",
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		m[string(key)] = value
+	}
+
+	return m, nil
+}
+
+// serializeEvents writes the events of a swap to the writer provided.
+func serializeEvents(w io.Writer, events []*LoopEvent) error {
+	err := binary.Write(w, byteOrder, uint32(len(events)))
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		m := make(kvMap)
+		err := putLoopEvent(m, event.Time, event.SwapStateData)
+		if err != nil {
+			return err
+		}
+
+		if err := m.serialize(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deserializeEvents reads the events of a swap that were written by
+// serializeEvents.
+func deserializeEvents(r io.Reader) ([]*LoopEvent, error) {
+	var count uint32
+	if err := binary.Read(r, byteOrder, &count); err != nil {
+		return nil, err
+	}
+
+	var events []*LoopEvent
+	for i := uint32(0); i < count; i++ {
+		m, err := deserializeKVMap(r)
+		if err != nil {
+			return nil, err
+		}
+
+		event, err := getLoopEvent(m)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// swapBackup contains the swaps of a backup.
+type swapBackup struct {
+	loopOuts []*LoopOut
+	loopIns  []*LoopIn
+}
+
+// serializeBackup serializes the swaps provided. Every swap is encoded as its
+// hash, followed by the key-value pairs of its contract and of each of its
+// events, as they are stored in the database.
+func serializeBackup(backup *swapBackup) ([]byte, error) {
+	var b bytes.Buffer
+	if err := b.WriteByte(backupVersion); err != nil {
+		return nil, err
+	}
+
+	err := binary.Write(&b, byteOrder, uint32(len(backup.loopOuts)))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, swap := range backup.loopOuts {
+		if _, err := b.Write(swap.Hash[:]); err != nil {
+			return nil, err
+		}
+
+		m := make(kvMap)
+		if err := putLoopOutContract(m, swap.Contract); err != nil {
+			return nil, err
+		}
+
+		if err := m.serialize(&b); err != nil {
+			return nil, err
+		}
+
+		if err := serializeEvents(&b, swap.Events); err != nil {
+			return nil, err
+		}
+	}
+
+	err = binary.Write(&b, byteOrder, uint32(len(backup.loopIns)))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, swap := range backup.loopIns {
+		if _, err := b.Write(swap.Hash[:]); err != nil {
+			return nil, err
+		}
+
+		m := make(kvMap)
+		if err := putLoopInContract(m, swap.Contract); err != nil {
+			return nil, err
+		}
+
+		if err := m.serialize(&b); err != nil {
+			return nil, err
+		}
+
+		if err := serializeEvents(&b, swap.Events); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.Bytes(), nil
+}
+
+// deserializeBackup deserializes swaps that were serialized with
+// serializeBackup.
+func deserializeBackup(plaintext []byte,
+	chainParams *chaincfg.Params) (*swapBackup, error) {
+
+	r := bytes.NewReader(plaintext)
+
+	version, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if version != backupVersion {
+		return nil, ErrBackupUnknownVersion
+	}
+
+	backup := &swapBackup{}
+
+	var count uint32
+	if err := binary.Read(r, byteOrder, &count); err != nil {
+		return nil, err
+	}
+
+	for i := uint32(0); i < count; i++ {
+		swap := &LoopOut{}
+		if _, err := io.ReadFull(r, swap.Hash[:]); err != nil {
+			return nil, err
+		}
+
+		m, err := deserializeKVMap(r)
+		if err != nil {
+			return nil, err
+		}
+
+		swap.Contract, err = getLoopOutContract(m, chainParams)
+		if err != nil {
+			return nil, err
+		}
+
+		swap.Events, err = deserializeEvents(r)
+		if err != nil {
+			return nil, err
+		}
+
+		backup.loopOuts = append(backup.loopOuts, swap)
+	}
+
+	if err := binary.Read(r, byteOrder, &count); err != nil {
+		return nil, err
+	}
+
+	for i := uint32(0); i < count; i++ {
+		swap := &LoopIn{}
+		if _, err := io.ReadFull(r, swap.Hash[:]); err != nil {
+			return nil, err
+		}
+
+		m, err := deserializeKVMap(r)
+		if err != nil {
+			return nil, err
+		}
+
+		swap.Contract, err = getLoopInContract(m, chainParams)
+		if err != nil {
+			return nil, err
+		}
+
+		swap.Events, err = deserializeEvents(r)
+		if err != nil {
+			return nil, err
+		}
+
+		backup.loopIns = append(backup.loopIns, swap)
+	}
+
+	return backup, nil
+}
+
+// encryptBackup encrypts the plaintext provided with XChaCha20-Poly1305. The
+// random nonce is prepended to the ciphertext.
+func encryptBackup(key *BackupKey, plaintext []byte) ([]byte, error) {
+	cipher, err := chacha20poly1305.NewX(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return cipher.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// decryptBackup decrypts a ciphertext that was created with encryptBackup.
+func decryptBackup(key *BackupKey, ciphertext []byte) ([]byte, error) {
+	cipher, err := chacha20poly1305.NewX(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < chacha20poly1305.NonceSizeX {
+		return nil, errors.New("swap backup too short")
+	}
+
+	nonce := ciphertext[:chacha20poly1305.NonceSizeX]
+	ciphertext = ciphertext[chacha20poly1305.NonceSizeX:]
+
+	plaintext, err := cipher.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt swap backup: %v", err)
+	}
+
+	return plaintext, nil
+}
+
+// writeFileAtomic replaces the file at the path provided with the content
+// provided. The content is written to a temporary file first, which is then
+// renamed, so that the file is never left partially written. Both the file and
+// its directory are synced, so that the new content survives a crash once this
+// function returns.
+func writeFileAtomic(path string, content []byte) error {
+	tempPath := path + ".tmp"
+
+	file, err := os.OpenFile(
+		tempPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600,
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(content); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tempPath, path); err != nil {
+		return err
+	}
+
+	return syncDir(filepath.Dir(path))
+}
+
+// syncDir syncs the directory at the path provided, so that renames of files
+// in it are persisted.
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	if err := dir.Sync(); err != nil {
+		_ = dir.Close()
+		return err
+	}
+
+	return dir.Close()
+}
+
+// backupSwapStore is a swap store that writes an encrypted backup of all
+// pending swaps every time swaps are created or updated. The pending swaps are
+// kept in memory, so that the backup is written without reading them from the
+// store again. Every store call writes the backup once, no matter how many
+// swaps it changes.
+type backupSwapStore struct {
+	SwapStore
+
+	path string
+	key  *BackupKey
+
+	// backupLock guards the pending swaps and serializes backup writes.
+	backupLock sync.Mutex
+
+	// loopOuts and loopIns are the pending swaps that are backed up.
+	loopOuts map[lntypes.Hash]*LoopOut
+	loopIns  map[lntypes.Hash]*LoopIn
+}
+
+// A compile-time flag to ensure that backupSwapStore implements the SwapStore
+// interface.
+var _ SwapStore = (*backupSwapStore)(nil)
+
+// NewBackupSwapStore wraps the store provided so that an encrypted backup of
+// its pending swaps is kept in the file at the path provided. The backup is
+// written immediately so that it covers swaps that already exist. To prevent
+// a backup from being overwritten after the database was lost, an error is
+// returned if an existing backup contains swaps that are missing from the
+// store. Those swaps must be restored with RestoreBackup first.
+func NewBackupSwapStore(store SwapStore, path string, key *BackupKey,
+	chainParams *chaincfg.Params) (SwapStore, error) {
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+
+	if fileExists(path) {
+		backup, err := readBackup(path, key, chainParams)
+		if err != nil {
+			return nil, err
+		}
+
+		missing, err := missingSwaps(store, backup)
+		if err != nil {
+			return nil, err
+		}
+
+		count := len(missing.loopOuts) + len(missing.loopIns)
+		if count > 0 {
+			return nil, fmt.Errorf("swap backup %v contains %v "+
+				"swaps that are missing from the database, "+
+				"restore them before continuing", path, count)
+		}
+	}
+
+	// Swaps that have reached a final state don't have any funds that
+	// need to be recovered, so we only read and back up pending swaps.
+	page, err := store.QuerySwaps(&SwapQuery{
+		StateTypes: []SwapStateType{StateTypePending},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &backupSwapStore{
+		SwapStore: store,
+		path:      path,
+		key:       key,
+		loopOuts:  make(map[lntypes.Hash]*LoopOut),
+		loopIns:   make(map[lntypes.Hash]*LoopIn),
+	}
+
+	for _, swap := range page.LoopOuts {
+		s.loopOuts[swap.Hash] = swap
+	}
+	for _, swap := range page.LoopIns {
+		s.loopIns[swap.Hash] = swap
+	}
+
+	if err := s.writeBackup(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// writeBackup replaces the backup file with a backup of the pending swaps. The
+// caller must hold the backup lock.
+func (s *backupSwapStore) writeBackup() error {
+	backup := &swapBackup{
+		loopOuts: make([]*LoopOut, 0, len(s.loopOuts)),
+		loopIns:  make([]*LoopIn, 0, len(s.loopIns)),
+	}
+	for _, swap := range s.loopOuts {
+		backup.loopOuts = append(backup.loopOuts, swap)
+	}
+	for _, swap := range s.loopIns {
+		backup.loopIns = append(backup.loopIns, swap)
+	}
+
+	// Sort the swaps so that the backup doesn't depend on map order.
+	sort.Slice(backup.loopOuts, func(i, j int) bool {
+		return bytes.Compare(
+			backup.loopOuts[i].Hash[:], backup.loopOuts[j].Hash[:],
+		) < 0
+	})
+	sort.Slice(backup.loopIns, func(i, j int) bool {
+		return bytes.Compare(
+			backup.loopIns[i].Hash[:], backup.loopIns[j].Hash[:],
+		) < 0
+	})
+
+	plaintext, err := serializeBackup(backup)
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := encryptBackup(s.key, plaintext)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.path, ciphertext)
+}
+
+// updateBackup applies a change to the pending swaps and writes the backup.
+// Because the change has already been stored, failing to write the backup is
+// only logged. The backup is written again with the next change.
+func (s *backupSwapStore) updateBackup(change func()) {
+	s.backupLock.Lock()
+	defer s.backupLock.Unlock()
+
+	change()
+
+	if err := s.writeBackup(); err != nil {
+		log.Errorf("Unable to write swap backup: %v", err)
+	}
+}
+
+// addLoopOutEvent adds an update to a pending loop out and removes the swap
+// from the backup if it reached a final state. The caller must hold the backup
+// lock.
+func (s *backupSwapStore) addLoopOutEvent(hash lntypes.Hash, time time.Time,
+	state SwapStateData) {
+
+	swap, ok := s.loopOuts[hash]
+	if !ok {
+		return
+	}
+
+	if state.State.Type() != StateTypePending {
+		delete(s.loopOuts, hash)
+		return
+	}
+
+	swap.Events = append(swap.Events, &LoopEvent{
+		SwapStateData: state,
+		Time:          time,
+	})
+}
+
+// addLoopInEvent adds an update to a pending loop in and removes the swap from
+// the backup if it reached a final state. The caller must hold the backup
+// lock.
+func (s *backupSwapStore) addLoopInEvent(hash lntypes.Hash, time time.Time,
+	state SwapStateData) {
+
+	swap, ok := s.loopIns[hash]
+	if !ok {
+		return
+	}
+
+	if state.State.Type() != StateTypePending {
+		delete(s.loopIns, hash)
+		return
+	}
+
+	swap.Events = append(swap.Events, &LoopEvent{
+		SwapStateData: state,
+		Time:          time,
+	})
+}
+
+// CreateLoopOut adds an initiated swap to the store and updates the backup.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *backupSwapStore) CreateLoopOut(hash lntypes.Hash,
+	swap *LoopOutContract) error {
+
+	if err := s.SwapStore.CreateLoopOut(hash, swap); err != nil {
+		return err
+	}
+
+	contract := *swap
+	s.updateBackup(func() {
+		s.loopOuts[hash] = &LoopOut{
+			Loop:     Loop{Hash: hash},
+			Contract: &contract,
+		}
+	})
+
+	return nil
+}
+
+// UpdateLoopOut stores a swap update and updates the backup.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *backupSwapStore) UpdateLoopOut(hash lntypes.Hash, time time.Time,
+	state SwapStateData) error {
+
+	err := s.SwapStore.UpdateLoopOut(hash, time, state)
+	if err != nil {
+		return err
+	}
+
+	s.updateBackup(func() {
+		s.addLoopOutEvent(hash, time, state)
+	})
+
+	return nil
+}
+
+// UpdateLoopOuts stores the swap updates and updates the backup.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *backupSwapStore) UpdateLoopOuts(time time.Time,
+	updates map[lntypes.Hash]SwapStateData) error {
+
+	err := s.SwapStore.UpdateLoopOuts(time, updates)
+	if err != nil {
+		return err
+	}
+
+	s.updateBackup(func() {
+		for hash, state := range updates {
+			s.addLoopOutEvent(hash, time, state)
+		}
+	})
+
+	return nil
+}
+
+// CreateLoopIn adds an initiated swap to the store and updates the backup.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *backupSwapStore) CreateLoopIn(hash lntypes.Hash,
+	swap *LoopInContract) error {
+
+	if err := s.SwapStore.CreateLoopIn(hash, swap); err != nil {
+		return err
+	}
+
+	contract := *swap
+	s.updateBackup(func() {
+		s.loopIns[hash] = &LoopIn{
+			Loop:     Loop{Hash: hash},
+			Contract: &contract,
+		}
+	})
+
+	return nil
+}
+
+// UpdateLoopIn stores a swap update and updates the backup.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *backupSwapStore) UpdateLoopIn(hash lntypes.Hash, time time.Time,
+	state SwapStateData) error {
+
+	err := s.SwapStore.UpdateLoopIn(hash, time, state)
+	if err != nil {
+		return err
+	}
+
+	s.updateBackup(func() {
+		s.addLoopInEvent(hash, time, state)
+	})
+
+	return nil
+}
+
+// UpdateLoopIns stores the swap updates and updates the backup.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *backupSwapStore) UpdateLoopIns(time time.Time,
+	updates map[lntypes.Hash]SwapStateData) error {
+
+	err := s.SwapStore.UpdateLoopIns(time, updates)
+	if err != nil {
+		return err
+	}
+
+	s.updateBackup(func() {
+		for hash, state := range updates {
+			s.addLoopInEvent(hash, time, state)
+		}
+	})
+
+	return nil
+}
+
+// readBackup reads and decrypts the swap backup at the path provided.
+func readBackup(path string, key *BackupKey,
+	chainParams *chaincfg.Params) (*swapBackup, error) {
+
+	ciphertext, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := decryptBackup(key, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	return deserializeBackup(plaintext, chainParams)
+}
+
+// ReadBackup reads and decrypts the swap backup at the path provided and
+// returns the swaps that it contains.
+func ReadBackup(path string, key *BackupKey, chainParams *chaincfg.Params) (
+	[]*LoopOut, []*LoopIn, error) {
+
+	backup, err := readBackup(path, key, chainParams)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return backup.loopOuts, backup.loopIns, nil
+}
+
+// missingSwaps returns the swaps of a backup that don't exist in the store.
+// Swaps that completed and were archived after the backup was written exist
+// in the store as well.
+func missingSwaps(store SwapStore, backup *swapBackup) (*swapBackup, error) {
+	loopOuts, loopIns, err := fetchAllSwaps(store)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[lntypes.Hash]bool)
+	for _, swap := range loopOuts {
+		known[swap.Hash] = true
+	}
+	for _, swap := range loopIns {
+		known[swap.Hash] = true
+	}
+
+	missing := &swapBackup{}
+	for _, swap := range backup.loopOuts {
+		if !known[swap.Hash] {
+			missing.loopOuts = append(missing.loopOuts, swap)
+		}
+	}
+	for _, swap := range backup.loopIns {
+		if !known[swap.Hash] {
+			missing.loopIns = append(missing.loopIns, swap)
+		}
+	}
+
+	return missing, nil
+}
+
+// RestoreBackup decrypts the swap backup at the path provided and adds the
+// swaps that it contains to the store. Swaps that already exist in the store
+// are skipped. The number of restored swaps is returned.
+func RestoreBackup(store SwapStore, path string, key *BackupKey,
+	chainParams *chaincfg.Params) (int, error) {
+
+	backup, err := readBackup(path, key, chainParams)
+	if err != nil {
+		return 0, err
+	}
+
+	missing, err := missingSwaps(store, backup)
+	if err != nil {
+		return 0, err
+	}
+
+	return addSwaps(store, missing.loopOuts, missing.loopIns)
+}
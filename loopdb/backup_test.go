@@ -0,0 +1,275 @@
+package loopdb
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/loop/test"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSwapBackup tests that pending swaps are written to an encrypted backup
+// and can be restored into an empty store from it.
+func TestSwapBackup(t *testing.T) {
+	tempDirName, err := ioutil.TempDir("", "clientstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDirName)
+
+	params := &chaincfg.MainNetParams
+	backupPath := filepath.Join(tempDirName, BackupFileName)
+	key := &BackupKey{1, 2, 3}
+
+	boltStore, err := NewBoltSwapStore(
+		filepath.Join(tempDirName, "lost"), params,
+	)
+	require.NoError(t, err)
+	defer boltStore.Close()
+
+	store, err := NewBackupSwapStore(boltStore, backupPath, key, params)
+	require.NoError(t, err)
+
+	contract := SwapContract{
+		AmountRequested:  100,
+		Preimage:         testPreimage,
+		CltvExpiry:       144,
+		SenderKey:        senderKey,
+		ReceiverKey:      receiverKey,
+		MaxMinerFee:      10,
+		MaxSwapFee:       20,
+		InitiationHeight: 99,
+		InitiationTime:   time.Unix(0, testTime.UnixNano()),
+		Label:            "backup",
+	}
+
+	// Create a loop out that has revealed its preimage.
+	loopOut := &LoopOutContract{
+		SwapContract:      contract,
+		DestAddr:          test.GetDestAddr(t, 0),
+		SwapInvoice:       "swapinvoice",
+		PrepayInvoice:     "prepayinvoice",
+		SweepConfTarget:   2,
+		HtlcConfirmations: 2,
+		OutgoingChanSet:   ChannelSet{1, 2},
+		SweepDestinations: []SweepDestination{
+			{Addr: test.GetDestAddr(t, 1), Weight: 1},
+		},
+		SwapPublicationDeadline: time.Unix(0, testTime.UnixNano()),
+	}
+	loopOutHash := testPreimage.Hash()
+	require.NoError(t, store.CreateLoopOut(loopOutHash, loopOut))
+	require.NoError(t, store.UpdateLoopOut(
+		loopOutHash, testTime, SwapStateData{
+			State:       StatePreimageRevealed,
+			HtlcTxHash:  &chainhash.Hash{1},
+			SweepTxHash: &chainhash.Hash{2},
+			SweepFee:    300,
+		},
+	))
+
+	// Create a loop in that is waiting for its htlc to be swept.
+	loopInPreimage := lntypes.Preimage{5}
+	loopIn := &LoopInContract{
+		SwapContract:   contract,
+		HtlcConfTarget: 2,
+		HtlcFundingOutpoints: []wire.OutPoint{
+			{Hash: chainhash.Hash{3}, Index: 1},
+		},
+	}
+	loopIn.Preimage = loopInPreimage
+	loopInHash := loopInPreimage.Hash()
+	require.NoError(t, store.CreateLoopIn(loopInHash, loopIn))
+	require.NoError(t, store.UpdateLoopIn(
+		loopInHash, testTime, SwapStateData{
+			State:      StateHtlcPublished,
+			HtlcTxHash: &chainhash.Hash{4},
+		},
+	))
+
+	// Create a loop out that has already completed and doesn't need to be
+	// backed up.
+	successPreimage := lntypes.Preimage{6}
+	successSwap := *loopOut
+	successSwap.Preimage = successPreimage
+	require.NoError(t, store.CreateLoopOut(
+		successPreimage.Hash(), &successSwap,
+	))
+	require.NoError(t, store.UpdateLoopOut(
+		successPreimage.Hash(), testTime, SwapStateData{
+			State: StateSuccess,
+		},
+	))
+
+	pendingOuts, err := boltStore.FetchLoopOutSwaps()
+	require.NoError(t, err)
+	pendingIns, err := boltStore.FetchLoopInSwaps()
+	require.NoError(t, err)
+
+	// Open an empty store, as if the database was lost. Because the
+	// backup contains swaps that this store doesn't know, we may not
+	// overwrite it.
+	newStore, err := NewBoltSwapStore(
+		filepath.Join(tempDirName, "new"), params,
+	)
+	require.NoError(t, err)
+	defer newStore.Close()
+
+	_, err = NewBackupSwapStore(newStore, backupPath, key, params)
+	require.Error(t, err)
+
+	// The backup can't be restored with a different key.
+	_, err = RestoreBackup(newStore, backupPath, &BackupKey{9}, params)
+	require.Error(t, err)
+
+	restored, err := RestoreBackup(newStore, backupPath, key, params)
+	require.NoError(t, err)
+	require.Equal(t, 2, restored)
+
+	loopOuts, err := newStore.FetchLoopOutSwaps()
+	require.NoError(t, err)
+	require.Len(t, loopOuts, 1)
+
+	for _, swap := range pendingOuts {
+		if swap.Hash == loopOutHash {
+			require.Equal(t, swap, loopOuts[0])
+		}
+	}
+
+	loopIns, err := newStore.FetchLoopInSwaps()
+	require.NoError(t, err)
+	require.Equal(t, pendingIns, loopIns)
+
+	// Restoring again doesn't create any duplicates, and the backup may
+	// now be written by the restored store.
+	restored, err = RestoreBackup(newStore, backupPath, key, params)
+	require.NoError(t, err)
+	require.Zero(t, restored)
+
+	_, err = NewBackupSwapStore(newStore, backupPath, key, params)
+	require.NoError(t, err)
+}
+
+// TestSwapBackupWriteFailure tests that a swap is still created if the backup
+// can't be written, because the swap is already stored at that point.
+func TestSwapBackupWriteFailure(t *testing.T) {
+	tempDirName, err := ioutil.TempDir("", "clientstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDirName)
+
+	params := &chaincfg.MainNetParams
+	backupPath := filepath.Join(tempDirName, BackupFileName)
+
+	boltStore, err := NewBoltSwapStore(tempDirName, params)
+	require.NoError(t, err)
+	defer boltStore.Close()
+
+	store, err := NewBackupSwapStore(
+		boltStore, backupPath, &BackupKey{1}, params,
+	)
+	require.NoError(t, err)
+
+	// Replace the backup with a non-empty directory, so that it can't be
+	// overwritten anymore.
+	require.NoError(t, os.Remove(backupPath))
+	require.NoError(t, os.MkdirAll(filepath.Join(backupPath, "dir"), 0700))
+
+	loopIn := &LoopInContract{
+		SwapContract: SwapContract{
+			AmountRequested:  100,
+			Preimage:         testPreimage,
+			CltvExpiry:       144,
+			SenderKey:        senderKey,
+			ReceiverKey:      receiverKey,
+			MaxMinerFee:      10,
+			MaxSwapFee:       20,
+			InitiationHeight: 99,
+			InitiationTime:   time.Unix(0, testTime.UnixNano()),
+		},
+		HtlcConfTarget: 2,
+	}
+	require.NoError(t, store.CreateLoopIn(testPreimage.Hash(), loopIn))
+
+	loopIns, err := boltStore.FetchLoopInSwaps()
+	require.NoError(t, err)
+	require.Len(t, loopIns, 1)
+}
+
+// TestSwapBackupBatchUpdate tests that the backup follows updates that are
+// stored for several swaps at once, and that swaps are removed from it once
+// they complete.
+func TestSwapBackupBatchUpdate(t *testing.T) {
+	tempDirName, err := ioutil.TempDir("", "clientstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDirName)
+
+	params := &chaincfg.MainNetParams
+	backupPath := filepath.Join(tempDirName, BackupFileName)
+	key := &BackupKey{1}
+
+	boltStore, err := NewBoltSwapStore(tempDirName, params)
+	require.NoError(t, err)
+	defer boltStore.Close()
+
+	store, err := NewBackupSwapStore(boltStore, backupPath, key, params)
+	require.NoError(t, err)
+
+	var hashes []lntypes.Hash
+	for i := byte(1); i <= 2; i++ {
+		loopIn := &LoopInContract{
+			SwapContract: SwapContract{
+				AmountRequested: 100,
+				Preimage:        lntypes.Preimage{i},
+				CltvExpiry:      144,
+				SenderKey:       senderKey,
+				ReceiverKey:     receiverKey,
+				InitiationTime: time.Unix(
+					0, testTime.UnixNano(),
+				),
+			},
+			HtlcConfTarget: 2,
+		}
+		hash := loopIn.Preimage.Hash()
+		require.NoError(t, store.CreateLoopIn(hash, loopIn))
+
+		hashes = append(hashes, hash)
+	}
+
+	// Publish the htlcs of both swaps in one update.
+	htlcTxHash := &chainhash.Hash{1}
+	require.NoError(t, store.UpdateLoopIns(
+		testTime, map[lntypes.Hash]SwapStateData{
+			hashes[0]: {
+				State:      StateHtlcPublished,
+				HtlcTxHash: htlcTxHash,
+			},
+			hashes[1]: {
+				State:      StateHtlcPublished,
+				HtlcTxHash: htlcTxHash,
+			},
+		},
+	))
+
+	_, loopIns, err := ReadBackup(backupPath, key, params)
+	require.NoError(t, err)
+	require.Len(t, loopIns, 2)
+
+	stored, err := boltStore.FetchLoopInSwaps()
+	require.NoError(t, err)
+	require.ElementsMatch(t, stored, loopIns)
+
+	// Once the first swap completed, only the second one is backed up.
+	require.NoError(t, store.UpdateLoopIn(
+		hashes[0], testTime, SwapStateData{State: StateSuccess},
+	))
+
+	_, loopIns, err = ReadBackup(backupPath, key, params)
+	require.NoError(t, err)
+	require.Len(t, loopIns, 1)
+	require.Equal(t, hashes[1], loopIns[0].Hash)
+}
@@ -0,0 +1,598 @@
+package loopdb
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/coreos/bbolt"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/zpay32"
+)
+
+var (
+	// loopOutQuarantineBucketKey is a bucket that contains the out swaps
+	// that couldn't be read by the database check. It has the same layout
+	// as the loopOutBucket, swaps are moved into it when the database is
+	// repaired.
+	//
+	// maps: swapHash -> swapBucket
+	loopOutQuarantineBucketKey = []byte("uncharge-swaps-quarantine")
+
+	// loopInQuarantineBucketKey is a bucket that contains the in swaps
+	// that couldn't be read by the database check. It has the same layout
+	// as the loopInBucket, swaps are moved into it when the database is
+	// repaired.
+	//
+	// maps: swapHash -> swapBucket
+	loopInQuarantineBucketKey = []byte("loop-in-quarantine")
+
+	// ErrRepairNotSupported is returned when a database of a backend that
+	// doesn't support repairs is checked with repair enabled.
+	ErrRepairNotSupported = errors.New("repair is only supported for " +
+		"bbolt databases")
+)
+
+// CheckProblem is a problem with a swap that was found by CheckDatabase.
+type CheckProblem struct {
+	// Swap is the hex encoded hash of the swap.
+	Swap string
+
+	// SwapType is the type of the swap.
+	SwapType swap.Type
+
+	// Archived is true if the swap has been archived.
+	Archived bool
+
+	// Description describes the problem.
+	Description string
+
+	// Unreadable is true if the swap can't be read from the database.
+	// Unreadable swaps prevent all other swaps from being loaded.
+	Unreadable bool
+
+	// Repaired is true if the problem was repaired.
+	Repaired bool
+}
+
+// String returns a string representation of the problem.
+func (p *CheckProblem) String() string {
+	s := fmt.Sprintf("%v %v: %v", p.SwapType, p.Swap, p.Description)
+	if p.Archived {
+		s += " (archived)"
+	}
+	if p.Repaired {
+		s += ", moved to quarantine"
+	}
+
+	return s
+}
+
+// CheckReport is the result of a database check.
+type CheckReport struct {
+	// Backend is the backend of the checked database.
+	Backend Backend
+
+	// Version is the version of the checked database.
+	Version uint32
+
+	// LoopOuts is the number of loop out swaps that were checked.
+	LoopOuts int
+
+	// LoopIns is the number of loop in swaps that were checked.
+	LoopIns int
+
+	// Problems are the problems that were found.
+	Problems []*CheckProblem
+}
+
+// addProblem adds a problem with the swap provided to the report.
+func (r *CheckReport) addProblem(swapKey []byte, swapType swap.Type,
+	archived, unreadable bool, format string, args ...interface{}) {
+
+	r.Problems = append(r.Problems, &CheckProblem{
+		Swap:        hex.EncodeToString(swapKey),
+		SwapType:    swapType,
+		Archived:    archived,
+		Description: fmt.Sprintf(format, args...),
+		Unreadable:  unreadable,
+	})
+}
+
+// CheckDatabase checks every swap in the swap database of the backend provided
+// in the directory provided, including archived swaps. It checks that the
+// contract and updates of every swap can be read and that they are
+// consistent. The database must not be in use and must have the latest
+// version. If repair is set, swaps that can't be read are moved to a
+// quarantine bucket, so that the remaining swaps can be loaded again. Repair
+// is only supported for bbolt databases.
+func CheckDatabase(backend Backend, dbPath string,
+	chainParams *chaincfg.Params, repair bool) (*CheckReport, error) {
+
+	switch backend {
+	case BackendBolt:
+		return checkBoltDatabase(
+			filepath.Join(dbPath, dbFileName), chainParams, repair,
+		)
+
+	case BackendSqlite:
+		if repair {
+			return nil, ErrRepairNotSupported
+		}
+
+		return checkSqliteDatabase(
+			filepath.Join(dbPath, SqliteFileName), chainParams,
+		)
+
+	default:
+		return nil, fmt.Errorf("unknown database backend: %v", backend)
+	}
+}
+
+// checkBoltDatabase checks all swaps of a bbolt database.
+func checkBoltDatabase(path string, chainParams *chaincfg.Params,
+	repair bool) (*CheckReport, error) {
+
+	if !fileExists(path) {
+		return nil, fmt.Errorf("database %v not found", path)
+	}
+
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{
+		ReadOnly: !repair,
+		Timeout:  snapshotOpenTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open database, make sure that loopd "+
+			"isn't running: %v", err)
+	}
+	defer db.Close()
+
+	version, err := getDBVersion(db)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != latestDBVersion {
+		return nil, fmt.Errorf("database has version %v, start loopd "+
+			"once to migrate it to version %v", version,
+			latestDBVersion)
+	}
+
+	report := &CheckReport{
+		Backend: BackendBolt,
+		Version: version,
+	}
+
+	err = db.View(func(tx *bbolt.Tx) error {
+		swapTypes := []swap.Type{swap.TypeOut, swap.TypeIn}
+		for _, swapType := range swapTypes {
+			for _, archived := range []bool{false, true} {
+				err := checkBoltSwaps(
+					tx, swapType, archived, chainParams,
+					report,
+				)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !repair {
+		return report, nil
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, problem := range report.Problems {
+			if !problem.Unreadable {
+				continue
+			}
+
+			if err := quarantineSwap(tx, problem); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, problem := range report.Problems {
+		problem.Repaired = problem.Unreadable
+	}
+
+	return report, nil
+}
+
+// checkBoltSwaps checks the swaps of the type provided in a bbolt database.
+func checkBoltSwaps(tx *bbolt.Tx, swapType swap.Type, archived bool,
+	chainParams *chaincfg.Params, report *CheckReport) error {
+
+	rootBucket := swapRootBucket(tx, swapType, archived)
+	if rootBucket == nil {
+		// Databases that were only opened by older versions don't
+		// have archive buckets.
+		if archived {
+			return nil
+		}
+
+		return fmt.Errorf("%v bucket does not exist", swapType)
+	}
+
+	return rootBucket.ForEach(func(swapKey, v []byte) error {
+		// Only go into things that we know are sub-bucket keys.
+		if v != nil {
+			return nil
+		}
+
+		addProblem := func(unreadable bool, format string,
+			args ...interface{}) {
+
+			report.addProblem(
+				swapKey, swapType, archived, unreadable,
+				format, args...,
+			)
+		}
+
+		hash, err := lntypes.MakeHash(swapKey)
+		if err != nil {
+			addProblem(true, "invalid swap hash: %v", err)
+			return nil
+		}
+
+		swapBucket := rootBucket.Bucket(swapKey)
+
+		switch swapType {
+		case swap.TypeOut:
+			report.LoopOuts++
+
+			loopOut, err := fetchLoopOut(
+				swapBucket, swapKey, chainParams,
+			)
+			if err != nil {
+				addProblem(true, "unreadable: %v", err)
+				return nil
+			}
+
+			for _, problem := range checkLoopOut(
+				hash, loopOut, chainParams,
+			) {
+				addProblem(false, "%v", problem)
+			}
+
+		case swap.TypeIn:
+			report.LoopIns++
+
+			loopIn, err := fetchLoopIn(
+				swapBucket, swapKey, chainParams,
+			)
+			if err != nil {
+				addProblem(true, "unreadable: %v", err)
+				return nil
+			}
+
+			for _, problem := range checkLoopIn(hash, loopIn) {
+				addProblem(false, "%v", problem)
+			}
+		}
+
+		return nil
+	})
+}
+
+// quarantineBucketKey returns the key of the bucket that unreadable swaps of
+// the type provided are moved to.
+func quarantineBucketKey(swapType swap.Type) []byte {
+	if swapType == swap.TypeIn {
+		return loopInQuarantineBucketKey
+	}
+
+	return loopOutQuarantineBucketKey
+}
+
+// quarantineSwap moves the swap that a problem was found with from its root
+// bucket to the quarantine bucket of its swap type.
+func quarantineSwap(tx *bbolt.Tx, problem *CheckProblem) error {
+	swapKey, err := hex.DecodeString(problem.Swap)
+	if err != nil {
+		return err
+	}
+
+	rootBucket := swapRootBucket(tx, problem.SwapType, problem.Archived)
+	if rootBucket == nil {
+		return fmt.Errorf("%v bucket does not exist", problem.SwapType)
+	}
+
+	swapBucket := rootBucket.Bucket(swapKey)
+	if swapBucket == nil {
+		return fmt.Errorf("swap %v not found", problem.Swap)
+	}
+
+	quarantineBucket, err := tx.CreateBucketIfNotExists(
+		quarantineBucketKey(problem.SwapType),
+	)
+	if err != nil {
+		return err
+	}
+
+	// A swap with the same hash may already have been quarantined from
+	// the archive.
+	if quarantineBucket.Bucket(swapKey) != nil {
+		return fmt.Errorf("swap %v already quarantined", problem.Swap)
+	}
+
+	quarantinedBucket, err := quarantineBucket.CreateBucket(swapKey)
+	if err != nil {
+		return err
+	}
+
+	if err := copyBucket(quarantinedBucket, swapBucket); err != nil {
+		return err
+	}
+
+	return rootBucket.DeleteBucket(swapKey)
+}
+
+// checkSqliteDatabase checks all swaps of a sqlite database.
+func checkSqliteDatabase(path string, chainParams *chaincfg.Params) (
+	*CheckReport, error) {
+
+	if !fileExists(path) {
+		return nil, fmt.Errorf("database %v not found", path)
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	version, err := getSqlVersion(db)
+	if err != nil {
+		return nil, err
+	}
+
+	latestVersion := uint32(len(sqlMigrations))
+	if version != latestVersion {
+		return nil, fmt.Errorf("database has version %v, start loopd "+
+			"once to migrate it to version %v", version,
+			latestVersion)
+	}
+
+	report := &CheckReport{
+		Backend: BackendSqlite,
+		Version: version,
+	}
+
+	type sqlSwapRow struct {
+		id       int64
+		hash     []byte
+		swapType swap.Type
+		archived bool
+	}
+
+	rows, err := db.Query(
+		"SELECT id, swap_hash, swap_type, archived FROM swaps " +
+			"ORDER BY id",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var swapRows []sqlSwapRow
+	for rows.Next() {
+		var row sqlSwapRow
+		err := rows.Scan(
+			&row.id, &row.hash, &row.swapType, &row.archived,
+		)
+		if err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+
+		swapRows = append(swapRows, row)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	store := &sqliteSwapStore{db: db, chainParams: chainParams}
+	for _, row := range swapRows {
+		addProblem := func(unreadable bool, format string,
+			args ...interface{}) {
+
+			report.addProblem(
+				row.hash, row.swapType, row.archived,
+				unreadable, format, args...,
+			)
+		}
+
+		hash, err := lntypes.MakeHash(row.hash)
+		if err != nil {
+			addProblem(true, "invalid swap hash: %v", err)
+			continue
+		}
+
+		// Every swap is loaded on its own, so that a swap that can't
+		// be read doesn't hide the problems of other swaps.
+		filter := &swapFilter{
+			query: "SELECT id FROM swaps WHERE id = ?",
+			args:  []interface{}{row.id},
+		}
+
+		switch row.swapType {
+		case swap.TypeOut:
+			report.LoopOuts++
+
+			loopOuts, err := store.loadLoopOuts(filter)
+			if err != nil {
+				addProblem(true, "unreadable: %v", err)
+				continue
+			}
+
+			if len(loopOuts) != 1 {
+				addProblem(true, "loop out contract not found")
+				continue
+			}
+
+			for _, problem := range checkLoopOut(
+				hash, loopOuts[0], chainParams,
+			) {
+				addProblem(false, "%v", problem)
+			}
+
+		case swap.TypeIn:
+			report.LoopIns++
+
+			loopIns, err := store.loadLoopIns(filter)
+			if err != nil {
+				addProblem(true, "unreadable: %v", err)
+				continue
+			}
+
+			if len(loopIns) != 1 {
+				addProblem(true, "loop in contract not found")
+				continue
+			}
+
+			for _, problem := range checkLoopIn(hash, loopIns[0]) {
+				addProblem(false, "%v", problem)
+			}
+
+		default:
+			addProblem(true, "unknown swap type %v", row.swapType)
+		}
+	}
+
+	return report, nil
+}
+
+// checkLoopOut returns the problems of a loop out swap that could be read.
+func checkLoopOut(hash lntypes.Hash, loopOut *LoopOut,
+	chainParams *chaincfg.Params) []string {
+
+	problems := checkSwap(
+		hash, &loopOut.Contract.SwapContract, &loopOut.Loop,
+	)
+
+	swapInvoice, err := zpay32.Decode(
+		loopOut.Contract.SwapInvoice, chainParams,
+	)
+	switch {
+	case err != nil:
+		problems = append(problems, fmt.Sprintf("invalid swap "+
+			"invoice: %v", err))
+
+	case swapInvoice.PaymentHash == nil ||
+		*swapInvoice.PaymentHash != hash:
+
+		problems = append(problems, "swap invoice doesn't pay to swap "+
+			"hash")
+	}
+
+	_, err = zpay32.Decode(loopOut.Contract.PrepayInvoice, chainParams)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("invalid prepay "+
+			"invoice: %v", err))
+	}
+
+	return problems
+}
+
+// checkLoopIn returns the problems of a loop in swap that could be read.
+func checkLoopIn(hash lntypes.Hash, loopIn *LoopIn) []string {
+	problems := checkSwap(
+		hash, &loopIn.Contract.SwapContract, &loopIn.Loop,
+	)
+
+	for i, event := range loopIn.Events {
+		if event.HtlcTx == nil || event.HtlcTxHash == nil {
+			continue
+		}
+
+		if event.HtlcTx.TxHash() != *event.HtlcTxHash {
+			problems = append(problems, fmt.Sprintf("update %v: "+
+				"htlc tx doesn't match htlc txid %v", i,
+				event.HtlcTxHash))
+		}
+	}
+
+	return problems
+}
+
+// checkSwap returns the problems with the contract and updates that all swaps
+// share.
+func checkSwap(hash lntypes.Hash, contract *SwapContract, loop *Loop) []string {
+	var problems []string
+	addProblem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if contract.Preimage.Hash() != hash {
+		addProblem("preimage doesn't match swap hash")
+	}
+
+	_, err := btcec.ParsePubKey(contract.SenderKey[:], btcec.S256())
+	if err != nil {
+		addProblem("invalid sender key: %v", err)
+	}
+
+	_, err = btcec.ParsePubKey(contract.ReceiverKey[:], btcec.S256())
+	if err != nil {
+		addProblem("invalid receiver key: %v", err)
+	}
+
+	if contract.ProtocolVersion != ProtocolVersionUnrecorded &&
+		!contract.ProtocolVersion.Valid() {
+
+		addProblem("unknown protocol version %d",
+			uint32(contract.ProtocolVersion))
+	}
+
+	for i, event := range loop.Events {
+		if event.State > StateFailIncorrectHtlcAmt {
+			addProblem("update %v: unknown state %d", i,
+				uint8(event.State))
+		}
+
+		if i == 0 {
+			continue
+		}
+
+		prev := loop.Events[i-1]
+		if event.Time.Before(prev.Time) {
+			addProblem("update %v at %v is older than update %v "+
+				"at %v", i, event.Time, i-1, prev.Time)
+		}
+
+		if prev.State.Type() == StateTypePending {
+			continue
+		}
+
+		// A final state may only be followed by a pending state if
+		// the tx that spent the htlc was reorged out. The spend is
+		// cleared when the swap reverts to its previous state.
+		reverted := event.State.Type() == StateTypePending &&
+			prev.SpendTxHash != nil && event.SpendTxHash == nil
+
+		if !reverted {
+			addProblem("update %v to state %v follows final state "+
+				"%v", i, event.State, prev.State)
+		}
+	}
+
+	return problems
+}
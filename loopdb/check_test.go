@@ -0,0 +1,212 @@
+package loopdb
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/coreos/bbolt"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightninglabs/loop/test"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/stretchr/testify/require"
+)
+
+// createCheckTestSwaps adds a loop out swap with an update that follows its
+// final state and a valid loop in swap to the store provided and returns
+// their hashes.
+func createCheckTestSwaps(t *testing.T, store SwapStore) (lntypes.Hash,
+	lntypes.Hash) {
+
+	var senderKey, receiverKey [33]byte
+	_, pubKey := test.CreateKey(1)
+	copy(senderKey[:], pubKey.SerializeCompressed())
+	_, pubKey = test.CreateKey(2)
+	copy(receiverKey[:], pubKey.SerializeCompressed())
+
+	loopOutPreimage := lntypes.Preimage{1}
+	loopOutHash := loopOutPreimage.Hash()
+
+	swapInvoice, err := test.GetInvoice(loopOutHash, 10000, "swap")
+	require.NoError(t, err)
+
+	prepayInvoice, err := test.GetInvoice(lntypes.Hash{2}, 100, "prepay")
+	require.NoError(t, err)
+
+	destAddr, err := btcutil.NewAddressScriptHash(
+		[]byte{0}, &chaincfg.TestNet3Params,
+	)
+	require.NoError(t, err)
+
+	loopOut := &LoopOutContract{
+		SwapContract: SwapContract{
+			Preimage:       loopOutPreimage,
+			SenderKey:      senderKey,
+			ReceiverKey:    receiverKey,
+			InitiationTime: time.Unix(0, testTime.UnixNano()),
+		},
+		DestAddr:      destAddr,
+		SwapInvoice:   swapInvoice,
+		PrepayInvoice: prepayInvoice,
+	}
+	require.NoError(t, store.CreateLoopOut(loopOutHash, loopOut))
+
+	for _, state := range []SwapState{StateSuccess, StatePreimageRevealed} {
+		require.NoError(t, store.UpdateLoopOut(
+			loopOutHash, testTime, SwapStateData{State: state},
+		))
+	}
+
+	loopInPreimage := lntypes.Preimage{3}
+	loopInHash := loopInPreimage.Hash()
+	loopIn := &LoopInContract{
+		SwapContract: SwapContract{
+			Preimage:       loopInPreimage,
+			SenderKey:      senderKey,
+			ReceiverKey:    receiverKey,
+			InitiationTime: time.Unix(0, testTime.UnixNano()),
+		},
+	}
+	require.NoError(t, store.CreateLoopIn(loopInHash, loopIn))
+
+	require.NoError(t, store.UpdateLoopIn(
+		loopInHash, testTime, SwapStateData{State: StateSuccess},
+	))
+
+	return loopOutHash, loopInHash
+}
+
+// TestCheckDatabase tests that problems with swaps are found in all swap
+// store backends.
+func TestCheckDatabase(t *testing.T) {
+	for _, backend := range testBackends {
+		backend := backend
+
+		t.Run(string(backend), func(t *testing.T) {
+			testCheckDatabase(t, backend)
+		})
+	}
+}
+
+func testCheckDatabase(t *testing.T, backend Backend) {
+	tempDirName, err := ioutil.TempDir("", "clientstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDirName)
+
+	params := &chaincfg.TestNet3Params
+	store, err := NewSwapStore(backend, tempDirName, params)
+	require.NoError(t, err)
+
+	loopOutHash, loopInHash := createCheckTestSwaps(t, store)
+	require.NoError(t, store.ArchiveSwaps([]lntypes.Hash{loopInHash}))
+	require.NoError(t, store.Close())
+
+	report, err := CheckDatabase(backend, tempDirName, params, false)
+	require.NoError(t, err)
+	require.Equal(t, backend, report.Backend)
+	require.Equal(t, 1, report.LoopOuts)
+	require.Equal(t, 1, report.LoopIns)
+
+	// Only the update of the loop out swap after its final state is
+	// reported.
+	require.Equal(t, []*CheckProblem{{
+		Swap:     loopOutHash.String(),
+		SwapType: swap.TypeOut,
+		Description: "update 1 to state PreimageRevealed follows " +
+			"final state Success",
+	}}, report.Problems)
+
+	// Problems that don't prevent swaps from being read can't be repaired.
+	if backend == BackendSqlite {
+		_, err := CheckDatabase(backend, tempDirName, params, true)
+		require.Equal(t, ErrRepairNotSupported, err)
+		return
+	}
+
+	report, err = CheckDatabase(backend, tempDirName, params, true)
+	require.NoError(t, err)
+	require.Len(t, report.Problems, 1)
+	require.False(t, report.Problems[0].Repaired)
+}
+
+// TestCheckDatabaseRepair tests that swaps that can't be read are moved to
+// quarantine when a bbolt database is repaired.
+func TestCheckDatabaseRepair(t *testing.T) {
+	tempDirName, err := ioutil.TempDir("", "clientstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDirName)
+
+	params := &chaincfg.TestNet3Params
+	store, err := NewBoltSwapStore(tempDirName, params)
+	require.NoError(t, err)
+
+	_, loopInHash := createCheckTestSwaps(t, store)
+	require.NoError(t, store.Close())
+
+	// Corrupt the contract of the loop in swap.
+	db, err := bbolt.Open(
+		filepath.Join(tempDirName, dbFileName), 0600, nil,
+	)
+	require.NoError(t, err)
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		swapBucket := tx.Bucket(loopInBucketKey).Bucket(loopInHash[:])
+		return swapBucket.Put(contractKey, []byte{1, 2, 3})
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	// The corrupt swap prevents all loop in swaps from being loaded.
+	store, err = NewBoltSwapStore(tempDirName, params)
+	require.NoError(t, err)
+
+	_, err = store.FetchLoopInSwaps()
+	require.Error(t, err)
+	require.NoError(t, store.Close())
+
+	report, err := CheckDatabase(BackendBolt, tempDirName, params, false)
+	require.NoError(t, err)
+	require.Len(t, report.Problems, 2)
+
+	problem := report.Problems[1]
+	require.Equal(t, loopInHash.String(), problem.Swap)
+	require.True(t, problem.Unreadable)
+	require.False(t, problem.Repaired)
+
+	report, err = CheckDatabase(BackendBolt, tempDirName, params, true)
+	require.NoError(t, err)
+	require.Len(t, report.Problems, 2)
+	require.False(t, report.Problems[0].Repaired)
+	require.True(t, report.Problems[1].Repaired)
+
+	// After the repair, the remaining swaps can be loaded and the corrupt
+	// swap is kept in quarantine.
+	report, err = CheckDatabase(BackendBolt, tempDirName, params, false)
+	require.NoError(t, err)
+	require.Equal(t, 0, report.LoopIns)
+	require.Len(t, report.Problems, 1)
+
+	store, err = NewBoltSwapStore(tempDirName, params)
+	require.NoError(t, err)
+	defer store.Close()
+
+	loopIns, err := store.FetchLoopInSwaps()
+	require.NoError(t, err)
+	require.Empty(t, loopIns)
+
+	loopOuts, err := store.FetchLoopOutSwaps()
+	require.NoError(t, err)
+	require.Len(t, loopOuts, 1)
+
+	err = store.db.View(func(tx *bbolt.Tx) error {
+		quarantineBucket := tx.Bucket(loopInQuarantineBucketKey)
+		require.NotNil(t, quarantineBucket.Bucket(loopInHash[:]))
+
+		return nil
+	})
+	require.NoError(t, err)
+}
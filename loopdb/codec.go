@@ -1,7 +1,11 @@
 package loopdb
 
 import (
+	"encoding/binary"
 	"fmt"
+	"io"
+
+	"github.com/btcsuite/btcd/wire"
 )
 
 // itob returns an 8-byte big endian representation of v.
@@ -40,3 +44,21 @@ func MarshalProtocolVersion(version ProtocolVersion) []byte {
 
 	return versionBytes[:]
 }
+
+// writeOutpoint writes an outpoint as its tx hash followed by its index.
+func writeOutpoint(w io.Writer, outpoint *wire.OutPoint) error {
+	if _, err := w.Write(outpoint.Hash[:]); err != nil {
+		return err
+	}
+
+	return binary.Write(w, byteOrder, outpoint.Index)
+}
+
+// readOutpoint reads an outpoint that was written by writeOutpoint.
+func readOutpoint(r io.Reader, outpoint *wire.OutPoint) error {
+	if _, err := io.ReadFull(r, outpoint.Hash[:]); err != nil {
+		return err
+	}
+
+	return binary.Read(r, byteOrder, &outpoint.Index)
+}
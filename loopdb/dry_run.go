@@ -0,0 +1,155 @@
+package loopdb
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/coreos/bbolt"
+)
+
+// MigrationReport describes the result of migrating a copy of a swap database
+// to the latest version.
+type MigrationReport struct {
+	// Backend is the backend of the migrated database.
+	Backend Backend
+
+	// FromVersion is the version of the database before the migration.
+	FromVersion uint32
+
+	// ToVersion is the version of the database after the migration.
+	ToVersion uint32
+
+	// LoopOuts is the number of loop out swaps that were read from the
+	// migrated database, including archived swaps.
+	LoopOuts int
+
+	// LoopIns is the number of loop in swaps that were read from the
+	// migrated database, including archived swaps.
+	LoopIns int
+}
+
+// DryRunMigrations applies the migrations that the swap database of the
+// backend provided in the directory provided is missing to a copy of it, and
+// reads all swaps from the migrated copy. The database itself is left
+// untouched and must not be in use.
+func DryRunMigrations(backend Backend, dbPath string,
+	chainParams *chaincfg.Params) (*MigrationReport, error) {
+
+	var (
+		fileName      string
+		latestVersion uint32
+		copyDB        func(path, copyPath string) (uint32, error)
+	)
+
+	switch backend {
+	case BackendBolt:
+		fileName = dbFileName
+		latestVersion = latestDBVersion
+		copyDB = copyBoltDatabase
+
+	case BackendSqlite:
+		fileName = SqliteFileName
+		latestVersion = uint32(len(sqlMigrations))
+		copyDB = copySqliteDatabase
+
+	default:
+		return nil, fmt.Errorf("unknown database backend: %v", backend)
+	}
+
+	path := filepath.Join(dbPath, fileName)
+	if !fileExists(path) {
+		return nil, fmt.Errorf("database %v not found", path)
+	}
+
+	// The copy is kept next to the database, which may be too large for
+	// the temporary directory of the system.
+	tempDir, err := ioutil.TempDir(dbPath, "migration-dry-run")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	fromVersion, err := copyDB(path, filepath.Join(tempDir, fileName))
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Migrating copy of %v from db_version=%v to "+
+		"latest_version=%v", path, fromVersion, latestVersion)
+
+	var store SwapStore
+	if backend == BackendSqlite {
+		store, err = openSqliteSwapStore(tempDir, chainParams, false)
+	} else {
+		store, err = openBoltSwapStore(tempDir, chainParams, false)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("migration failed: %w", err)
+	}
+	defer store.Close()
+
+	loopOuts, loopIns, err := fetchAllSwaps(store)
+	if err != nil {
+		return nil, fmt.Errorf("read migrated swaps: %v", err)
+	}
+
+	return &MigrationReport{
+		Backend:     backend,
+		FromVersion: fromVersion,
+		ToVersion:   latestVersion,
+		LoopOuts:    len(loopOuts),
+		LoopIns:     len(loopIns),
+	}, nil
+}
+
+// copyBoltDatabase writes a copy of the bbolt database at the path provided to
+// a new file and returns the version of the database.
+func copyBoltDatabase(path, copyPath string) (uint32, error) {
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{
+		ReadOnly: true,
+		Timeout:  snapshotOpenTimeout,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("open database, make sure that loopd "+
+			"isn't running: %v", err)
+	}
+	defer db.Close()
+
+	version, err := getDBVersion(db)
+	if err != nil {
+		return 0, err
+	}
+
+	store := &boltSwapStore{db: db}
+	if err := store.Snapshot(copyPath); err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// copySqliteDatabase writes a copy of the sqlite database at the path provided
+// to a new file and returns the version of the database.
+func copySqliteDatabase(path, copyPath string) (uint32, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	version, err := getSqlVersion(db)
+	if err != nil {
+		return 0, err
+	}
+
+	store := &sqliteSwapStore{db: db}
+	if err := store.Snapshot(copyPath); err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
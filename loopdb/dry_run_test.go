@@ -0,0 +1,115 @@
+package loopdb
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/coreos/bbolt"
+	"github.com/stretchr/testify/require"
+)
+
+// createOldSqliteDb creates a sqlite database with the schema of the version
+// provided.
+func createOldSqliteDb(t *testing.T, dbPath string, version uint32) {
+	db, err := sql.Open("sqlite", filepath.Join(dbPath, SqliteFileName))
+	require.NoError(t, err)
+	defer db.Close()
+
+	for _, migration := range sqlMigrations[:version] {
+		_, err := db.Exec(migration)
+		require.NoError(t, err)
+	}
+
+	_, err = db.Exec(fmt.Sprintf("PRAGMA user_version = %d", version))
+	require.NoError(t, err)
+}
+
+// TestDryRunMigrations tests that migrations are applied to a copy of the
+// database, leaving the database itself untouched.
+func TestDryRunMigrations(t *testing.T) {
+	tempDirName, err := ioutil.TempDir("", "clientstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDirName)
+
+	params := &chaincfg.MainNetParams
+
+	// There are no databases to migrate yet.
+	_, err = DryRunMigrations(BackendBolt, tempDirName, params)
+	require.Error(t, err)
+
+	createVersionZeroDb(t, tempDirName)
+
+	report, err := DryRunMigrations(BackendBolt, tempDirName, params)
+	require.NoError(t, err)
+	require.Equal(t, &MigrationReport{
+		Backend:     BackendBolt,
+		FromVersion: 0,
+		ToVersion:   latestDBVersion,
+	}, report)
+
+	sqliteVersion := uint32(len(sqlMigrations))
+	createOldSqliteDb(t, tempDirName, sqliteVersion-1)
+
+	report, err = DryRunMigrations(BackendSqlite, tempDirName, params)
+	require.NoError(t, err)
+	require.Equal(t, &MigrationReport{
+		Backend:     BackendSqlite,
+		FromVersion: sqliteVersion - 1,
+		ToVersion:   sqliteVersion,
+	}, report)
+
+	// Neither the databases nor the directory were changed, and no copy
+	// was left behind.
+	files, err := ioutil.ReadDir(tempDirName)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	sqlitePath := filepath.Join(tempDirName, SqliteFileName)
+	db, err := sql.Open("sqlite", sqlitePath)
+	require.NoError(t, err)
+	version, err := getSqlVersion(db)
+	require.NoError(t, err)
+	require.Equal(t, sqliteVersion-1, version)
+	require.NoError(t, db.Close())
+
+	bdb, err := bbolt.Open(
+		filepath.Join(tempDirName, dbFileName), 0600, nil,
+	)
+	require.NoError(t, err)
+	version, err = getDBVersion(bdb)
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), version)
+	require.NoError(t, bdb.Close())
+
+	// The sqlite database is backed up before it is migrated.
+	sqliteStore, err := NewSqliteSwapStore(tempDirName, params)
+	require.NoError(t, err)
+	require.NoError(t, sqliteStore.Close())
+
+	backups, err := filepath.Glob(filepath.Join(
+		tempDirName, fmt.Sprintf("%v.v%d-*.backup", SqliteFileName,
+			sqliteVersion-1),
+	))
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+
+	// Databases with a newer version are refused.
+	db, err = sql.Open("sqlite", sqlitePath)
+	require.NoError(t, err)
+	_, err = db.Exec(fmt.Sprintf("PRAGMA user_version = %d",
+		sqliteVersion+1))
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	_, err = DryRunMigrations(BackendSqlite, tempDirName, params)
+	require.True(t, errors.Is(err, ErrDBReversion))
+
+	_, err = NewSqliteSwapStore(tempDirName, params)
+	require.True(t, errors.Is(err, ErrDBReversion))
+}
@@ -9,7 +9,8 @@ import (
 // SwapStore is the primary database interface used by the loopd system. It
 // houses information for all pending completed/failed swaps.
 type SwapStore interface {
-	// FetchLoopOutSwaps returns all swaps currently in the store.
+	// FetchLoopOutSwaps returns all swaps currently in the store that
+	// have not been archived.
 	FetchLoopOutSwaps() ([]*LoopOut, error)
 
 	// CreateLoopOut adds an initiated swap to the store.
@@ -21,7 +22,14 @@ type SwapStore interface {
 	UpdateLoopOut(hash lntypes.Hash, time time.Time,
 		state SwapStateData) error
 
-	// FetchLoopInSwaps returns all swaps currently in the store.
+	// UpdateLoopOuts stores a new event for each of the target loop out
+	// swaps in a single transaction. Either all or none of the events are
+	// stored.
+	UpdateLoopOuts(time time.Time,
+		updates map[lntypes.Hash]SwapStateData) error
+
+	// FetchLoopInSwaps returns all swaps currently in the store that have
+	// not been archived.
 	FetchLoopInSwaps() ([]*LoopIn, error)
 
 	// CreateLoopIn adds an initiated swap to the store.
@@ -33,6 +41,27 @@ type SwapStore interface {
 	UpdateLoopIn(hash lntypes.Hash, time time.Time,
 		state SwapStateData) error
 
+	// UpdateLoopIns stores a new event for each of the target loop in
+	// swaps in a single transaction. Either all or none of the events are
+	// stored.
+	UpdateLoopIns(time time.Time,
+		updates map[lntypes.Hash]SwapStateData) error
+
+	// QuerySwaps returns the page of swaps that the query provided
+	// selects. Only swaps that match the query are fully read from the
+	// store.
+	QuerySwaps(query *SwapQuery) (*SwapPage, error)
+
+	// ArchiveSwaps moves the completed swaps with the hashes provided to
+	// the archive, so that they are no longer returned by
+	// FetchLoopOutSwaps and FetchLoopInSwaps. Archived swaps can still be
+	// queried with QuerySwaps.
+	ArchiveSwaps(hashes []lntypes.Hash) error
+
+	// Snapshot writes a consistent copy of the database to a new file at
+	// the path provided, while the database remains in use.
+	Snapshot(path string) error
+
 	// Close closes the underlying database.
 	Close() error
 }
@@ -47,6 +47,10 @@ type SwapContract struct {
 	// Label contains an optional label for the swap.
 	Label string
 
+	// Initiator is an optional string that identifies the software that
+	// initiated the swap.
+	Initiator string
+
 	// ProtocolVersion stores the protocol version when the swap was
 	// created.
 	ProtocolVersion ProtocolVersion
@@ -56,6 +60,10 @@ type SwapContract struct {
 type Loop struct {
 	Hash   lntypes.Hash
 	Events []*LoopEvent
+
+	// Archived is true if the swap has been moved to the archive of
+	// completed swaps.
+	Archived bool
 }
 
 // LoopEvent contains the dynamic data of a swap.
@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/coreos/bbolt"
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
 	"github.com/lightninglabs/loop/labels"
 	"github.com/lightningnetwork/lnd/routing/route"
 )
@@ -30,6 +33,18 @@ type LoopInContract struct {
 	// Label contains an optional label for the swap. Note that this field
 	// is stored separately to the rest of the contract on disk.
 	Label string
+
+	// HtlcFundingOutpoints optionally restricts the wallet outputs that
+	// fund the htlc. If empty, the wallet selects the outputs. Note that
+	// this field is stored separately to the rest of the contract on
+	// disk.
+	HtlcFundingOutpoints []wire.OutPoint
+
+	// ChangeAddr is an optional address that receives the change of the
+	// htlc funding tx if HtlcFundingOutpoints is set. If nil, a new
+	// wallet address is used. Note that this field is stored separately
+	// to the rest of the contract on disk.
+	ChangeAddr btcutil.Address
 }
 
 // LoopIn is a combination of the contract and the updates.
@@ -120,7 +135,7 @@ func serializeLoopInContract(swap *LoopInContract) (
 
 // putLabel performs validation of a label and writes it to the bucket provided
 // under the label key if it is non-zero.
-func putLabel(bucket *bbolt.Bucket, label string) error {
+func putLabel(bucket kvWriter, label string) error {
 	if len(label) == 0 {
 		return nil
 	}
@@ -133,9 +148,81 @@ func putLabel(bucket *bbolt.Bucket, label string) error {
 	return bucket.Put(labelKey, []byte(label))
 }
 
+// putInitiator writes the initiator of a swap to the bucket provided under the
+// initiator key if it is non-zero.
+func putInitiator(bucket kvWriter, initiator string) error {
+	if len(initiator) == 0 {
+		return nil
+	}
+
+	return bucket.Put(initiatorKey, []byte(initiator))
+}
+
+// putHtlcFunding writes the optional htlc funding outpoints and change address
+// of a loop in swap to the bucket provided.
+func putHtlcFunding(bucket kvWriter, swap *LoopInContract) error {
+	if len(swap.HtlcFundingOutpoints) == 0 {
+		return nil
+	}
+
+	var b bytes.Buffer
+	for i := range swap.HtlcFundingOutpoints {
+		err := writeOutpoint(&b, &swap.HtlcFundingOutpoints[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	err := bucket.Put(htlcFundingOutpointsKey, b.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if swap.ChangeAddr == nil {
+		return nil
+	}
+
+	return bucket.Put(changeAddrKey, []byte(swap.ChangeAddr.String()))
+}
+
+// getHtlcFunding reads the optional htlc funding outpoints and change address
+// of a loop in swap from the bucket provided.
+func getHtlcFunding(bucket kvReader, contract *LoopInContract,
+	chainParams *chaincfg.Params) error {
+
+	outpointBytes := bucket.Get(htlcFundingOutpointsKey)
+	if outpointBytes == nil {
+		return nil
+	}
+
+	r := bytes.NewReader(outpointBytes)
+	for r.Len() > 0 {
+		var outpoint wire.OutPoint
+		if err := readOutpoint(r, &outpoint); err != nil {
+			return err
+		}
+
+		contract.HtlcFundingOutpoints = append(
+			contract.HtlcFundingOutpoints, outpoint,
+		)
+	}
+
+	changeAddr := bucket.Get(changeAddrKey)
+	if changeAddr == nil {
+		return nil
+	}
+
+	var err error
+	contract.ChangeAddr, err = btcutil.DecodeAddress(
+		string(changeAddr), chainParams,
+	)
+
+	return err
+}
+
 // getLabel attempts to get an optional label stored under the label key in a
 // bucket. If it is not present, an empty label is returned.
-func getLabel(bucket *bbolt.Bucket) string {
+func getLabel(bucket kvReader) string {
 	label := bucket.Get(labelKey)
 	if label == nil {
 		return ""
@@ -144,6 +231,18 @@ func getLabel(bucket *bbolt.Bucket) string {
 	return string(label)
 }
 
+// getInitiator attempts to get an optional initiator stored under the
+// initiator key in a bucket. If it is not present, an empty string is
+// returned.
+func getInitiator(bucket kvReader) string {
+	initiator := bucket.Get(initiatorKey)
+	if initiator == nil {
+		return ""
+	}
+
+	return string(initiator)
+}
+
 // deserializeLoopInContract deserializes the loop in contract from a byte slice.
 func deserializeLoopInContract(value []byte) (*LoopInContract, error) {
 	r := bytes.NewReader(value)
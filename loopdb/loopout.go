@@ -11,6 +11,8 @@ import (
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightningnetwork/lnd/routing/route"
 )
 
 // LoopOutContract contains the data that is serialized to persistent storage
@@ -24,6 +26,10 @@ type LoopOutContract struct {
 	// DestAddr is the destination address of the loop out swap.
 	DestAddr btcutil.Address
 
+	// SweepDestinations optionally splits the swept htlc value across
+	// multiple addresses. If empty, the full value is swept to DestAddr.
+	SweepDestinations []SweepDestination
+
 	// SwapInvoice is the invoice that is to be paid by the client to
 	// initiate the loop out swap.
 	SwapInvoice string
@@ -44,6 +50,14 @@ type LoopOutContract struct {
 	// If empty, any channel may be used.
 	OutgoingChanSet ChannelSet
 
+	// LastHop is the last hop that the swap payment must be routed
+	// through. If nil, any last hop may be used.
+	LastHop *route.Vertex
+
+	// PaymentCltvLimit is the maximum total time lock of the swap and
+	// prepay payment routes. If zero, no limit is applied.
+	PaymentCltvLimit int32
+
 	// PrepayInvoice is the invoice that the client should pay to the
 	// server that will be returned if the swap is complete.
 	PrepayInvoice string
@@ -59,6 +73,33 @@ type LoopOutContract struct {
 	SwapPublicationDeadline time.Time
 }
 
+// Destinations returns the addresses that the htlc of the swap is swept to.
+// If no sweep destinations are set, the full value is swept to DestAddr.
+func (c *LoopOutContract) Destinations() []SweepDestination {
+	if len(c.SweepDestinations) > 0 {
+		return c.SweepDestinations
+	}
+
+	return []SweepDestination{{Addr: c.DestAddr, Weight: 1}}
+}
+
+// SweepDestination is an address that receives a share of the swept value of
+// a loop out swap.
+type SweepDestination struct {
+	// Addr is the address that receives the share.
+	Addr btcutil.Address
+
+	// Weight is the weight of this destination. Every destination receives
+	// the share of the swept value that corresponds to its weight relative
+	// to the total weight of all destinations.
+	Weight uint32
+}
+
+// String returns the string representation of a sweep destination.
+func (d SweepDestination) String() string {
+	return fmt.Sprintf("%v (weight %v)", d.Addr, d.Weight)
+}
+
 // ChannelSet stores a set of channels.
 type ChannelSet []uint64
 
@@ -172,7 +213,7 @@ func deserializeLoopOutContract(value []byte, chainParams *chaincfg.Params) (
 	if err != nil {
 		return nil, err
 	}
-	contract.DestAddr, err = btcutil.DecodeAddress(addr, chainParams)
+	contract.DestAddr, err = swap.DecodeAddress(addr, chainParams)
 	if err != nil {
 		return nil, err
 	}
@@ -296,3 +337,110 @@ func serializeLoopOutContract(swap *LoopOutContract) (
 
 	return b.Bytes(), nil
 }
+
+// putPaymentRestrictions writes the optional payment restrictions of a loop
+// out swap to the bucket provided.
+func putPaymentRestrictions(bucket kvWriter,
+	swap *LoopOutContract) error {
+
+	if swap.LastHop != nil {
+		err := bucket.Put(lastHopKey, swap.LastHop[:])
+		if err != nil {
+			return err
+		}
+	}
+
+	if swap.PaymentCltvLimit == 0 {
+		return nil
+	}
+
+	var b bytes.Buffer
+	err := binary.Write(&b, byteOrder, swap.PaymentCltvLimit)
+	if err != nil {
+		return err
+	}
+
+	return bucket.Put(paymentCltvLimitKey, b.Bytes())
+}
+
+// getPaymentRestrictions reads the optional payment restrictions of a loop
+// out swap from the bucket provided.
+func getPaymentRestrictions(bucket kvReader,
+	contract *LoopOutContract) error {
+
+	lastHopBytes := bucket.Get(lastHopKey)
+	if lastHopBytes != nil {
+		lastHop, err := route.NewVertexFromBytes(lastHopBytes)
+		if err != nil {
+			return err
+		}
+		contract.LastHop = &lastHop
+	}
+
+	cltvLimitBytes := bucket.Get(paymentCltvLimitKey)
+	if cltvLimitBytes == nil {
+		return nil
+	}
+
+	return binary.Read(
+		bytes.NewReader(cltvLimitBytes), byteOrder,
+		&contract.PaymentCltvLimit,
+	)
+}
+
+// putSweepDestinations writes the optional sweep destinations of a loop out
+// swap to the bucket provided.
+func putSweepDestinations(bucket kvWriter, swap *LoopOutContract) error {
+	if len(swap.SweepDestinations) == 0 {
+		return nil
+	}
+
+	var b bytes.Buffer
+	for _, dest := range swap.SweepDestinations {
+		err := wire.WriteVarString(&b, 0, dest.Addr.String())
+		if err != nil {
+			return err
+		}
+
+		if err := binary.Write(&b, byteOrder, dest.Weight); err != nil {
+			return err
+		}
+	}
+
+	return bucket.Put(sweepDestinationsKey, b.Bytes())
+}
+
+// getSweepDestinations reads the optional sweep destinations of a loop out
+// swap from the bucket provided.
+func getSweepDestinations(bucket kvReader, contract *LoopOutContract,
+	chainParams *chaincfg.Params) error {
+
+	destBytes := bucket.Get(sweepDestinationsKey)
+	if destBytes == nil {
+		return nil
+	}
+
+	r := bytes.NewReader(destBytes)
+	for r.Len() > 0 {
+		addr, err := wire.ReadVarString(r, 0)
+		if err != nil {
+			return err
+		}
+
+		var dest SweepDestination
+		dest.Addr, err = swap.DecodeAddress(addr, chainParams)
+		if err != nil {
+			return err
+		}
+
+		if err := binary.Read(r, byteOrder, &dest.Weight); err != nil {
+			return err
+		}
+
+		contract.SweepDestinations = append(
+			contract.SweepDestinations, dest,
+		)
+	}
+
+	return nil
+}
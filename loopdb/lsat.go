@@ -0,0 +1,358 @@
+package loopdb
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/coreos/bbolt"
+	"github.com/lightninglabs/loop/lsat"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+var (
+	// lsatBucketKey is a bucket that contains the LSAT tokens that were
+	// obtained from the swap server.
+	lsatBucketKey = []byte("lsat")
+
+	// lsatTokensBucketKey is a bucket that contains all LSAT tokens, even
+	// if they are no longer current.
+	//
+	// path: lsatBucket -> lsatTokensBucket
+	//
+	// maps: paymentHash -> token
+	lsatTokensBucketKey = []byte("tokens")
+
+	// lsatCurrentTokenKey is the key that stores the payment hash of the
+	// token that is currently used, either pending or paid.
+	//
+	// path: lsatBucket -> lsatCurrentTokenKey
+	lsatCurrentTokenKey = []byte("current-token")
+
+	// lsatTokenFileNames are the names of the files that the paid and the
+	// pending token of an lsat.FileStore are kept in.
+	lsatTokenFileNames = []string{"lsat.token", "lsat.token.pending"}
+
+	// ErrNoBoltStore is returned when an LSAT store is created for a swap
+	// store that isn't backed by bbolt.
+	ErrNoBoltStore = errors.New("LSAT tokens can only be stored in a " +
+		"bbolt swap store")
+)
+
+// BoltLsatStore is an implementation of the lsat.Store interface that keeps
+// the tokens in the bbolt swap database, next to the swaps. There is always
+// just one current token that is either pending or fully paid.
+type BoltLsatStore struct {
+	db *bbolt.DB
+}
+
+// A compile-time flag to ensure that BoltLsatStore implements the lsat.Store
+// interface.
+var _ lsat.Store = (*BoltLsatStore)(nil)
+
+// NewBoltLsatStore creates an LSAT store in the database of the bbolt swap
+// store provided. If the database doesn't contain a token yet, the current
+// token of an lsat.FileStore in the token directory provided is imported and
+// its files are removed.
+func NewBoltLsatStore(store SwapStore, tokenDir string) (*BoltLsatStore,
+	error) {
+
+	boltStore, ok := store.(*boltSwapStore)
+	if !ok {
+		return nil, ErrNoBoltStore
+	}
+
+	s := &BoltLsatStore{
+		db: boltStore.db,
+	}
+
+	if err := s.importFileStore(tokenDir); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// importFileStore imports the current token of the lsat.FileStore in the
+// directory provided, if there is one.
+func (s *BoltLsatStore) importFileStore(tokenDir string) error {
+	var tokenFiles []string
+	for _, fileName := range lsatTokenFileNames {
+		tokenFile := filepath.Join(tokenDir, fileName)
+		if fileExists(tokenFile) {
+			tokenFiles = append(tokenFiles, tokenFile)
+		}
+	}
+
+	if len(tokenFiles) == 0 {
+		return nil
+	}
+
+	fileStore, err := lsat.NewFileStore(tokenDir)
+	if err != nil {
+		return err
+	}
+
+	token, err := fileStore.CurrentToken()
+	if err != nil {
+		return err
+	}
+
+	currentToken, err := s.CurrentToken()
+	switch {
+	case err == lsat.ErrNoToken:
+		if err := s.StoreToken(token); err != nil {
+			return err
+		}
+
+	case err != nil:
+		return err
+
+	// The token was imported before, or exported when the database was
+	// migrated to sqlite. It may have been paid for since.
+	case currentToken.PaymentHash == token.PaymentHash:
+		if currentToken.IsPending() && !token.IsPending() {
+			if err := s.StoreToken(token); err != nil {
+				return err
+			}
+		}
+
+	// We don't replace the token in the database with one that was
+	// obtained outside of it, and keep the files so that the token isn't
+	// lost.
+	default:
+		log.Warnf("Not importing LSAT token %v from %v, the database "+
+			"already contains token %v", token.PaymentHash,
+			tokenDir, currentToken.PaymentHash)
+
+		return nil
+	}
+
+	// The token is safely stored in the database, so removing its files
+	// can be just best effort. They are never read again once the
+	// database contains a token.
+	for _, tokenFile := range tokenFiles {
+		_ = os.Remove(tokenFile)
+	}
+
+	log.Infof("Imported LSAT token %v from %v", token.PaymentHash,
+		tokenDir)
+
+	return nil
+}
+
+// CurrentToken returns the token that is currently contained in the store or
+// an error if there is none.
+//
+// NOTE: This is part of the lsat.Store interface.
+func (s *BoltLsatStore) CurrentToken() (*lsat.Token, error) {
+	var token *lsat.Token
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		token, err = currentLsatToken(tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// AllTokens returns all tokens that the store has knowledge of, even if they
+// might be expired. The tokens are mapped by their hex encoded payment hash.
+//
+// NOTE: This is part of the lsat.Store interface.
+func (s *BoltLsatStore) AllTokens() (map[string]*lsat.Token, error) {
+	tokens := make(map[string]*lsat.Token)
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		tokensBucket := lsatTokensBucket(tx)
+		if tokensBucket == nil {
+			return nil
+		}
+
+		return tokensBucket.ForEach(func(k, v []byte) error {
+			token, err := lsat.DeserializeToken(v)
+			if err != nil {
+				return err
+			}
+
+			tokens[token.PaymentHash.String()] = token
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// StoreToken saves a token to the store. A pending token can only be replaced
+// by the same token once it is paid.
+//
+// NOTE: This is part of the lsat.Store interface.
+func (s *BoltLsatStore) StoreToken(newToken *lsat.Token) error {
+	// Serialize the token first, before we open a transaction.
+	tokenBytes, err := lsat.SerializeToken(newToken)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		currentToken, err := currentLsatToken(tx)
+		switch {
+		// No token in the store yet, it becomes the current token.
+		case err == lsat.ErrNoToken:
+
+		case err != nil:
+			return err
+
+		// Replace a pending token with a paid one.
+		case currentToken.IsPending() && !newToken.IsPending():
+			// Make sure we replace the same token, just with
+			// a different state.
+			if currentToken.PaymentHash != newToken.PaymentHash {
+				return errors.New("new paid token doesn't " +
+					"match existing pending token")
+			}
+
+		// Catch all, we get here if an existing token is attempted to
+		// be replaced with another token outside of the pending->paid
+		// flow.
+		default:
+			return lsat.ErrNoReplace
+		}
+
+		lsatBucket, err := tx.CreateBucketIfNotExists(lsatBucketKey)
+		if err != nil {
+			return err
+		}
+
+		tokensBucket, err := lsatBucket.CreateBucketIfNotExists(
+			lsatTokensBucketKey,
+		)
+		if err != nil {
+			return err
+		}
+
+		hash := newToken.PaymentHash
+		if err := tokensBucket.Put(hash[:], tokenBytes); err != nil {
+			return err
+		}
+
+		return lsatBucket.Put(lsatCurrentTokenKey, hash[:])
+	})
+}
+
+// exportLsatToken writes the current token of a bbolt swap store to an
+// lsat.FileStore in the directory provided, so that it is still used once the
+// swaps are stored in sqlite. The token is kept in the bbolt database.
+func exportLsatToken(store *boltSwapStore, tokenDir string) error {
+	lsatStore := &BoltLsatStore{db: store.db}
+	token, err := lsatStore.CurrentToken()
+	if err == lsat.ErrNoToken {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fileStore, err := lsat.NewFileStore(tokenDir)
+	if err != nil {
+		return err
+	}
+
+	fileToken, err := fileStore.CurrentToken()
+	switch {
+	case err == lsat.ErrNoToken:
+
+	case err != nil:
+		return err
+
+	// A token that was obtained outside of the database is not replaced.
+	case fileToken.PaymentHash != token.PaymentHash:
+		log.Warnf("Not exporting LSAT token %v to %v, it already "+
+			"contains token %v", token.PaymentHash, tokenDir,
+			fileToken.PaymentHash)
+
+		return nil
+
+	case !fileToken.IsPending() || token.IsPending():
+		return nil
+	}
+
+	log.Infof("Exporting LSAT token %v to %v", token.PaymentHash,
+		tokenDir)
+
+	return fileStore.StoreToken(token)
+}
+
+// RemovePendingToken removes the current token from the store if its payment
+// is still pending, so that a new token is paid for. It returns the payment
+// hash of the removed token.
+func (s *BoltLsatStore) RemovePendingToken() (lntypes.Hash, error) {
+	var hash lntypes.Hash
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		token, err := currentLsatToken(tx)
+		if err != nil {
+			return err
+		}
+
+		if !token.IsPending() {
+			return errors.New("current token is paid")
+		}
+
+		hash = token.PaymentHash
+		err = lsatTokensBucket(tx).Delete(hash[:])
+		if err != nil {
+			return err
+		}
+
+		return tx.Bucket(lsatBucketKey).Delete(lsatCurrentTokenKey)
+	})
+	if err != nil {
+		return lntypes.Hash{}, err
+	}
+
+	return hash, nil
+}
+
+// lsatTokensBucket returns the bucket that contains all tokens, or nil if no
+// token was stored yet.
+func lsatTokensBucket(tx *bbolt.Tx) *bbolt.Bucket {
+	lsatBucket := tx.Bucket(lsatBucketKey)
+	if lsatBucket == nil {
+		return nil
+	}
+
+	return lsatBucket.Bucket(lsatTokensBucketKey)
+}
+
+// currentLsatToken reads the current token, returning lsat.ErrNoToken if there
+// is none.
+func currentLsatToken(tx *bbolt.Tx) (*lsat.Token, error) {
+	lsatBucket := tx.Bucket(lsatBucketKey)
+	if lsatBucket == nil {
+		return nil, lsat.ErrNoToken
+	}
+
+	hash := lsatBucket.Get(lsatCurrentTokenKey)
+	if hash == nil {
+		return nil, lsat.ErrNoToken
+	}
+
+	tokensBucket := lsatBucket.Bucket(lsatTokensBucketKey)
+	if tokensBucket == nil {
+		return nil, errors.New("lsat tokens bucket does not exist")
+	}
+
+	tokenBytes := tokensBucket.Get(hash)
+	if tokenBytes == nil {
+		return nil, errors.New("current lsat token not found")
+	}
+
+	return lsat.DeserializeToken(tokenBytes)
+}
@@ -0,0 +1,167 @@
+package loopdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/lightninglabs/loop/lsat"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaroon.v2"
+)
+
+// makeLsatToken creates a token with the payment hash and preimage provided,
+// which is pending if the preimage is empty.
+func makeLsatToken(t *testing.T, hash lntypes.Hash,
+	preimage lntypes.Preimage) *lsat.Token {
+
+	mac, err := macaroon.New(
+		[]byte("aabbccddeeff00112233445566778899"), []byte("AA=="),
+		"LSAT", macaroon.LatestVersion,
+	)
+	require.NoError(t, err)
+
+	macBytes, err := mac.MarshalBinary()
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+	for _, value := range []interface{}{
+		uint32(len(macBytes)), macBytes, hash, preimage, uint64(1000),
+		uint64(10), testTime.UnixNano(),
+	} {
+		require.NoError(t, binary.Write(&b, binary.BigEndian, value))
+	}
+
+	token, err := lsat.DeserializeToken(b.Bytes())
+	require.NoError(t, err)
+
+	return token
+}
+
+// TestBoltLsatStore tests storing LSAT tokens in the bbolt swap database and
+// importing them from token files.
+func TestBoltLsatStore(t *testing.T) {
+	tempDirName, err := ioutil.TempDir("", "clientstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDirName)
+
+	preimage := lntypes.Preimage{1}
+	pendingToken := makeLsatToken(t, preimage.Hash(), lntypes.Preimage{})
+	paidToken := makeLsatToken(t, preimage.Hash(), preimage)
+
+	// Start with a pending token in the token files.
+	fileStore, err := lsat.NewFileStore(tempDirName)
+	require.NoError(t, err)
+	require.NoError(t, fileStore.StoreToken(pendingToken))
+
+	params := &chaincfg.MainNetParams
+	store, err := NewBoltSwapStore(tempDirName, params)
+	require.NoError(t, err)
+
+	// The pending token is imported and its file is removed.
+	lsatStore, err := NewBoltLsatStore(store, tempDirName)
+	require.NoError(t, err)
+
+	token, err := lsatStore.CurrentToken()
+	require.NoError(t, err)
+	require.Equal(t, pendingToken, token)
+
+	_, err = fileStore.CurrentToken()
+	require.Equal(t, lsat.ErrNoToken, err)
+
+	// A pending token can only be replaced by the same token once it is
+	// paid.
+	otherToken := makeLsatToken(t, lntypes.Hash{2}, lntypes.Preimage{})
+	require.Equal(t, lsat.ErrNoReplace, lsatStore.StoreToken(otherToken))
+
+	otherToken = makeLsatToken(t, lntypes.Hash{2}, lntypes.Preimage{2})
+	require.Error(t, lsatStore.StoreToken(otherToken))
+
+	require.NoError(t, lsatStore.StoreToken(paidToken))
+	require.Equal(t, lsat.ErrNoReplace, lsatStore.StoreToken(paidToken))
+
+	// Paid tokens can't be removed.
+	_, err = lsatStore.RemovePendingToken()
+	require.Error(t, err)
+
+	tokens, err := lsatStore.AllTokens()
+	require.NoError(t, err)
+	require.Equal(t, map[string]*lsat.Token{
+		paidToken.PaymentHash.String(): paidToken,
+	}, tokens)
+
+	// The paid token is exported to the token files when the database is
+	// migrated to sqlite.
+	require.NoError(t, store.Close())
+
+	_, err = MigrateBoltToSqlite(tempDirName, params)
+	require.NoError(t, err)
+
+	token, err = fileStore.CurrentToken()
+	require.NoError(t, err)
+	require.Equal(t, paidToken, token)
+
+	// The exported token matches the token in the database, so it isn't
+	// imported again and its file is removed.
+	store, err = NewBoltSwapStore(tempDirName, params)
+	require.NoError(t, err)
+	defer store.Close()
+
+	lsatStore, err = NewBoltLsatStore(store, tempDirName)
+	require.NoError(t, err)
+
+	token, err = lsatStore.CurrentToken()
+	require.NoError(t, err)
+	require.Equal(t, paidToken, token)
+
+	require.False(t, fileExists(filepath.Join(tempDirName, "lsat.token")))
+
+	// LSAT tokens can only be stored in bbolt databases.
+	sqliteStore, err := NewSqliteSwapStore(tempDirName, params)
+	require.NoError(t, err)
+	defer sqliteStore.Close()
+
+	_, err = NewBoltLsatStore(sqliteStore, tempDirName)
+	require.Equal(t, ErrNoBoltStore, err)
+}
+
+// TestBoltLsatStoreRemovePending tests removing a pending LSAT token from the
+// bbolt swap database.
+func TestBoltLsatStoreRemovePending(t *testing.T) {
+	tempDirName, err := ioutil.TempDir("", "clientstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDirName)
+
+	store, err := NewBoltSwapStore(tempDirName, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	defer store.Close()
+
+	lsatStore, err := NewBoltLsatStore(store, tempDirName)
+	require.NoError(t, err)
+
+	_, err = lsatStore.RemovePendingToken()
+	require.Equal(t, lsat.ErrNoToken, err)
+
+	pendingToken := makeLsatToken(t, lntypes.Hash{1}, lntypes.Preimage{})
+	require.NoError(t, lsatStore.StoreToken(pendingToken))
+
+	hash, err := lsatStore.RemovePendingToken()
+	require.NoError(t, err)
+	require.Equal(t, pendingToken.PaymentHash, hash)
+
+	_, err = lsatStore.CurrentToken()
+	require.Equal(t, lsat.ErrNoToken, err)
+
+	tokens, err := lsatStore.AllTokens()
+	require.NoError(t, err)
+	require.Empty(t, tokens)
+
+	// A new token can be stored once the pending token is removed.
+	otherToken := makeLsatToken(t, lntypes.Hash{2}, lntypes.Preimage{})
+	require.NoError(t, lsatStore.StoreToken(otherToken))
+}
@@ -3,6 +3,7 @@ package loopdb
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/coreos/bbolt"
@@ -37,6 +38,7 @@ var (
 		migrateSwapPublicationDeadline,
 		migrateLastHop,
 		migrateUpdates,
+		migrateArchive,
 	}
 
 	latestDBVersion = uint32(len(migrations))
@@ -67,6 +69,46 @@ func getDBVersion(db *bbolt.DB) (uint32, error) {
 	return version, nil
 }
 
+// checkDBVersion returns an error if the database has a newer version than the
+// latest version that we know of. A database that wasn't initialized yet
+// passes the check.
+func checkDBVersion(db *bbolt.DB) error {
+	return db.View(func(tx *bbolt.Tx) error {
+		metaBucket := tx.Bucket(metaBucketKey)
+		if metaBucket == nil {
+			return nil
+		}
+
+		data := metaBucket.Get(dbVersionKey)
+		if data == nil {
+			return nil
+		}
+
+		version := byteOrder.Uint32(data)
+		if version > latestDBVersion {
+			return errNewerVersion(version, latestDBVersion)
+		}
+
+		return nil
+	})
+}
+
+// errNewerVersion returns the error for a database that has a newer version
+// than the latest version that we know of.
+func errNewerVersion(version, latestVersion uint32) error {
+	return fmt.Errorf("%w: db_version=%v is newer than latest_version=%v, "+
+		"the database was migrated by a newer version of loop. Use "+
+		"that version, or restore the backup that was made before the "+
+		"migration", ErrDBReversion, version, latestVersion)
+}
+
+// migrationBackupPath returns the path of the copy of a database file that is
+// written before the database is migrated from the version provided.
+func migrationBackupPath(dbFile string, version uint32) string {
+	return fmt.Sprintf("%v.v%d-%v.backup", dbFile, version,
+		time.Now().Format("20060102-150405"))
+}
+
 // setDBVersion updates the current db version.
 func setDBVersion(tx *bbolt.Tx, version uint32) error {
 	metaBucket, err := tx.CreateBucketIfNotExists(metaBucketKey)
@@ -82,7 +124,11 @@ func setDBVersion(tx *bbolt.Tx, version uint32) error {
 // syncVersions function is used for safe db version synchronization. It
 // applies migration functions to the current database and recovers the
 // previous state of db if at least one error/panic appeared during migration.
-func syncVersions(db *bbolt.DB, chainParams *chaincfg.Params) error {
+// If backup is set, a copy of the database is written next to it before it is
+// migrated.
+func syncVersions(db *bbolt.DB, chainParams *chaincfg.Params,
+	backup bool) error {
+
 	currentVersion, err := getDBVersion(db)
 	if err != nil {
 		return err
@@ -101,7 +147,7 @@ func syncVersions(db *bbolt.DB, chainParams *chaincfg.Params) error {
 			"lower version=%d", currentVersion,
 			latestDBVersion)
 
-		return ErrDBReversion
+		return errNewerVersion(currentVersion, latestDBVersion)
 
 	// If the current database version matches the latest version number,
 	// then we don't need to perform any migrations.
@@ -109,6 +155,21 @@ func syncVersions(db *bbolt.DB, chainParams *chaincfg.Params) error {
 		return nil
 	}
 
+	// Keep a copy of the database as it was before the migration, so that
+	// it can be restored if the migration turns out to be faulty or an
+	// older version of loop needs to be run again.
+	if backup {
+		backupPath := migrationBackupPath(db.Path(), currentVersion)
+
+		store := &boltSwapStore{db: db}
+		if err := store.Snapshot(backupPath); err != nil {
+			return fmt.Errorf("backup before migration: %v", err)
+		}
+
+		log.Infof("Backed up database with db_version=%v to %v",
+			currentVersion, backupPath)
+	}
+
 	log.Infof("Performing database schema migration")
 
 	// Otherwise we execute the migrations serially within a single
@@ -0,0 +1,19 @@
+package loopdb
+
+import (
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/coreos/bbolt"
+)
+
+// migrateArchive creates the buckets that completed swaps are archived in. The
+// db version is bumped for this, because older versions would silently ignore
+// archived swaps.
+func migrateArchive(tx *bbolt.Tx, _ *chaincfg.Params) error {
+	_, err := tx.CreateBucketIfNotExists(loopOutArchiveBucketKey)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.CreateBucketIfNotExists(loopInArchiveBucketKey)
+	return err
+}
@@ -0,0 +1,227 @@
+package loopdb
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/routing/route"
+)
+
+// swapCursorLength is the length of a serialized swap cursor.
+const swapCursorLength = 8 + lntypes.HashSize
+
+// SwapQuery selects the swaps that are returned by QuerySwaps. Swaps are
+// ordered by their initiation time and hash. Fields that have their zero value
+// don't restrict the swaps that are returned.
+type SwapQuery struct {
+	// Hash restricts the query to the swap with this hash.
+	Hash *lntypes.Hash
+
+	// SwapType restricts the query to swaps of this type.
+	SwapType *swap.Type
+
+	// StateTypes restricts the query to swaps whose current state has one
+	// of these types.
+	StateTypes []SwapStateType
+
+	// StartTime restricts the query to swaps that were initiated at or
+	// after this time.
+	StartTime time.Time
+
+	// EndTime restricts the query to swaps that were initiated before this
+	// time.
+	EndTime time.Time
+
+	// UpdatedBefore restricts the query to swaps whose last update was
+	// before this time. Swaps without updates don't match.
+	UpdatedBefore time.Time
+
+	// Label restricts the query to swaps with this label.
+	Label string
+
+	// OutgoingChanID restricts the query to loop out swaps that may use
+	// this channel for their swap payment.
+	OutgoingChanID uint64
+
+	// LastHop restricts the query to swaps with this last hop.
+	LastHop *route.Vertex
+
+	// Initiator restricts the query to swaps with this initiator.
+	Initiator string
+
+	// IncludeArchived includes archived swaps in the query. By default,
+	// only swaps that haven't been archived are returned.
+	IncludeArchived bool
+
+	// After restricts the query to swaps that are ordered after this
+	// cursor. It is set to the cursor of the previous page to fetch the
+	// next page.
+	After *SwapCursor
+
+	// Limit is the maximum number of swaps that are returned.
+	Limit uint32
+}
+
+// SwapCursor is the position of a swap in the order of swaps that are
+// returned by QuerySwaps.
+type SwapCursor struct {
+	// InitiationTime is the initiation time of the swap.
+	InitiationTime time.Time
+
+	// Hash is the hash of the swap.
+	Hash lntypes.Hash
+}
+
+// Serialize serializes the swap cursor.
+func (c *SwapCursor) Serialize() []byte {
+	var b [swapCursorLength]byte
+	byteOrder.PutUint64(b[:8], uint64(c.InitiationTime.UnixNano()))
+	copy(b[8:], c.Hash[:])
+
+	return b[:]
+}
+
+// DeserializeSwapCursor deserializes a swap cursor.
+func DeserializeSwapCursor(b []byte) (*SwapCursor, error) {
+	if len(b) != swapCursorLength {
+		return nil, errors.New("invalid swap cursor length")
+	}
+
+	cursor := &SwapCursor{
+		InitiationTime: time.Unix(0, int64(byteOrder.Uint64(b[:8]))),
+	}
+	copy(cursor.Hash[:], b[8:])
+
+	return cursor, nil
+}
+
+// less returns true if the cursor is ordered before the cursor provided.
+func (c *SwapCursor) less(other *SwapCursor) bool {
+	t1, t2 := c.InitiationTime.UnixNano(), other.InitiationTime.UnixNano()
+	if t1 != t2 {
+		return t1 < t2
+	}
+
+	return bytes.Compare(c.Hash[:], other.Hash[:]) < 0
+}
+
+// SwapPage is a page of swaps that is returned by QuerySwaps.
+type SwapPage struct {
+	// LoopOuts are the loop out swaps of the page.
+	LoopOuts []*LoopOut
+
+	// LoopIns are the loop in swaps of the page.
+	LoopIns []*LoopIn
+
+	// NextCursor is the cursor of the last swap of the page if more swaps
+	// match the query, and nil otherwise.
+	NextCursor *SwapCursor
+}
+
+// matchType returns true if swaps of the type provided may match the query.
+func (q *SwapQuery) matchType(swapType swap.Type) bool {
+	if q.SwapType != nil && *q.SwapType != swapType {
+		return false
+	}
+
+	// Only loop out swaps have outgoing channels.
+	return q.OutgoingChanID == 0 || swapType == swap.TypeOut
+}
+
+// matchContract returns true if the fields of a swap that never change match
+// the query.
+func (q *SwapQuery) matchContract(contract *SwapContract, label string,
+	lastHop *route.Vertex, chanSet ChannelSet) bool {
+
+	if !q.StartTime.IsZero() &&
+		contract.InitiationTime.UnixNano() < q.StartTime.UnixNano() {
+
+		return false
+	}
+
+	if !q.EndTime.IsZero() &&
+		contract.InitiationTime.UnixNano() >= q.EndTime.UnixNano() {
+
+		return false
+	}
+
+	if q.Label != "" && q.Label != label {
+		return false
+	}
+
+	if q.Initiator != "" && q.Initiator != contract.Initiator {
+		return false
+	}
+
+	if q.LastHop != nil && (lastHop == nil || *lastHop != *q.LastHop) {
+		return false
+	}
+
+	if q.OutgoingChanID != 0 {
+		var found bool
+		for _, chanID := range chanSet {
+			if chanID == q.OutgoingChanID {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchState returns true if the current state of a swap matches the query.
+func (q *SwapQuery) matchState(state SwapState) bool {
+	if len(q.StateTypes) == 0 {
+		return true
+	}
+
+	for _, stateType := range q.StateTypes {
+		if state.Type() == stateType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// queryResult is a swap that matches a query.
+type queryResult struct {
+	cursor   SwapCursor
+	swapType swap.Type
+	archived bool
+}
+
+// paginate sorts the results of a query and returns the page that the query
+// selects, along with the cursor of the next page if there is one.
+func (q *SwapQuery) paginate(results []queryResult) ([]queryResult,
+	*SwapCursor) {
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].cursor.less(&results[j].cursor)
+	})
+
+	if q.After != nil {
+		start := sort.Search(len(results), func(i int) bool {
+			return q.After.less(&results[i].cursor)
+		})
+		results = results[start:]
+	}
+
+	if q.Limit == 0 || uint32(len(results)) <= q.Limit {
+		return results, nil
+	}
+
+	results = results[:q.Limit]
+	next := results[len(results)-1].cursor
+
+	return results, &next
+}
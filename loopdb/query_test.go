@@ -0,0 +1,286 @@
+package loopdb
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightninglabs/loop/test"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/stretchr/testify/require"
+)
+
+// TestQuerySwaps tests filtering and pagination of swaps for all swap store
+// backends.
+func TestQuerySwaps(t *testing.T) {
+	for _, backend := range testBackends {
+		backend := backend
+
+		t.Run(string(backend), func(t *testing.T) {
+			testQuerySwaps(t, backend)
+		})
+	}
+}
+
+func testQuerySwaps(t *testing.T, backend Backend) {
+	tempDirName, err := ioutil.TempDir("", "clientstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDirName)
+
+	store, err := NewSwapStore(
+		backend, tempDirName, &chaincfg.MainNetParams,
+	)
+	require.NoError(t, err)
+	defer store.Close()
+
+	lastHop := route.Vertex{1, 2, 3}
+
+	newContract := func(preimage lntypes.Preimage,
+		minute int) SwapContract {
+
+		return SwapContract{
+			Preimage:        preimage,
+			AmountRequested: 100,
+			SenderKey:       senderKey,
+			ReceiverKey:     receiverKey,
+			InitiationTime: time.Unix(
+				0, testTime.Add(
+					time.Duration(minute)*time.Minute,
+				).UnixNano(),
+			),
+		}
+	}
+
+	// Create loop outs and loop ins with different initiation times.
+	out1 := &LoopOutContract{
+		SwapContract:    newContract(lntypes.Preimage{1}, 1),
+		DestAddr:        test.GetDestAddr(t, 0),
+		OutgoingChanSet: ChannelSet{5, 6},
+	}
+	out1.Label = "label"
+
+	out2 := &LoopOutContract{
+		SwapContract: newContract(lntypes.Preimage{2}, 2),
+		DestAddr:     test.GetDestAddr(t, 0),
+		LastHop:      &lastHop,
+	}
+	out2.Initiator = "autoloop"
+
+	in3 := &LoopInContract{
+		SwapContract: newContract(lntypes.Preimage{3}, 2),
+		LastHop:      &lastHop,
+		Label:        "label",
+	}
+
+	in4 := &LoopInContract{
+		SwapContract: newContract(lntypes.Preimage{4}, 4),
+	}
+	in4.Initiator = "autoloop"
+
+	hash1, hash2 := out1.Preimage.Hash(), out2.Preimage.Hash()
+	hash3, hash4 := in3.Preimage.Hash(), in4.Preimage.Hash()
+
+	require.NoError(t, store.CreateLoopOut(hash1, out1))
+	require.NoError(t, store.CreateLoopOut(hash2, out2))
+	require.NoError(t, store.CreateLoopIn(hash3, in3))
+	require.NoError(t, store.CreateLoopIn(hash4, in4))
+
+	// Swap 1 succeeds and swap 4 fails an hour later, the others are
+	// pending.
+	require.NoError(t, store.UpdateLoopOut(
+		hash1, testTime, SwapStateData{State: StatePreimageRevealed},
+	))
+	require.NoError(t, store.UpdateLoopOut(
+		hash1, testTime, SwapStateData{State: StateSuccess},
+	))
+	require.NoError(t, store.UpdateLoopIn(
+		hash4, testTime.Add(time.Hour),
+		SwapStateData{State: StateFailTimeout},
+	))
+
+	// Swaps 2 and 3 have the same initiation time, so their order depends
+	// on their hash.
+	all := []lntypes.Hash{hash1, hash2, hash3, hash4}
+	if string(hash3[:]) < string(hash2[:]) {
+		all = []lntypes.Hash{hash1, hash3, hash2, hash4}
+	}
+
+	// pageHashes returns the hashes of a page in query order.
+	pageHashes := func(page *SwapPage) []lntypes.Hash {
+		var cursors []queryResult
+		for _, swp := range page.LoopOuts {
+			cursors = append(cursors, queryResult{
+				cursor: SwapCursor{
+					InitiationTime: swp.Contract.InitiationTime,
+					Hash:           swp.Hash,
+				},
+			})
+		}
+		for _, swp := range page.LoopIns {
+			cursors = append(cursors, queryResult{
+				cursor: SwapCursor{
+					InitiationTime: swp.Contract.InitiationTime,
+					Hash:           swp.Hash,
+				},
+			})
+		}
+
+		cursors, _ = (&SwapQuery{}).paginate(cursors)
+
+		var hashes []lntypes.Hash
+		for _, c := range cursors {
+			hashes = append(hashes, c.cursor.Hash)
+		}
+
+		return hashes
+	}
+
+	loopOut := swap.TypeOut
+	loopIn := swap.TypeIn
+
+	tests := []struct {
+		name     string
+		query    *SwapQuery
+		expected []lntypes.Hash
+	}{
+		{
+			name:     "all swaps",
+			query:    &SwapQuery{},
+			expected: all,
+		},
+		{
+			name:     "loop out",
+			query:    &SwapQuery{SwapType: &loopOut},
+			expected: []lntypes.Hash{hash1, hash2},
+		},
+		{
+			name:     "loop in",
+			query:    &SwapQuery{SwapType: &loopIn},
+			expected: []lntypes.Hash{hash3, hash4},
+		},
+		{
+			name: "pending",
+			query: &SwapQuery{
+				StateTypes: []SwapStateType{StateTypePending},
+			},
+			expected: all[1:3],
+		},
+		{
+			name: "completed",
+			query: &SwapQuery{
+				StateTypes: []SwapStateType{
+					StateTypeSuccess, StateTypeFail,
+				},
+			},
+			expected: []lntypes.Hash{hash1, hash4},
+		},
+		{
+			name: "updated before",
+			query: &SwapQuery{
+				UpdatedBefore: testTime.Add(time.Minute),
+			},
+			expected: []lntypes.Hash{hash1},
+		},
+		{
+			name: "time range",
+			query: &SwapQuery{
+				StartTime: out2.InitiationTime,
+				EndTime:   in4.InitiationTime,
+			},
+			expected: all[1:3],
+		},
+		{
+			name:     "label",
+			query:    &SwapQuery{Label: "label"},
+			expected: []lntypes.Hash{hash1, hash3},
+		},
+		{
+			name:     "outgoing channel",
+			query:    &SwapQuery{OutgoingChanID: 6},
+			expected: []lntypes.Hash{hash1},
+		},
+		{
+			name:     "last hop",
+			query:    &SwapQuery{LastHop: &lastHop},
+			expected: all[1:3],
+		},
+		{
+			name:     "initiator",
+			query:    &SwapQuery{Initiator: "autoloop"},
+			expected: []lntypes.Hash{hash2, hash4},
+		},
+		{
+			name:     "hash",
+			query:    &SwapQuery{Hash: &hash3},
+			expected: []lntypes.Hash{hash3},
+		},
+		{
+			name: "no match",
+			query: &SwapQuery{
+				SwapType:  &loopIn,
+				Initiator: "autoloop",
+				Label:     "label",
+			},
+		},
+	}
+
+	for _, testCase := range tests {
+		page, err := store.QuerySwaps(testCase.query)
+		require.NoError(t, err, testCase.name)
+		require.Nil(t, page.NextCursor, testCase.name)
+		require.Equal(
+			t, testCase.expected, pageHashes(page), testCase.name,
+		)
+	}
+
+	// Walk through all swaps in pages of different sizes.
+	for _, limit := range []uint32{1, 2, 4} {
+		var (
+			hashes []lntypes.Hash
+			query  = &SwapQuery{Limit: limit}
+		)
+		for {
+			page, err := store.QuerySwaps(query)
+			require.NoError(t, err)
+
+			hashes = append(hashes, pageHashes(page)...)
+			if page.NextCursor == nil {
+				break
+			}
+
+			cursor, err := DeserializeSwapCursor(
+				page.NextCursor.Serialize(),
+			)
+			require.NoError(t, err)
+			require.Equal(t, page.NextCursor.Hash, cursor.Hash)
+
+			query.After = cursor
+		}
+
+		require.Equal(t, all, hashes)
+	}
+
+	// Swaps that are returned by a query are complete.
+	page, err := store.QuerySwaps(&SwapQuery{Label: "label"})
+	require.NoError(t, err)
+
+	loopOuts, err := store.FetchLoopOutSwaps()
+	require.NoError(t, err)
+	for _, swp := range loopOuts {
+		if swp.Hash == hash1 {
+			require.Equal(t, swp, page.LoopOuts[0])
+		}
+	}
+
+	loopIns, err := store.FetchLoopInSwaps()
+	require.NoError(t, err)
+	for _, swp := range loopIns {
+		if swp.Hash == hash3 {
+			require.Equal(t, swp, page.LoopIns[0])
+		}
+	}
+}
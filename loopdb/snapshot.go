@@ -0,0 +1,189 @@
+package loopdb
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/coreos/bbolt"
+)
+
+const (
+	// snapshotOpenTimeout is how long we wait for the file lock of a bbolt
+	// snapshot that is opened for verification.
+	snapshotOpenTimeout = time.Second * 5
+)
+
+// sqliteHeader is the header that every sqlite database file starts with.
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// SnapshotInfo describes a verified snapshot of a swap database.
+type SnapshotInfo struct {
+	// Backend is the database backend that the snapshot was taken from.
+	Backend Backend
+
+	// Version is the database version of the snapshot.
+	Version uint32
+
+	// LoopOuts is the number of loop out swaps in the snapshot.
+	LoopOuts int
+
+	// LoopIns is the number of loop in swaps in the snapshot.
+	LoopIns int
+}
+
+// Snapshot writes a consistent copy of the database, including its metadata,
+// to a new file at the path provided. The copy is taken in a single read
+// transaction, so swaps can continue to be updated while it is written.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) Snapshot(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.View(func(tx *bbolt.Tx) error {
+		_, err := tx.WriteTo(f)
+		return err
+	})
+	if err == nil {
+		err = f.Sync()
+	}
+
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	// Don't leave a torn snapshot behind.
+	if err != nil {
+		_ = os.Remove(path)
+		return err
+	}
+
+	return nil
+}
+
+// Snapshot writes a consistent copy of the database, including its metadata,
+// to a new file at the path provided. The copy is taken in a single read
+// transaction, so swaps can continue to be updated while it is written.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *sqliteSwapStore) Snapshot(path string) error {
+	// Sqlite refuses to vacuum into an existing file that isn't empty,
+	// but we don't want to touch an existing file at all.
+	if fileExists(path) {
+		return fmt.Errorf("snapshot file %v already exists", path)
+	}
+
+	if _, err := s.db.Exec("VACUUM INTO ?", path); err != nil {
+		_ = os.Remove(path)
+		return err
+	}
+
+	return nil
+}
+
+// VerifySnapshot opens the database snapshot at the path provided read-only
+// and checks that it has the latest database version and that all of its
+// swaps deserialize cleanly. The backend of the snapshot is detected from its
+// file contents.
+func VerifySnapshot(path string, chainParams *chaincfg.Params) (*SnapshotInfo,
+	error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, len(sqliteHeader))
+	_, err = io.ReadFull(f, header)
+	_ = f.Close()
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+
+	if bytes.Equal(header, sqliteHeader) {
+		return verifySqliteSnapshot(path, chainParams)
+	}
+
+	return verifyBoltSnapshot(path, chainParams)
+}
+
+// verifyBoltSnapshot verifies a bbolt database snapshot.
+func verifyBoltSnapshot(path string, chainParams *chaincfg.Params) (
+	*SnapshotInfo, error) {
+
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{
+		ReadOnly: true,
+		Timeout:  snapshotOpenTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open bbolt snapshot: %v", err)
+	}
+	defer db.Close()
+
+	version, err := getDBVersion(db)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != latestDBVersion {
+		return nil, fmt.Errorf("snapshot has db version %v, expected "+
+			"%v", version, latestDBVersion)
+	}
+
+	return verifySnapshotSwaps(
+		&boltSwapStore{db: db, chainParams: chainParams}, BackendBolt,
+		version,
+	)
+}
+
+// verifySqliteSnapshot verifies a sqlite database snapshot.
+func verifySqliteSnapshot(path string, chainParams *chaincfg.Params) (
+	*SnapshotInfo, error) {
+
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite snapshot: %v", err)
+	}
+	defer db.Close()
+
+	version, err := getSqlVersion(db)
+	if err != nil {
+		return nil, err
+	}
+
+	latestVersion := uint32(len(sqlMigrations))
+	if version != latestVersion {
+		return nil, fmt.Errorf("snapshot has db version %v, expected "+
+			"%v", version, latestVersion)
+	}
+
+	return verifySnapshotSwaps(
+		&sqliteSwapStore{db: db, chainParams: chainParams},
+		BackendSqlite, version,
+	)
+}
+
+// verifySnapshotSwaps reads all swaps from a snapshot, including archived
+// swaps.
+func verifySnapshotSwaps(store SwapStore, backend Backend, version uint32) (
+	*SnapshotInfo, error) {
+
+	loopOuts, loopIns, err := fetchAllSwaps(store)
+	if err != nil {
+		return nil, fmt.Errorf("read swaps: %v", err)
+	}
+
+	return &SnapshotInfo{
+		Backend:  backend,
+		Version:  version,
+		LoopOuts: len(loopOuts),
+		LoopIns:  len(loopIns),
+	}, nil
+}
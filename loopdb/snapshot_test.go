@@ -0,0 +1,92 @@
+package loopdb
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/lightninglabs/loop/test"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSnapshot tests taking and verifying snapshots of all swap store
+// backends.
+func TestSnapshot(t *testing.T) {
+	for _, backend := range testBackends {
+		backend := backend
+
+		t.Run(string(backend), func(t *testing.T) {
+			testSnapshot(t, backend)
+		})
+	}
+}
+
+func testSnapshot(t *testing.T, backend Backend) {
+	tempDirName, err := ioutil.TempDir("", "clientstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDirName)
+
+	params := &chaincfg.MainNetParams
+	store, err := NewSwapStore(backend, tempDirName, params)
+	require.NoError(t, err)
+	defer store.Close()
+
+	loopOut := &LoopOutContract{
+		SwapContract: SwapContract{
+			Preimage:    testPreimage,
+			SenderKey:   senderKey,
+			ReceiverKey: receiverKey,
+		},
+		DestAddr: test.GetDestAddr(t, 0),
+	}
+	hash := testPreimage.Hash()
+	require.NoError(t, store.CreateLoopOut(hash, loopOut))
+	require.NoError(t, store.UpdateLoopOut(
+		hash, testTime, SwapStateData{State: StateSuccess},
+	))
+
+	snapshotPath := filepath.Join(tempDirName, "snapshot")
+	require.NoError(t, store.Snapshot(snapshotPath))
+
+	info, err := VerifySnapshot(snapshotPath, params)
+	require.NoError(t, err)
+	require.Equal(t, backend, info.Backend)
+	require.Equal(t, 1, info.LoopOuts)
+	require.Equal(t, 0, info.LoopIns)
+
+	// The snapshot is a complete copy of the store.
+	loopOuts, err := store.FetchLoopOutSwaps()
+	require.NoError(t, err)
+
+	restoredDir := filepath.Join(tempDirName, "restored")
+	require.NoError(t, os.Mkdir(restoredDir, 0700))
+
+	restoredFile := filepath.Join(restoredDir, dbFileName)
+	if backend == BackendSqlite {
+		restoredFile = filepath.Join(restoredDir, SqliteFileName)
+	}
+	require.NoError(t, os.Rename(snapshotPath, restoredFile))
+
+	snapshotStore, err := NewSwapStore(backend, restoredDir, params)
+	require.NoError(t, err)
+	defer snapshotStore.Close()
+
+	restoredLoopOuts, err := snapshotStore.FetchLoopOutSwaps()
+	require.NoError(t, err)
+	require.Equal(t, loopOuts, restoredLoopOuts)
+
+	// Existing files are never overwritten.
+	existingPath := filepath.Join(tempDirName, "existing")
+	require.NoError(t, ioutil.WriteFile(existingPath, []byte{1}, 0600))
+	require.Error(t, store.Snapshot(existingPath))
+
+	contents, err := ioutil.ReadFile(existingPath)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1}, contents)
+
+	// A file that isn't a database snapshot fails verification.
+	_, err = VerifySnapshot(existingPath, params)
+	require.Error(t, err)
+}
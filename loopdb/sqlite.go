@@ -0,0 +1,1128 @@
+package loopdb
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/labels"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/routing/route"
+
+	// Register the pure go sqlite driver.
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// SqliteFileName is the file name of the sqlite swap store.
+	SqliteFileName = "loop.sqlite"
+)
+
+// sqliteSwapStore stores swap data in a sqlite database.
+type sqliteSwapStore struct {
+	db          *sql.DB
+	chainParams *chaincfg.Params
+}
+
+// A compile-time flag to ensure that sqliteSwapStore implements the SwapStore
+// interface.
+var _ SwapStore = (*sqliteSwapStore)(nil)
+
+// NewSqliteSwapStore opens the sqlite swap store in the directory provided,
+// creating it if it doesn't exist yet, and migrates its schema to the latest
+// version. If the schema needs to be migrated, a copy of the database is
+// written next to it first.
+func NewSqliteSwapStore(dbPath string, chainParams *chaincfg.Params) (
+	*sqliteSwapStore, error) {
+
+	return openSqliteSwapStore(dbPath, chainParams, true)
+}
+
+// openSqliteSwapStore opens the sqlite swap store in the directory provided
+// and migrates its schema to the latest version, backing it up first if
+// backup is set.
+func openSqliteSwapStore(dbPath string, chainParams *chaincfg.Params,
+	backup bool) (*sqliteSwapStore, error) {
+
+	if !fileExists(dbPath) {
+		if err := os.MkdirAll(dbPath, 0700); err != nil {
+			return nil, err
+		}
+	}
+
+	path := filepath.Join(dbPath, SqliteFileName)
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sqlite only supports a single writer, so we serialize all access
+	// through a single connection to prevent busy errors.
+	db.SetMaxOpenConns(1)
+
+	if err := syncSqlVersion(db, path, backup); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &sqliteSwapStore{
+		db:          db,
+		chainParams: chainParams,
+	}, nil
+}
+
+// sqlSwap contains the columns of the swaps table.
+type sqlSwap struct {
+	id       int64
+	hash     lntypes.Hash
+	contract SwapContract
+	archived bool
+}
+
+// swapFilter restricts the swaps that are loaded to the ids that are selected
+// by a subquery. A nil filter selects all swaps.
+type swapFilter struct {
+	// query is the subquery that selects the swap ids.
+	query string
+
+	// args are the arguments of the subquery.
+	args []interface{}
+}
+
+// activeSwaps is the filter that selects the swaps that haven't been archived.
+var activeSwaps = &swapFilter{
+	query: "SELECT id FROM swaps WHERE archived = 0",
+}
+
+// where returns the condition that applies the filter to the swap id column
+// provided, prefixed by the operator provided, along with its arguments.
+func (f *swapFilter) where(op, column string) (string, []interface{}) {
+	if f == nil {
+		return "", nil
+	}
+
+	return " " + op + " " + column + " IN (" + f.query + ")", f.args
+}
+
+// querySwaps returns the swaps of the type provided that the filter selects,
+// ordered by hash. The columns of the swap type table that are provided are
+// scanned into typeDest, after which scanType is called for every row.
+func (s *sqliteSwapStore) querySwaps(swapType swap.Type, typeTable string,
+	typeColumns string, filter *swapFilter, scanType func() error,
+	typeDest ...interface{}) ([]*sqlSwap, error) {
+
+	where, args := filter.where("AND", "s.id")
+	rows, err := s.db.Query(`
+		SELECT s.id, s.swap_hash, s.preimage, s.amount_requested,
+			s.sender_key, s.receiver_key, s.cltv_expiry,
+			s.max_swap_fee, s.max_miner_fee, s.initiation_height,
+			s.initiation_time, s.label, s.protocol_version,
+			s.initiator, s.archived, `+
+		typeColumns+`
+		FROM swaps s JOIN `+typeTable+` t ON t.swap_id = s.id
+		WHERE s.swap_type = ?`+where+`
+		ORDER BY s.swap_hash`,
+		append([]interface{}{uint8(swapType)}, args...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var swaps []*sqlSwap
+	for rows.Next() {
+		var (
+			swp                          sqlSwap
+			hash, preimage               []byte
+			senderKey, receiverKey       []byte
+			initiationTime               int64
+			protocolVersion              uint32
+			amount, maxSwapFee, maxMiner int64
+		)
+
+		dest := append([]interface{}{
+			&swp.id, &hash, &preimage, &amount, &senderKey,
+			&receiverKey, &swp.contract.CltvExpiry, &maxSwapFee,
+			&maxMiner, &swp.contract.InitiationHeight,
+			&initiationTime, &swp.contract.Label, &protocolVersion,
+			&swp.contract.Initiator, &swp.archived,
+		}, typeDest...)
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		swp.hash, err = lntypes.MakeHash(hash)
+		if err != nil {
+			return nil, err
+		}
+
+		swp.contract.Preimage, err = lntypes.MakePreimage(preimage)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(senderKey) != keyLength || len(receiverKey) != keyLength {
+			return nil, errors.New("invalid htlc key length")
+		}
+		copy(swp.contract.SenderKey[:], senderKey)
+		copy(swp.contract.ReceiverKey[:], receiverKey)
+
+		swp.contract.AmountRequested = btcutil.Amount(amount)
+		swp.contract.MaxSwapFee = btcutil.Amount(maxSwapFee)
+		swp.contract.MaxMinerFee = btcutil.Amount(maxMiner)
+		swp.contract.InitiationTime = time.Unix(0, initiationTime)
+		swp.contract.ProtocolVersion = ProtocolVersion(protocolVersion)
+
+		if err := scanType(); err != nil {
+			return nil, err
+		}
+
+		swaps = append(swaps, &swp)
+	}
+
+	return swaps, rows.Err()
+}
+
+// FetchLoopOutSwaps returns all loop out swaps currently in the store that
+// have not been archived.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *sqliteSwapStore) FetchLoopOutSwaps() ([]*LoopOut, error) {
+	return s.loadLoopOuts(activeSwaps)
+}
+
+// loadLoopOuts returns the loop out swaps that the filter selects.
+func (s *sqliteSwapStore) loadLoopOuts(filter *swapFilter) ([]*LoopOut,
+	error) {
+
+	var (
+		loopOuts []*LoopOut
+
+		destAddr, swapInvoice, prepayInvoice string
+		maxSwapRoutingFee, maxPrepayFee      int64
+		sweepConfTarget, cltvLimit           int32
+		htlcConfs                            uint32
+		lastHop                              []byte
+		deadline                             int64
+	)
+
+	// The type specific columns are scanned into the variables above for
+	// every row, and copied into the contract of the swap by scanType.
+	var contracts []*LoopOutContract
+	scanType := func() error {
+		addr, err := swap.DecodeAddress(destAddr, s.chainParams)
+		if err != nil {
+			return err
+		}
+
+		contract := &LoopOutContract{
+			DestAddr:                addr,
+			SwapInvoice:             swapInvoice,
+			MaxSwapRoutingFee:       btcutil.Amount(maxSwapRoutingFee),
+			SweepConfTarget:         sweepConfTarget,
+			HtlcConfirmations:       htlcConfs,
+			PaymentCltvLimit:        cltvLimit,
+			PrepayInvoice:           prepayInvoice,
+			MaxPrepayRoutingFee:     btcutil.Amount(maxPrepayFee),
+			SwapPublicationDeadline: time.Unix(0, deadline),
+		}
+
+		if lastHop != nil {
+			vertex, err := route.NewVertexFromBytes(lastHop)
+			if err != nil {
+				return err
+			}
+			contract.LastHop = &vertex
+		}
+
+		contracts = append(contracts, contract)
+		return nil
+	}
+
+	swaps, err := s.querySwaps(
+		swap.TypeOut, "loop_out_swaps", `t.dest_address,
+			t.swap_invoice, t.max_swap_routing_fee,
+			t.sweep_conf_target, t.htlc_confirmations, t.last_hop,
+			t.payment_cltv_limit, t.prepay_invoice,
+			t.max_prepay_routing_fee, t.publication_deadline`,
+		filter, scanType, &destAddr, &swapInvoice, &maxSwapRoutingFee,
+		&sweepConfTarget, &htlcConfs, &lastHop, &cltvLimit,
+		&prepayInvoice, &maxPrepayFee, &deadline,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	channels, err := s.fetchChannels(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	destinations, err := s.fetchSweepDestinations(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	updates, err := s.fetchUpdates(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, swp := range swaps {
+		contract := contracts[i]
+		contract.SwapContract = swp.contract
+		contract.OutgoingChanSet = channels[swp.id]
+		contract.SweepDestinations = destinations[swp.id]
+
+		loopOuts = append(loopOuts, &LoopOut{
+			Loop: Loop{
+				Hash:     swp.hash,
+				Events:   updates[swp.id],
+				Archived: swp.archived,
+			},
+			Contract: contract,
+		})
+	}
+
+	return loopOuts, nil
+}
+
+// FetchLoopInSwaps returns all loop in swaps currently in the store that have
+// not been archived.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *sqliteSwapStore) FetchLoopInSwaps() ([]*LoopIn, error) {
+	return s.loadLoopIns(activeSwaps)
+}
+
+// loadLoopIns returns the loop in swaps that the filter selects.
+func (s *sqliteSwapStore) loadLoopIns(filter *swapFilter) ([]*LoopIn, error) {
+
+	var (
+		loopIns []*LoopIn
+
+		htlcConfTarget int32
+		lastHop        []byte
+		externalHtlc   bool
+		changeAddr     sql.NullString
+	)
+
+	// The type specific columns are scanned into the variables above for
+	// every row, and copied into the contract of the swap by scanType.
+	var contracts []*LoopInContract
+	scanType := func() error {
+		contract := &LoopInContract{
+			HtlcConfTarget: htlcConfTarget,
+			ExternalHtlc:   externalHtlc,
+		}
+
+		if lastHop != nil {
+			vertex, err := route.NewVertexFromBytes(lastHop)
+			if err != nil {
+				return err
+			}
+			contract.LastHop = &vertex
+		}
+
+		if changeAddr.Valid {
+			addr, err := btcutil.DecodeAddress(
+				changeAddr.String, s.chainParams,
+			)
+			if err != nil {
+				return err
+			}
+			contract.ChangeAddr = addr
+		}
+
+		contracts = append(contracts, contract)
+		return nil
+	}
+
+	swaps, err := s.querySwaps(
+		swap.TypeIn, "loop_in_swaps", `t.htlc_conf_target,
+			t.last_hop, t.external_htlc, t.change_address`,
+		filter, scanType, &htlcConfTarget, &lastHop, &externalHtlc,
+		&changeAddr,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	outpoints, err := s.fetchFundingOutpoints(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	updates, err := s.fetchUpdates(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, swp := range swaps {
+		contract := contracts[i]
+		contract.SwapContract = swp.contract
+
+		// The label of a loop in is kept in the loop in contract.
+		contract.Label = swp.contract.Label
+		contract.SwapContract.Label = ""
+
+		contract.HtlcFundingOutpoints = outpoints[swp.id]
+
+		loopIns = append(loopIns, &LoopIn{
+			Loop: Loop{
+				Hash:     swp.hash,
+				Events:   updates[swp.id],
+				Archived: swp.archived,
+			},
+			Contract: contract,
+		})
+	}
+
+	return loopIns, nil
+}
+
+// QuerySwaps returns the page of swaps that the query provided selects.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *sqliteSwapStore) QuerySwaps(query *SwapQuery) (*SwapPage, error) {
+	conditions, args := queryConditions(query)
+
+	var where string
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+	selectSwaps := `
+		SELECT s.id FROM swaps s` + where + `
+		ORDER BY s.initiation_time, s.swap_hash`
+
+	page := &SwapPage{}
+	filter := &swapFilter{
+		query: selectSwaps,
+		args:  args,
+	}
+
+	// If the query is limited, we look for one more swap than the limit
+	// to find out whether there is a next page.
+	if query.Limit != 0 {
+		filter.query += fmt.Sprintf(" LIMIT %d", query.Limit)
+
+		var err error
+		page.NextCursor, err = s.nextCursor(
+			where, args, query.Limit,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if query.matchType(swap.TypeOut) {
+		page.LoopOuts, err = s.loadLoopOuts(filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if query.matchType(swap.TypeIn) {
+		page.LoopIns, err = s.loadLoopIns(filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+// nextCursor returns the cursor of the last swap of a page of the limit
+// provided if there are more swaps that match the conditions, and nil
+// otherwise.
+func (s *sqliteSwapStore) nextCursor(where string, args []interface{},
+	limit uint32) (*SwapCursor, error) {
+
+	rows, err := s.db.Query(`
+		SELECT s.initiation_time, s.swap_hash FROM swaps s`+where+`
+		ORDER BY s.initiation_time, s.swap_hash
+		LIMIT ?`, append(args, limit+1)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cursors []*SwapCursor
+	for rows.Next() {
+		var (
+			initiationTime int64
+			hash           []byte
+		)
+		if err := rows.Scan(&initiationTime, &hash); err != nil {
+			return nil, err
+		}
+
+		cursor := &SwapCursor{
+			InitiationTime: time.Unix(0, initiationTime),
+		}
+		copy(cursor.Hash[:], hash)
+
+		cursors = append(cursors, cursor)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if uint32(len(cursors)) <= limit {
+		return nil, nil
+	}
+
+	return cursors[limit-1], nil
+}
+
+// queryConditions returns the sql conditions on the swaps table that select
+// the swaps of a query, along with their arguments.
+func queryConditions(query *SwapQuery) ([]string, []interface{}) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+
+	if !query.IncludeArchived {
+		conditions = append(conditions, "s.archived = 0")
+	}
+
+	if query.Hash != nil {
+		conditions = append(conditions, "s.swap_hash = ?")
+		args = append(args, query.Hash[:])
+	}
+
+	if query.SwapType != nil {
+		conditions = append(conditions, "s.swap_type = ?")
+		args = append(args, uint8(*query.SwapType))
+	}
+
+	// The state of a swap is the state of its last update, or initiated
+	// if it has no updates. State types are mapped to the states that
+	// they contain.
+	if len(query.StateTypes) != 0 {
+		var states []string
+		for state := 0; state <= math.MaxUint8; state++ {
+			if query.matchState(SwapState(state)) {
+				states = append(states, strconv.Itoa(state))
+			}
+		}
+
+		conditions = append(conditions, fmt.Sprintf(`COALESCE((
+			SELECT u.state FROM swap_updates u
+			WHERE u.swap_id = s.id ORDER BY u.id DESC LIMIT 1
+		), %d) IN (%s)`, StateInitiated, strings.Join(states, ",")))
+	}
+
+	if !query.StartTime.IsZero() {
+		conditions = append(conditions, "s.initiation_time >= ?")
+		args = append(args, query.StartTime.UnixNano())
+	}
+
+	if !query.EndTime.IsZero() {
+		conditions = append(conditions, "s.initiation_time < ?")
+		args = append(args, query.EndTime.UnixNano())
+	}
+
+	if !query.UpdatedBefore.IsZero() {
+		conditions = append(conditions, `(
+			SELECT u.update_time FROM swap_updates u
+			WHERE u.swap_id = s.id ORDER BY u.id DESC LIMIT 1
+		) < ?`)
+		args = append(args, query.UpdatedBefore.UnixNano())
+	}
+
+	if query.Label != "" {
+		conditions = append(conditions, "s.label = ?")
+		args = append(args, query.Label)
+	}
+
+	if query.Initiator != "" {
+		conditions = append(conditions, "s.initiator = ?")
+		args = append(args, query.Initiator)
+	}
+
+	if query.OutgoingChanID != 0 {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM loop_out_channels c
+			WHERE c.swap_id = s.id AND c.channel_id = ?
+		)`)
+		args = append(args, int64(query.OutgoingChanID))
+	}
+
+	if query.LastHop != nil {
+		conditions = append(conditions, `(EXISTS (
+			SELECT 1 FROM loop_out_swaps o
+			WHERE o.swap_id = s.id AND o.last_hop = ?
+		) OR EXISTS (
+			SELECT 1 FROM loop_in_swaps i
+			WHERE i.swap_id = s.id AND i.last_hop = ?
+		))`)
+		args = append(args, query.LastHop[:], query.LastHop[:])
+	}
+
+	if query.After != nil {
+		conditions = append(conditions, `(s.initiation_time > ? OR
+			(s.initiation_time = ? AND s.swap_hash > ?))`)
+		afterTime := query.After.InitiationTime.UnixNano()
+		args = append(
+			args, afterTime, afterTime, query.After.Hash[:],
+		)
+	}
+
+	return conditions, args
+}
+
+// fetchChannels returns the outgoing channel sets of the loop out swaps that
+// the filter selects by swap id.
+func (s *sqliteSwapStore) fetchChannels(filter *swapFilter) (
+	map[int64]ChannelSet, error) {
+
+	where, args := filter.where("WHERE", "swap_id")
+	rows, err := s.db.Query(`
+		SELECT swap_id, channel_id FROM loop_out_channels`+where+`
+		ORDER BY swap_id, position`, args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	channels := make(map[int64]ChannelSet)
+	for rows.Next() {
+		var (
+			swapID int64
+			chanID int64
+		)
+		if err := rows.Scan(&swapID, &chanID); err != nil {
+			return nil, err
+		}
+
+		channels[swapID] = append(channels[swapID], uint64(chanID))
+	}
+
+	return channels, rows.Err()
+}
+
+// fetchSweepDestinations returns the sweep destinations of the loop out swaps
+// that the filter selects by swap id.
+func (s *sqliteSwapStore) fetchSweepDestinations(filter *swapFilter) (
+	map[int64][]SweepDestination, error) {
+
+	where, args := filter.where("WHERE", "swap_id")
+	rows, err := s.db.Query(`
+		SELECT swap_id, address, weight FROM sweep_destinations`+where+`
+		ORDER BY swap_id, position`, args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	destinations := make(map[int64][]SweepDestination)
+	for rows.Next() {
+		var (
+			swapID int64
+			addr   string
+			dest   SweepDestination
+		)
+		if err := rows.Scan(&swapID, &addr, &dest.Weight); err != nil {
+			return nil, err
+		}
+
+		dest.Addr, err = swap.DecodeAddress(addr, s.chainParams)
+		if err != nil {
+			return nil, err
+		}
+
+		destinations[swapID] = append(destinations[swapID], dest)
+	}
+
+	return destinations, rows.Err()
+}
+
+// fetchFundingOutpoints returns the htlc funding outpoints of the loop in
+// swaps that the filter selects by swap id.
+func (s *sqliteSwapStore) fetchFundingOutpoints(filter *swapFilter) (
+	map[int64][]wire.OutPoint, error) {
+
+	where, args := filter.where("WHERE", "swap_id")
+	rows, err := s.db.Query(`
+		SELECT swap_id, txid, output_index FROM htlc_funding_outpoints`+
+		where+`
+		ORDER BY swap_id, position`, args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	outpoints := make(map[int64][]wire.OutPoint)
+	for rows.Next() {
+		var (
+			swapID   int64
+			txid     []byte
+			outpoint wire.OutPoint
+		)
+		err := rows.Scan(&swapID, &txid, &outpoint.Index)
+		if err != nil {
+			return nil, err
+		}
+
+		hash, err := chainhash.NewHash(txid)
+		if err != nil {
+			return nil, err
+		}
+		outpoint.Hash = *hash
+
+		outpoints[swapID] = append(outpoints[swapID], outpoint)
+	}
+
+	return outpoints, rows.Err()
+}
+
+// fetchUpdates returns the updates of the swaps that the filter selects by
+// swap id.
+func (s *sqliteSwapStore) fetchUpdates(filter *swapFilter) (
+	map[int64][]*LoopEvent, error) {
+
+	where, args := filter.where("WHERE", "swap_id")
+	rows, err := s.db.Query(`
+		SELECT swap_id, update_time, state, cost_server, cost_onchain,
+			cost_offchain, htlc_txid, htlc_tx, sweep_txid,
+			sweep_fee, htlc_outpoint_txid, htlc_outpoint_index,
+			htlc_conf_height, spend_txid, spend_conf_height
+		FROM swap_updates`+where+`
+		ORDER BY swap_id, id`, args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	updates := make(map[int64][]*LoopEvent)
+	for rows.Next() {
+		var (
+			swapID                  int64
+			updateTime              int64
+			state                   uint8
+			server, onchain, offchn int64
+			htlcTxID, htlcTx        []byte
+			sweepTxID               []byte
+			sweepFee                int64
+			htlcOutpointTxID        []byte
+			htlcOutpointIndex       uint32
+			htlcConfHeight          int32
+			spendTxID               []byte
+			spendConfHeight         int32
+		)
+		err := rows.Scan(
+			&swapID, &updateTime, &state, &server, &onchain,
+			&offchn, &htlcTxID, &htlcTx, &sweepTxID, &sweepFee,
+			&htlcOutpointTxID, &htlcOutpointIndex, &htlcConfHeight,
+			&spendTxID, &spendConfHeight,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		event := &LoopEvent{
+			SwapStateData: SwapStateData{
+				State: SwapState(state),
+				Cost: SwapCost{
+					Server:   btcutil.Amount(server),
+					Onchain:  btcutil.Amount(onchain),
+					Offchain: btcutil.Amount(offchn),
+				},
+				SweepFee:        btcutil.Amount(sweepFee),
+				HtlcConfHeight:  htlcConfHeight,
+				SpendConfHeight: spendConfHeight,
+			},
+			Time: time.Unix(0, updateTime),
+		}
+
+		if htlcTxID != nil {
+			event.HtlcTxHash, err = chainhash.NewHash(htlcTxID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if sweepTxID != nil {
+			event.SweepTxHash, err = chainhash.NewHash(sweepTxID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if htlcTx != nil {
+			event.HtlcTx = &wire.MsgTx{}
+			err := event.HtlcTx.Deserialize(bytes.NewReader(htlcTx))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if htlcOutpointTxID != nil {
+			hash, err := chainhash.NewHash(htlcOutpointTxID)
+			if err != nil {
+				return nil, err
+			}
+			event.HtlcOutpoint = wire.NewOutPoint(
+				hash, htlcOutpointIndex,
+			)
+		}
+
+		if spendTxID != nil {
+			event.SpendTxHash, err = chainhash.NewHash(spendTxID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		updates[swapID] = append(updates[swapID], event)
+	}
+
+	return updates, rows.Err()
+}
+
+// insertSwap inserts the shared columns of a swap and returns its id.
+func insertSwap(tx *sql.Tx, hash lntypes.Hash, swapType swap.Type,
+	contract *SwapContract, label string) (int64, error) {
+
+	// If the hash doesn't match the pre-image, then this is an invalid
+	// swap so we'll bail out early.
+	if hash != contract.Preimage.Hash() {
+		return 0, errors.New("hash and preimage do not match")
+	}
+
+	var exists bool
+	err := tx.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM swaps WHERE swap_hash = ?)",
+		hash[:],
+	).Scan(&exists)
+	if err != nil {
+		return 0, err
+	}
+	if exists {
+		return 0, fmt.Errorf("swap %v already exists", hash)
+	}
+
+	// Check that the label does not exceed our maximum length.
+	if len(label) > labels.MaxLength {
+		return 0, labels.ErrLabelTooLong
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO swaps (swap_hash, swap_type, preimage,
+			amount_requested, sender_key, receiver_key,
+			cltv_expiry, max_swap_fee, max_miner_fee,
+			initiation_height, initiation_time, label,
+			protocol_version, initiator)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		hash[:], uint8(swapType), contract.Preimage[:],
+		int64(contract.AmountRequested), contract.SenderKey[:],
+		contract.ReceiverKey[:], contract.CltvExpiry,
+		int64(contract.MaxSwapFee), int64(contract.MaxMinerFee),
+		contract.InitiationHeight,
+		contract.InitiationTime.UnixNano(), label,
+		uint32(contract.ProtocolVersion), contract.Initiator,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// lastHopBytes returns the column value of an optional last hop.
+func lastHopBytes(lastHop *route.Vertex) []byte {
+	if lastHop == nil {
+		return nil
+	}
+
+	return lastHop[:]
+}
+
+// update runs the function provided in a database transaction, which is
+// committed if the function returns without an error.
+func (s *sqliteSwapStore) update(f func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := f(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CreateLoopOut adds an initiated swap to the store.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *sqliteSwapStore) CreateLoopOut(hash lntypes.Hash,
+	contract *LoopOutContract) error {
+
+	return s.update(func(tx *sql.Tx) error {
+		id, err := insertSwap(
+			tx, hash, swap.TypeOut, &contract.SwapContract,
+			contract.Label,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO loop_out_swaps (swap_id, dest_address,
+				swap_invoice, max_swap_routing_fee,
+				sweep_conf_target, htlc_confirmations,
+				last_hop, payment_cltv_limit, prepay_invoice,
+				max_prepay_routing_fee, publication_deadline)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, contract.DestAddr.String(), contract.SwapInvoice,
+			int64(contract.MaxSwapRoutingFee),
+			contract.SweepConfTarget, contract.HtlcConfirmations,
+			lastHopBytes(contract.LastHop),
+			contract.PaymentCltvLimit, contract.PrepayInvoice,
+			int64(contract.MaxPrepayRoutingFee),
+			contract.SwapPublicationDeadline.UnixNano(),
+		)
+		if err != nil {
+			return err
+		}
+
+		for i, chanID := range contract.OutgoingChanSet {
+			_, err := tx.Exec(`
+				INSERT INTO loop_out_channels (swap_id,
+					position, channel_id)
+				VALUES (?, ?, ?)`, id, i, int64(chanID),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		for i, dest := range contract.SweepDestinations {
+			_, err := tx.Exec(`
+				INSERT INTO sweep_destinations (swap_id,
+					position, address, weight)
+				VALUES (?, ?, ?, ?)`, id, i, dest.Addr.String(),
+				dest.Weight,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// CreateLoopIn adds an initiated swap to the store.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *sqliteSwapStore) CreateLoopIn(hash lntypes.Hash,
+	contract *LoopInContract) error {
+
+	return s.update(func(tx *sql.Tx) error {
+		id, err := insertSwap(
+			tx, hash, swap.TypeIn, &contract.SwapContract,
+			contract.Label,
+		)
+		if err != nil {
+			return err
+		}
+
+		var changeAddr sql.NullString
+		if contract.ChangeAddr != nil {
+			changeAddr.String = contract.ChangeAddr.String()
+			changeAddr.Valid = true
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO loop_in_swaps (swap_id, htlc_conf_target,
+				last_hop, external_htlc, change_address)
+			VALUES (?, ?, ?, ?, ?)`,
+			id, contract.HtlcConfTarget,
+			lastHopBytes(contract.LastHop), contract.ExternalHtlc,
+			changeAddr,
+		)
+		if err != nil {
+			return err
+		}
+
+		for i, outpoint := range contract.HtlcFundingOutpoints {
+			_, err := tx.Exec(`
+				INSERT INTO htlc_funding_outpoints (swap_id,
+					position, txid, output_index)
+				VALUES (?, ?, ?, ?)`, id, i, outpoint.Hash[:],
+				outpoint.Index,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// updateLoop saves a new swap state transition to the store.
+func (s *sqliteSwapStore) updateLoop(swapType swap.Type, hash lntypes.Hash,
+	time time.Time, state SwapStateData) error {
+
+	return s.update(func(tx *sql.Tx) error {
+		return insertSwapUpdate(tx, swapType, hash, time, state)
+	})
+}
+
+// updateLoops saves a new swap state transition for each of the given swaps
+// in a single db transaction, so that either all or none of them are stored.
+func (s *sqliteSwapStore) updateLoops(swapType swap.Type, time time.Time,
+	updates map[lntypes.Hash]SwapStateData) error {
+
+	return s.update(func(tx *sql.Tx) error {
+		for hash, state := range updates {
+			err := insertSwapUpdate(tx, swapType, hash, time, state)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// insertSwapUpdate appends a new swap state transition to the updates of the
+// swap with the given hash.
+func insertSwapUpdate(tx *sql.Tx, swapType swap.Type, hash lntypes.Hash,
+	time time.Time, state SwapStateData) error {
+
+	// Archived swaps are completed and can't be updated anymore.
+	var id int64
+	err := tx.QueryRow(
+		"SELECT id FROM swaps WHERE swap_hash = ? AND "+
+			"swap_type = ? AND archived = 0", hash[:],
+		uint8(swapType),
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return errors.New("swap not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	var htlcTxID, sweepTxID, htlcTx []byte
+	if state.HtlcTxHash != nil {
+		htlcTxID = state.HtlcTxHash[:]
+	}
+
+	var sweepFee btcutil.Amount
+	if state.SweepTxHash != nil {
+		sweepTxID = state.SweepTxHash[:]
+		sweepFee = state.SweepFee
+	}
+
+	if state.HtlcTx != nil {
+		var b bytes.Buffer
+		if err := state.HtlcTx.Serialize(&b); err != nil {
+			return err
+		}
+		htlcTx = b.Bytes()
+	}
+
+	var (
+		htlcOutpointTxID  []byte
+		htlcOutpointIndex uint32
+		spendTxID         []byte
+	)
+	if state.HtlcOutpoint != nil {
+		htlcOutpointTxID = state.HtlcOutpoint.Hash[:]
+		htlcOutpointIndex = state.HtlcOutpoint.Index
+	}
+
+	if state.SpendTxHash != nil {
+		spendTxID = state.SpendTxHash[:]
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO swap_updates (swap_id, update_time, state,
+			cost_server, cost_onchain, cost_offchain,
+			htlc_txid, htlc_tx, sweep_txid, sweep_fee,
+			htlc_outpoint_txid, htlc_outpoint_index,
+			htlc_conf_height, spend_txid, spend_conf_height)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		id, time.UnixNano(), uint8(state.State),
+		int64(state.Cost.Server), int64(state.Cost.Onchain),
+		int64(state.Cost.Offchain), htlcTxID, htlcTx,
+		sweepTxID, int64(sweepFee), htlcOutpointTxID,
+		htlcOutpointIndex, state.HtlcConfHeight, spendTxID,
+		state.SpendConfHeight,
+	)
+	return err
+}
+
+// UpdateLoopOut stores a swap update. This appends to the event log for
+// a particular swap as it goes through the various stages in its lifetime.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *sqliteSwapStore) UpdateLoopOut(hash lntypes.Hash, time time.Time,
+	state SwapStateData) error {
+
+	return s.updateLoop(swap.TypeOut, hash, time, state)
+}
+
+// UpdateLoopOuts stores a swap update for each of the given loop out swaps in
+// a single transaction.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *sqliteSwapStore) UpdateLoopOuts(time time.Time,
+	updates map[lntypes.Hash]SwapStateData) error {
+
+	return s.updateLoops(swap.TypeOut, time, updates)
+}
+
+// UpdateLoopIn stores a swap update. This appends to the event log for
+// a particular swap as it goes through the various stages in its lifetime.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *sqliteSwapStore) UpdateLoopIn(hash lntypes.Hash, time time.Time,
+	state SwapStateData) error {
+
+	return s.updateLoop(swap.TypeIn, hash, time, state)
+}
+
+// UpdateLoopIns stores a swap update for each of the given loop in swaps in a
+// single transaction.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *sqliteSwapStore) UpdateLoopIns(time time.Time,
+	updates map[lntypes.Hash]SwapStateData) error {
+
+	return s.updateLoops(swap.TypeIn, time, updates)
+}
+
+// Close closes the underlying database.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *sqliteSwapStore) Close() error {
+	return s.db.Close()
+}
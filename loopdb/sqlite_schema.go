@@ -0,0 +1,213 @@
+package loopdb
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+var (
+	// sqlMigrations contains the statements that migrate the sqlite schema
+	// from one version to the next. The schema version is the number of
+	// migrations that have been applied and is stored in the user_version
+	// of the database. Migrations may only be appended to this list.
+	sqlMigrations = []string{
+		// Version 1: the initial schema. Every swap has a row in the
+		// swaps table, which contains the fields that all swaps
+		// share, and a row in the table of its swap type. Lists are
+		// stored in separate tables and ordered by their position.
+		`
+		CREATE TABLE swaps (
+			id INTEGER PRIMARY KEY,
+			swap_hash BLOB NOT NULL UNIQUE,
+			swap_type INTEGER NOT NULL,
+			preimage BLOB NOT NULL,
+			amount_requested INTEGER NOT NULL,
+			sender_key BLOB NOT NULL,
+			receiver_key BLOB NOT NULL,
+			cltv_expiry INTEGER NOT NULL,
+			max_swap_fee INTEGER NOT NULL,
+			max_miner_fee INTEGER NOT NULL,
+			initiation_height INTEGER NOT NULL,
+			initiation_time INTEGER NOT NULL,
+			label TEXT NOT NULL,
+			protocol_version INTEGER NOT NULL
+		);
+
+		CREATE TABLE loop_out_swaps (
+			swap_id INTEGER PRIMARY KEY REFERENCES swaps(id),
+			dest_address TEXT NOT NULL,
+			swap_invoice TEXT NOT NULL,
+			max_swap_routing_fee INTEGER NOT NULL,
+			sweep_conf_target INTEGER NOT NULL,
+			htlc_confirmations INTEGER NOT NULL,
+			last_hop BLOB,
+			payment_cltv_limit INTEGER NOT NULL,
+			prepay_invoice TEXT NOT NULL,
+			max_prepay_routing_fee INTEGER NOT NULL,
+			publication_deadline INTEGER NOT NULL
+		);
+
+		CREATE TABLE loop_out_channels (
+			swap_id INTEGER NOT NULL REFERENCES swaps(id),
+			position INTEGER NOT NULL,
+			channel_id INTEGER NOT NULL,
+			PRIMARY KEY (swap_id, position)
+		);
+
+		CREATE TABLE sweep_destinations (
+			swap_id INTEGER NOT NULL REFERENCES swaps(id),
+			position INTEGER NOT NULL,
+			address TEXT NOT NULL,
+			weight INTEGER NOT NULL,
+			PRIMARY KEY (swap_id, position)
+		);
+
+		CREATE TABLE loop_in_swaps (
+			swap_id INTEGER PRIMARY KEY REFERENCES swaps(id),
+			htlc_conf_target INTEGER NOT NULL,
+			last_hop BLOB,
+			external_htlc BOOLEAN NOT NULL,
+			change_address TEXT
+		);
+
+		CREATE TABLE htlc_funding_outpoints (
+			swap_id INTEGER NOT NULL REFERENCES swaps(id),
+			position INTEGER NOT NULL,
+			txid BLOB NOT NULL,
+			output_index INTEGER NOT NULL,
+			PRIMARY KEY (swap_id, position)
+		);
+
+		CREATE TABLE swap_updates (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			swap_id INTEGER NOT NULL REFERENCES swaps(id),
+			update_time INTEGER NOT NULL,
+			state INTEGER NOT NULL,
+			cost_server INTEGER NOT NULL,
+			cost_onchain INTEGER NOT NULL,
+			cost_offchain INTEGER NOT NULL,
+			htlc_txid BLOB,
+			htlc_tx BLOB,
+			sweep_txid BLOB,
+			sweep_fee INTEGER NOT NULL
+		);
+
+		CREATE INDEX swap_updates_swap_id ON swap_updates (swap_id);
+		`,
+
+		// Version 2: store the initiator of swaps and index the columns
+		// that swaps are listed by.
+		`
+		ALTER TABLE swaps ADD COLUMN initiator TEXT NOT NULL DEFAULT '';
+
+		CREATE INDEX swaps_initiation_time ON swaps (initiation_time,
+			swap_hash);
+		`,
+
+		// Version 3: store the htlc outpoint, the tx that spent the htlc
+		// and their confirmation heights with swap updates.
+		`
+		ALTER TABLE swap_updates ADD COLUMN htlc_outpoint_txid BLOB;
+		ALTER TABLE swap_updates ADD COLUMN htlc_outpoint_index INTEGER
+			NOT NULL DEFAULT 0;
+		ALTER TABLE swap_updates ADD COLUMN htlc_conf_height INTEGER
+			NOT NULL DEFAULT 0;
+		ALTER TABLE swap_updates ADD COLUMN spend_txid BLOB;
+		ALTER TABLE swap_updates ADD COLUMN spend_conf_height INTEGER
+			NOT NULL DEFAULT 0;
+		`,
+
+		// Version 4: mark swaps that have been archived, so that the
+		// swaps that are fetched on every startup are limited to the
+		// ones that haven't been.
+		`
+		ALTER TABLE swaps ADD COLUMN archived BOOLEAN NOT NULL
+			DEFAULT 0;
+
+		CREATE INDEX swaps_archived ON swaps (archived);
+		`,
+	}
+)
+
+// getSqlVersion returns the schema version of the sqlite database.
+func getSqlVersion(db *sql.DB) (uint32, error) {
+	var version uint32
+	err := db.QueryRow("PRAGMA user_version").Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// syncSqlVersion applies all migrations that the sqlite database is missing.
+// All migrations are applied in a single transaction. If backup is set, a copy
+// of an existing database is written next to the database file at the path
+// provided before it is migrated.
+func syncSqlVersion(db *sql.DB, path string, backup bool) error {
+	currentVersion, err := getSqlVersion(db)
+	if err != nil {
+		return err
+	}
+
+	latestVersion := uint32(len(sqlMigrations))
+
+	log.Infof("Checking for schema update: latest_version=%v, "+
+		"db_version=%v", latestVersion, currentVersion)
+
+	switch {
+
+	// If the database reports a higher version that we are aware of, the
+	// user is probably trying to revert to a prior version of lnd. We fail
+	// here to prevent reversions and unintended corruption.
+	case currentVersion > latestVersion:
+		log.Errorf("Refusing to revert from db_version=%d to "+
+			"lower version=%d", currentVersion,
+			latestVersion)
+
+		return errNewerVersion(currentVersion, latestVersion)
+
+	// If the current database version matches the latest version number,
+	// then we don't need to perform any migrations.
+	case currentVersion == latestVersion:
+		return nil
+	}
+
+	// A new database has no schema yet, so there is nothing to back up.
+	if backup && currentVersion > 0 {
+		backupPath := migrationBackupPath(path, currentVersion)
+
+		store := &sqliteSwapStore{db: db}
+		if err := store.Snapshot(backupPath); err != nil {
+			return fmt.Errorf("backup before migration: %v", err)
+		}
+
+		log.Infof("Backed up database with db_version=%v to %v",
+			currentVersion, backupPath)
+	}
+
+	log.Infof("Performing schema migration")
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for v := currentVersion; v < latestVersion; v++ {
+		log.Infof("Applying schema migration #%v", v+1)
+
+		if _, err := tx.Exec(sqlMigrations[v]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("schema migration #%v: %v", v+1, err)
+		}
+	}
+
+	// The pragma doesn't support bind parameters.
+	_, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", latestVersion))
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
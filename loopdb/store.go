@@ -12,7 +12,9 @@ import (
 
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/coreos/bbolt"
+	"github.com/lightninglabs/loop/swap"
 	"github.com/lightningnetwork/lnd/lntypes"
 )
 
@@ -37,6 +39,20 @@ var (
 	// maps: swapHash -> swapBucket
 	loopInBucketKey = []byte("loop-in")
 
+	// loopOutArchiveBucketKey is a bucket that contains the completed out
+	// swaps that have been archived. It has the same layout as the
+	// loopOutBucket, swaps are moved into it from there.
+	//
+	// maps: swapHash -> swapBucket
+	loopOutArchiveBucketKey = []byte("uncharge-swaps-archive")
+
+	// loopInArchiveBucketKey is a bucket that contains the completed in
+	// swaps that have been archived. It has the same layout as the
+	// loopInBucket, swaps are moved into it from there.
+	//
+	// maps: swapHash -> swapBucket
+	loopInArchiveBucketKey = []byte("loop-in-archive")
+
 	// updatesBucketKey is a bucket that contains all updates pertaining to
 	// a swap. This is a sub-bucket of the swap bucket for a particular
 	// swap. This list only ever grows.
@@ -52,6 +68,32 @@ var (
 	// htlcTxHashKey contains the confirmed htlc tx id.
 	htlcTxHashKey = []byte{1}
 
+	// sweepTxHashKey contains the tx id of the most recently published
+	// htlc sweep tx.
+	sweepTxHashKey = []byte{2}
+
+	// sweepFeeKey contains the fee of the most recently published htlc
+	// sweep tx.
+	sweepFeeKey = []byte{3}
+
+	// htlcTxKey contains the serialized loop in htlc tx that was created
+	// by the client.
+	htlcTxKey = []byte{4}
+
+	// htlcOutpointKey contains the outpoint of the confirmed htlc.
+	htlcOutpointKey = []byte{5}
+
+	// htlcConfHeightKey contains the confirmation height of the htlc.
+	htlcConfHeightKey = []byte{6}
+
+	// spendTxHashKey contains the tx id of the confirmed tx that spent
+	// the htlc.
+	spendTxHashKey = []byte{7}
+
+	// spendConfHeightKey contains the confirmation height of the tx that
+	// spent the htlc.
+	spendConfHeightKey = []byte{8}
+
 	// contractKey is the key that stores the serialized swap contract. It
 	// is nested within the sub-bucket for each active swap.
 	//
@@ -69,6 +111,16 @@ var (
 	// value: string label
 	labelKey = []byte("label")
 
+	// initiatorKey is the key that stores an optional string that
+	// identifies the software that initiated the swap. If a swap was
+	// created before we started storing initiators, or was created without
+	// one, this key will not be present.
+	//
+	// path: loopInBucket/loopOutBucket -> swapBucket[hash] -> initiatorKey
+	//
+	// value: string initiator
+	initiatorKey = []byte("initiator")
+
 	// protocolVersionKey is used to optionally store the protocol version
 	// for the serialized swap contract. It is nested within the sub-bucket
 	// for each active swap.
@@ -94,6 +146,52 @@ var (
 	// value: uint32 confirmation value
 	confirmationsKey = []byte("confirmations")
 
+	// htlcFundingOutpointsKey is the key that stores the wallet outputs
+	// that fund the htlc of a loop in swap. If the wallet selects the
+	// outputs, this key will not be present.
+	//
+	// path: loopInBucket -> swapBucket[hash] -> htlcFundingOutpointsKey
+	//
+	// value: concatenation of txid || uint32 output index
+	htlcFundingOutpointsKey = []byte("htlc-funding-outpoints")
+
+	// changeAddrKey is the key that stores the address that receives the
+	// change of the htlc funding tx of a loop in swap. If a wallet address
+	// is used, this key will not be present.
+	//
+	// path: loopInBucket -> swapBucket[hash] -> changeAddrKey
+	//
+	// value: encoded address string
+	changeAddrKey = []byte("change-address")
+
+	// lastHopKey is the key that stores the last hop that the swap payment
+	// of a loop out swap must be routed through. If the last hop is not
+	// restricted, this key will not be present.
+	//
+	// path: loopOutBucket -> swapBucket[hash] -> lastHopKey
+	//
+	// value: 33 byte node pubkey
+	lastHopKey = []byte("last-hop")
+
+	// paymentCltvLimitKey is the key that stores the maximum total time
+	// lock of the payments of a loop out swap. If the time lock is not
+	// limited, this key will not be present.
+	//
+	// path: loopOutBucket -> swapBucket[hash] -> paymentCltvLimitKey
+	//
+	// value: int32 cltv limit
+	paymentCltvLimitKey = []byte("payment-cltv-limit")
+
+	// sweepDestinationsKey is the key that stores the addresses that the
+	// htlc of a loop out swap is swept to, along with their weights. If
+	// the htlc is swept to the destination address of the contract only,
+	// this key will not be present.
+	//
+	// path: loopOutBucket -> swapBucket[hash] -> sweepDestinationsKey
+	//
+	// value: concatenation of var string address || uint32 weight
+	sweepDestinationsKey = []byte("sweep-destinations")
+
 	byteOrder = binary.BigEndian
 
 	keyLength = 33
@@ -124,10 +222,19 @@ type boltSwapStore struct {
 // interface.
 var _ = (*boltSwapStore)(nil)
 
-// NewBoltSwapStore creates a new client swap store.
+// NewBoltSwapStore creates a new client swap store. If the database needs to
+// be migrated, a copy of it is written next to it first.
 func NewBoltSwapStore(dbPath string, chainParams *chaincfg.Params) (
 	*boltSwapStore, error) {
 
+	return openBoltSwapStore(dbPath, chainParams, true)
+}
+
+// openBoltSwapStore opens the bbolt swap store in the directory provided and
+// migrates it to the latest version, backing it up first if backup is set.
+func openBoltSwapStore(dbPath string, chainParams *chaincfg.Params,
+	backup bool) (*boltSwapStore, error) {
+
 	// If the target path for the swap store doesn't exist, then we'll
 	// create it now before we proceed.
 	if !fileExists(dbPath) {
@@ -144,6 +251,13 @@ func NewBoltSwapStore(dbPath string, chainParams *chaincfg.Params) (
 		return nil, err
 	}
 
+	// Refuse to touch a database that was migrated by a newer version
+	// before we create any buckets in it.
+	if err := checkDBVersion(bdb); err != nil {
+		_ = bdb.Close()
+		return nil, err
+	}
+
 	// We'll create all the buckets we need if this is the first time we're
 	// starting up. If they already exist, then these calls will be noops.
 	err = bdb.Update(func(tx *bbolt.Tx) error {
@@ -160,6 +274,13 @@ func NewBoltSwapStore(dbPath string, chainParams *chaincfg.Params) (
 			if err != nil {
 				return err
 			}
+
+			// Existing databases get the archive buckets with
+			// the migration to the version that introduced them.
+			err = migrateArchive(tx, chainParams)
+			if err != nil {
+				return err
+			}
 		}
 
 		// Try creating these buckets, because loop in was added without
@@ -177,13 +298,15 @@ func NewBoltSwapStore(dbPath string, chainParams *chaincfg.Params) (
 		return nil
 	})
 	if err != nil {
+		_ = bdb.Close()
 		return nil, err
 	}
 
 	// Finally, before we start, we'll sync the DB versions to pick up any
 	// possible DB migrations.
-	err = syncVersions(bdb, chainParams)
+	err = syncVersions(bdb, chainParams, backup)
 	if err != nil {
+		_ = bdb.Close()
 		return nil, err
 	}
 
@@ -193,7 +316,8 @@ func NewBoltSwapStore(dbPath string, chainParams *chaincfg.Params) (
 	}, nil
 }
 
-// FetchLoopOutSwaps returns all loop out swaps currently in the store.
+// FetchLoopOutSwaps returns all loop out swaps currently in the store that
+// have not been archived.
 //
 // NOTE: Part of the loopdb.SwapStore interface.
 func (s *boltSwapStore) FetchLoopOutSwaps() ([]*LoopOut, error) {
@@ -223,102 +347,54 @@ func (s *boltSwapStore) FetchLoopOutSwaps() ([]*LoopOut, error) {
 					swapHash)
 			}
 
-			// With the main swap bucket obtained, we'll grab the
-			// raw swap contract bytes and decode it.
-			contractBytes := swapBucket.Get(contractKey)
-			if contractBytes == nil {
-				return errors.New("contract not found")
-			}
-
-			contract, err := deserializeLoopOutContract(
-				contractBytes, s.chainParams,
+			loop, err := fetchLoopOut(
+				swapBucket, swapHash, s.chainParams,
 			)
 			if err != nil {
 				return err
 			}
 
-			// Get our label for this swap, if it is present.
-			contract.Label = getLabel(swapBucket)
-
-			// Read the list of concatenated outgoing channel ids
-			// that form the outgoing set.
-			setBytes := swapBucket.Get(outgoingChanSetKey)
-			if outgoingChanSetKey != nil {
-				r := bytes.NewReader(setBytes)
-			readLoop:
-				for {
-					var chanID uint64
-					err := binary.Read(r, byteOrder, &chanID)
-					switch {
-					case err == io.EOF:
-						break readLoop
-					case err != nil:
-						return err
-					}
-
-					contract.OutgoingChanSet = append(
-						contract.OutgoingChanSet,
-						chanID,
-					)
-				}
-			}
+			swaps = append(swaps, loop)
 
-			// Set our default number of confirmations for the swap.
-			contract.HtlcConfirmations = DefaultLoopOutHtlcConfirmations
-
-			// If we have the number of confirmations stored for
-			// this swap, we overwrite our default with the stored
-			// value.
-			confBytes := swapBucket.Get(confirmationsKey)
-			if confBytes != nil {
-				r := bytes.NewReader(confBytes)
-				err := binary.Read(
-					r, byteOrder, &contract.HtlcConfirmations,
-				)
-				if err != nil {
-					return err
-				}
-			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
 
-			updates, err := deserializeUpdates(swapBucket)
-			if err != nil {
-				return err
-			}
+	return swaps, nil
+}
 
-			// Try to unmarshal the protocol version for the swap.
-			// If the protocol version is not stored (which is
-			// the case for old clients), we'll assume the
-			// ProtocolVersionUnrecorded instead.
-			contract.ProtocolVersion, err =
-				UnmarshalProtocolVersion(
-					swapBucket.Get(protocolVersionKey),
-				)
-			if err != nil {
-				return err
-			}
+// fetchLoopOut reads the loop out swap with the hash provided from its swap
+// bucket.
+func fetchLoopOut(swapBucket *bbolt.Bucket, swapHash []byte,
+	chainParams *chaincfg.Params) (*LoopOut, error) {
 
-			loop := LoopOut{
-				Loop: Loop{
-					Events: updates,
-				},
-				Contract: contract,
-			}
+	// With the main swap bucket obtained, we'll decode the swap contract.
+	contract, err := getLoopOutContract(swapBucket, chainParams)
+	if err != nil {
+		return nil, err
+	}
 
-			loop.Hash, err = lntypes.MakeHash(swapHash)
-			if err != nil {
-				return err
-			}
+	updates, err := deserializeUpdates(swapBucket)
+	if err != nil {
+		return nil, err
+	}
 
-			swaps = append(swaps, &loop)
+	loop := LoopOut{
+		Loop: Loop{
+			Events: updates,
+		},
+		Contract: contract,
+	}
 
-			return nil
-		})
-	})
+	loop.Hash, err = lntypes.MakeHash(swapHash)
 	if err != nil {
 		return nil, err
 	}
 
-	return swaps, nil
+	return &loop, nil
 }
 
 // deserializeUpdates deserializes the list of swap updates that are stored as a
@@ -340,26 +416,11 @@ func deserializeUpdates(swapBucket *bbolt.Bucket) ([]*LoopEvent, error) {
 			return fmt.Errorf("expected state sub-bucket for %x", k)
 		}
 
-		basicState := updateBucket.Get(basicStateKey)
-		if basicState == nil {
-			return errors.New("no basic state for update")
-		}
-
-		event, err := deserializeLoopEvent(basicState)
+		event, err := getLoopEvent(updateBucket)
 		if err != nil {
 			return err
 		}
 
-		// Deserialize htlc tx hash if this updates contains one.
-		htlcTxHashBytes := updateBucket.Get(htlcTxHashKey)
-		if htlcTxHashBytes != nil {
-			htlcTxHash, err := chainhash.NewHash(htlcTxHashBytes)
-			if err != nil {
-				return err
-			}
-			event.HtlcTxHash = htlcTxHash
-		}
-
 		updates = append(updates, event)
 		return nil
 	})
@@ -370,7 +431,8 @@ func deserializeUpdates(swapBucket *bbolt.Bucket) ([]*LoopEvent, error) {
 	return updates, nil
 }
 
-// FetchLoopInSwaps returns all loop in swaps currently in the store.
+// FetchLoopInSwaps returns all loop in swaps currently in the store that have
+// not been archived.
 //
 // NOTE: Part of the loopdb.SwapStore interface.
 func (s *boltSwapStore) FetchLoopInSwaps() ([]*LoopIn, error) {
@@ -400,67 +462,315 @@ func (s *boltSwapStore) FetchLoopInSwaps() ([]*LoopIn, error) {
 					swapHash)
 			}
 
-			// With the main swap bucket obtained, we'll grab the
-			// raw swap contract bytes and decode it.
-			contractBytes := swapBucket.Get(contractKey)
-			if contractBytes == nil {
-				return errors.New("contract not found")
-			}
-
-			contract, err := deserializeLoopInContract(
-				contractBytes,
+			loop, err := fetchLoopIn(
+				swapBucket, swapHash, s.chainParams,
 			)
 			if err != nil {
 				return err
 			}
 
-			// Get our label for this swap, if it is present.
-			contract.Label = getLabel(swapBucket)
+			swaps = append(swaps, loop)
 
-			updates, err := deserializeUpdates(swapBucket)
-			if err != nil {
-				return err
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return swaps, nil
+}
+
+// fetchLoopIn reads the loop in swap with the hash provided from its swap
+// bucket.
+func fetchLoopIn(swapBucket *bbolt.Bucket, swapHash []byte,
+	chainParams *chaincfg.Params) (*LoopIn, error) {
+
+	// With the main swap bucket obtained, we'll decode the swap contract.
+	contract, err := getLoopInContract(swapBucket, chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	updates, err := deserializeUpdates(swapBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	loop := LoopIn{
+		Loop: Loop{
+			Events: updates,
+		},
+		Contract: contract,
+	}
+
+	loop.Hash, err = lntypes.MakeHash(swapHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &loop, nil
+}
+
+// QuerySwaps returns the page of swaps that the query provided selects. Only
+// the contracts and last updates of swaps are read to match them against the
+// query, all updates are only read for the swaps of the page.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) QuerySwaps(query *SwapQuery) (*SwapPage, error) {
+	page := &SwapPage{}
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		var results []queryResult
+		matchSwaps := func(swapType swap.Type, archived bool) error {
+			rootBucket := swapRootBucket(tx, swapType, archived)
+			if rootBucket == nil {
+				// Databases that were only opened by older
+				// versions don't have archive buckets.
+				if archived {
+					return nil
+				}
+
+				return errors.New("bucket does not exist")
 			}
 
-			// Try to unmarshal the protocol version for the swap.
-			// If the protocol version is not stored (which is
-			// the case for old clients), we'll assume the
-			// ProtocolVersionUnrecorded instead.
-			contract.ProtocolVersion, err =
-				UnmarshalProtocolVersion(
-					swapBucket.Get(protocolVersionKey),
+			return rootBucket.ForEach(func(swapHash, v []byte) error {
+				// Only go into things that we know are
+				// sub-bucket keys.
+				if v != nil {
+					return nil
+				}
+
+				if query.Hash != nil &&
+					!bytes.Equal(swapHash, query.Hash[:]) {
+
+					return nil
+				}
+
+				swapBucket := rootBucket.Bucket(swapHash)
+				if swapBucket == nil {
+					return fmt.Errorf("swap bucket %x not "+
+						"found", swapHash)
+				}
+
+				cursor, err := s.matchSwap(
+					swapType, swapBucket, swapHash, query,
 				)
-			if err != nil {
+				if err != nil || cursor == nil {
+					return err
+				}
+
+				results = append(results, queryResult{
+					cursor:   *cursor,
+					swapType: swapType,
+					archived: archived,
+				})
+
+				return nil
+			})
+		}
+
+		for _, swapType := range []swap.Type{swap.TypeOut, swap.TypeIn} {
+			if !query.matchType(swapType) {
+				continue
+			}
+
+			if err := matchSwaps(swapType, false); err != nil {
+				return err
+			}
+
+			if !query.IncludeArchived {
+				continue
+			}
+
+			if err := matchSwaps(swapType, true); err != nil {
 				return err
 			}
+		}
+
+		results, page.NextCursor = query.paginate(results)
+
+		for _, result := range results {
+			rootBucket := swapRootBucket(
+				tx, result.swapType, result.archived,
+			)
+			swapHash := result.cursor.Hash[:]
+			swapBucket := rootBucket.Bucket(swapHash)
+
+			if result.swapType == swap.TypeOut {
+				loop, err := fetchLoopOut(
+					swapBucket, swapHash, s.chainParams,
+				)
+				if err != nil {
+					return err
+				}
 
-			loop := LoopIn{
-				Loop: Loop{
-					Events: updates,
-				},
-				Contract: contract,
+				loop.Archived = result.archived
+				page.LoopOuts = append(page.LoopOuts, loop)
+				continue
 			}
 
-			loop.Hash, err = lntypes.MakeHash(swapHash)
+			loop, err := fetchLoopIn(
+				swapBucket, swapHash, s.chainParams,
+			)
 			if err != nil {
 				return err
 			}
 
-			swaps = append(swaps, &loop)
+			loop.Archived = result.archived
+			page.LoopIns = append(page.LoopIns, loop)
+		}
 
-			return nil
-		})
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return swaps, nil
+	return page, nil
+}
+
+// matchSwap matches the swap in the swap bucket provided against a query. If
+// the swap matches, its cursor is returned.
+func (s *boltSwapStore) matchSwap(swapType swap.Type, swapBucket *bbolt.Bucket,
+	swapHash []byte, query *SwapQuery) (*SwapCursor, error) {
+
+	var (
+		contract *SwapContract
+		matches  bool
+	)
+
+	switch swapType {
+	case swap.TypeOut:
+		loopOut, err := getLoopOutContract(swapBucket, s.chainParams)
+		if err != nil {
+			return nil, err
+		}
+
+		contract = &loopOut.SwapContract
+		matches = query.matchContract(
+			contract, loopOut.Label, loopOut.LastHop,
+			loopOut.OutgoingChanSet,
+		)
+
+	case swap.TypeIn:
+		loopIn, err := getLoopInContract(swapBucket, s.chainParams)
+		if err != nil {
+			return nil, err
+		}
+
+		contract = &loopIn.SwapContract
+		matches = query.matchContract(
+			contract, loopIn.Label, loopIn.LastHop, nil,
+		)
+	}
+
+	if !matches {
+		return nil, nil
+	}
+
+	event, err := lastEvent(swapBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	state := StateInitiated
+	if event != nil {
+		state = event.State
+	}
+
+	if !query.matchState(state) {
+		return nil, nil
+	}
+
+	if !query.UpdatedBefore.IsZero() &&
+		(event == nil || !event.Time.Before(query.UpdatedBefore)) {
+
+		return nil, nil
+	}
+
+	cursor := &SwapCursor{
+		InitiationTime: contract.InitiationTime,
+	}
+	copy(cursor.Hash[:], swapHash)
+
+	return cursor, nil
+}
+
+// lastState returns the current state of the swap in the swap bucket provided
+// without deserializing all of its updates.
+func lastState(swapBucket *bbolt.Bucket) (SwapState, error) {
+	event, err := lastEvent(swapBucket)
+	if err != nil {
+		return 0, err
+	}
+
+	if event == nil {
+		return StateInitiated, nil
+	}
+
+	return event.State, nil
+}
+
+// lastEvent returns the last update of the swap in the swap bucket provided
+// without deserializing all of its updates, or nil if the swap has no updates.
+func lastEvent(swapBucket *bbolt.Bucket) (*LoopEvent, error) {
+	updatesBucket := swapBucket.Bucket(updatesBucketKey)
+	if updatesBucket == nil {
+		return nil, errors.New("updates bucket not found")
+	}
+
+	// Update ids are big endian encoded, so the last key holds the most
+	// recent update.
+	k, _ := updatesBucket.Cursor().Last()
+	if k == nil {
+		return nil, nil
+	}
+
+	updateBucket := updatesBucket.Bucket(k)
+	if updateBucket == nil {
+		return nil, fmt.Errorf("expected state sub-bucket for %x", k)
+	}
+
+	return getLoopEvent(updateBucket)
+}
+
+// swapTypeBucketKey returns the key of the root bucket of swaps of the type
+// provided.
+func swapTypeBucketKey(swapType swap.Type) []byte {
+	if swapType == swap.TypeIn {
+		return loopInBucketKey
+	}
+
+	return loopOutBucketKey
+}
+
+// archiveBucketKey returns the key of the bucket that archived swaps of the
+// type provided are kept in.
+func archiveBucketKey(swapType swap.Type) []byte {
+	if swapType == swap.TypeIn {
+		return loopInArchiveBucketKey
+	}
+
+	return loopOutArchiveBucketKey
+}
+
+// swapRootBucket returns the root bucket of the swaps of the type provided, or
+// the bucket of its archived swaps. Nil is returned if the bucket doesn't
+// exist.
+func swapRootBucket(tx *bbolt.Tx, swapType swap.Type,
+	archived bool) *bbolt.Bucket {
+
+	if archived {
+		return tx.Bucket(archiveBucketKey(swapType))
+	}
+
+	return tx.Bucket(swapTypeBucketKey(swapType))
 }
 
 // createLoopBucket creates the bucket for a particular swap.
-func createLoopBucket(tx *bbolt.Tx, swapTypeKey []byte, hash lntypes.Hash) (
-	*bbolt.Bucket, error) {
+func createLoopBucket(tx *bbolt.Tx, swapTypeKey, archiveKey []byte,
+	hash lntypes.Hash) (*bbolt.Bucket, error) {
 
 	// First, we'll grab the root bucket that houses all of our
 	// swaps of this type.
@@ -470,11 +780,16 @@ func createLoopBucket(tx *bbolt.Tx, swapTypeKey []byte, hash lntypes.Hash) (
 	}
 
 	// If the swap already exists, then we'll exit as we don't want
-	// to override a swap.
+	// to override a swap. This includes swaps that have been archived.
 	if swapTypeBucket.Get(hash[:]) != nil {
 		return nil, fmt.Errorf("swap %v already exists", hash)
 	}
 
+	archiveBucket := tx.Bucket(archiveKey)
+	if archiveBucket != nil && archiveBucket.Bucket(hash[:]) != nil {
+		return nil, fmt.Errorf("swap %v already exists", hash)
+	}
+
 	// From the swap type bucket, we'll make a new sub swap bucket using the
 	// swap hash to store the individual swap.
 	return swapTypeBucket.CreateBucket(hash[:])
@@ -495,204 +810,592 @@ func (s *boltSwapStore) CreateLoopOut(hash lntypes.Hash,
 	// Otherwise, we'll create a new swap within the database.
 	return s.db.Update(func(tx *bbolt.Tx) error {
 		// Create the swap bucket.
-		swapBucket, err := createLoopBucket(tx, loopOutBucketKey, hash)
+		swapBucket, err := createLoopBucket(
+			tx, loopOutBucketKey, loopOutArchiveBucketKey, hash,
+		)
 		if err != nil {
 			return err
 		}
 
 		// With the swap bucket created, we'll store the swap itself.
-		contractBytes, err := serializeLoopOutContract(swap)
-		if err != nil {
+		if err := putLoopOutContract(swapBucket, swap); err != nil {
 			return err
 		}
 
-		err = swapBucket.Put(contractKey, contractBytes)
+		// Finally, we'll create an empty updates bucket for this swap
+		// to track any future updates to the swap itself.
+		_, err = swapBucket.CreateBucket(updatesBucketKey)
+		return err
+	})
+}
+
+// CreateLoopIn adds an initiated swap to the store.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) CreateLoopIn(hash lntypes.Hash,
+	swap *LoopInContract) error {
+
+	// If the hash doesn't match the pre-image, then this is an invalid
+	// swap so we'll bail out early.
+	if hash != swap.Preimage.Hash() {
+		return errors.New("hash and preimage do not match")
+	}
+
+	// Otherwise, we'll create a new swap within the database.
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		// Create the swap bucket.
+		swapBucket, err := createLoopBucket(
+			tx, loopInBucketKey, loopInArchiveBucketKey, hash,
+		)
 		if err != nil {
 			return err
 		}
 
-		if err := putLabel(swapBucket, swap.Label); err != nil {
+		// With the swap bucket created, we'll store the swap itself.
+		if err := putLoopInContract(swapBucket, swap); err != nil {
 			return err
 		}
 
-		// Write the outgoing channel set.
-		var b bytes.Buffer
-		for _, chanID := range swap.OutgoingChanSet {
-			err := binary.Write(&b, byteOrder, chanID)
-			if err != nil {
-				return err
-			}
+		// Finally, we'll create an empty updates bucket for this swap
+		// to track any future updates to the swap itself.
+		_, err = swapBucket.CreateBucket(updatesBucketKey)
+		return err
+	})
+}
+
+// updateLoop saves a new swap state transition to the store. It takes in a
+// bucket key so that this function can be used for both in and out swaps.
+func (s *boltSwapStore) updateLoop(bucketKey []byte, hash lntypes.Hash,
+	time time.Time, state SwapStateData) error {
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return putLoopUpdate(tx, bucketKey, hash, time, state)
+	})
+}
+
+// updateLoops saves a new swap state transition for each of the given swaps
+// in a single db transaction, so that either all or none of them are stored.
+func (s *boltSwapStore) updateLoops(bucketKey []byte, time time.Time,
+	updates map[lntypes.Hash]SwapStateData) error {
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		for hash, state := range updates {
+			err := putLoopUpdate(tx, bucketKey, hash, time, state)
+			if err != nil {
+				return err
+			}
 		}
-		err = swapBucket.Put(outgoingChanSetKey, b.Bytes())
+
+		return nil
+	})
+}
+
+// putLoopUpdate appends a new swap state transition to the updates of the
+// swap with the given hash.
+func putLoopUpdate(tx *bbolt.Tx, bucketKey []byte, hash lntypes.Hash,
+	time time.Time, state SwapStateData) error {
+
+	// Starting from the root bucket, we'll traverse the bucket
+	// hierarchy all the way down to the swap bucket, and the
+	// update sub-bucket within that.
+	rootBucket := tx.Bucket(bucketKey)
+	if rootBucket == nil {
+		return errors.New("bucket does not exist")
+	}
+	swapBucket := rootBucket.Bucket(hash[:])
+	if swapBucket == nil {
+		return errors.New("swap not found")
+	}
+	updatesBucket := swapBucket.Bucket(updatesBucketKey)
+	if updatesBucket == nil {
+		return errors.New("udpate bucket not found")
+	}
+
+	// Each update for this swap will get a new monotonically
+	// increasing ID number that we'll obtain now.
+	id, err := updatesBucket.NextSequence()
+	if err != nil {
+		return err
+	}
+
+	nextUpdateBucket, err := updatesBucket.CreateBucket(itob(id))
+	if err != nil {
+		return fmt.Errorf("cannot create update bucket")
+	}
+
+	// With the ID obtained, we'll write out this new update value.
+	return putLoopEvent(nextUpdateBucket, time, state)
+}
+
+// UpdateLoopOut stores a swap update. This appends to the event log for
+// a particular swap as it goes through the various stages in its lifetime.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) UpdateLoopOut(hash lntypes.Hash, time time.Time,
+	state SwapStateData) error {
+
+	return s.updateLoop(loopOutBucketKey, hash, time, state)
+}
+
+// UpdateLoopOuts stores a swap update for each of the given loop out swaps in
+// a single transaction.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) UpdateLoopOuts(time time.Time,
+	updates map[lntypes.Hash]SwapStateData) error {
+
+	return s.updateLoops(loopOutBucketKey, time, updates)
+}
+
+// UpdateLoopIn stores a swap update. This appends to the event log for
+// a particular swap as it goes through the various stages in its lifetime.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) UpdateLoopIn(hash lntypes.Hash, time time.Time,
+	state SwapStateData) error {
+
+	return s.updateLoop(loopInBucketKey, hash, time, state)
+}
+
+// UpdateLoopIns stores a swap update for each of the given loop in swaps in a
+// single transaction.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) UpdateLoopIns(time time.Time,
+	updates map[lntypes.Hash]SwapStateData) error {
+
+	return s.updateLoops(loopInBucketKey, time, updates)
+}
+
+// Close closes the underlying database.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) Close() error {
+	return s.db.Close()
+}
+
+// kvReader is the read part of a bbolt bucket that is required to read the
+// values of a swap. Abstracting it allows swaps to be read from other
+// key-value sources, such as backups, using the same encoding.
+type kvReader interface {
+	Get(key []byte) []byte
+}
+
+// kvWriter is the write part of a bbolt bucket that is required to write the
+// values of a swap.
+type kvWriter interface {
+	Put(key, value []byte) error
+}
+
+// putLoopOutContract writes a loop out contract to the bucket provided.
+func putLoopOutContract(bucket kvWriter, swap *LoopOutContract) error {
+	contractBytes, err := serializeLoopOutContract(swap)
+	if err != nil {
+		return err
+	}
+
+	err = bucket.Put(contractKey, contractBytes)
+	if err != nil {
+		return err
+	}
+
+	// Write the outgoing channel set.
+	var b bytes.Buffer
+	for _, chanID := range swap.OutgoingChanSet {
+		err := binary.Write(&b, byteOrder, chanID)
 		if err != nil {
 			return err
 		}
+	}
+	err = bucket.Put(outgoingChanSetKey, b.Bytes())
+	if err != nil {
+		return err
+	}
 
-		// Write label to disk if we have one.
-		if err := putLabel(swapBucket, swap.Label); err != nil {
-			return err
+	// Write label to disk if we have one.
+	if err := putLabel(bucket, swap.Label); err != nil {
+		return err
+	}
+
+	// Write the initiator if we have one.
+	if err := putInitiator(bucket, swap.Initiator); err != nil {
+		return err
+	}
+
+	// Write our confirmation target under its own key.
+	var buf bytes.Buffer
+	err = binary.Write(&buf, byteOrder, swap.HtlcConfirmations)
+	if err != nil {
+		return err
+	}
+
+	err = bucket.Put(confirmationsKey, buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	// Write the optional payment restrictions.
+	if err := putPaymentRestrictions(bucket, swap); err != nil {
+		return err
+	}
+
+	// Write the optional sweep destinations.
+	if err := putSweepDestinations(bucket, swap); err != nil {
+		return err
+	}
+
+	// Store the current protocol version.
+	return bucket.Put(protocolVersionKey,
+		MarshalProtocolVersion(swap.ProtocolVersion),
+	)
+}
+
+// getLoopOutContract reads a loop out contract from the bucket provided.
+func getLoopOutContract(bucket kvReader, chainParams *chaincfg.Params) (
+	*LoopOutContract, error) {
+
+	// We'll grab the raw swap contract bytes and decode them.
+	contractBytes := bucket.Get(contractKey)
+	if contractBytes == nil {
+		return nil, errors.New("contract not found")
+	}
+
+	contract, err := deserializeLoopOutContract(contractBytes, chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get our label and initiator for this swap, if they are present.
+	contract.Label = getLabel(bucket)
+	contract.Initiator = getInitiator(bucket)
+
+	// Read the list of concatenated outgoing channel ids that form the
+	// outgoing set.
+	setBytes := bucket.Get(outgoingChanSetKey)
+	if setBytes != nil {
+		r := bytes.NewReader(setBytes)
+	readLoop:
+		for {
+			var chanID uint64
+			err := binary.Read(r, byteOrder, &chanID)
+			switch {
+			case err == io.EOF:
+				break readLoop
+			case err != nil:
+				return nil, err
+			}
+
+			contract.OutgoingChanSet = append(
+				contract.OutgoingChanSet, chanID,
+			)
 		}
+	}
+
+	// Set our default number of confirmations for the swap.
+	contract.HtlcConfirmations = DefaultLoopOutHtlcConfirmations
 
-		// Write our confirmation target under its own key.
-		var buf bytes.Buffer
-		err = binary.Write(&buf, byteOrder, swap.HtlcConfirmations)
+	// If we have the number of confirmations stored for this swap, we
+	// overwrite our default with the stored value.
+	confBytes := bucket.Get(confirmationsKey)
+	if confBytes != nil {
+		r := bytes.NewReader(confBytes)
+		err := binary.Read(r, byteOrder, &contract.HtlcConfirmations)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = getPaymentRestrictions(bucket, contract)
+	if err != nil {
+		return nil, err
+	}
+
+	err = getSweepDestinations(bucket, contract, chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	// Try to unmarshal the protocol version for the swap. If the protocol
+	// version is not stored (which is the case for old clients), we'll
+	// assume the ProtocolVersionUnrecorded instead.
+	contract.ProtocolVersion, err = UnmarshalProtocolVersion(
+		bucket.Get(protocolVersionKey),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return contract, nil
+}
+
+// putLoopInContract writes a loop in contract to the bucket provided.
+func putLoopInContract(bucket kvWriter, swap *LoopInContract) error {
+	contractBytes, err := serializeLoopInContract(swap)
+	if err != nil {
+		return err
+	}
+
+	err = bucket.Put(contractKey, contractBytes)
+	if err != nil {
+		return err
+	}
+
+	// Store the current protocol version.
+	err = bucket.Put(protocolVersionKey,
+		MarshalProtocolVersion(swap.ProtocolVersion),
+	)
+	if err != nil {
+		return err
+	}
+
+	// Write label to disk if we have one.
+	if err := putLabel(bucket, swap.Label); err != nil {
+		return err
+	}
+
+	// Write the initiator if we have one.
+	if err := putInitiator(bucket, swap.Initiator); err != nil {
+		return err
+	}
+
+	// Write the outputs that fund our htlc if we have them.
+	return putHtlcFunding(bucket, swap)
+}
+
+// getLoopInContract reads a loop in contract from the bucket provided.
+func getLoopInContract(bucket kvReader, chainParams *chaincfg.Params) (
+	*LoopInContract, error) {
+
+	// We'll grab the raw swap contract bytes and decode them.
+	contractBytes := bucket.Get(contractKey)
+	if contractBytes == nil {
+		return nil, errors.New("contract not found")
+	}
+
+	contract, err := deserializeLoopInContract(contractBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get our label and initiator for this swap, if they are present.
+	contract.Label = getLabel(bucket)
+	contract.Initiator = getInitiator(bucket)
+
+	// Get the outputs that fund our htlc, if present.
+	err = getHtlcFunding(bucket, contract, chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	// Try to unmarshal the protocol version for the swap. If the protocol
+	// version is not stored (which is the case for old clients), we'll
+	// assume the ProtocolVersionUnrecorded instead.
+	contract.ProtocolVersion, err = UnmarshalProtocolVersion(
+		bucket.Get(protocolVersionKey),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return contract, nil
+}
+
+// putLoopEvent writes a swap state transition to the update bucket provided.
+func putLoopEvent(bucket kvWriter, time time.Time, state SwapStateData) error {
+	updateValue, err := serializeLoopEvent(time, state)
+	if err != nil {
+		return err
+	}
+
+	err = bucket.Put(basicStateKey, updateValue)
+	if err != nil {
+		return err
+	}
+
+	// Write the htlc tx hash if available.
+	if state.HtlcTxHash != nil {
+		err := bucket.Put(htlcTxHashKey, state.HtlcTxHash[:])
 		if err != nil {
 			return err
 		}
+	}
 
-		err = swapBucket.Put(confirmationsKey, buf.Bytes())
+	// Write the sweep tx hash and its fee if available.
+	if state.SweepTxHash != nil {
+		err := bucket.Put(sweepTxHashKey, state.SweepTxHash[:])
 		if err != nil {
 			return err
 		}
 
-		// Store the current protocol version.
-		err = swapBucket.Put(protocolVersionKey,
-			MarshalProtocolVersion(swap.ProtocolVersion),
-		)
+		var b bytes.Buffer
+		err = binary.Write(&b, byteOrder, state.SweepFee)
 		if err != nil {
 			return err
 		}
 
-		// Finally, we'll create an empty updates bucket for this swap
-		// to track any future updates to the swap itself.
-		_, err = swapBucket.CreateBucket(updatesBucketKey)
-		return err
-	})
-}
+		err = bucket.Put(sweepFeeKey, b.Bytes())
+		if err != nil {
+			return err
+		}
+	}
 
-// CreateLoopIn adds an initiated swap to the store.
-//
-// NOTE: Part of the loopdb.SwapStore interface.
-func (s *boltSwapStore) CreateLoopIn(hash lntypes.Hash,
-	swap *LoopInContract) error {
+	// Write the htlc tx if available.
+	if state.HtlcTx != nil {
+		var b bytes.Buffer
+		if err := state.HtlcTx.Serialize(&b); err != nil {
+			return err
+		}
 
-	// If the hash doesn't match the pre-image, then this is an invalid
-	// swap so we'll bail out early.
-	if hash != swap.Preimage.Hash() {
-		return errors.New("hash and preimage do not match")
+		err = bucket.Put(htlcTxKey, b.Bytes())
+		if err != nil {
+			return err
+		}
 	}
 
-	// Otherwise, we'll create a new swap within the database.
-	return s.db.Update(func(tx *bbolt.Tx) error {
-		// Create the swap bucket.
-		swapBucket, err := createLoopBucket(tx, loopInBucketKey, hash)
-		if err != nil {
+	// Write the htlc outpoint and its confirmation height if available.
+	if state.HtlcOutpoint != nil {
+		var b bytes.Buffer
+		if err := writeOutpoint(&b, state.HtlcOutpoint); err != nil {
 			return err
 		}
 
-		// With the swap bucket created, we'll store the swap itself.
-		contractBytes, err := serializeLoopInContract(swap)
+		err = bucket.Put(htlcOutpointKey, b.Bytes())
 		if err != nil {
 			return err
 		}
+	}
 
-		err = swapBucket.Put(contractKey, contractBytes)
+	if state.HtlcConfHeight != 0 {
+		err = putHeight(bucket, htlcConfHeightKey, state.HtlcConfHeight)
 		if err != nil {
 			return err
 		}
+	}
 
-		// Store the current protocol version.
-		err = swapBucket.Put(protocolVersionKey,
-			MarshalProtocolVersion(swap.ProtocolVersion),
-		)
+	// Write the tx that spent the htlc and its confirmation height if
+	// available.
+	if state.SpendTxHash != nil {
+		err := bucket.Put(spendTxHashKey, state.SpendTxHash[:])
 		if err != nil {
 			return err
 		}
+	}
 
-		// Write label to disk if we have one.
-		if err := putLabel(swapBucket, swap.Label); err != nil {
+	if state.SpendConfHeight != 0 {
+		err = putHeight(bucket, spendConfHeightKey, state.SpendConfHeight)
+		if err != nil {
 			return err
 		}
+	}
 
-		// Finally, we'll create an empty updates bucket for this swap
-		// to track any future updates to the swap itself.
-		_, err = swapBucket.CreateBucket(updatesBucketKey)
-		return err
-	})
+	return nil
 }
 
-// updateLoop saves a new swap state transition to the store. It takes in a
-// bucket key so that this function can be used for both in and out swaps.
-func (s *boltSwapStore) updateLoop(bucketKey []byte, hash lntypes.Hash,
-	time time.Time, state SwapStateData) error {
+// getLoopEvent reads a swap state transition from the update bucket provided.
+func getLoopEvent(bucket kvReader) (*LoopEvent, error) {
+	basicState := bucket.Get(basicStateKey)
+	if basicState == nil {
+		return nil, errors.New("no basic state for update")
+	}
 
-	return s.db.Update(func(tx *bbolt.Tx) error {
-		// Starting from the root bucket, we'll traverse the bucket
-		// hierarchy all the way down to the swap bucket, and the
-		// update sub-bucket within that.
-		rootBucket := tx.Bucket(bucketKey)
-		if rootBucket == nil {
-			return errors.New("bucket does not exist")
-		}
-		swapBucket := rootBucket.Bucket(hash[:])
-		if swapBucket == nil {
-			return errors.New("swap not found")
-		}
-		updatesBucket := swapBucket.Bucket(updatesBucketKey)
-		if updatesBucket == nil {
-			return errors.New("udpate bucket not found")
+	event, err := deserializeLoopEvent(basicState)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deserialize htlc tx hash if this updates contains one.
+	htlcTxHashBytes := bucket.Get(htlcTxHashKey)
+	if htlcTxHashBytes != nil {
+		htlcTxHash, err := chainhash.NewHash(htlcTxHashBytes)
+		if err != nil {
+			return nil, err
 		}
+		event.HtlcTxHash = htlcTxHash
+	}
 
-		// Each update for this swap will get a new monotonically
-		// increasing ID number that we'll obtain now.
-		id, err := updatesBucket.NextSequence()
+	// Deserialize sweep tx hash and fee if this update contains them.
+	sweepTxHashBytes := bucket.Get(sweepTxHashKey)
+	if sweepTxHashBytes != nil {
+		sweepTxHash, err := chainhash.NewHash(sweepTxHashBytes)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		event.SweepTxHash = sweepTxHash
+	}
 
-		nextUpdateBucket, err := updatesBucket.CreateBucket(itob(id))
+	sweepFeeBytes := bucket.Get(sweepFeeKey)
+	if sweepFeeBytes != nil {
+		r := bytes.NewReader(sweepFeeBytes)
+		err := binary.Read(r, byteOrder, &event.SweepFee)
 		if err != nil {
-			return fmt.Errorf("cannot create update bucket")
+			return nil, err
 		}
+	}
 
-		// With the ID obtained, we'll write out this new update value.
-		updateValue, err := serializeLoopEvent(time, state)
+	// Deserialize the htlc tx if this update contains one.
+	htlcTxBytes := bucket.Get(htlcTxKey)
+	if htlcTxBytes != nil {
+		event.HtlcTx = &wire.MsgTx{}
+		err := event.HtlcTx.Deserialize(bytes.NewReader(htlcTxBytes))
 		if err != nil {
-			return err
+			return nil, err
 		}
+	}
 
-		err = nextUpdateBucket.Put(basicStateKey, updateValue)
+	// Deserialize the htlc outpoint and the confirmation heights if this
+	// update contains them.
+	htlcOutpointBytes := bucket.Get(htlcOutpointKey)
+	if htlcOutpointBytes != nil {
+		event.HtlcOutpoint = &wire.OutPoint{}
+		err := readOutpoint(
+			bytes.NewReader(htlcOutpointBytes), event.HtlcOutpoint,
+		)
 		if err != nil {
-			return err
+			return nil, err
 		}
+	}
 
-		// Write the htlc tx hash if available.
-		if state.HtlcTxHash != nil {
-			err := nextUpdateBucket.Put(
-				htlcTxHashKey, state.HtlcTxHash[:],
-			)
-			if err != nil {
-				return err
-			}
+	event.HtlcConfHeight, err = getHeight(bucket, htlcConfHeightKey)
+	if err != nil {
+		return nil, err
+	}
+
+	spendTxHashBytes := bucket.Get(spendTxHashKey)
+	if spendTxHashBytes != nil {
+		spendTxHash, err := chainhash.NewHash(spendTxHashBytes)
+		if err != nil {
+			return nil, err
 		}
+		event.SpendTxHash = spendTxHash
+	}
 
-		return nil
-	})
+	event.SpendConfHeight, err = getHeight(bucket, spendConfHeightKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return event, nil
 }
 
-// UpdateLoopOut stores a swap update. This appends to the event log for
-// a particular swap as it goes through the various stages in its lifetime.
-//
-// NOTE: Part of the loopdb.SwapStore interface.
-func (s *boltSwapStore) UpdateLoopOut(hash lntypes.Hash, time time.Time,
-	state SwapStateData) error {
+// putHeight writes a block height to the bucket provided.
+func putHeight(bucket kvWriter, key []byte, height int32) error {
+	var b [4]byte
+	byteOrder.PutUint32(b[:], uint32(height))
 
-	return s.updateLoop(loopOutBucketKey, hash, time, state)
+	return bucket.Put(key, b[:])
 }
 
-// UpdateLoopIn stores a swap update. This appends to the event log for
-// a particular swap as it goes through the various stages in its lifetime.
-//
-// NOTE: Part of the loopdb.SwapStore interface.
-func (s *boltSwapStore) UpdateLoopIn(hash lntypes.Hash, time time.Time,
-	state SwapStateData) error {
+// getHeight reads an optional block height from the bucket provided. Zero is
+// returned if the height isn't stored.
+func getHeight(bucket kvReader, key []byte) (int32, error) {
+	b := bucket.Get(key)
+	if b == nil {
+		return 0, nil
+	}
 
-	return s.updateLoop(loopInBucketKey, hash, time, state)
-}
+	if len(b) != 4 {
+		return 0, fmt.Errorf("invalid height size: %v", len(b))
+	}
 
-// Close closes the underlying database.
-//
-// NOTE: Part of the loopdb.SwapStore interface.
-func (s *boltSwapStore) Close() error {
-	return s.db.Close()
+	return int32(byteOrder.Uint32(b)), nil
 }
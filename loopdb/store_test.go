@@ -2,6 +2,7 @@ package loopdb
 
 import (
 	"crypto/sha256"
+	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -11,7 +12,10 @@ import (
 
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
 	"github.com/coreos/bbolt"
+	"github.com/lightninglabs/loop/swap"
 	"github.com/lightninglabs/loop/test"
 	"github.com/lightningnetwork/lnd/lntypes"
 	"github.com/lightningnetwork/lnd/routing/route"
@@ -37,10 +41,14 @@ var (
 	})
 
 	testTime = time.Date(2018, time.January, 9, 14, 00, 00, 0, time.UTC)
+
+	// testBackends are the swap store backends that the store tests are
+	// run against.
+	testBackends = []Backend{BackendBolt, BackendSqlite}
 )
 
-// TestLoopOutStore tests all the basic functionality of the current bbolt
-// swap store.
+// TestLoopOutStore tests all the basic loop out functionality of the swap
+// store backends.
 func TestLoopOutStore(t *testing.T) {
 	destAddr := test.GetDestAddr(t, 0)
 	initiationTime := time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC)
@@ -91,18 +99,54 @@ func TestLoopOutStore(t *testing.T) {
 		testLoopOutStore(t, &labelledSwap)
 	})
 
+	lastHop := route.Vertex{1, 2, 3}
+	routeRestrictedSwap := unrestrictedSwap
+	routeRestrictedSwap.LastHop = &lastHop
+	routeRestrictedSwap.PaymentCltvLimit = 500
+	t.Run("payment restrictions", func(t *testing.T) {
+		testLoopOutStore(t, &routeRestrictedSwap)
+	})
+
+	taprootAddr, err := swap.NewAddressTaproot(
+		testPreimage[:], &chaincfg.MainNetParams,
+	)
+	require.NoError(t, err)
+
+	splitSwap := unrestrictedSwap
+	splitSwap.SweepDestinations = []SweepDestination{
+		{Addr: destAddr, Weight: 3},
+		{Addr: test.GetDestAddr(t, 1), Weight: 1},
+		{Addr: taprootAddr, Weight: 2},
+	}
+	t.Run("sweep destinations", func(t *testing.T) {
+		testLoopOutStore(t, &splitSwap)
+	})
 }
 
-// testLoopOutStore tests the basic functionality of the current bbolt
-// swap store for specific swap parameters.
+// testLoopOutStore tests the basic functionality of all swap store backends
+// for specific swap parameters.
 func testLoopOutStore(t *testing.T, pendingSwap *LoopOutContract) {
+	for _, backend := range testBackends {
+		backend := backend
+
+		t.Run(string(backend), func(t *testing.T) {
+			testLoopOutStoreBackend(t, backend, pendingSwap)
+		})
+	}
+}
+
+func testLoopOutStoreBackend(t *testing.T, backend Backend,
+	pendingSwap *LoopOutContract) {
+
 	tempDirName, err := ioutil.TempDir("", "clientstore")
 	if err != nil {
 		t.Fatal(err)
 	}
 	defer os.RemoveAll(tempDirName)
 
-	store, err := NewBoltSwapStore(tempDirName, &chaincfg.MainNetParams)
+	store, err := NewSwapStore(
+		backend, tempDirName, &chaincfg.MainNetParams,
+	)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -143,6 +187,27 @@ func testLoopOutStore(t *testing.T, pendingSwap *LoopOutContract) {
 
 		if expectedState == StatePreimageRevealed {
 			require.NotNil(t, swaps[0].State().HtlcTxHash)
+			require.Equal(
+				t, &chainhash.Hash{2, 7, 3},
+				swaps[0].State().SweepTxHash,
+			)
+			require.Equal(
+				t, btcutil.Amount(1234), swaps[0].State().SweepFee,
+			)
+			require.Equal(
+				t, wire.NewOutPoint(&chainhash.Hash{1, 6, 2}, 1),
+				swaps[0].State().HtlcOutpoint,
+			)
+			require.EqualValues(
+				t, 600, swaps[0].State().HtlcConfHeight,
+			)
+			require.Equal(
+				t, &chainhash.Hash{3, 8, 4},
+				swaps[0].State().SpendTxHash,
+			)
+			require.EqualValues(
+				t, 610, swaps[0].State().SpendConfHeight,
+			)
 		}
 	}
 
@@ -166,8 +231,16 @@ func testLoopOutStore(t *testing.T, pendingSwap *LoopOutContract) {
 	err = store.UpdateLoopOut(
 		hash, testTime,
 		SwapStateData{
-			State:      StatePreimageRevealed,
-			HtlcTxHash: &chainhash.Hash{1, 6, 2},
+			State:       StatePreimageRevealed,
+			HtlcTxHash:  &chainhash.Hash{1, 6, 2},
+			SweepTxHash: &chainhash.Hash{2, 7, 3},
+			SweepFee:    1234,
+			HtlcOutpoint: wire.NewOutPoint(
+				&chainhash.Hash{1, 6, 2}, 1,
+			),
+			HtlcConfHeight:  600,
+			SpendTxHash:     &chainhash.Hash{3, 8, 4},
+			SpendConfHeight: 610,
 		},
 	)
 	if err != nil {
@@ -194,15 +267,17 @@ func testLoopOutStore(t *testing.T, pendingSwap *LoopOutContract) {
 
 	// If we re-open the same store, then the state of the current swap
 	// should be the same.
-	store, err = NewBoltSwapStore(tempDirName, &chaincfg.MainNetParams)
+	store, err = NewSwapStore(
+		backend, tempDirName, &chaincfg.MainNetParams,
+	)
 	if err != nil {
 		t.Fatal(err)
 	}
 	checkSwap(StateFailInsufficientValue)
 }
 
-// TestLoopInStore tests all the basic functionality of the current bbolt
-// swap store.
+// TestLoopInStore tests all the basic loop in functionality of the swap
+// store backends.
 func TestLoopInStore(t *testing.T) {
 	initiationTime := time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC)
 
@@ -239,16 +314,48 @@ func TestLoopInStore(t *testing.T) {
 	t.Run("loop in with label", func(t *testing.T) {
 		testLoopInStore(t, labelledSwap)
 	})
+
+	changeAddr, err := btcutil.NewAddressWitnessPubKeyHash(
+		make([]byte, 20), &chaincfg.MainNetParams,
+	)
+	require.NoError(t, err)
+
+	fundedSwap := pendingSwap
+	fundedSwap.ExternalHtlc = false
+	fundedSwap.HtlcFundingOutpoints = []wire.OutPoint{
+		{Hash: chainhash.Hash{1, 2}, Index: 3},
+		{Hash: chainhash.Hash{4}, Index: 0},
+	}
+	fundedSwap.ChangeAddr = changeAddr
+	t.Run("loop in with htlc funding", func(t *testing.T) {
+		testLoopInStore(t, fundedSwap)
+	})
 }
 
+// testLoopInStore tests the basic functionality of all swap store backends
+// for specific swap parameters.
 func testLoopInStore(t *testing.T, pendingSwap LoopInContract) {
+	for _, backend := range testBackends {
+		backend := backend
+
+		t.Run(string(backend), func(t *testing.T) {
+			testLoopInStoreBackend(t, backend, pendingSwap)
+		})
+	}
+}
+
+func testLoopInStoreBackend(t *testing.T, backend Backend,
+	pendingSwap LoopInContract) {
+
 	tempDirName, err := ioutil.TempDir("", "clientstore")
 	if err != nil {
 		t.Fatal(err)
 	}
 	defer os.RemoveAll(tempDirName)
 
-	store, err := NewBoltSwapStore(tempDirName, &chaincfg.MainNetParams)
+	store, err := NewSwapStore(
+		backend, tempDirName, &chaincfg.MainNetParams,
+	)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -303,17 +410,52 @@ func testLoopInStore(t *testing.T, pendingSwap LoopInContract) {
 	}
 	checkSwap(StateInitiated)
 
-	// Next, we'll update to the next state of the pre-image being
-	// revealed. The state should be reflected here again.
+	// Record an htlc tx along with its hash and assert that both are
+	// restored.
+	htlcTx := wire.NewMsgTx(2)
+	htlcTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: chainhash.Hash{5}},
+		SignatureScript:  []byte{},
+		Witness:          wire.TxWitness{{1, 2}},
+	})
+	htlcTx.AddTxOut(&wire.TxOut{PkScript: []byte{3}, Value: 100})
+	htlcTxHash := htlcTx.TxHash()
+
 	err = store.UpdateLoopIn(
 		hash, testTime,
 		SwapStateData{
-			State: StatePreimageRevealed,
+			State:      StateHtlcPublished,
+			HtlcTxHash: &htlcTxHash,
+			HtlcTx:     htlcTx,
 		},
 	)
-	if err != nil {
-		t.Fatal(err)
-	}
+	require.NoError(t, err)
+	checkSwap(StateHtlcPublished)
+
+	swaps, err = store.FetchLoopInSwaps()
+	require.NoError(t, err)
+	require.Equal(t, &htlcTxHash, swaps[0].State().HtlcTxHash)
+	require.Equal(t, htlcTx, swaps[0].State().HtlcTx)
+
+	// Updating multiple swaps at once fails as a whole if one of them is
+	// unknown.
+	err = store.UpdateLoopIns(
+		testTime, map[lntypes.Hash]SwapStateData{
+			hash:      {State: StatePreimageRevealed},
+			{1, 2, 3}: {State: StatePreimageRevealed},
+		},
+	)
+	require.Error(t, err)
+	checkSwap(StateHtlcPublished)
+
+	// Next, we'll update to the next state of the pre-image being
+	// revealed. The state should be reflected here again.
+	err = store.UpdateLoopIns(
+		testTime, map[lntypes.Hash]SwapStateData{
+			hash: {State: StatePreimageRevealed},
+		},
+	)
+	require.NoError(t, err)
 	checkSwap(StatePreimageRevealed)
 
 	// Next, we'll update to the final state to ensure that the state is
@@ -335,7 +477,9 @@ func testLoopInStore(t *testing.T, pendingSwap LoopInContract) {
 
 	// If we re-open the same store, then the state of the current swap
 	// should be the same.
-	store, err = NewBoltSwapStore(tempDirName, &chaincfg.MainNetParams)
+	store, err = NewSwapStore(
+		backend, tempDirName, &chaincfg.MainNetParams,
+	)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -390,6 +534,66 @@ func TestVersionMigrated(t *testing.T) {
 	if ver != latestDBVersion {
 		t.Fatal("db not at latest version")
 	}
+
+	// The archive buckets were created by the migration.
+	err = store.db.View(func(tx *bbolt.Tx) error {
+		require.NotNil(t, tx.Bucket(loopOutArchiveBucketKey))
+		require.NotNil(t, tx.Bucket(loopInArchiveBucketKey))
+		return nil
+	})
+	require.NoError(t, err)
+
+	// A copy of the version zero database was written before it was
+	// migrated.
+	backups, err := filepath.Glob(
+		filepath.Join(tempDirName, dbFileName+".v0-*.backup"),
+	)
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+
+	backup, err := bbolt.Open(backups[0], 0600, &bbolt.Options{
+		ReadOnly: true,
+	})
+	require.NoError(t, err)
+	defer backup.Close()
+
+	ver, err = getDBVersion(backup)
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), ver)
+}
+
+// TestVersionNewer tests that a database with a newer version than the latest
+// known version isn't opened or modified.
+func TestVersionNewer(t *testing.T) {
+	tempDirName, err := ioutil.TempDir("", "clientstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDirName)
+
+	createVersionZeroDb(t, tempDirName)
+
+	path := filepath.Join(tempDirName, dbFileName)
+	bdb, err := bbolt.Open(path, 0600, nil)
+	require.NoError(t, err)
+
+	err = bdb.Update(func(tx *bbolt.Tx) error {
+		return setDBVersion(tx, latestDBVersion+1)
+	})
+	require.NoError(t, err)
+	require.NoError(t, bdb.Close())
+
+	_, err = NewBoltSwapStore(tempDirName, &chaincfg.MainNetParams)
+	require.True(t, errors.Is(err, ErrDBReversion))
+
+	// The swap buckets weren't created in the newer database.
+	bdb, err = bbolt.Open(path, 0600, nil)
+	require.NoError(t, err)
+	defer bdb.Close()
+
+	err = bdb.View(func(tx *bbolt.Tx) error {
+		require.Nil(t, tx.Bucket(loopOutBucketKey))
+		return nil
+	})
+	require.NoError(t, err)
 }
 
 // createVersionZeroDb creates a database with an empty meta bucket. In version
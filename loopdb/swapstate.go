@@ -2,6 +2,7 @@ package loopdb
 
 import (
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
 )
 
@@ -166,4 +167,32 @@ type SwapStateData struct {
 
 	// HtlcTxHash is the tx id of the confirmed htlc.
 	HtlcTxHash *chainhash.Hash
+
+	// HtlcTx is the loop in htlc tx that was created by the client. It is
+	// recorded before the tx is published, so that it can be republished
+	// after a restart.
+	HtlcTx *wire.MsgTx
+
+	// SweepTxHash is the tx id of the most recently published transaction
+	// that sweeps the htlc, if any.
+	SweepTxHash *chainhash.Hash
+
+	// SweepFee is the fee paid by the sweep tx identified by SweepTxHash.
+	SweepFee btcutil.Amount
+
+	// HtlcOutpoint is the outpoint of the confirmed htlc.
+	HtlcOutpoint *wire.OutPoint
+
+	// HtlcConfHeight is the height of the block that confirmed the htlc,
+	// or zero if it isn't known.
+	HtlcConfHeight int32
+
+	// SpendTxHash is the tx id of the confirmed tx that spent the htlc.
+	// This is a sweep or timeout tx of our own, or the sweep tx of the
+	// server.
+	SpendTxHash *chainhash.Hash
+
+	// SpendConfHeight is the height of the block that confirmed the tx
+	// identified by SpendTxHash, or zero if it isn't known.
+	SpendConfHeight int32
 }
@@ -1,26 +1,36 @@
 package loop
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"math"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/psbt"
+	"github.com/btcsuite/btcwallet/wtxmgr"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/lightninglabs/lndclient"
 	"github.com/lightninglabs/loop/labels"
 	"github.com/lightninglabs/loop/loopdb"
 	"github.com/lightninglabs/loop/swap"
+	"github.com/lightninglabs/loop/sweep"
 	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/input"
 	"github.com/lightningnetwork/lnd/lnrpc/invoicesrpc"
 	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 	"github.com/lightningnetwork/lnd/lnwire"
 )
 
@@ -43,6 +53,22 @@ var (
 	// TimeoutTxConfTarget defines the confirmation target for the loop in
 	// timeout tx.
 	TimeoutTxConfTarget = int32(2)
+
+	// TimeoutFeeBumpStep is the fraction of the timeout tx fee estimate
+	// that is added to the fee for every block that passed since the htlc
+	// expired. A timeout tx that is stuck in the mempool is therefore
+	// replaced (rbf) by one that pays more with every new block.
+	TimeoutFeeBumpStep = 0.1
+
+	// DefaultTimeoutMaxFeeRate is the default for the fee rate that the
+	// timeout tx is bumped to at most. It corresponds to 100 sat/vbyte.
+	DefaultTimeoutMaxFeeRate = chainfee.SatPerKWeight(25000)
+
+	// htlcFundingLockID is the id that is used to lease the wallet outputs
+	// that fund loop in htlcs.
+	htlcFundingLockID = wtxmgr.LockID(
+		sha256.Sum256([]byte("loop in htlc funding")),
+	)
 )
 
 // loopInSwap contains all the in-memory state related to a pending loop in
@@ -63,11 +89,49 @@ type loopInSwap struct {
 	// htlcTxHash is the confirmed htlc tx id.
 	htlcTxHash *chainhash.Hash
 
+	// htlcTx is the htlc tx that we created ourselves, if any.
+	htlcTx *wire.MsgTx
+
 	timeoutAddr btcutil.Address
 
+	// timeoutTxHash is the tx id of the most recently published timeout
+	// tx, if any.
+	timeoutTxHash *chainhash.Hash
+
+	// timeoutFee is the fee paid by the timeout tx identified by
+	// timeoutTxHash.
+	timeoutFee btcutil.Amount
+
+	// timeoutTx is the timeout tx identified by timeoutTxHash. It is only
+	// known if it was created by this run of the swap.
+	timeoutTx *wire.MsgTx
+
+	// invoiceState is the last known state of the swap invoice.
+	invoiceState channeldb.ContractState
+
+	// htlcPsbtChan receives requests to publish the htlc tx of a swap
+	// with an external htlc from a signed psbt.
+	htlcPsbtChan chan *htlcPsbtRequest
+
+	// done is closed when the swap finished executing.
+	done chan struct{}
+
 	wg sync.WaitGroup
 }
 
+// htlcPsbtRequest is a request to publish the htlc tx of a loop in swap with
+// an external htlc from a signed psbt. It is handled by the swap goroutine, so
+// that the swap follows the published tx.
+type htlcPsbtRequest struct {
+	packet *psbt.Packet
+
+	// txHashChan receives the hash of the published htlc tx.
+	txHashChan chan *chainhash.Hash
+
+	// errChan receives an error if the htlc tx was not published.
+	errChan chan error
+}
+
 // loopInInitResult contains information about a just-initiated loop in swap.
 type loopInInitResult struct {
 	swap          *loopInSwap
@@ -96,6 +160,32 @@ func newLoopInSwap(globalCtx context.Context, cfg *swapConfig,
 		return nil, ErrSwapFeeTooHigh
 	}
 
+	// If the htlc is funded from specific wallet outputs, make sure that
+	// we can actually spend them before we commit to the swap.
+	if len(request.HtlcFundingOutpoints) > 0 {
+		if request.ExternalHtlc {
+			return nil, errors.New("htlc funding outpoints cannot " +
+				"be set for external htlcs")
+		}
+
+		utxos, err := getHtlcFundingUtxos(
+			globalCtx, cfg.lnd, request.HtlcFundingOutpoints,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		var total btcutil.Amount
+		for _, utxo := range utxos {
+			total += utxo.Value
+		}
+		if total < request.Amount {
+			return nil, fmt.Errorf("htlc funding outpoints value "+
+				"%v below swap amount %v", total,
+				request.Amount)
+		}
+	}
+
 	// Calculate the swap invoice amount. The prepay is added which
 	// effectively forces the server to pay us back our prepayment on a
 	// successful swap.
@@ -192,9 +282,11 @@ func newLoopInSwap(globalCtx context.Context, cfg *swapConfig,
 	initiationTime := time.Now()
 
 	contract := loopdb.LoopInContract{
-		HtlcConfTarget: request.HtlcConfTarget,
-		LastHop:        request.LastHop,
-		ExternalHtlc:   request.ExternalHtlc,
+		HtlcConfTarget:       request.HtlcConfTarget,
+		LastHop:              request.LastHop,
+		ExternalHtlc:         request.ExternalHtlc,
+		HtlcFundingOutpoints: request.HtlcFundingOutpoints,
+		ChangeAddr:           request.ChangeAddr,
 		SwapContract: loopdb.SwapContract{
 			InitiationHeight: currentHeight,
 			InitiationTime:   initiationTime,
@@ -206,6 +298,7 @@ func newLoopInSwap(globalCtx context.Context, cfg *swapConfig,
 			MaxMinerFee:      request.MaxMinerFee,
 			MaxSwapFee:       request.MaxSwapFee,
 			Label:            request.Label,
+			Initiator:        request.Initiator,
 			ProtocolVersion:  loopdb.CurrentInternalProtocolVersion,
 		},
 	}
@@ -220,6 +313,8 @@ func newLoopInSwap(globalCtx context.Context, cfg *swapConfig,
 	swap := &loopInSwap{
 		LoopInContract: contract,
 		swapKit:        *swapKit,
+		htlcPsbtChan:   make(chan *htlcPsbtRequest),
+		done:           make(chan struct{}),
 	}
 
 	if err := swap.initHtlcs(); err != nil {
@@ -326,6 +421,8 @@ func resumeLoopInSwap(reqContext context.Context, cfg *swapConfig,
 	swap := &loopInSwap{
 		LoopInContract: *pend.Contract,
 		swapKit:        *swapKit,
+		htlcPsbtChan:   make(chan *htlcPsbtRequest),
+		done:           make(chan struct{}),
 	}
 
 	if err := swap.initHtlcs(); err != nil {
@@ -339,6 +436,10 @@ func resumeLoopInSwap(reqContext context.Context, cfg *swapConfig,
 		swap.state = lastUpdate.State
 		swap.lastUpdateTime = lastUpdate.Time
 		swap.htlcTxHash = lastUpdate.HtlcTxHash
+		swap.htlcTx = lastUpdate.HtlcTx
+		swap.timeoutTxHash = lastUpdate.SweepTxHash
+		swap.timeoutFee = lastUpdate.SweepFee
+		swap.restoreOnChainState(lastUpdate)
 	}
 
 	return swap, nil
@@ -391,6 +492,9 @@ func (s *loopInSwap) sendUpdate(ctx context.Context) error {
 	info.HtlcAddressP2WSH = s.htlcP2WSH.Address
 	info.HtlcAddressNP2WSH = s.htlcNP2WSH.Address
 	info.ExternalHtlc = s.ExternalHtlc
+	info.HtlcTxHash = s.htlcTxHash
+	info.SweepTxHash = s.timeoutTxHash
+	info.SweepFee = s.timeoutFee
 
 	select {
 	case s.statusChan <- *info:
@@ -406,6 +510,7 @@ func (s *loopInSwap) sendUpdate(ctx context.Context) error {
 func (s *loopInSwap) execute(mainCtx context.Context,
 	cfg *executeConfig, height int32) error {
 
+	defer close(s.done)
 	defer s.wg.Wait()
 
 	s.executeConfig = *cfg
@@ -470,6 +575,13 @@ func (s *loopInSwap) execute(mainCtx context.Context,
 func (s *loopInSwap) executeSwap(globalCtx context.Context) error {
 	var err error
 
+	// The swap may have failed before it was executed, if it was part of
+	// a batch whose htlcs could no longer be published. Its final state
+	// was already announced when the swap was started.
+	if s.state.Type() != loopdb.StateTypePending {
+		return nil
+	}
+
 	// For loop in, the client takes the first step by publishing the
 	// on-chain htlc. Only do this is we haven't already done so in a
 	// previous run.
@@ -492,84 +604,179 @@ func (s *loopInSwap) executeSwap(globalCtx context.Context) error {
 				return nil
 			}
 		}
+	} else if s.state == loopdb.StateHtlcPublished && s.htlcTx != nil {
+		// We may have crashed after recording our htlc tx, but before
+		// it reached the mempool. Publish it again to be sure.
+		s.log.Infof("Republishing on chain HTLC tx %v", s.htlcTxHash)
+
+		err = s.lnd.WalletKit.PublishTransaction(
+			globalCtx, s.htlcTx,
+			labels.LoopInHtlcLabel(swap.ShortHash(&s.hash)),
+		)
+		if err != nil {
+			s.log.Warnf("Republish htlc tx: %v", err)
+		}
 	}
 
-	// Wait for the htlc to confirm. After a restart this will pick up a
-	// previously published tx.
-	conf, err := s.waitForHtlcConf(globalCtx)
-	if err != nil {
-		return err
-	}
+	// Go through the on-chain part of the swap until the tx that spends
+	// the htlc is safe from reorgs. If it is reorged out, we start over
+	// from the state that we were in before it confirmed.
+	for {
+		// Wait for the htlc to confirm. After a restart this will pick
+		// up a previously published tx.
+		conf, err := s.waitForHtlcConf(globalCtx)
+		if err != nil {
+			return err
+		}
 
-	// Determine the htlc outpoint by inspecting the htlc tx.
-	htlcOutpoint, htlcValue, err := swap.GetScriptOutput(
-		conf.Tx, s.htlc.PkScript,
-	)
-	if err != nil {
-		return err
-	}
+		// Determine the htlc outpoint by inspecting the htlc tx.
+		htlcOutpoint, htlcValue, err := swap.GetScriptOutput(
+			conf.Tx, s.htlc.PkScript,
+		)
+		if err != nil {
+			return err
+		}
 
-	// Verify that the confirmed (external) htlc value matches the swap
-	// amount. Otherwise fail the swap immediately.
-	if htlcValue != s.LoopInContract.AmountRequested {
-		s.setState(loopdb.StateFailIncorrectHtlcAmt)
-		return s.persistAndAnnounceState(globalCtx)
-	}
+		s.htlcOutpoint = htlcOutpoint
 
-	// TODO: Add miner fee of htlc tx to swap cost balance.
+		// Verify that the confirmed (external) htlc value matches the
+		// swap amount. Otherwise fail the swap immediately.
+		if htlcValue != s.LoopInContract.AmountRequested {
+			s.setState(loopdb.StateFailIncorrectHtlcAmt)
+			return s.persistAndAnnounceState(globalCtx)
+		}
 
-	// The server is expected to see the htlc on-chain and knowing that it
-	// can sweep that htlc with the preimage, it should pay our swap
-	// invoice, receive the preimage and sweep the htlc. We are waiting for
-	// this to happen and simultaneously watch the htlc expiry height. When
-	// the htlc expires, we will publish a timeout tx to reclaim the funds.
-	err = s.waitForSwapComplete(globalCtx, htlcOutpoint, htlcValue)
-	if err != nil {
-		return err
-	}
+		// The server is expected to see the htlc on-chain and knowing
+		// that it can sweep that htlc with the preimage, it should pay
+		// our swap invoice, receive the preimage and sweep the htlc. We
+		// are waiting for this to happen and simultaneously watch the
+		// htlc expiry height. When the htlc expires, we will publish a
+		// timeout tx to reclaim the funds.
+		spend, err := s.waitForSwapComplete(
+			globalCtx, conf.Tx, htlcOutpoint, htlcValue,
+		)
+		if err != nil {
+			return err
+		}
 
-	// Persist swap outcome.
-	if err := s.persistAndAnnounceState(globalCtx); err != nil {
-		return err
-	}
+		// If the htlc tx was reorged out before it was spent, we wait
+		// for the htlc to confirm again.
+		if spend == nil {
+			s.htlcOutpoint, s.htlcConfHeight = nil, 0
 
-	return nil
+			s.reorged = true
+			err = s.sendUpdate(globalCtx)
+			s.reorged = false
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+		s.recordHtlcSpend(spend)
+
+		// If we track the spend for reorgs, we announce the outcome
+		// right away, but only persist it once the spend is safe. If
+		// we restart before that, the swap is resumed and we pick up
+		// the spend again to track it.
+		tracking := s.reorgSafetyDepth > 1
+		if tracking {
+			if err := s.sendUpdate(globalCtx); err != nil {
+				return err
+			}
+		}
+
+		safe, err := s.waitForSafeConf(
+			globalCtx, &s.executeConfig, htlcOutpoint,
+			s.htlc.PkScript, spend,
+		)
+		if err != nil {
+			return err
+		}
+
+		// Persist swap outcome.
+		if safe && tracking {
+			return s.persistState()
+		}
+		if safe {
+			return s.persistAndAnnounceState(globalCtx)
+		}
+
+		// The spend was reorged out, so we revert to our previous state
+		// and wait for the htlc to be spent again. If our timeout tx
+		// was reorged out, it is republished once we are back to
+		// watching the htlc. Our previous state is still the last one
+		// on disk.
+		s.revertHtlcSpend(spend, htlcValue)
+		s.recordHtlcSpend(nil)
+
+		s.reorged = true
+		err = s.sendUpdate(globalCtx)
+		s.reorged = false
+		if err != nil {
+			return err
+		}
+	}
 }
 
 // waitForHtlcConf watches the chain until the htlc confirms.
 func (s *loopInSwap) waitForHtlcConf(globalCtx context.Context) (
 	*chainntnfs.TxConfirmation, error) {
 
-	// Register for confirmation of the htlc. It is essential to specify not
-	// just the pk script, because an attacker may publish the same htlc
-	// with a lower value and we don't want to follow through with that tx.
-	// In the unlikely event that our call to SendOutputs crashes and we
-	// restart, htlcTxHash will be nil at this point. Then only register
-	// with PkScript and accept the risk that the call triggers on a
-	// different htlc outpoint.
-	s.log.Infof("Register for htlc conf (hh=%v, txid=%v)",
-		s.InitiationHeight, s.htlcTxHash)
+	var (
+		cancel                        context.CancelFunc
+		confChanP2WSH, confChanNP2WSH chan *chainntnfs.TxConfirmation
+		confErrP2WSH, confErrNP2WSH   chan error
+	)
 
-	if s.htlcTxHash == nil {
-		s.log.Warnf("No htlc tx hash available, registering with " +
-			"just the pkscript")
-	}
+	// register registers for confirmation of the htlc, replacing any
+	// previous registration. It is essential to specify not just the pk
+	// script, because an attacker may publish the same htlc with a lower
+	// value and we don't want to follow through with that tx. In the
+	// unlikely event that our call to SendOutputs crashes and we restart,
+	// htlcTxHash will be nil at this point. Then only register with
+	// PkScript and accept the risk that the call triggers on a different
+	// htlc outpoint.
+	register := func() error {
+		s.log.Infof("Register for htlc conf (hh=%v, txid=%v)",
+			s.InitiationHeight, s.htlcTxHash)
+
+		if s.htlcTxHash == nil {
+			s.log.Warnf("No htlc tx hash available, registering " +
+				"with just the pkscript")
+		}
 
-	ctx, cancel := context.WithCancel(globalCtx)
-	defer cancel()
+		if cancel != nil {
+			cancel()
+		}
 
-	notifier := s.lnd.ChainNotifier
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(globalCtx)
 
-	confChanP2WSH, confErrP2WSH, err := notifier.RegisterConfirmationsNtfn(
-		ctx, s.htlcTxHash, s.htlcP2WSH.PkScript, 1, s.InitiationHeight,
-	)
-	if err != nil {
-		return nil, err
+		notifier := s.lnd.ChainNotifier
+
+		var err error
+		confChanP2WSH, confErrP2WSH, err =
+			notifier.RegisterConfirmationsNtfn(
+				ctx, s.htlcTxHash, s.htlcP2WSH.PkScript, 1,
+				s.InitiationHeight,
+			)
+		if err != nil {
+			return err
+		}
+
+		confChanNP2WSH, confErrNP2WSH, err =
+			notifier.RegisterConfirmationsNtfn(
+				ctx, s.htlcTxHash, s.htlcNP2WSH.PkScript, 1,
+				s.InitiationHeight,
+			)
+		return err
 	}
 
-	confChanNP2WSH, confErrNP2WSH, err := notifier.RegisterConfirmationsNtfn(
-		ctx, s.htlcTxHash, s.htlcNP2WSH.PkScript, 1, s.InitiationHeight,
-	)
+	err := register()
+	defer func() {
+		cancel()
+	}()
 	if err != nil {
 		return nil, err
 	}
@@ -600,6 +807,20 @@ func (s *loopInSwap) waitForHtlcConf(globalCtx context.Context) (
 		case notification := <-s.blockEpochChan:
 			s.height = notification.(int32)
 
+		// The external htlc is funded from a psbt. Once its tx is
+		// recorded, we only follow this particular tx.
+		case req := <-s.htlcPsbtChan:
+			txHash, err := s.publishPsbtHtlc(globalCtx, req.packet)
+			if err != nil {
+				req.errChan <- err
+				continue
+			}
+			req.txHashChan <- txHash
+
+			if err := register(); err != nil {
+				return nil, err
+			}
+
 		// Cancel.
 		case <-globalCtx.Done():
 			return nil, globalCtx.Err()
@@ -616,10 +837,121 @@ func (s *loopInSwap) waitForHtlcConf(globalCtx context.Context) (
 	//   that case, we weren't able to record the tx hash.
 	txHash := conf.Tx.TxHash()
 	s.htlcTxHash = &txHash
+	s.htlcConfHeight = int32(conf.BlockHeight)
 
 	return conf, nil
 }
 
+// publishHtlcPsbt hands a signed psbt that funds the external htlc of the swap
+// to the swap goroutine and waits for the htlc tx to be published. It may be
+// called from any goroutine.
+func (s *loopInSwap) publishHtlcPsbt(ctx context.Context,
+	packet *psbt.Packet) (*chainhash.Hash, error) {
+
+	req := &htlcPsbtRequest{
+		packet:     packet,
+		txHashChan: make(chan *chainhash.Hash, 1),
+		errChan:    make(chan error, 1),
+	}
+
+	select {
+	case s.htlcPsbtChan <- req:
+	case <-s.done:
+		return nil, errors.New("swap is no longer executing")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case txHash := <-req.txHashChan:
+		return txHash, nil
+	case err := <-req.errChan:
+		return nil, err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// publishPsbtHtlc extracts the htlc tx from a signed psbt, records it and
+// publishes it. Because the tx is recorded before it is published, it is
+// republished if we restart before it reached the mempool. If the tx can't be
+// published, the previously recorded htlc tx is restored, so that a corrected
+// psbt can be handed in. A recorded tx may be replaced as long as the htlc
+// hasn't confirmed.
+func (s *loopInSwap) publishPsbtHtlc(ctx context.Context,
+	packet *psbt.Packet) (*chainhash.Hash, error) {
+
+	blocksRemaining := s.CltvExpiry - s.height
+	if blocksRemaining < MinLoopInPublishDelta {
+		return nil, fmt.Errorf("htlc expires in %v blocks",
+			blocksRemaining)
+	}
+
+	// External htlcs can be published as native or nested segwit output.
+	tx, err := extractHtlcTx(
+		packet, s.AmountRequested, s.htlcP2WSH, s.htlcNP2WSH,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	fee, err := psbtFee(packet, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		prevTxHash = s.htlcTxHash
+		prevTx     = s.htlcTx
+		prevState  = s.state
+		prevCost   = s.cost
+	)
+	restore := func() {
+		s.htlcTxHash = prevTxHash
+		s.htlcTx = prevTx
+		s.state = prevState
+		s.cost = prevCost
+	}
+
+	if prevTxHash != nil {
+		s.log.Infof("Replacing unconfirmed htlc tx %v", prevTxHash)
+	}
+
+	// Until the htlc confirmed, the fee of the htlc tx is the only
+	// on-chain cost of a swap with an external htlc. It replaces the fee
+	// of a tx that we recorded before.
+	txHash := tx.TxHash()
+	s.htlcTxHash = &txHash
+	s.htlcTx = tx
+	s.cost.Onchain = fee
+	s.setState(loopdb.StateHtlcPublished)
+
+	if err := s.persistAndAnnounceState(ctx); err != nil {
+		restore()
+		return nil, err
+	}
+
+	s.log.Infof("Publishing external htlc tx %v", txHash)
+
+	err = s.lnd.WalletKit.PublishTransaction(
+		ctx, tx, labels.LoopInHtlcLabel(swap.ShortHash(&s.hash)),
+	)
+	if err != nil {
+		// Restore the previous htlc tx, so that we don't republish a
+		// tx that can't be published after a restart.
+		restore()
+		s.lastUpdateTime = time.Now()
+
+		if err := s.persistAndAnnounceState(ctx); err != nil {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("publish htlc tx: %v", err)
+	}
+
+	return &txHash, nil
+}
+
 // publishOnChainHtlc checks whether there are still enough blocks left and if
 // so, it publishes the htlc and advances the swap state.
 func (s *loopInSwap) publishOnChainHtlc(ctx context.Context) (bool, error) {
@@ -642,50 +974,618 @@ func (s *loopInSwap) publishOnChainHtlc(ctx context.Context) (bool, error) {
 		return false, fmt.Errorf("estimate fee: %v", err)
 	}
 
-	// Transition to state HtlcPublished before calling SendOutputs to
-	// prevent us from ever paying multiple times after a crash.
+	// Fund the htlc from the requested wallet outputs, or let the wallet
+	// select the outputs otherwise.
+	var utxos []*lnwallet.Utxo
+	if len(s.HtlcFundingOutpoints) > 0 {
+		utxos, err = getHtlcFundingUtxos(
+			ctx, s.lnd, s.HtlcFundingOutpoints,
+		)
+	} else {
+		utxos, err = selectHtlcFundingUtxos(
+			ctx, s.lnd, s.LoopInContract.AmountRequested, 1,
+			feeRate,
+		)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return s.publishFundedHtlc(ctx, utxos, feeRate)
+}
+
+// publishFundedHtlc creates an htlc tx that is funded from the given wallet
+// outputs, and publishes it. The outputs are leased while the tx is created,
+// and the tx is recorded before it is published.
+func (s *loopInSwap) publishFundedHtlc(ctx context.Context,
+	utxos []*lnwallet.Utxo, feeRate chainfee.SatPerKWeight) (bool, error) {
+
+	// Lease the outputs, so that the wallet doesn't spend them elsewhere
+	// until our htlc tx reaches the mempool.
+	releaseOutputs, err := leaseHtlcFundingUtxos(ctx, s.lnd, utxos)
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := s.createFundedHtlcTx(ctx, utxos, feeRate)
+	if err != nil {
+		releaseOutputs()
+		return false, err
+	}
+
+	// Add the fee of the htlc tx to our on-chain cost.
+	fee, err := htlcTxFee(tx, utxos)
+	if err != nil {
+		releaseOutputs()
+		return false, err
+	}
+	s.cost.Onchain += fee
+
+	// Because we know the tx in advance, we record it along with the
+	// transition to state HtlcPublished before publishing the tx. This
+	// prevents us from ever paying multiple times after a crash, and
+	// allows us to republish the tx after a restart.
+	txHash := tx.TxHash()
+	s.htlcTxHash = &txHash
+	s.htlcTx = tx
 	s.setState(loopdb.StateHtlcPublished)
 	err = s.persistAndAnnounceState(ctx)
 	if err != nil {
+		releaseOutputs()
 		return false, err
 	}
 
-	s.log.Infof("Publishing on chain HTLC with fee rate %v", feeRate)
+	s.log.Infof("Publishing on chain HTLC tx %v with fee rate %v", txHash,
+		feeRate)
+
+	err = s.lnd.WalletKit.PublishTransaction(
+		ctx, tx, labels.LoopInHtlcLabel(swap.ShortHash(&s.hash)),
+	)
+	if err != nil {
+		return false, fmt.Errorf("publish htlc tx: %v", err)
+	}
+
+	return true, nil
+}
+
+// createFundedHtlcTx creates and signs an htlc tx that spends the given
+// wallet outputs. Any change is paid to the swap's change address or to a new
+// wallet address.
+func (s *loopInSwap) createFundedHtlcTx(ctx context.Context,
+	utxos []*lnwallet.Utxo, feeRate chainfee.SatPerKWeight) (*wire.MsgTx,
+	error) {
+
+	var err error
+	changeAddr := s.ChangeAddr
+	if changeAddr == nil {
+		changeAddr, err = s.lnd.WalletKit.NextAddr(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Internal loop-in is always P2WSH.
+	htlcOutputs := []*wire.TxOut{{
+		PkScript: s.htlcP2WSH.PkScript,
+		Value:    int64(s.LoopInContract.AmountRequested),
+	}}
+
+	return createHtlcFundingTx(
+		ctx, s.lnd, htlcOutputs, utxos, feeRate, changeAddr,
+	)
+}
+
+// createHtlcFundingTx creates and signs a tx that pays to the given P2WSH
+// htlc outputs and spends the given wallet outputs. Any change is paid to the
+// change address.
+func createHtlcFundingTx(ctx context.Context, lnd *lndclient.LndServices,
+	htlcOutputs []*wire.TxOut, utxos []*lnwallet.Utxo,
+	feeRate chainfee.SatPerKWeight, changeAddr btcutil.Address) (
+	*wire.MsgTx, error) {
+
+	changePkScript, err := txscript.PayToAddrScript(changeAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		tx             = wire.NewMsgTx(2)
+		weightEstimate input.TxWeightEstimator
+		amount         btcutil.Amount
+		total          btcutil.Amount
+	)
+	for _, htlcOutput := range htlcOutputs {
+		tx.AddTxOut(htlcOutput)
+		weightEstimate.AddP2WSHOutput()
+		amount += btcutil.Amount(htlcOutput.Value)
+	}
+
+	for _, utxo := range utxos {
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: utxo.OutPoint,
+		})
+		total += utxo.Value
+
+		switch utxo.AddressType {
+		case lnwallet.WitnessPubKey:
+			weightEstimate.AddP2WKHInput()
+
+		case lnwallet.NestedWitnessPubKey:
+			weightEstimate.AddNestedP2WKHInput()
+
+		default:
+			return nil, fmt.Errorf("unsupported address type %v "+
+				"of outpoint %v", utxo.AddressType,
+				utxo.OutPoint)
+		}
+	}
+
+	// First try to create the tx with a change output. If the change is
+	// dust, we leave it to the miners instead.
+	changeEstimate := weightEstimate
+	err = sweep.AddOutputEstimate(&changeEstimate, changeAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	fee := feeRate.FeeForWeight(int64(changeEstimate.Weight()))
+	change := total - amount - fee
+	if change >= lnwallet.DefaultDustLimit() {
+		tx.AddTxOut(&wire.TxOut{
+			PkScript: changePkScript,
+			Value:    int64(change),
+		})
+	} else {
+		fee = feeRate.FeeForWeight(int64(weightEstimate.Weight()))
+		if total < amount+fee {
+			return nil, fmt.Errorf("htlc funding outpoints value "+
+				"%v insufficient for swap amount %v and fee %v",
+				total, amount, fee)
+		}
+	}
+
+	// Let the wallet sign all of our inputs.
+	signDescs := make([]*lndclient.SignDescriptor, len(utxos))
+	for i, utxo := range utxos {
+		signDescs[i] = &lndclient.SignDescriptor{
+			Output: &wire.TxOut{
+				PkScript: utxo.PkScript,
+				Value:    int64(utxo.Value),
+			},
+			HashType:   txscript.SigHashAll,
+			InputIndex: i,
+		}
+	}
+
+	inputScripts, err := lnd.Signer.ComputeInputScript(
+		ctx, tx, signDescs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("signing: %v", err)
+	}
+	if len(inputScripts) != len(utxos) {
+		return nil, fmt.Errorf("expected %v input scripts, got %v",
+			len(utxos), len(inputScripts))
+	}
+
+	for i, inputScript := range inputScripts {
+		tx.TxIn[i].Witness = inputScript.Witness
+		tx.TxIn[i].SignatureScript = inputScript.SigScript
+	}
+
+	return tx, nil
+}
+
+// newHtlcPsbt returns an unsigned psbt without inputs that pays the given
+// amount to the htlc. The psbt serves as a template for external wallets that
+// fund the htlc.
+func newHtlcPsbt(htlc *swap.Htlc, amount btcutil.Amount) (*psbt.Packet,
+	error) {
+
+	return psbt.New(
+		nil, []*wire.TxOut{{
+			PkScript: htlc.PkScript,
+			Value:    int64(amount),
+		}}, 2, 0, nil,
+	)
+}
+
+// extractHtlcTx finalizes the given signed psbt and extracts its tx. The tx
+// must pay exactly the given amount to a single output of one of the htlcs.
+func extractHtlcTx(packet *psbt.Packet, amount btcutil.Amount,
+	htlcs ...*swap.Htlc) (*wire.MsgTx, error) {
+
+	err := psbt.MaybeFinalizeAll(packet)
+	if err != nil {
+		return nil, fmt.Errorf("finalize psbt: %v", err)
+	}
+
+	tx, err := psbt.Extract(packet)
+	if err != nil {
+		return nil, fmt.Errorf("extract tx: %v", err)
+	}
+
+	var htlcOutput *wire.TxOut
+	for _, txOut := range tx.TxOut {
+		for _, htlc := range htlcs {
+			if !bytes.Equal(txOut.PkScript, htlc.PkScript) {
+				continue
+			}
+
+			if htlcOutput != nil {
+				return nil, errors.New("tx pays to multiple " +
+					"htlc outputs")
+			}
+			htlcOutput = txOut
+		}
+	}
+
+	if htlcOutput == nil {
+		return nil, errors.New("tx does not pay to htlc")
+	}
+
+	if btcutil.Amount(htlcOutput.Value) != amount {
+		return nil, fmt.Errorf("htlc output value %v does not match "+
+			"swap amount %v", btcutil.Amount(htlcOutput.Value),
+			amount)
+	}
+
+	return tx, nil
+}
+
+// psbtFee returns the fee that is paid by the tx that was extracted from the
+// signed psbt provided. The value of every input must be known from its
+// witness or non-witness utxo.
+func psbtFee(packet *psbt.Packet, tx *wire.MsgTx) (btcutil.Amount, error) {
+	if len(packet.Inputs) != len(tx.TxIn) {
+		return 0, errors.New("psbt input count doesn't match tx")
+	}
+
+	var fee btcutil.Amount
+	for i, in := range packet.Inputs {
+		switch {
+		case in.WitnessUtxo != nil:
+			fee += btcutil.Amount(in.WitnessUtxo.Value)
+
+		case in.NonWitnessUtxo != nil:
+			index := tx.TxIn[i].PreviousOutPoint.Index
+			if int(index) >= len(in.NonWitnessUtxo.TxOut) {
+				return 0, fmt.Errorf("input %v spends unknown "+
+					"output", i)
+			}
+
+			fee += btcutil.Amount(
+				in.NonWitnessUtxo.TxOut[index].Value,
+			)
+
+		default:
+			return 0, fmt.Errorf("value of input %v unknown", i)
+		}
+	}
+
+	for _, txOut := range tx.TxOut {
+		fee -= btcutil.Amount(txOut.Value)
+	}
+
+	if fee < 0 {
+		return 0, errors.New("psbt outputs exceed inputs")
+	}
+
+	return fee, nil
+}
+
+// htlcTxFee returns the fee that is paid by an htlc tx that spends the given
+// wallet outputs.
+func htlcTxFee(tx *wire.MsgTx, utxos []*lnwallet.Utxo) (btcutil.Amount,
+	error) {
+
+	utxoMap := make(map[wire.OutPoint]*lnwallet.Utxo, len(utxos))
+	for _, utxo := range utxos {
+		utxoMap[utxo.OutPoint] = utxo
+	}
+
+	var fee btcutil.Amount
+	for _, txIn := range tx.TxIn {
+		utxo, ok := utxoMap[txIn.PreviousOutPoint]
+		if !ok {
+			return 0, fmt.Errorf("htlc tx input %v is not a "+
+				"wallet output", txIn.PreviousOutPoint)
+		}
+
+		fee += utxo.Value
+	}
+
+	for _, txOut := range tx.TxOut {
+		fee -= btcutil.Amount(txOut.Value)
+	}
+
+	if fee < 0 {
+		return 0, fmt.Errorf("htlc tx outputs exceed inputs by %v",
+			-fee)
+	}
+
+	return fee, nil
+}
+
+// selectHtlcFundingUtxos selects confirmed wallet outputs that cover the given
+// amount and the fee of a tx that pays the amount to the given number of
+// htlcs and has a change output at the given fee rate. Larger outputs are
+// selected first to keep the htlc tx small.
+func selectHtlcFundingUtxos(ctx context.Context, lnd *lndclient.LndServices,
+	amount btcutil.Amount, numHtlcs int, feeRate chainfee.SatPerKWeight) (
+	[]*lnwallet.Utxo, error) {
+
+	unspent, err := lnd.WalletKit.ListUnspent(ctx, 1, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("list unspent: %v", err)
+	}
+
+	sort.Slice(unspent, func(i, j int) bool {
+		return unspent[i].Value > unspent[j].Value
+	})
+
+	var weightEstimate input.TxWeightEstimator
+	for i := 0; i < numHtlcs; i++ {
+		weightEstimate.AddP2WSHOutput()
+	}
+	weightEstimate.AddP2WKHOutput()
+
+	var (
+		selected []*lnwallet.Utxo
+		total    btcutil.Amount
+	)
+	for _, utxo := range unspent {
+		switch utxo.AddressType {
+		case lnwallet.WitnessPubKey:
+			weightEstimate.AddP2WKHInput()
+
+		case lnwallet.NestedWitnessPubKey:
+			weightEstimate.AddNestedP2WKHInput()
+
+		// We can only sign for key spend outputs.
+		default:
+			continue
+		}
+
+		selected = append(selected, utxo)
+		total += utxo.Value
+
+		fee := feeRate.FeeForWeight(int64(weightEstimate.Weight()))
+		if total >= amount+fee {
+			return selected, nil
+		}
+	}
+
+	return nil, fmt.Errorf("insufficient wallet funds for htlc of %v",
+		amount)
+}
+
+// leaseHtlcFundingUtxos leases the given wallet outputs, so that the wallet
+// doesn't spend them elsewhere. It returns a function that releases the
+// outputs again. If any of the outputs can't be leased, the outputs that were
+// already leased are released.
+func leaseHtlcFundingUtxos(ctx context.Context, lnd *lndclient.LndServices,
+	utxos []*lnwallet.Utxo) (func(), error) {
+
+	var leased []wire.OutPoint
+	releaseOutputs := func() {
+		for _, outpoint := range leased {
+			err := lnd.WalletKit.ReleaseOutput(
+				ctx, htlcFundingLockID, outpoint,
+			)
+			if err != nil {
+				log.Warnf("Release output %v: %v", outpoint,
+					err)
+			}
+		}
+	}
+
+	for _, utxo := range utxos {
+		_, err := lnd.WalletKit.LeaseOutput(
+			ctx, htlcFundingLockID, utxo.OutPoint,
+		)
+		if err != nil {
+			releaseOutputs()
+			return nil, fmt.Errorf("lease output %v: %v",
+				utxo.OutPoint, err)
+		}
+
+		leased = append(leased, utxo.OutPoint)
+	}
+
+	return releaseOutputs, nil
+}
+
+// getHtlcFundingUtxos looks up the given outpoints in the list of unspent
+// wallet outputs and returns them in the same order. An error is returned if
+// any of the outpoints is not an unspent wallet output.
+func getHtlcFundingUtxos(ctx context.Context, lnd *lndclient.LndServices,
+	outpoints []wire.OutPoint) ([]*lnwallet.Utxo, error) {
+
+	unspent, err := lnd.WalletKit.ListUnspent(ctx, 0, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("list unspent: %v", err)
+	}
+
+	utxoMap := make(map[wire.OutPoint]*lnwallet.Utxo, len(unspent))
+	for _, utxo := range unspent {
+		utxoMap[utxo.OutPoint] = utxo
+	}
+
+	utxos := make([]*lnwallet.Utxo, len(outpoints))
+	for i, outpoint := range outpoints {
+		utxo, ok := utxoMap[outpoint]
+		if !ok {
+			return nil, fmt.Errorf("outpoint %v is not an "+
+				"unspent wallet output", outpoint)
+		}
+
+		// Remove the outpoint from the map so that a duplicate
+		// outpoint is rejected.
+		delete(utxoMap, outpoint)
+		utxos[i] = utxo
+	}
+
+	return utxos, nil
+}
+
+// publishOnChainHtlcBatch publishes the htlcs of all given swaps in a single
+// tx and advances their state. It must be called before the swaps are
+// executed. If any of the swaps is too close to its expiry to publish its
+// htlc, none of the htlcs is published and all swaps are failed. The swaps
+// need to be executed even if an error is returned. Failed swaps then
+// announce their final state, swaps whose batch tx was recorded republish it
+// and all other swaps publish their htlcs individually.
+func publishOnChainHtlcBatch(ctx context.Context, lnd *lndclient.LndServices,
+	swaps []*loopInSwap, height, htlcConfTarget int32) (*chainhash.Hash,
+	error) {
+
+	// Verify whether it still makes sense to publish the htlcs.
+	for _, s := range swaps {
+		blocksRemaining := s.CltvExpiry - height
+		if blocksRemaining >= MinLoopInPublishDelta {
+			continue
+		}
+
+		// Fail all swaps of the batch in a single db transaction.
+		updateTime := time.Now()
+		updates := make(map[lntypes.Hash]loopdb.SwapStateData)
+		for _, other := range swaps {
+			state := other.stateData()
+			state.State = loopdb.StateFailTimeout
+			updates[other.hash] = state
+		}
+
+		err := swaps[0].store.UpdateLoopIns(updateTime, updates)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, other := range swaps {
+			other.state = loopdb.StateFailTimeout
+			other.lastUpdateTime = updateTime
+		}
+
+		return nil, fmt.Errorf("swap %v too close to expiry to "+
+			"publish htlc: %v blocks remaining",
+			swap.ShortHash(&s.hash), blocksRemaining)
+	}
+
+	// Get fee estimate from lnd.
+	feeRate, err := lnd.WalletKit.EstimateFee(ctx, htlcConfTarget)
+	if err != nil {
+		return nil, fmt.Errorf("estimate fee: %v", err)
+	}
 
 	// Internal loop-in is always P2WSH.
-	tx, err := s.lnd.WalletKit.SendOutputs(
-		ctx, []*wire.TxOut{{
+	var (
+		htlcOutputs = make([]*wire.TxOut, len(swaps))
+		total       btcutil.Amount
+	)
+	for i, s := range swaps {
+		htlcOutputs[i] = &wire.TxOut{
 			PkScript: s.htlcP2WSH.PkScript,
 			Value:    int64(s.LoopInContract.AmountRequested),
-		}}, feeRate, labels.LoopInHtlcLabel(swap.ShortHash(&s.hash)),
+		}
+		total += s.LoopInContract.AmountRequested
+	}
+
+	utxos, err := selectHtlcFundingUtxos(
+		ctx, lnd, total, len(swaps), feeRate,
 	)
 	if err != nil {
-		return false, fmt.Errorf("send outputs: %v", err)
+		return nil, err
 	}
-	txHash := tx.TxHash()
-	s.log.Infof("Published on chain HTLC tx %v", txHash)
 
-	// Persist the htlc hash so that after a restart we are still waiting
-	// for our own htlc. We don't need to announce to clients, because the
-	// state remains unchanged.
-	//
-	// TODO(joostjager): Store tx hash before calling SendOutputs. This is
-	// not yet possible with the current lnd api.
-	s.htlcTxHash = &txHash
-	s.lastUpdateTime = time.Now()
-	if err := s.persistState(); err != nil {
-		return false, fmt.Errorf("persist htlc tx: %v", err)
+	// Lease the outputs, so that the wallet doesn't spend them elsewhere
+	// until our htlc tx reaches the mempool.
+	releaseOutputs, err := leaseHtlcFundingUtxos(ctx, lnd, utxos)
+	if err != nil {
+		return nil, err
 	}
 
-	return true, nil
+	changeAddr, err := lnd.WalletKit.NextAddr(ctx)
+	if err != nil {
+		releaseOutputs()
+		return nil, err
+	}
+
+	tx, err := createHtlcFundingTx(
+		ctx, lnd, htlcOutputs, utxos, feeRate, changeAddr,
+	)
+	if err != nil {
+		releaseOutputs()
+		return nil, err
+	}
+
+	fee, err := htlcTxFee(tx, utxos)
+	if err != nil {
+		releaseOutputs()
+		return nil, err
+	}
+
+	// Because we know the tx in advance, we record it along with the
+	// transition of all swaps to state HtlcPublished before publishing
+	// the tx. The swaps are updated in a single db transaction, so that
+	// after a crash either all or none of them republish the tx. The fee
+	// of the tx is split between the swaps, proportional to their
+	// amounts. Every swap locates its own htlc output in the tx once it
+	// confirms. The swaps are announced once they are executed.
+	var (
+		txHash     = tx.TxHash()
+		updateTime = time.Now()
+		updates    = make(map[lntypes.Hash]loopdb.SwapStateData)
+		remaining  = fee
+	)
+	for i, s := range swaps {
+		share := fee * s.LoopInContract.AmountRequested / total
+		if i == len(swaps)-1 {
+			share = remaining
+		}
+		remaining -= share
+
+		state := s.stateData()
+		state.State = loopdb.StateHtlcPublished
+		state.Cost.Onchain += share
+		state.HtlcTxHash = &txHash
+		state.HtlcTx = tx
+		updates[s.hash] = state
+	}
+
+	err = swaps[0].store.UpdateLoopIns(updateTime, updates)
+	if err != nil {
+		releaseOutputs()
+		return nil, err
+	}
+
+	for _, s := range swaps {
+		s.cost = updates[s.hash].Cost
+		s.htlcTxHash = &txHash
+		s.htlcTx = tx
+		s.state = loopdb.StateHtlcPublished
+		s.lastUpdateTime = updateTime
+	}
+
+	log.Infof("Publishing on chain HTLC batch tx %v for %v swaps with "+
+		"fee rate %v", txHash, len(swaps), feeRate)
+
+	err = lnd.WalletKit.PublishTransaction(
+		ctx, tx, labels.LoopInBatchHtlcLabel(len(swaps)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("publish htlc tx: %v", err)
+	}
 
+	return &txHash, nil
 }
 
 // waitForSwapComplete waits until a spending tx of the htlc gets confirmed and
 // the swap invoice is either settled or canceled. If the htlc times out, the
-// timeout tx will be published.
+// timeout tx will be published. It returns the details of the htlc spend, or
+// nil without an error if the htlc tx is reorged out before it is spent.
 func (s *loopInSwap) waitForSwapComplete(ctx context.Context,
-	htlcOutpoint *wire.OutPoint, htlcValue btcutil.Amount) error {
+	htlcTx *wire.MsgTx, htlcOutpoint *wire.OutPoint,
+	htlcValue btcutil.Amount) (*chainntnfs.SpendDetail, error) {
 
 	// Register the htlc spend notification.
 	rpcCtx, cancel := context.WithCancel(ctx)
@@ -694,19 +1594,36 @@ func (s *loopInSwap) waitForSwapComplete(ctx context.Context,
 		rpcCtx, htlcOutpoint, s.htlc.PkScript, s.InitiationHeight,
 	)
 	if err != nil {
-		return fmt.Errorf("register spend ntfn: %v", err)
+		return nil, fmt.Errorf("register spend ntfn: %v", err)
 	}
 
-	// Register for swap invoice updates.
-	rpcCtx, cancel = context.WithCancel(ctx)
-	defer cancel()
-	s.log.Infof("Subscribing to swap invoice %v", s.hash)
-	swapInvoiceChan, swapInvoiceErr, err := s.lnd.Invoices.SubscribeSingleInvoice(
-		rpcCtx, s.hash,
+	// Register for swap invoice updates, unless we already know its final
+	// state because we are watching the htlc again after a reorg.
+	var (
+		swapInvoiceChan <-chan lndclient.InvoiceUpdate
+		swapInvoiceErr  <-chan error
+	)
+	if !s.invoiceFinalized() {
+		rpcCtx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		s.log.Infof("Subscribing to swap invoice %v", s.hash)
+		swapInvoiceChan, swapInvoiceErr, err =
+			s.lnd.Invoices.SubscribeSingleInvoice(rpcCtx, s.hash)
+		if err != nil {
+			return nil, fmt.Errorf("subscribe to swap invoice: %v",
+				err)
+		}
+	}
+
+	// Track the htlc tx up to the safety depth, so that we notice if it
+	// is reorged out before it is spent.
+	htlcTracker, err := s.trackHtlcConf(
+		ctx, &s.executeConfig, htlcTx, s.htlc.PkScript,
 	)
 	if err != nil {
-		return fmt.Errorf("subscribe to swap invoice: %v", err)
+		return nil, err
 	}
+	defer htlcTracker.stop()
 
 	// checkTimeout publishes the timeout tx if the contract has expired.
 	checkTimeout := func() error {
@@ -721,45 +1638,68 @@ func (s *loopInSwap) waitForSwapComplete(ctx context.Context,
 	// publish the tx immediately.
 	err = checkTimeout()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	htlcSpend := false
-	invoiceFinalized := false
-	for !htlcSpend || !invoiceFinalized {
+	var htlcSpend *chainntnfs.SpendDetail
+	for htlcSpend == nil || !s.invoiceFinalized() {
 		select {
 		// Spend notification error.
 		case err := <-spendErr:
-			return err
+			return nil, err
 
 		// Receive block epochs and start publishing the timeout tx
 		// whenever possible.
 		case notification := <-s.blockEpochChan:
 			s.height = notification.(int32)
+			htlcTracker.notifyHeight(s.height)
 
 			err := checkTimeout()
 			if err != nil {
-				return err
+				return nil, err
 			}
 
+		// The htlc tx either reached the safety depth, so that we no
+		// longer need to track it, or it was reorged out before it was
+		// spent.
+		case result := <-htlcTracker.results():
+			if result.err != nil {
+				return nil, result.err
+			}
+			if !result.safe {
+				return nil, nil
+			}
+
+			htlcTracker = nil
+
 		// The htlc spend is confirmed. Inspect the spending tx to
 		// determine the final swap state.
 		case spendDetails := <-spendChan:
 			s.log.Infof("Htlc spend by tx: %v",
 				spendDetails.SpenderTxHash)
 
+			// From now on, we track the spend instead of the htlc
+			// tx.
+			htlcTracker.stop()
+			htlcTracker = nil
+
 			err := s.processHtlcSpend(
 				ctx, spendDetails, htlcValue,
 			)
 			if err != nil {
-				return err
+				return nil, err
 			}
 
-			htlcSpend = true
+			htlcSpend = spendDetails
 
 		// Swap invoice ntfn error.
 		case err := <-swapInvoiceErr:
-			return err
+			return nil, err
+
+		// The htlc already confirmed, so it can't be funded from a
+		// psbt anymore.
+		case req := <-s.htlcPsbtChan:
+			req.errChan <- errors.New("htlc already confirmed")
 
 		// An update to the swap invoice occurred. Check the new state
 		// and update the swap state accordingly.
@@ -767,6 +1707,8 @@ func (s *loopInSwap) waitForSwapComplete(ctx context.Context,
 			s.log.Infof("Received swap invoice update: %v",
 				update.State)
 
+			s.invoiceState = update.State
+
 			switch update.State {
 
 			// Swap invoice was paid, so update server cost balance.
@@ -783,24 +1725,28 @@ func (s *loopInSwap) waitForSwapComplete(ctx context.Context,
 					s.setState(loopdb.StateInvoiceSettled)
 					err := s.persistAndAnnounceState(ctx)
 					if err != nil {
-						return err
+						return nil, err
 					}
 				}
 
-				invoiceFinalized = true
-
 			// Canceled invoice has no effect on server cost
 			// balance.
 			case channeldb.ContractCanceled:
-				invoiceFinalized = true
 			}
 
 		case <-ctx.Done():
-			return ctx.Err()
+			return nil, ctx.Err()
 		}
 	}
 
-	return nil
+	return htlcSpend, nil
+}
+
+// invoiceFinalized returns whether the swap invoice was either settled or
+// canceled.
+func (s *loopInSwap) invoiceFinalized() bool {
+	return s.invoiceState == channeldb.ContractSettled ||
+		s.invoiceState == channeldb.ContractCanceled
 }
 
 func (s *loopInSwap) processHtlcSpend(ctx context.Context,
@@ -819,6 +1765,8 @@ func (s *loopInSwap) processHtlcSpend(ctx context.Context,
 	} else {
 		s.setState(loopdb.StateFailTimeout)
 
+		s.cost.Onchain += timeoutTxFee(spend.SpendingTx, htlcValue)
+
 		// Now that the timeout tx confirmed, we can safely cancel the
 		// swap invoice. We still need to query the final invoice state.
 		// This is not a hodl invoice, so it may be that the invoice was
@@ -828,15 +1776,59 @@ func (s *loopInSwap) processHtlcSpend(ctx context.Context,
 		if err != nil && err != channeldb.ErrInvoiceAlreadySettled {
 			return err
 		}
-
-		// TODO: Add miner fee of timeout tx to swap cost balance.
 	}
 
 	return nil
 }
 
+// revertHtlcSpend reverts the swap to the state that it was in before the
+// given htlc spend confirmed, after the spend was reorged out.
+func (s *loopInSwap) revertHtlcSpend(spend *chainntnfs.SpendDetail,
+	htlcValue btcutil.Amount) {
+
+	htlcInput := spend.SpendingTx.TxIn[spend.SpenderInputIndex]
+
+	if s.htlc.IsSuccessWitness(htlcInput.Witness) {
+		s.cost.Server -= htlcValue
+	} else {
+		s.cost.Onchain -= timeoutTxFee(spend.SpendingTx, htlcValue)
+	}
+
+	if s.invoiceState == channeldb.ContractSettled {
+		s.setState(loopdb.StateInvoiceSettled)
+	} else {
+		s.setState(loopdb.StateHtlcPublished)
+	}
+}
+
+// timeoutTxFee returns the fee paid by a timeout tx that spends an htlc with
+// the given value. Our timeout tx is the only spender of the htlc, so all that
+// it doesn't send back to our wallet went to the miner.
+func timeoutTxFee(timeoutTx *wire.MsgTx, htlcValue btcutil.Amount) btcutil.Amount {
+	var timeoutValue btcutil.Amount
+	for _, txOut := range timeoutTx.TxOut {
+		timeoutValue += btcutil.Amount(txOut.Value)
+	}
+
+	return htlcValue - timeoutValue
+}
+
+// timeoutFeeMultiplier returns the factor by which we scale our timeout tx fee
+// estimate. The estimate is used as is in the block in which the htlc expires
+// and raised stepwise with every block after that.
+func (s *loopInSwap) timeoutFeeMultiplier() float64 {
+	blocksPastExpiry := s.height - s.LoopInContract.CltvExpiry
+	if blocksPastExpiry <= 0 {
+		return 1
+	}
+
+	return 1 + float64(blocksPastExpiry)*TimeoutFeeBumpStep
+}
+
 // publishTimeoutTx publishes a timeout tx after the on-chain htlc has expired.
-// The swap failed and we are reclaiming our funds.
+// The swap failed and we are reclaiming our funds. The fee is bumped with
+// every new block until the timeout tx confirms, so that our timeout tx
+// replaces (rbf) any previous attempt that is stuck in the mempool.
 func (s *loopInSwap) publishTimeoutTx(ctx context.Context,
 	htlcOutpoint *wire.OutPoint, htlcValue btcutil.Amount) error {
 
@@ -847,15 +1839,68 @@ func (s *loopInSwap) publishTimeoutTx(ctx context.Context,
 			return err
 		}
 	}
+	destinations := []loopdb.SweepDestination{
+		{Addr: s.timeoutAddr, Weight: 1},
+	}
 
-	// Calculate sweep tx fee
+	// Calculate sweep tx fee and scale it with the number of blocks that
+	// passed since the htlc expired.
 	fee, err := s.sweeper.GetSweepFee(
-		ctx, s.htlc.AddTimeoutToEstimator, s.timeoutAddr,
+		ctx, s.htlc.AddTimeoutToEstimator, destinations,
 		TimeoutTxConfTarget,
 	)
 	if err != nil {
 		return err
 	}
+	fee = btcutil.Amount(float64(fee) * s.timeoutFeeMultiplier())
+
+	// Ensure it doesn't exceed the fee that we are willing to pay at most.
+	weight, err := sweep.GetSweepWeight(
+		s.htlc.AddTimeoutToEstimator, destinations,
+	)
+	if err != nil {
+		return err
+	}
+	maxFee := s.timeoutMaxFeeRate.FeeForWeight(weight)
+	if fee > maxFee {
+		s.log.Warnf("Required timeout fee %v exceeds max fee of %v",
+			fee, maxFee)
+
+		fee = maxFee
+	}
+
+	// A replacement timeout tx is only accepted into the mempool if it
+	// pays at least the incremental relay fee more than the tx it
+	// replaces. If our fee doesn't allow for that, we republish our
+	// previous timeout tx as is. After a restart we no longer have it, so
+	// we replace it with the minimum fee increase if our maximum fee
+	// allows for it.
+	if s.timeoutTxHash != nil {
+		minFee := s.timeoutFee + sweep.IncrementalRelayFee(weight)
+
+		switch {
+		case fee >= minFee:
+
+		case s.timeoutTx != nil:
+			s.log.Infof("Fee %v can't replace previous timeout tx "+
+				"with fee %v, republishing it", fee,
+				s.timeoutFee)
+
+			s.publishTimeout(ctx, s.timeoutTx)
+
+			return nil
+
+		case minFee <= maxFee:
+			fee = minFee
+
+		default:
+			s.log.Infof("Fee %v can't replace previous timeout tx "+
+				"with fee %v, waiting for it to confirm", fee,
+				s.timeoutFee)
+
+			return nil
+		}
+	}
 
 	witnessFunc := func(sig []byte) (wire.TxWitness, error) {
 		return s.htlc.GenTimeoutWitness(sig), nil
@@ -864,25 +1909,47 @@ func (s *loopInSwap) publishTimeoutTx(ctx context.Context,
 	sequence := uint32(0)
 	timeoutTx, err := s.sweeper.CreateSweepTx(
 		ctx, s.height, sequence, s.htlc, *htlcOutpoint, s.SenderKey,
-		witnessFunc, htlcValue, fee, s.timeoutAddr,
+		witnessFunc, htlcValue, fee, destinations,
 	)
 	if err != nil {
 		return err
 	}
 
+	// Record the timeout tx in our event history before publishing it, so
+	// that we know about every tx that may end up confirming.
 	timeoutTxHash := timeoutTx.TxHash()
+	if s.timeoutTxHash == nil || *s.timeoutTxHash != timeoutTxHash {
+		s.lastUpdateTime = time.Now()
+		s.timeoutTxHash = &timeoutTxHash
+		s.timeoutFee = fee
+
+		err := s.persistAndAnnounceState(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	s.timeoutTx = timeoutTx
+
 	s.log.Infof("Publishing timeout tx %v with fee %v to addr %v",
 		timeoutTxHash, fee, s.timeoutAddr)
 
-	err = s.lnd.WalletKit.PublishTransaction(
+	s.publishTimeout(ctx, timeoutTx)
+
+	return nil
+}
+
+// publishTimeout publishes our timeout tx. Errors are only logged, because we
+// will retry with the next block.
+func (s *loopInSwap) publishTimeout(ctx context.Context,
+	timeoutTx *wire.MsgTx) {
+
+	err := s.lnd.WalletKit.PublishTransaction(
 		ctx, timeoutTx,
 		labels.LoopInSweepTimeout(swap.ShortHash(&s.hash)),
 	)
 	if err != nil {
 		s.log.Warnf("publish timeout: %v", err)
 	}
-
-	return nil
 }
 
 // persistAndAnnounceState updates the swap state on disk and sends out an
@@ -899,14 +1966,30 @@ func (s *loopInSwap) persistAndAnnounceState(ctx context.Context) error {
 
 // persistState updates the swap state on disk.
 func (s *loopInSwap) persistState() error {
-	return s.store.UpdateLoopIn(
-		s.hash, s.lastUpdateTime,
-		loopdb.SwapStateData{
-			State:      s.state,
-			Cost:       s.cost,
-			HtlcTxHash: s.htlcTxHash,
-		},
-	)
+	return s.store.UpdateLoopIn(s.hash, s.lastUpdateTime, s.stateData())
+}
+
+// stateData returns the swap state that is stored with a swap update.
+func (s *loopInSwap) stateData() loopdb.SwapStateData {
+	// The htlc tx is only needed to republish it while waiting for its
+	// confirmation, so we don't store it with any later updates.
+	var htlcTx *wire.MsgTx
+	if s.state == loopdb.StateHtlcPublished {
+		htlcTx = s.htlcTx
+	}
+
+	return loopdb.SwapStateData{
+		State:           s.state,
+		Cost:            s.cost,
+		HtlcTxHash:      s.htlcTxHash,
+		HtlcTx:          htlcTx,
+		SweepTxHash:     s.timeoutTxHash,
+		SweepFee:        s.timeoutFee,
+		HtlcOutpoint:    s.htlcOutpoint,
+		HtlcConfHeight:  s.htlcConfHeight,
+		SpendTxHash:     s.spendTxHash,
+		SpendConfHeight: s.spendConfHeight,
+	}
 }
 
 // setState updates the swap state and last update timestamp.
@@ -2,18 +2,26 @@ package loop
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/lightninglabs/lndclient"
 	"github.com/lightninglabs/loop/loopdb"
 	"github.com/lightninglabs/loop/swap"
+	"github.com/lightninglabs/loop/sweep"
 	"github.com/lightninglabs/loop/test"
 	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 	"github.com/stretchr/testify/require"
 
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/psbt"
 )
 
 var (
@@ -59,14 +67,24 @@ func TestLoopInSuccess(t *testing.T) {
 	ctx.assertState(loopdb.StateInitiated)
 
 	ctx.assertState(loopdb.StateHtlcPublished)
-	ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
-
-	// Expect htlc to be published.
-	htlcTx := <-ctx.lnd.SendOutputsChannel
 
-	// Expect the same state to be written again with the htlc tx hash.
+	// Expect the htlc tx to be recorded before it is published.
 	state := ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
 	require.NotNil(t, state.HtlcTxHash)
+	require.NotNil(t, state.HtlcTx)
+
+	// Expect htlc to be published.
+	htlcTx := <-ctx.lnd.TxPublishChannel
+	require.Equal(t, state.HtlcTx, htlcTx)
+	require.Equal(t, *state.HtlcTxHash, htlcTx.TxHash())
+
+	// The fee of the htlc tx is recorded as on-chain cost.
+	htlcFee := ctx.lnd.Utxos[0].Value
+	for _, txOut := range htlcTx.TxOut {
+		htlcFee -= btcutil.Amount(txOut.Value)
+	}
+	require.Greater(t, int64(htlcFee), int64(0))
+	require.Equal(t, htlcFee, state.Cost.Onchain)
 
 	// Expect register for htlc conf.
 	<-ctx.lnd.RegisterConfChannel
@@ -74,7 +92,7 @@ func TestLoopInSuccess(t *testing.T) {
 
 	// Confirm htlc.
 	ctx.lnd.ConfChannel <- &chainntnfs.TxConfirmation{
-		Tx: &htlcTx,
+		Tx: htlcTx,
 	}
 
 	// Client starts listening for spend of htlc.
@@ -117,6 +135,699 @@ func TestLoopInSuccess(t *testing.T) {
 	}
 }
 
+// TestLoopInBatchPublish tests that the htlcs of a batch of loop in swaps are
+// published in a single tx and that every swap tracks its own htlc output.
+func TestLoopInBatchPublish(t *testing.T) {
+	defer test.Guard(t)()
+
+	ctx := newLoopInTestContext(t)
+
+	height := int32(600)
+
+	cfg := newSwapConfig(&ctx.lnd.LndServices, ctx.store, ctx.server)
+
+	// The mock wallet funds the batch from a single output.
+	ctx.lnd.Utxos = []*lnwallet.Utxo{{
+		AddressType: lnwallet.WitnessPubKey,
+		Value:       2*testLoopInRequest.Amount + 100000,
+	}}
+
+	var swaps []*loopInSwap
+	for i := 0; i < 2; i++ {
+		initResult, err := newLoopInSwap(
+			context.Background(), cfg, height, &testLoopInRequest,
+		)
+		require.NoError(t, err)
+		ctx.store.assertLoopInStored()
+
+		swaps = append(swaps, initResult.swap)
+	}
+
+	type publishResult struct {
+		txHash *chainhash.Hash
+		err    error
+	}
+	resultChan := make(chan publishResult)
+	go func() {
+		txHash, err := publishOnChainHtlcBatch(
+			context.Background(), &ctx.lnd.LndServices, swaps,
+			height, 2,
+		)
+		resultChan <- publishResult{txHash: txHash, err: err}
+	}()
+
+	// Both swaps record the batch tx along with the transition to
+	// HtlcPublished before it is published.
+	var (
+		states      []loopdb.SwapStateData
+		onchainCost btcutil.Amount
+	)
+	for range swaps {
+		state := ctx.store.assertLoopInState(
+			loopdb.StateHtlcPublished,
+		)
+		require.NotNil(t, state.HtlcTx)
+		require.Equal(t, state.HtlcTx.TxHash(), *state.HtlcTxHash)
+
+		states = append(states, state)
+		onchainCost += state.Cost.Onchain
+	}
+	require.Equal(t, states[0].HtlcTx, states[1].HtlcTx)
+
+	// Expect a single tx that funds both htlcs and pays change to the
+	// wallet.
+	htlcTx := *<-ctx.lnd.TxPublishChannel
+	require.Equal(t, states[0].HtlcTx, &htlcTx)
+	require.Len(t, htlcTx.TxOut, 3)
+	for i, swap := range swaps {
+		require.Equal(
+			t, swap.htlcP2WSH.PkScript, htlcTx.TxOut[i].PkScript,
+		)
+		require.Equal(
+			t, int64(swap.AmountRequested), htlcTx.TxOut[i].Value,
+		)
+	}
+
+	// The fee of the batch tx is split between both swaps.
+	htlcFee := ctx.lnd.Utxos[0].Value
+	for _, txOut := range htlcTx.TxOut {
+		htlcFee -= btcutil.Amount(txOut.Value)
+	}
+	require.Greater(t, int64(htlcFee), int64(0))
+	require.Equal(t, htlcFee, onchainCost)
+	require.Equal(t, htlcFee/2, swaps[0].cost.Onchain)
+
+	txHash := htlcTx.TxHash()
+	result := <-resultChan
+	require.NoError(t, result.err)
+	require.Equal(t, &txHash, result.txHash)
+
+	// Execute the second swap, which should only republish the batch tx
+	// and wait for it to confirm.
+	swap := swaps[1]
+	errChan := make(chan error)
+	go func() {
+		errChan <- swap.execute(context.Background(), ctx.cfg, height)
+	}()
+
+	ctx.assertState(loopdb.StateHtlcPublished)
+
+	republished := <-ctx.lnd.TxPublishChannel
+	require.Equal(t, txHash, republished.TxHash())
+
+	confReg := <-ctx.lnd.RegisterConfChannel
+	require.Equal(t, &txHash, confReg.TxID)
+	<-ctx.lnd.RegisterConfChannel
+
+	ctx.lnd.ConfChannel <- &chainntnfs.TxConfirmation{
+		Tx: &htlcTx,
+	}
+
+	// The swap registers for the spend of its own htlc output.
+	spendReg := <-ctx.lnd.RegisterSpendChannel
+	require.Equal(t, wire.OutPoint{Hash: txHash, Index: 1},
+		*spendReg.Outpoint)
+
+	subscription := <-ctx.lnd.SingleInvoiceSubcribeChannel
+	require.Equal(t, swap.hash, subscription.Hash)
+
+	subscription.Update <- lndclient.InvoiceUpdate{
+		State:   channeldb.ContractSettled,
+		AmtPaid: 49000,
+	}
+
+	ctx.assertState(loopdb.StateInvoiceSettled)
+	ctx.store.assertLoopInState(loopdb.StateInvoiceSettled)
+
+	successTx := wire.MsgTx{}
+	successTx.AddTxIn(&wire.TxIn{
+		Witness: [][]byte{{}, {}, {}},
+	})
+
+	ctx.lnd.SpendChannel <- &chainntnfs.SpendDetail{
+		SpendingTx:        &successTx,
+		SpenderInputIndex: 0,
+	}
+
+	ctx.assertState(loopdb.StateSuccess)
+	ctx.store.assertLoopInState(loopdb.StateSuccess)
+
+	require.NoError(t, <-errChan)
+}
+
+// TestLoopInBatchExpiry tests that all swaps of a batch fail if one of them is
+// too close to its expiry to publish its htlc, and that the failure is
+// announced once the swaps are executed.
+func TestLoopInBatchExpiry(t *testing.T) {
+	defer test.Guard(t)()
+
+	ctx := newLoopInTestContext(t)
+
+	height := int32(600)
+
+	cfg := newSwapConfig(&ctx.lnd.LndServices, ctx.store, ctx.server)
+
+	var swaps []*loopInSwap
+	for i := 0; i < 2; i++ {
+		initResult, err := newLoopInSwap(
+			context.Background(), cfg, height, &testLoopInRequest,
+		)
+		require.NoError(t, err)
+		ctx.store.assertLoopInStored()
+
+		swaps = append(swaps, initResult.swap)
+	}
+
+	errChan := make(chan error)
+	go func() {
+		_, err := publishOnChainHtlcBatch(
+			context.Background(), &ctx.lnd.LndServices, swaps,
+			swaps[1].CltvExpiry-MinLoopInPublishDelta+1, 2,
+		)
+		errChan <- err
+	}()
+
+	ctx.store.assertLoopInState(loopdb.StateFailTimeout)
+	ctx.store.assertLoopInState(loopdb.StateFailTimeout)
+	require.Error(t, <-errChan)
+
+	// Executing a failed swap announces its final state without
+	// publishing anything.
+	go func() {
+		errChan <- swaps[0].execute(context.Background(), ctx.cfg, height)
+	}()
+
+	ctx.assertState(loopdb.StateFailTimeout)
+	require.NoError(t, <-errChan)
+}
+
+// newLoopInBatchSwaps creates the given number of loop in swaps that can be
+// published as a batch.
+func newLoopInBatchSwaps(t *testing.T, ctx *loopInTestContext, height int32,
+	count int) []*loopInSwap {
+
+	cfg := newSwapConfig(&ctx.lnd.LndServices, ctx.store, ctx.server)
+
+	swaps := make([]*loopInSwap, count)
+	for i := range swaps {
+		initResult, err := newLoopInSwap(
+			context.Background(), cfg, height, &testLoopInRequest,
+		)
+		require.NoError(t, err)
+		ctx.store.assertLoopInStored()
+
+		swaps[i] = initResult.swap
+	}
+
+	return swaps
+}
+
+// TestLoopInBatchFundingFailure tests that the swaps of a batch that can't be
+// funded are left untouched, so that they can publish their htlcs
+// individually once they are executed.
+func TestLoopInBatchFundingFailure(t *testing.T) {
+	defer test.Guard(t)()
+
+	ctx := newLoopInTestContext(t)
+
+	height := int32(600)
+	swaps := newLoopInBatchSwaps(t, ctx, height, 2)
+
+	// The wallet has no outputs to fund the batch.
+	ctx.lnd.Utxos = nil
+
+	_, err := publishOnChainHtlcBatch(
+		context.Background(), &ctx.lnd.LndServices, swaps, height, 2,
+	)
+	require.Error(t, err)
+
+	for _, swap := range swaps {
+		require.Equal(t, loopdb.StateInitiated, swap.state)
+		require.Nil(t, swap.htlcTx)
+		require.Zero(t, swap.cost.Onchain)
+	}
+
+	// Once the wallet can fund it, the swap publishes its own htlc.
+	ctx.lnd.Utxos = []*lnwallet.Utxo{{
+		AddressType: lnwallet.WitnessPubKey,
+		Value:       testLoopInRequest.Amount + 100000,
+	}}
+
+	swap := swaps[0]
+	execCtx, cancel := context.WithCancel(context.Background())
+	errChan := make(chan error)
+	go func() {
+		errChan <- swap.execute(execCtx, ctx.cfg, height)
+	}()
+
+	ctx.assertState(loopdb.StateInitiated)
+	ctx.assertState(loopdb.StateHtlcPublished)
+	state := ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
+
+	htlcTx := <-ctx.lnd.TxPublishChannel
+	require.Equal(t, *state.HtlcTxHash, htlcTx.TxHash())
+	require.Len(t, htlcTx.TxOut, 2)
+	require.Equal(t, swap.htlcP2WSH.PkScript, htlcTx.TxOut[0].PkScript)
+
+	<-ctx.lnd.RegisterConfChannel
+	<-ctx.lnd.RegisterConfChannel
+
+	cancel()
+	require.Equal(t, context.Canceled, <-errChan)
+}
+
+// TestLoopInBatchPublishFailure tests that the swaps of a batch whose tx was
+// recorded but couldn't be published republish the tx once they are
+// executed.
+func TestLoopInBatchPublishFailure(t *testing.T) {
+	defer test.Guard(t)()
+
+	ctx := newLoopInTestContext(t)
+
+	height := int32(600)
+	swaps := newLoopInBatchSwaps(t, ctx, height, 2)
+
+	ctx.lnd.Utxos = []*lnwallet.Utxo{{
+		AddressType: lnwallet.WitnessPubKey,
+		Value:       2*testLoopInRequest.Amount + 100000,
+	}}
+	ctx.lnd.SetPublishErr(errors.New("publish failed"))
+
+	errChan := make(chan error)
+	go func() {
+		_, err := publishOnChainHtlcBatch(
+			context.Background(), &ctx.lnd.LndServices, swaps,
+			height, 2,
+		)
+		errChan <- err
+	}()
+
+	state := ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
+	ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
+	require.Error(t, <-errChan)
+
+	for _, swap := range swaps {
+		require.Equal(t, loopdb.StateHtlcPublished, swap.state)
+		require.Equal(t, state.HtlcTxHash, swap.htlcTxHash)
+	}
+
+	// Once executed, the swap republishes the recorded batch tx.
+	ctx.lnd.SetPublishErr(nil)
+
+	execCtx, cancel := context.WithCancel(context.Background())
+	go func() {
+		errChan <- swaps[1].execute(execCtx, ctx.cfg, height)
+	}()
+
+	ctx.assertState(loopdb.StateHtlcPublished)
+
+	republished := <-ctx.lnd.TxPublishChannel
+	require.Equal(t, *state.HtlcTxHash, republished.TxHash())
+
+	<-ctx.lnd.RegisterConfChannel
+	<-ctx.lnd.RegisterConfChannel
+
+	cancel()
+	require.Equal(t, context.Canceled, <-errChan)
+}
+
+// TestLoopInFundedHtlc tests that the htlc of a loop in swap is funded from
+// the requested wallet outputs, with the change paid to the change address.
+func TestLoopInFundedHtlc(t *testing.T) {
+	defer test.Guard(t)()
+
+	ctx := newLoopInTestContext(t)
+
+	height := int32(600)
+
+	cfg := newSwapConfig(&ctx.lnd.LndServices, ctx.store, ctx.server)
+
+	walletPkScript, err := txscript.PayToAddrScript(
+		test.GetDestAddr(t, 0),
+	)
+	require.NoError(t, err)
+
+	ctx.lnd.Utxos = []*lnwallet.Utxo{
+		{
+			AddressType: lnwallet.WitnessPubKey,
+			Value:       30000,
+			PkScript:    walletPkScript,
+			OutPoint:    wire.OutPoint{Hash: chainhash.Hash{1}},
+		},
+		{
+			AddressType: lnwallet.WitnessPubKey,
+			Value:       40000,
+			PkScript:    walletPkScript,
+			OutPoint:    wire.OutPoint{Hash: chainhash.Hash{2}},
+		},
+	}
+
+	changeAddr := test.GetDestAddr(t, 1)
+
+	req := testLoopInRequest
+	req.ChangeAddr = changeAddr
+
+	// An outpoint that is not an unspent wallet output is rejected when
+	// the swap is initiated.
+	req.HtlcFundingOutpoints = []wire.OutPoint{
+		{Hash: chainhash.Hash{3}},
+	}
+	_, err = newLoopInSwap(context.Background(), cfg, height, &req)
+	require.Error(t, err)
+
+	// The same goes for outpoints that don't cover the swap amount.
+	req.HtlcFundingOutpoints = []wire.OutPoint{
+		ctx.lnd.Utxos[0].OutPoint,
+	}
+	_, err = newLoopInSwap(context.Background(), cfg, height, &req)
+	require.Error(t, err)
+
+	req.HtlcFundingOutpoints = []wire.OutPoint{
+		ctx.lnd.Utxos[0].OutPoint, ctx.lnd.Utxos[1].OutPoint,
+	}
+	initResult, err := newLoopInSwap(
+		context.Background(), cfg, height, &req,
+	)
+	require.NoError(t, err)
+	swap := initResult.swap
+
+	ctx.store.assertLoopInStored()
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- swap.execute(context.Background(), ctx.cfg, height)
+	}()
+
+	ctx.assertState(loopdb.StateInitiated)
+	ctx.assertState(loopdb.StateHtlcPublished)
+
+	// The htlc tx hash is recorded before the tx is published.
+	state := ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
+	require.NotNil(t, state.HtlcTxHash)
+
+	htlcTx := <-ctx.lnd.TxPublishChannel
+	require.Equal(t, htlcTx.TxHash(), *state.HtlcTxHash)
+
+	// The htlc tx spends exactly the requested outpoints.
+	require.Len(t, htlcTx.TxIn, 2)
+	for i, txIn := range htlcTx.TxIn {
+		require.Equal(
+			t, req.HtlcFundingOutpoints[i], txIn.PreviousOutPoint,
+		)
+		require.NotEmpty(t, txIn.Witness)
+	}
+
+	// The first output is the htlc, the second one the change.
+	changePkScript, err := txscript.PayToAddrScript(changeAddr)
+	require.NoError(t, err)
+
+	require.Len(t, htlcTx.TxOut, 2)
+	require.Equal(t, swap.htlcP2WSH.PkScript, htlcTx.TxOut[0].PkScript)
+	require.Equal(t, int64(req.Amount), htlcTx.TxOut[0].Value)
+	require.Equal(t, changePkScript, htlcTx.TxOut[1].PkScript)
+	require.Less(t, htlcTx.TxOut[1].Value, int64(70000-req.Amount))
+
+	<-ctx.lnd.RegisterConfChannel
+	<-ctx.lnd.RegisterConfChannel
+
+	ctx.lnd.ConfChannel <- &chainntnfs.TxConfirmation{
+		Tx: htlcTx,
+	}
+
+	<-ctx.lnd.RegisterSpendChannel
+
+	subscription := <-ctx.lnd.SingleInvoiceSubcribeChannel
+	subscription.Update <- lndclient.InvoiceUpdate{
+		State:   channeldb.ContractSettled,
+		AmtPaid: 49000,
+	}
+
+	ctx.assertState(loopdb.StateInvoiceSettled)
+	ctx.store.assertLoopInState(loopdb.StateInvoiceSettled)
+
+	successTx := wire.MsgTx{}
+	successTx.AddTxIn(&wire.TxIn{
+		Witness: [][]byte{{}, {}, {}},
+	})
+
+	ctx.lnd.SpendChannel <- &chainntnfs.SpendDetail{
+		SpendingTx:        &successTx,
+		SpenderInputIndex: 0,
+	}
+
+	ctx.assertState(loopdb.StateSuccess)
+	ctx.store.assertLoopInState(loopdb.StateSuccess)
+
+	require.NoError(t, <-errChan)
+}
+
+// TestExtractHtlcTx tests that a signed htlc psbt is only accepted if it pays
+// exactly the swap amount to the htlc.
+func TestExtractHtlcTx(t *testing.T) {
+	_, senderKey := test.CreateKey(1)
+	_, receiverKey := test.CreateKey(2)
+
+	var sender, receiver [33]byte
+	copy(sender[:], senderKey.SerializeCompressed())
+	copy(receiver[:], receiverKey.SerializeCompressed())
+
+	htlc, err := swap.NewHtlc(
+		swap.HtlcV2, 1000, sender, receiver, testPreimage.Hash(),
+		swap.HtlcP2WSH, &chaincfg.TestNet3Params,
+	)
+	require.NoError(t, err)
+
+	const amount = btcutil.Amount(50000)
+
+	changeOutput := &wire.TxOut{
+		PkScript: []byte{txscript.OP_TRUE},
+		Value:    10000,
+	}
+	htlcOutput := &wire.TxOut{
+		PkScript: htlc.PkScript,
+		Value:    int64(amount),
+	}
+
+	// The psbt template that is handed out only pays to the htlc.
+	template, err := newHtlcPsbt(htlc, amount)
+	require.NoError(t, err)
+	require.Equal(t, []*wire.TxOut{htlcOutput}, template.UnsignedTx.TxOut)
+
+	tests := []struct {
+		name    string
+		outputs []*wire.TxOut
+		signed  bool
+		success bool
+	}{
+		{
+			name:    "valid htlc tx",
+			outputs: []*wire.TxOut{changeOutput, htlcOutput},
+			signed:  true,
+			success: true,
+		},
+		{
+			name:    "unsigned",
+			outputs: []*wire.TxOut{htlcOutput},
+			signed:  false,
+		},
+		{
+			name:    "no htlc output",
+			outputs: []*wire.TxOut{changeOutput},
+			signed:  true,
+		},
+		{
+			name: "wrong amount",
+			outputs: []*wire.TxOut{{
+				PkScript: htlc.PkScript,
+				Value:    int64(amount - 1),
+			}},
+			signed: true,
+		},
+		{
+			name:    "multiple htlc outputs",
+			outputs: []*wire.TxOut{htlcOutput, htlcOutput},
+			signed:  true,
+		},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			packet, err := psbt.New(
+				[]*wire.OutPoint{{Hash: chainhash.Hash{1}}},
+				testCase.outputs, 2, 0, []uint32{0},
+			)
+			require.NoError(t, err)
+
+			if testCase.signed {
+				packet.Inputs[0].FinalScriptWitness = []byte{
+					1, 1, 1,
+				}
+			}
+
+			tx, err := extractHtlcTx(packet, amount, htlc)
+			if !testCase.success {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, wire.TxWitness{{1}}, tx.TxIn[0].Witness)
+		})
+	}
+}
+
+// TestLoopInPsbtHtlc tests that the htlc tx of a loop in swap with an external
+// htlc that is published from a psbt is handed to the swap, which records and
+// announces it and then only follows this particular tx. Txes that can't be
+// published are rolled back, and unconfirmed txes can be replaced.
+func TestLoopInPsbtHtlc(t *testing.T) {
+	defer test.Guard(t)()
+
+	ctx := newLoopInTestContext(t)
+	height := int32(600)
+
+	cfg := newSwapConfig(&ctx.lnd.LndServices, ctx.store, ctx.server)
+
+	req := testLoopInRequest
+	req.ExternalHtlc = true
+
+	initResult, err := newLoopInSwap(
+		context.Background(), cfg, height, &req,
+	)
+	require.NoError(t, err)
+	s := initResult.swap
+
+	ctx.store.assertLoopInStored()
+
+	swapCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- s.execute(swapCtx, ctx.cfg, height)
+	}()
+
+	ctx.assertState(loopdb.StateInitiated)
+	ctx.assertState(loopdb.StateHtlcPublished)
+	ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
+
+	// Without a known htlc tx, the swap watches both htlc outputs.
+	for i := 0; i < 2; i++ {
+		reg := <-ctx.lnd.RegisterConfChannel
+		require.Nil(t, reg.TxID)
+	}
+
+	// newPacket returns a signed psbt that funds the htlc from an input of
+	// the value provided, which is taken from the tx with the hash byte
+	// provided.
+	newPacket := func(txHash byte, inputValue btcutil.Amount) *psbt.Packet {
+		packet, err := psbt.New(
+			[]*wire.OutPoint{{Hash: chainhash.Hash{txHash}}},
+			[]*wire.TxOut{{
+				PkScript: s.htlcP2WSH.PkScript,
+				Value:    int64(req.Amount),
+			}}, 2, 0, []uint32{0},
+		)
+		require.NoError(t, err)
+
+		packet.Inputs[0].WitnessUtxo = &wire.TxOut{
+			Value: int64(inputValue),
+		}
+		packet.Inputs[0].FinalScriptWitness = []byte{1, 1, 1}
+
+		return packet
+	}
+
+	type result struct {
+		txHash *chainhash.Hash
+		err    error
+	}
+	publishPsbt := func(packet *psbt.Packet) chan result {
+		resultChan := make(chan result, 1)
+		go func() {
+			txHash, err := s.publishHtlcPsbt(
+				context.Background(), packet,
+			)
+			resultChan <- result{txHash, err}
+		}()
+
+		return resultChan
+	}
+
+	// If the htlc tx can't be published, the swap records it and then
+	// restores the state without any htlc tx, so that a corrected psbt
+	// can be handed in.
+	ctx.lnd.SetPublishErr(errors.New("fee too low"))
+	resultChan := publishPsbt(newPacket(1, req.Amount+100))
+
+	state := ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
+	require.NotNil(t, state.HtlcTxHash)
+	<-ctx.statusChan
+
+	state = ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
+	require.Nil(t, state.HtlcTxHash)
+	require.Nil(t, state.HtlcTx)
+	require.Zero(t, state.Cost.Onchain)
+	<-ctx.statusChan
+
+	require.Error(t, (<-resultChan).err)
+	ctx.lnd.SetPublishErr(nil)
+
+	// The swap records the htlc tx and announces it before publishing it.
+	// The fee of the tx is added to the on-chain cost of the swap.
+	resultChan = publishPsbt(newPacket(1, req.Amount+1000))
+
+	state = ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
+	require.NotNil(t, state.HtlcTxHash)
+	require.NotNil(t, state.HtlcTx)
+	require.Equal(t, btcutil.Amount(1000), state.Cost.Onchain)
+
+	info := <-ctx.statusChan
+	require.Equal(t, state.HtlcTxHash, info.HtlcTxHash)
+
+	htlcTx := <-ctx.lnd.TxPublishChannel
+	require.Equal(t, *state.HtlcTxHash, htlcTx.TxHash())
+
+	res := <-resultChan
+	require.NoError(t, res.err)
+	require.Equal(t, state.HtlcTxHash, res.txHash)
+
+	// From now on, the swap only follows the published tx.
+	for i := 0; i < 2; i++ {
+		reg := <-ctx.lnd.RegisterConfChannel
+		require.Equal(t, state.HtlcTxHash, reg.TxID)
+	}
+
+	// While the htlc is unconfirmed, the tx may be replaced, for example
+	// with one that pays a higher fee. Its fee replaces the fee of the
+	// previous tx in the on-chain cost.
+	resultChan = publishPsbt(newPacket(2, req.Amount+2000))
+
+	replacement := ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
+	require.NotEqual(t, state.HtlcTxHash, replacement.HtlcTxHash)
+	require.Equal(t, btcutil.Amount(2000), replacement.Cost.Onchain)
+	<-ctx.statusChan
+
+	htlcTx = <-ctx.lnd.TxPublishChannel
+	require.Equal(t, *replacement.HtlcTxHash, htlcTx.TxHash())
+
+	res = <-resultChan
+	require.NoError(t, res.err)
+
+	for i := 0; i < 2; i++ {
+		reg := <-ctx.lnd.RegisterConfChannel
+		require.Equal(t, replacement.HtlcTxHash, reg.TxID)
+	}
+
+	// Once the swap stopped executing, psbts are no longer accepted.
+	cancel()
+	require.Equal(t, context.Canceled, <-errChan)
+
+	require.Error(t, (<-publishPsbt(newPacket(3, req.Amount+3000))).err)
+}
+
 // TestLoopInTimeout tests scenarios where the server doesn't sweep the htlc
 // and the client is forced to reclaim the funds using the timeout tx.
 func TestLoopInTimeout(t *testing.T) {
@@ -184,17 +895,18 @@ func testLoopInTimeout(t *testing.T,
 	ctx.assertState(loopdb.StateInitiated)
 
 	ctx.assertState(loopdb.StateHtlcPublished)
-	ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
 
 	var htlcTx wire.MsgTx
 	if externalValue == 0 {
-		// Expect htlc to be published.
-		htlcTx = <-ctx.lnd.SendOutputsChannel
-
-		// Expect the same state to be written again with the htlc tx hash.
+		// Expect the htlc tx hash to be recorded before the htlc is
+		// published.
 		state := ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
 		require.NotNil(t, state.HtlcTxHash)
+
+		htlcTx = *<-ctx.lnd.TxPublishChannel
 	} else {
+		ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
+
 		// Create an external htlc publish tx.
 		var pkScript []byte
 		if outputType == swap.HtlcNP2WSH {
@@ -244,20 +956,63 @@ func testLoopInTimeout(t *testing.T,
 		t.Fatal("client subscribing to wrong invoice")
 	}
 
-	// Let htlc expire.
-	ctx.blockEpochChan <- s.LoopInContract.CltvExpiry
+	// publishTimeout lets the given block arrive and returns the timeout
+	// tx that is published in response, along with the state that it is
+	// recorded with.
+	publishTimeout := func(height int32) (*wire.MsgTx,
+		loopdb.SwapStateData) {
+
+		ctx.blockEpochChan <- height
+
+		// Expect a signing request for the htlc tx output value.
+		signReq := <-ctx.lnd.SignOutputRawChannel
+		require.Equal(
+			t, htlcTx.TxOut[0].Value,
+			signReq.SignDescriptors[0].Output.Value,
+		)
+
+		// Expect the timeout tx to be recorded before it is published.
+		ctx.assertState(loopdb.StateHtlcPublished)
+		state := ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
 
-	// Expect a signing request for the htlc tx output value.
-	signReq := <-ctx.lnd.SignOutputRawChannel
-	if signReq.SignDescriptors[0].Output.Value != htlcTx.TxOut[0].Value {
+		timeoutTx := <-ctx.lnd.TxPublishChannel
+		require.Equal(t, timeoutTx.TxHash(), *state.SweepTxHash)
 
-		t.Fatal("invalid signing amount")
+		return timeoutTx, state
 	}
 
-	// Expect timeout tx to be published.
-	timeoutTx := <-ctx.lnd.TxPublishChannel
+	// Let htlc expire.
+	timeoutTx, timeoutState := publishTimeout(s.LoopInContract.CltvExpiry)
+
+	// The timeout tx doesn't confirm, so the next block is expected to
+	// replace it with a tx that pays a higher fee.
+	_, bumpState := publishTimeout(s.LoopInContract.CltvExpiry + 1)
+	require.Greater(
+		t, int64(bumpState.SweepFee), int64(timeoutState.SweepFee),
+	)
+
+	// Many blocks later, the fee is capped at our maximum fee rate.
+	weight, err := sweep.GetSweepWeight(
+		s.htlc.AddTimeoutToEstimator, []loopdb.SweepDestination{
+			{Addr: s.timeoutAddr, Weight: 1},
+		},
+	)
+	require.NoError(t, err)
 
-	// Confirm timeout tx.
+	_, cappedState := publishTimeout(s.LoopInContract.CltvExpiry + 100)
+	require.Equal(
+		t, DefaultTimeoutMaxFeeRate.FeeForWeight(weight),
+		cappedState.SweepFee,
+	)
+
+	// At the capped fee, our timeout tx can't be replaced anymore, so the
+	// next block republishes it as is without recording a new tx.
+	ctx.blockEpochChan <- s.LoopInContract.CltvExpiry + 101
+
+	republished := <-ctx.lnd.TxPublishChannel
+	require.Equal(t, *cappedState.SweepTxHash, republished.TxHash())
+
+	// Confirm our first timeout tx.
 	ctx.lnd.SpendChannel <- &chainntnfs.SpendDetail{
 		SpendingTx:        timeoutTx,
 		SpenderInputIndex: 0,
@@ -273,7 +1028,14 @@ func testLoopInTimeout(t *testing.T,
 	}
 
 	ctx.assertState(loopdb.StateFailTimeout)
-	ctx.store.assertLoopInState(loopdb.StateFailTimeout)
+	state := ctx.store.assertLoopInState(loopdb.StateFailTimeout)
+
+	// The fee of the timeout tx that confirmed is added to our on-chain
+	// cost.
+	require.Equal(
+		t, timeoutState.Cost.Onchain+timeoutState.SweepFee,
+		state.Cost.Onchain,
+	)
 
 	err = <-errChan
 	if err != nil {
@@ -322,6 +1084,136 @@ func TestLoopInResume(t *testing.T) {
 	}
 }
 
+// TestLoopInRepublishHtlc tests that a resumed swap republishes the htlc tx
+// that it recorded before a restart.
+func TestLoopInRepublishHtlc(t *testing.T) {
+	defer test.Guard(t)()
+
+	ctx := newLoopInTestContext(t)
+	cfg := newSwapConfig(&ctx.lnd.LndServices, ctx.store, ctx.server)
+
+	contract := &loopdb.LoopInContract{
+		HtlcConfTarget: 2,
+		SwapContract: loopdb.SwapContract{
+			Preimage:        testPreimage,
+			AmountRequested: 100000,
+			CltvExpiry:      744,
+			ReceiverKey:     [33]byte{5},
+			SenderKey:       [33]byte{4},
+			MaxSwapFee:      60000,
+			MaxMinerFee:     50000,
+			ProtocolVersion: loopdb.ProtocolVersionHtlcV2,
+		},
+	}
+
+	htlcTx := wire.NewMsgTx(2)
+	htlcTx.AddTxIn(&wire.TxIn{})
+	htlcTx.AddTxOut(&wire.TxOut{Value: 100000})
+	htlcTxHash := htlcTx.TxHash()
+
+	pendSwap := &loopdb.LoopIn{
+		Contract: contract,
+		Loop: loopdb.Loop{
+			Events: []*loopdb.LoopEvent{
+				{
+					SwapStateData: loopdb.SwapStateData{
+						State:      loopdb.StateHtlcPublished,
+						HtlcTxHash: &htlcTxHash,
+						HtlcTx:     htlcTx,
+					},
+				},
+			},
+			Hash: testPreimage.Hash(),
+		},
+	}
+
+	swap, err := resumeLoopInSwap(context.Background(), cfg, pendSwap)
+	require.NoError(t, err)
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	errChan := make(chan error)
+	go func() {
+		errChan <- swap.execute(runCtx, ctx.cfg, 600)
+	}()
+
+	ctx.assertState(loopdb.StateHtlcPublished)
+
+	// The recorded htlc tx is published again before we wait for its
+	// confirmation.
+	require.Equal(t, htlcTx, <-ctx.lnd.TxPublishChannel)
+
+	confReg := <-ctx.lnd.RegisterConfChannel
+	require.Equal(t, &htlcTxHash, confReg.TxID)
+	<-ctx.lnd.RegisterConfChannel
+
+	cancel()
+	require.Equal(t, context.Canceled, <-errChan)
+}
+
+// TestSelectHtlcFundingUtxos tests the selection of wallet outputs that fund
+// an htlc.
+func TestSelectHtlcFundingUtxos(t *testing.T) {
+	lnd := test.NewMockLnd()
+	lnd.Utxos = []*lnwallet.Utxo{
+		{
+			AddressType: lnwallet.WitnessPubKey,
+			Value:       20000,
+			OutPoint:    wire.OutPoint{Index: 0},
+		},
+		{
+			AddressType: lnwallet.UnknownAddressType,
+			Value:       100000,
+			OutPoint:    wire.OutPoint{Index: 1},
+		},
+		{
+			AddressType: lnwallet.NestedWitnessPubKey,
+			Value:       40000,
+			OutPoint:    wire.OutPoint{Index: 2},
+		},
+		{
+			AddressType: lnwallet.WitnessPubKey,
+			Value:       30000,
+			OutPoint:    wire.OutPoint{Index: 3},
+		},
+	}
+
+	const feeRate = chainfee.SatPerKWeight(1000)
+
+	// The largest output that we can sign for is selected first.
+	utxos, err := selectHtlcFundingUtxos(
+		context.Background(), &lnd.LndServices, 35000, 1, feeRate,
+	)
+	require.NoError(t, err)
+	require.Equal(t, []*lnwallet.Utxo{lnd.Utxos[2]}, utxos)
+
+	// Further outputs are added until the fee is covered too.
+	utxos, err = selectHtlcFundingUtxos(
+		context.Background(), &lnd.LndServices, 40000, 1, feeRate,
+	)
+	require.NoError(t, err)
+	require.Equal(t, []*lnwallet.Utxo{lnd.Utxos[2], lnd.Utxos[3]}, utxos)
+
+	// Every additional htlc output raises the fee.
+	utxos, err = selectHtlcFundingUtxos(
+		context.Background(), &lnd.LndServices, 39000, 1, feeRate,
+	)
+	require.NoError(t, err)
+	require.Equal(t, []*lnwallet.Utxo{lnd.Utxos[2]}, utxos)
+
+	utxos, err = selectHtlcFundingUtxos(
+		context.Background(), &lnd.LndServices, 39000, 3, feeRate,
+	)
+	require.NoError(t, err)
+	require.Equal(t, []*lnwallet.Utxo{lnd.Utxos[2], lnd.Utxos[3]}, utxos)
+
+	// The output of unknown type can't be used, so the wallet funds are
+	// insufficient.
+	_, err = selectHtlcFundingUtxos(
+		context.Background(), &lnd.LndServices, 90000, 1, feeRate,
+	)
+	require.Error(t, err)
+}
+
 func testLoopInResume(t *testing.T, state loopdb.SwapState, expired bool,
 	storedVersion loopdb.ProtocolVersion, scriptVersion swap.ScriptVersion) {
 
@@ -411,7 +1303,7 @@ func testLoopInResume(t *testing.T, state loopdb.SwapState, expired bool,
 		}
 
 		select {
-		case <-ctx.lnd.SendOutputsChannel:
+		case <-ctx.lnd.TxPublishChannel:
 			t.Fatal("unexpected tx published")
 		default:
 		}
@@ -427,15 +1319,13 @@ func testLoopInResume(t *testing.T, state loopdb.SwapState, expired bool,
 		}
 
 		ctx.assertState(loopdb.StateHtlcPublished)
-		ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
-
-		// Expect htlc to be published.
-		htlcTx = <-ctx.lnd.SendOutputsChannel
 
-		// Expect the same state to be written again with the htlc tx
-		// hash.
+		// Expect the htlc tx hash to be recorded before the htlc is
+		// published.
 		state := ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
 		require.NotNil(t, state.HtlcTxHash)
+
+		htlcTx = *<-ctx.lnd.TxPublishChannel
 	} else {
 		ctx.assertState(loopdb.StateHtlcPublished)
 
@@ -489,3 +1379,190 @@ func testLoopInResume(t *testing.T, state loopdb.SwapState, expired bool,
 
 	ctx.assertState(loopdb.StateSuccess)
 }
+
+// TestLoopInSpendReorg tests that a loop in swap watches its htlc again if
+// the htlc tx or the tx that spends the htlc is reorged out before it reaches
+// the safety depth, and that it completes once a spend is safe.
+func TestLoopInSpendReorg(t *testing.T) {
+	defer test.Guard(t)()
+
+	ctx := newLoopInTestContext(t)
+	ctx.cfg.reorgSafetyDepth = 3
+
+	height := int32(600)
+
+	cfg := newSwapConfig(&ctx.lnd.LndServices, ctx.store, ctx.server)
+
+	initResult, err := newLoopInSwap(
+		context.Background(), cfg, height, &testLoopInRequest,
+	)
+	require.NoError(t, err)
+	swap := initResult.swap
+
+	ctx.store.assertLoopInStored()
+
+	errChan := make(chan error)
+	go func() {
+		err := swap.execute(context.Background(), ctx.cfg, height)
+		if err != nil {
+			log.Error(err)
+		}
+		errChan <- err
+	}()
+
+	ctx.assertState(loopdb.StateInitiated)
+	ctx.assertState(loopdb.StateHtlcPublished)
+	ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
+	htlcTx := <-ctx.lnd.TxPublishChannel
+	htlcTxHash := htlcTx.TxHash()
+
+	// confirmHtlc confirms the htlc and waits for the client to watch it
+	// and to track the htlc tx up to the safety depth.
+	confirmHtlc := func() {
+		<-ctx.lnd.RegisterConfChannel
+		<-ctx.lnd.RegisterConfChannel
+
+		ctx.lnd.ConfChannel <- &chainntnfs.TxConfirmation{
+			Tx:          htlcTx,
+			BlockHeight: uint32(height),
+		}
+
+		<-ctx.lnd.RegisterSpendChannel
+
+		reg := <-ctx.lnd.RegisterConfChannel
+		require.Equal(t, &htlcTxHash, reg.TxID)
+		require.Equal(t, int32(3), reg.NumConfs)
+	}
+
+	// assertReorged asserts that the swap announces that it reverted to
+	// the given state.
+	assertReorged := func(state loopdb.SwapState) SwapInfo {
+		info := <-ctx.statusChan
+		require.Equal(t, state, info.State)
+		require.True(t, info.Reorged)
+
+		return info
+	}
+
+	confirmHtlc()
+	subscription := <-ctx.lnd.SingleInvoiceSubcribeChannel
+
+	// The htlc tx doesn't reach the safety depth in time, and lnd doesn't
+	// report it as confirmed anymore by the next block. It was reorged
+	// out, so we expect the client to wait for the htlc to confirm again.
+	ctx.blockEpochChan <- height + 3
+
+	reg := <-ctx.lnd.RegisterConfChannel
+	require.Equal(t, &htlcTxHash, reg.TxID)
+	require.Equal(t, int32(1), reg.NumConfs)
+
+	ctx.blockEpochChan <- height + 4
+
+	info := assertReorged(loopdb.StateHtlcPublished)
+	require.Nil(t, info.HtlcOutpoint)
+
+	// The invoice isn't settled yet, so the client subscribes to it again.
+	confirmHtlc()
+	subscription = <-ctx.lnd.SingleInvoiceSubcribeChannel
+
+	subscription.Update <- lndclient.InvoiceUpdate{
+		State:   channeldb.ContractSettled,
+		AmtPaid: 49000,
+	}
+
+	ctx.assertState(loopdb.StateInvoiceSettled)
+	settled := ctx.store.assertLoopInState(loopdb.StateInvoiceSettled)
+
+	// The server sweeps the htlc. We expect the outcome to be announced
+	// right away and the sweep to be tracked up to the safety depth.
+	successTx := wire.MsgTx{}
+	successTx.AddTxIn(&wire.TxIn{
+		Witness: [][]byte{{}, {}, {}},
+	})
+	successTx.AddTxOut(&wire.TxOut{
+		PkScript: []byte{0, 20, 1},
+		Value:    int64(testLoopInRequest.Amount),
+	})
+
+	successTxHash := successTx.TxHash()
+
+	// spendHtlc spends the htlc with the success tx at the given height.
+	spendHtlc := func(spendHeight int32) {
+		ctx.lnd.SpendChannel <- &chainntnfs.SpendDetail{
+			SpendingTx:        &successTx,
+			SpenderTxHash:     &successTxHash,
+			SpenderInputIndex: 0,
+			SpendingHeight:    spendHeight,
+		}
+
+		// The outcome is announced, but only persisted once the sweep
+		// is safe.
+		ctx.assertState(loopdb.StateSuccess)
+
+		reg := <-ctx.lnd.RegisterConfChannel
+		require.Equal(t, &successTxHash, reg.TxID)
+		require.Equal(t, int32(3), reg.NumConfs)
+	}
+
+	spendHtlc(height + 5)
+
+	// The sweep doesn't reach the safety depth in time, and the htlc is
+	// now spent by a different tx. Our sweep was reorged out, so we
+	// expect the swap to go back to its previous state.
+	ctx.blockEpochChan <- height + 8
+
+	otherTx := successTx.Copy()
+	otherTx.TxOut[0].Value--
+
+	<-ctx.lnd.RegisterSpendChannel
+	ctx.lnd.SpendChannel <- &chainntnfs.SpendDetail{
+		SpendingTx:     otherTx,
+		SpendingHeight: height + 8,
+	}
+
+	info = assertReorged(loopdb.StateInvoiceSettled)
+	require.Equal(t, settled.Cost, info.Cost)
+
+	// The client watches the htlc again, without subscribing to the
+	// invoice that was already settled. This time, lnd doesn't report any
+	// spend of the htlc by the next block, so the htlc is unspent and our
+	// sweep was reorged out again.
+	confirmHtlc()
+	spendHtlc(height + 9)
+
+	ctx.blockEpochChan <- height + 12
+	<-ctx.lnd.RegisterSpendChannel
+	ctx.blockEpochChan <- height + 13
+
+	assertReorged(loopdb.StateInvoiceSettled)
+
+	// Finally, the sweep is delayed, but lnd still reports it as the htlc
+	// spend, so we keep waiting until it reaches the safety depth and the
+	// outcome is persisted.
+	confirmHtlc()
+	spendHtlc(height + 14)
+
+	ctx.blockEpochChan <- height + 17
+
+	<-ctx.lnd.RegisterSpendChannel
+	ctx.lnd.SpendChannel <- &chainntnfs.SpendDetail{
+		SpendingTx:     &successTx,
+		SpenderTxHash:  &successTxHash,
+		SpendingHeight: height + 14,
+	}
+
+	ctx.lnd.ConfChannel <- &chainntnfs.TxConfirmation{
+		Tx: &successTx,
+	}
+
+	success := ctx.store.assertLoopInState(loopdb.StateSuccess)
+
+	// The htlc outpoint, the sweep and their confirmation heights are
+	// recorded with the outcome.
+	require.Equal(t, htlcTxHash, success.HtlcOutpoint.Hash)
+	require.Equal(t, height, success.HtlcConfHeight)
+	require.Equal(t, &successTxHash, success.SpendTxHash)
+	require.Equal(t, height+14, success.SpendConfHeight)
+
+	require.NoError(t, <-errChan)
+}
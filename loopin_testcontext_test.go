@@ -4,9 +4,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
 	"github.com/lightninglabs/loop/loopdb"
 	"github.com/lightninglabs/loop/sweep"
 	"github.com/lightninglabs/loop/test"
+	"github.com/lightningnetwork/lnd/lnwallet"
 )
 
 type loopInTestContext struct {
@@ -23,6 +28,18 @@ type loopInTestContext struct {
 func newLoopInTestContext(t *testing.T) *loopInTestContext {
 	lnd := test.NewMockLnd()
 	server := newServerMock(lnd)
+
+	// Provide a wallet output that internal htlcs are funded from.
+	pkScript, err := txscript.PayToAddrScript(test.GetDestAddr(t, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lnd.Utxos = []*lnwallet.Utxo{{
+		AddressType: lnwallet.WitnessPubKey,
+		Value:       btcutil.SatoshiPerBitcoin,
+		PkScript:    pkScript,
+		OutPoint:    wire.OutPoint{Hash: chainhash.Hash{9}},
+	}}
 	store := newStoreMock(t)
 	sweeper := sweep.Sweeper{Lnd: &lnd.LndServices}
 
@@ -35,10 +52,11 @@ func newLoopInTestContext(t *testing.T) *loopInTestContext {
 	}
 
 	cfg := executeConfig{
-		statusChan:     statusChan,
-		sweeper:        &sweeper,
-		blockEpochChan: blockEpochChan,
-		timerFactory:   timerFactory,
+		statusChan:        statusChan,
+		sweeper:           &sweeper,
+		blockEpochChan:    blockEpochChan,
+		timerFactory:      timerFactory,
+		timeoutMaxFeeRate: DefaultTimeoutMaxFeeRate,
 	}
 
 	return &loopInTestContext{
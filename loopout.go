@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
@@ -21,6 +22,8 @@ import (
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+	"github.com/lightningnetwork/lnd/routing/route"
 )
 
 var (
@@ -43,6 +46,26 @@ var (
 	// TODO(wilmer): tune?
 	DefaultSweepConfTargetDelta = DefaultSweepConfTarget * 2
 
+	// SweepFeeBumpDelta is the delta of blocks from a Loop Out swap's
+	// expiration height at which we start raising our sweep fee above the
+	// fee estimate with every new block, to make sure that our sweep
+	// confirms before the server is able to time out the htlc.
+	SweepFeeBumpDelta = MinLoopOutPreimageRevealDelta
+
+	// SweepFeeBumpStep is the fraction of the sweep fee estimate that is
+	// added to our sweep fee for every block that passes within the
+	// SweepFeeBumpDelta window. Once the preimage has been revealed, our
+	// maximum miner fee is raised by the same factor, up to the configured
+	// maximum sweep fee multiplier.
+	SweepFeeBumpStep = 0.1
+
+	// DefaultMaxSweepFeeMultiplier is the default for the factor by which
+	// the maximum miner fee of a loop out swap is raised at most once its
+	// preimage has been revealed. It is the fee multiplier that is reached
+	// at the htlc expiry.
+	DefaultMaxSweepFeeMultiplier = 1 + float64(SweepFeeBumpDelta)*
+		SweepFeeBumpStep
+
 	// paymentTimeout is the timeout for the loop out payment loop as
 	// communicated to lnd.
 	paymentTimeout = time.Minute * 30
@@ -62,6 +85,24 @@ type loopOutSwap struct {
 	// htlcTxHash is the confirmed htlc tx id.
 	htlcTxHash *chainhash.Hash
 
+	// sweepTxHash is the tx id of the most recently published sweep tx.
+	sweepTxHash *chainhash.Hash
+
+	// sweepFee is the fee paid by our most recently published sweep tx.
+	// For a batch sweep tx, this is the share of the fee that we paid.
+	sweepFee btcutil.Amount
+
+	// batchFee is the total fee of the most recent batch sweep tx that
+	// spent our htlc. It is zero if our most recent sweep was not a
+	// batch.
+	batchFee btcutil.Amount
+
+	// sweepTx is our most recently published individual sweep tx, which
+	// we republish as long as our fee doesn't rise enough to replace it.
+	// It is nil if our most recent sweep was a batch or was published
+	// before a restart.
+	sweepTx *wire.MsgTx
+
 	swapPaymentChan chan lndclient.PaymentResult
 	prePaymentChan  chan lndclient.PaymentResult
 
@@ -71,10 +112,25 @@ type loopOutSwap struct {
 // executeConfig contains extra configuration to execute the swap.
 type executeConfig struct {
 	sweeper         *sweep.Sweeper
+	batcher         *sweepBatcher
 	statusChan      chan<- SwapInfo
 	blockEpochChan  <-chan interface{}
 	timerFactory    func(d time.Duration) <-chan time.Time
 	loopOutMaxParts uint32
+
+	// maxSweepFeeMultiplier is the factor by which the maximum miner fee
+	// of a loop out swap is raised at most when we bump the fee of its
+	// sweep after revealing the preimage. A factor of one or less doesn't
+	// raise the maximum miner fee.
+	maxSweepFeeMultiplier float64
+
+	// timeoutMaxFeeRate is the fee rate that the timeout tx of a loop in
+	// swap is bumped to at most.
+	timeoutMaxFeeRate chainfee.SatPerKWeight
+
+	// reorgSafetyDepth is the number of confirmations that we track the
+	// tx that spends a swap htlc for, after the swap reached its outcome.
+	reorgSafetyDepth uint32
 }
 
 // loopOutInitResult contains information about a just-initiated loop out swap.
@@ -149,6 +205,7 @@ func newLoopOutSwap(globalCtx context.Context, cfg *swapConfig,
 	contract := loopdb.LoopOutContract{
 		SwapInvoice:             swapResp.swapInvoice,
 		DestAddr:                request.DestAddr,
+		SweepDestinations:       request.SweepDestinations,
 		MaxSwapRoutingFee:       request.MaxSwapRoutingFee,
 		SweepConfTarget:         request.SweepConfTarget,
 		HtlcConfirmations:       confs,
@@ -166,9 +223,12 @@ func newLoopOutSwap(globalCtx context.Context, cfg *swapConfig,
 			MaxMinerFee:      request.MaxMinerFee,
 			MaxSwapFee:       request.MaxSwapFee,
 			Label:            request.Label,
+			Initiator:        request.Initiator,
 			ProtocolVersion:  loopdb.CurrentInternalProtocolVersion,
 		},
-		OutgoingChanSet: chanSet,
+		OutgoingChanSet:  chanSet,
+		LastHop:          request.LastHop,
+		PaymentCltvLimit: request.PaymentCltvLimit,
 	}
 
 	swapKit := newSwapKit(
@@ -247,6 +307,9 @@ func resumeLoopOutSwap(reqContext context.Context, cfg *swapConfig,
 		swap.state = lastUpdate.State
 		swap.lastUpdateTime = lastUpdate.Time
 		swap.htlcTxHash = lastUpdate.HtlcTxHash
+		swap.sweepTxHash = lastUpdate.SweepTxHash
+		swap.sweepFee = lastUpdate.SweepFee
+		swap.restoreOnChainState(lastUpdate)
 	}
 
 	return swap, nil
@@ -258,6 +321,9 @@ func (s *loopOutSwap) sendUpdate(ctx context.Context) error {
 	s.log.Infof("Loop out swap state: %v", info.State)
 
 	info.HtlcAddressP2WSH = s.htlc.Address
+	info.HtlcTxHash = s.htlcTxHash
+	info.SweepTxHash = s.sweepTxHash
+	info.SweepFee = s.sweepFee
 
 	select {
 	case s.statusChan <- *info:
@@ -390,77 +456,156 @@ func (s *loopOutSwap) executeSwap(globalCtx context.Context) error {
 	// payments in a previous run, we cannot just abandon here.
 	s.payInvoices(globalCtx)
 
-	// Wait for confirmation of the on-chain htlc by watching for a tx
-	// producing the swap script output.
-	txConf, err := s.waitForConfirmedHtlc(globalCtx)
-	if err != nil {
-		return err
-	}
+	// Go through the on-chain part of the swap until the tx that spends
+	// the htlc is safe from reorgs. If it is reorged out, we start over
+	// from the state that we were in before it confirmed.
+	for {
+		// Wait for confirmation of the on-chain htlc by watching for a
+		// tx producing the swap script output.
+		txConf, err := s.waitForConfirmedHtlc(globalCtx)
+		if err != nil {
+			return err
+		}
 
-	// If no error and no confirmation, the swap is aborted without an
-	// error. The swap state has been updated to a final state.
-	if txConf == nil {
-		return nil
-	}
+		// If no error and no confirmation, the swap is aborted without
+		// an error. The swap state has been updated to a final state.
+		if txConf == nil {
+			return nil
+		}
 
-	// TODO: Off-chain payments can be canceled here. Most probably the HTLC
-	// is accepted by the server, but in case there are not for whatever
-	// reason, we don't need to have mission control start another payment
-	// attempt.
+		// TODO: Off-chain payments can be canceled here. Most probably
+		// the HTLC is accepted by the server, but in case there are not
+		// for whatever reason, we don't need to have mission control
+		// start another payment attempt.
 
-	// Retrieve outpoint for sweep.
-	htlcOutpoint, htlcValue, err := swap.GetScriptOutput(
-		txConf.Tx, s.htlc.PkScript,
-	)
-	if err != nil {
-		return err
-	}
+		// Retrieve outpoint for sweep.
+		htlcOutpoint, htlcValue, err := swap.GetScriptOutput(
+			txConf.Tx, s.htlc.PkScript,
+		)
+		if err != nil {
+			return err
+		}
 
-	s.log.Infof("Htlc value: %v", htlcValue)
+		s.log.Infof("Htlc value: %v", htlcValue)
 
-	// Verify amount if preimage hasn't been revealed yet.
-	if s.state != loopdb.StatePreimageRevealed && htlcValue < s.AmountRequested {
-		log.Warnf("Swap amount too low, expected %v but received %v",
-			s.AmountRequested, htlcValue)
+		s.htlcOutpoint = htlcOutpoint
 
-		s.state = loopdb.StateFailInsufficientValue
-		return nil
-	}
+		// Verify amount if preimage hasn't been revealed yet.
+		if s.state != loopdb.StatePreimageRevealed &&
+			htlcValue < s.AmountRequested {
 
-	// Try to spend htlc and continue (rbf) until a spend has confirmed.
-	spendDetails, err := s.waitForHtlcSpendConfirmed(globalCtx,
-		*htlcOutpoint,
-		func() error {
-			return s.sweep(globalCtx, *htlcOutpoint, htlcValue)
-		},
-	)
-	if err != nil {
-		return err
-	}
+			log.Warnf("Swap amount too low, expected %v but "+
+				"received %v", s.AmountRequested, htlcValue)
 
-	// Inspect witness stack to see if it is a success transaction. We
-	// don't just try to match with the hash of our sweep tx, because it
-	// may be swept by a different (fee) sweep tx from a previous run.
-	htlcInput, err := swap.GetTxInputByOutpoint(
-		spendDetails.SpendingTx, htlcOutpoint,
-	)
-	if err != nil {
-		return err
-	}
+			s.state = loopdb.StateFailInsufficientValue
+			return nil
+		}
 
-	sweepSuccessful := s.htlc.IsSuccessWitness(htlcInput.Witness)
-	if sweepSuccessful {
-		s.cost.Server -= htlcValue
+		// Try to spend htlc and continue (rbf) until a spend has
+		// confirmed.
+		spendDetails, err := s.waitForHtlcSpendConfirmed(globalCtx,
+			txConf.Tx, *htlcOutpoint,
+			func() error {
+				return s.sweep(
+					globalCtx, *htlcOutpoint, htlcValue,
+				)
+			},
+		)
+		if err != nil {
+			return err
+		}
 
-		s.cost.Onchain = htlcValue -
-			btcutil.Amount(spendDetails.SpendingTx.TxOut[0].Value)
+		// If the htlc tx was reorged out before it was spent, we wait
+		// for the htlc to confirm again.
+		if spendDetails == nil {
+			s.htlcOutpoint, s.htlcConfHeight = nil, 0
 
-		s.state = loopdb.StateSuccess
-	} else {
-		s.state = loopdb.StateFailSweepTimeout
-	}
+			s.reorged = true
+			err = s.sendUpdate(globalCtx)
+			s.reorged = false
+			if err != nil {
+				return err
+			}
 
-	return nil
+			continue
+		}
+
+		// Inspect witness stack to see if it is a success transaction.
+		// We don't just try to match with the hash of our sweep tx,
+		// because it may be swept by a different (fee) sweep tx from a
+		// previous run.
+		htlcInput, err := swap.GetTxInputByOutpoint(
+			spendDetails.SpendingTx, htlcOutpoint,
+		)
+		if err != nil {
+			return err
+		}
+
+		prevState, prevCost := s.state, s.cost
+		s.recordHtlcSpend(spendDetails)
+
+		sweepSuccessful := s.htlc.IsSuccessWitness(htlcInput.Witness)
+		if sweepSuccessful {
+			s.cost.Server -= htlcValue
+
+			// A batch sweep tx spends multiple htlcs, so we can't
+			// derive our fee from its outputs. Use the share of the
+			// fee that we recorded for it instead. Our own sweep
+			// pays everything that it doesn't send to the sweep
+			// destinations to the miner.
+			sweepTx := spendDetails.SpendingTx
+			if len(sweepTx.TxIn) > 1 {
+				s.cost.Onchain = s.sweepFee
+			} else {
+				s.cost.Onchain = htlcValue
+				for _, txOut := range sweepTx.TxOut {
+					s.cost.Onchain -= btcutil.Amount(
+						txOut.Value,
+					)
+				}
+			}
+
+			s.state = loopdb.StateSuccess
+		} else {
+			s.state = loopdb.StateFailSweepTimeout
+		}
+
+		// If we track the spend for reorgs, we announce the outcome
+		// right away. It is only persisted by executeAndFinalize once
+		// the spend is safe, so that we resume tracking it after a
+		// restart.
+		if s.reorgSafetyDepth > 1 {
+			if err := s.sendUpdate(globalCtx); err != nil {
+				return err
+			}
+		}
+
+		safe, err := s.waitForSafeConf(
+			globalCtx, &s.executeConfig, htlcOutpoint,
+			s.htlc.PkScript, spendDetails,
+		)
+		if err != nil {
+			return err
+		}
+
+		if safe {
+			return nil
+		}
+
+		// The spend was reorged out, so we revert to our previous state
+		// and sweep again. If the htlc was reorged out as well, we
+		// wait for it to confirm again first. Our previous state is
+		// still the last one on disk.
+		s.state, s.cost = prevState, prevCost
+		s.recordHtlcSpend(nil)
+
+		s.reorged = true
+		err = s.sendUpdate(globalCtx)
+		s.reorged = false
+		if err != nil {
+			return err
+		}
+	}
 }
 
 // persistState updates the swap state and sends out an update notification.
@@ -470,14 +615,7 @@ func (s *loopOutSwap) persistState(ctx context.Context) error {
 	s.lastUpdateTime = updateTime
 
 	// Update state in store.
-	err := s.store.UpdateLoopOut(
-		s.hash, updateTime,
-		loopdb.SwapStateData{
-			State:      s.state,
-			Cost:       s.cost,
-			HtlcTxHash: s.htlcTxHash,
-		},
-	)
+	err := s.store.UpdateLoopOut(s.hash, updateTime, s.stateData())
 	if err != nil {
 		return err
 	}
@@ -486,6 +624,21 @@ func (s *loopOutSwap) persistState(ctx context.Context) error {
 	return s.sendUpdate(ctx)
 }
 
+// stateData returns the swap state that is stored with a swap update.
+func (s *loopOutSwap) stateData() loopdb.SwapStateData {
+	return loopdb.SwapStateData{
+		State:           s.state,
+		Cost:            s.cost,
+		HtlcTxHash:      s.htlcTxHash,
+		SweepTxHash:     s.sweepTxHash,
+		SweepFee:        s.sweepFee,
+		HtlcOutpoint:    s.htlcOutpoint,
+		HtlcConfHeight:  s.htlcConfHeight,
+		SpendTxHash:     s.spendTxHash,
+		SpendConfHeight: s.spendConfHeight,
+	}
+}
+
 // payInvoices pays both swap invoices.
 func (s *loopOutSwap) payInvoices(ctx context.Context) {
 	// Pay the swap invoice.
@@ -493,21 +646,21 @@ func (s *loopOutSwap) payInvoices(ctx context.Context) {
 
 	s.swapPaymentChan = s.payInvoice(
 		ctx, s.SwapInvoice, s.MaxSwapRoutingFee,
-		s.LoopOutContract.OutgoingChanSet,
+		s.LoopOutContract.OutgoingChanSet, s.LoopOutContract.LastHop,
 	)
 
 	// Pay the prepay invoice.
 	s.log.Infof("Sending prepayment %v", s.PrepayInvoice)
 	s.prePaymentChan = s.payInvoice(
 		ctx, s.PrepayInvoice, s.MaxPrepayRoutingFee,
-		nil,
+		nil, nil,
 	)
 }
 
 // payInvoice pays a single invoice.
 func (s *loopOutSwap) payInvoice(ctx context.Context, invoice string,
-	maxFee btcutil.Amount,
-	outgoingChanIds loopdb.ChannelSet) chan lndclient.PaymentResult {
+	maxFee btcutil.Amount, outgoingChanIds loopdb.ChannelSet,
+	lastHop *route.Vertex) chan lndclient.PaymentResult {
 
 	resultChan := make(chan lndclient.PaymentResult)
 
@@ -515,7 +668,7 @@ func (s *loopOutSwap) payInvoice(ctx context.Context, invoice string,
 		var result lndclient.PaymentResult
 
 		status, err := s.payInvoiceAsync(
-			ctx, invoice, maxFee, outgoingChanIds,
+			ctx, invoice, maxFee, outgoingChanIds, lastHop,
 		)
 		if err != nil {
 			result.Err = err
@@ -537,7 +690,8 @@ func (s *loopOutSwap) payInvoice(ctx context.Context, invoice string,
 // payInvoiceAsync is the asynchronously executed part of paying an invoice.
 func (s *loopOutSwap) payInvoiceAsync(ctx context.Context,
 	invoice string, maxFee btcutil.Amount,
-	outgoingChanIds loopdb.ChannelSet) (*lndclient.PaymentStatus, error) {
+	outgoingChanIds loopdb.ChannelSet, lastHop *route.Vertex) (
+	*lndclient.PaymentStatus, error) {
 
 	// Extract hash from payment request. Unfortunately the request
 	// components aren't available directly.
@@ -553,6 +707,12 @@ func (s *loopOutSwap) payInvoiceAsync(ctx context.Context,
 		OutgoingChanIds: outgoingChanIds,
 		Timeout:         paymentTimeout,
 		MaxParts:        s.executeConfig.loopOutMaxParts,
+		LastHopPubkey:   lastHop,
+	}
+
+	if s.PaymentCltvLimit != 0 {
+		cltvLimit := s.PaymentCltvLimit
+		req.MaxCltv = &cltvLimit
 	}
 
 	// Lookup state of the swap payment.
@@ -749,20 +909,18 @@ func (s *loopOutSwap) waitForConfirmedHtlc(globalCtx context.Context) (
 	s.log.Infof("Htlc tx %v at height %v", htlcTxHash, txConf.BlockHeight)
 
 	s.htlcTxHash = &htlcTxHash
+	s.htlcConfHeight = int32(txConf.BlockHeight)
 
 	return txConf, nil
 }
 
 // waitForHtlcSpendConfirmed waits for the htlc to be spent either by our own
 // sweep or a server revocation tx. During this process, this function will try
-// to spend the htlc every block by calling spendFunc.
-//
-// TODO: Improve retry/fee increase mechanism. Once in the mempool, server can
-// sweep offchain. So we must make sure we sweep successfully before on-chain
-// timeout.
+// to spend the htlc every block by calling spendFunc. If the htlc tx is reorged
+// out before the htlc is spent, nil is returned without an error.
 func (s *loopOutSwap) waitForHtlcSpendConfirmed(globalCtx context.Context,
-	htlc wire.OutPoint, spendFunc func() error) (*chainntnfs.SpendDetail,
-	error) {
+	htlcTx *wire.MsgTx, htlc wire.OutPoint, spendFunc func() error) (
+	*chainntnfs.SpendDetail, error) {
 
 	// Register the htlc spend notification.
 	ctx, cancel := context.WithCancel(globalCtx)
@@ -784,6 +942,15 @@ func (s *loopOutSwap) waitForHtlcSpendConfirmed(globalCtx context.Context,
 		return nil, fmt.Errorf("track payment: %v", err)
 	}
 
+	// Track the htlc tx up to the safety depth, so that we notice if it
+	// is reorged out before we manage to spend it.
+	htlcTracker, err := s.trackHtlcConf(
+		ctx, &s.executeConfig, htlcTx, s.htlc.PkScript,
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	// paymentComplete tracks whether our payment is complete, and is used
 	// to decide whether we need to push our preimage to the server.
 	var paymentComplete bool
@@ -844,8 +1011,21 @@ func (s *loopOutSwap) waitForHtlcSpendConfirmed(globalCtx context.Context,
 		// timer.
 		case notification := <-s.blockEpochChan:
 			s.height = notification.(int32)
+			htlcTracker.notifyHeight(s.height)
 			timerChan = s.timerFactory(republishDelay)
 
+		// The htlc tx either reached the safety depth, so that we no
+		// longer need to track it, or it was reorged out.
+		case result := <-htlcTracker.results():
+			if result.err != nil {
+				return nil, result.err
+			}
+			if !result.safe {
+				return nil, nil
+			}
+
+			htlcTracker = nil
+
 		// Some time after start or after arrival of a new block, try
 		// to spend again.
 		case <-timerChan:
@@ -887,9 +1067,26 @@ func (s *loopOutSwap) pushPreimage(ctx context.Context) {
 	}
 }
 
+// sweepFeeMultiplier returns the factor by which we scale our sweep fee
+// estimate, based on the number of blocks that remain until the htlc expires.
+// Outside of the SweepFeeBumpDelta window the estimate is used as is, within
+// it the fee is raised stepwise with every block.
+func (s *loopOutSwap) sweepFeeMultiplier() float64 {
+	blocksLeft := s.CltvExpiry - s.height
+	if blocksLeft >= SweepFeeBumpDelta {
+		return 1
+	}
+
+	steps := SweepFeeBumpDelta - blocksLeft
+	return 1 + float64(steps)*SweepFeeBumpStep
+}
+
 // sweep tries to sweep the given htlc to a destination address. It takes into
 // account the max miner fee and marks the preimage as revealed when it
-// published the tx.
+// published the tx. When the htlc expiry approaches, the fee is bumped with
+// every new block so that our sweep replaces (rbf) any previous attempt that
+// did not confirm yet. If sweep batching is enabled, the htlc is swept
+// together with the htlcs of other swaps until the expiry approaches.
 //
 // TODO: Use lnd sweeper?
 func (s *loopOutSwap) sweep(ctx context.Context,
@@ -911,60 +1108,256 @@ func (s *loopOutSwap) sweep(ctx context.Context,
 		confTarget = DefaultSweepConfTarget
 	}
 	fee, err := s.sweeper.GetSweepFee(
-		ctx, s.htlc.AddSuccessToEstimator, s.DestAddr, confTarget,
+		ctx, s.htlc.AddSuccessToEstimator, s.Destinations(), confTarget,
 	)
 	if err != nil {
 		return err
 	}
 
+	// Scale the fee estimate if we are getting close to the htlc expiry.
+	// Once we have revealed our preimage, we also scale our maximum fee up
+	// to the configured multiplier, because failing to sweep before the
+	// server times out the htlc will cost us the full swap amount.
+	multiplier := s.sweepFeeMultiplier()
+	fee = btcutil.Amount(float64(fee) * multiplier)
+
+	maxMinerFee := s.MaxMinerFee
+	maxMultiplier := math.Min(multiplier, s.maxSweepFeeMultiplier)
+	if s.state == loopdb.StatePreimageRevealed && maxMultiplier > 1 {
+		maxMinerFee = btcutil.Amount(
+			float64(maxMinerFee) * maxMultiplier,
+		)
+	}
+
+	// Join the next sweep batch if batching is enabled and we are not
+	// close to our expiry yet. If the batch fails, we fall back to
+	// sweeping on our own.
+	batchSweep := s.batcher != nil &&
+		s.CltvExpiry-s.height > BatchSweepDeadlineDelta
+
+	if batchSweep && fee <= maxMinerFee {
+		err := s.batchSweep(
+			ctx, htlcOutpoint, htlcValue, fee, maxMinerFee,
+			witnessFunc,
+		)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		s.log.Warnf("Batch sweep failed, sweeping individually: %v",
+			err)
+	}
+
+	// If our last sweep was a batch that may still be in the mempool, our
+	// individual sweep only replaces it if it pays for the fee of the
+	// whole batch on top of its own fee. Once the batch is replaced, the
+	// other swaps of the batch sweep again in a new batch.
+	fee += s.batchFee
+
 	// Ensure it doesn't exceed our maximum fee allowed.
-	if fee > s.MaxMinerFee {
+	if fee > maxMinerFee {
 		s.log.Warnf("Required fee %v exceeds max miner fee of %v",
-			fee, s.MaxMinerFee)
+			fee, maxMinerFee)
 
 		if s.state == loopdb.StatePreimageRevealed {
 			// The currently required fee exceeds the max, but we
 			// already revealed the preimage. The best we can do now
 			// is to republish with the max fee.
-			fee = s.MaxMinerFee
+			fee = maxMinerFee
 		} else {
 			s.log.Warnf("Not revealing preimage")
 			return nil
 		}
 	}
 
+	// A replacement sweep is only accepted into the mempool if it pays at
+	// least the incremental relay fee more than the sweep it replaces. If
+	// our fee doesn't allow for that, we republish our previous sweep as
+	// is. After a restart we no longer have it, so we replace it with the
+	// minimum fee increase if our maximum fee allows for it.
+	if s.sweepTxHash != nil {
+		weight, err := sweep.GetSweepWeight(
+			s.htlc.AddSuccessToEstimator, s.Destinations(),
+		)
+		if err != nil {
+			return err
+		}
+
+		prevFee := s.sweepFee
+		if s.batchFee != 0 {
+			prevFee = s.batchFee
+		}
+		minFee := prevFee + sweep.IncrementalRelayFee(weight)
+
+		switch {
+		case fee >= minFee:
+
+		case s.sweepTx != nil:
+			s.log.Infof("Fee %v can't replace previous sweep with "+
+				"fee %v, republishing it", fee, prevFee)
+
+			s.publishSweep(ctx, s.sweepTx)
+
+			return nil
+
+		case minFee <= maxMinerFee:
+			fee = minFee
+
+		default:
+			s.log.Infof("Fee %v can't replace previous sweep with "+
+				"fee %v, waiting for it to confirm", fee,
+				prevFee)
+
+			return nil
+		}
+	}
+
 	// Create sweep tx.
 	sweepTx, err := s.sweeper.CreateSweepTx(
 		ctx, s.height, s.htlc.SuccessSequence(), s.htlc, htlcOutpoint,
-		s.ReceiverKey, witnessFunc, htlcValue, fee, s.DestAddr,
+		s.ReceiverKey, witnessFunc, htlcValue, fee, s.Destinations(),
 	)
 	if err != nil {
 		return err
 	}
 
-	// Before publishing the tx, already mark the preimage as revealed. This
-	// is a precaution in case the publish call never returns and would
-	// leave us thinking we didn't reveal yet.
-	if s.state != loopdb.StatePreimageRevealed {
+	// Before publishing the tx, already mark the preimage as revealed and
+	// record the sweep tx in our event history. This is a precaution in
+	// case the publish call never returns and would leave us thinking we
+	// didn't reveal yet.
+	sweepTxHash := sweepTx.TxHash()
+	if s.state != loopdb.StatePreimageRevealed || s.sweepTxHash == nil ||
+		*s.sweepTxHash != sweepTxHash {
+
 		s.state = loopdb.StatePreimageRevealed
+		s.sweepTxHash = &sweepTxHash
+		s.sweepFee = fee
+		s.batchFee = 0
 
 		err := s.persistState(ctx)
 		if err != nil {
 			return err
 		}
 	}
+	s.sweepTx = sweepTx
 
 	// Publish tx.
-	s.log.Infof("Sweep on chain HTLC to address %v with fee %v (tx %v)",
-		s.DestAddr, fee, sweepTx.TxHash())
+	s.log.Infof("Sweep on chain HTLC to %v with fee %v (tx %v)",
+		s.Destinations(), fee, sweepTxHash)
 
-	err = s.lnd.WalletKit.PublishTransaction(
+	s.publishSweep(ctx, sweepTx)
+
+	return nil
+}
+
+// publishSweep publishes our individual sweep tx. Errors are only logged,
+// because we will retry with the next sweep attempt.
+func (s *loopOutSwap) publishSweep(ctx context.Context, sweepTx *wire.MsgTx) {
+	err := s.lnd.WalletKit.PublishTransaction(
 		ctx, sweepTx,
 		labels.LoopOutSweepSuccess(swap.ShortHash(&s.hash)),
 	)
 	if err != nil {
 		s.log.Warnf("Publish sweep: %v", err)
 	}
+}
+
+// batchSweep sweeps the given htlc as part of the next sweep batch. The batch
+// charges us a share of the given fee, which it may raise up to maxFee to
+// replace our previous sweep.
+func (s *loopOutSwap) batchSweep(ctx context.Context,
+	htlcOutpoint wire.OutPoint, htlcValue, fee, maxFee btcutil.Amount,
+	witnessFunc func(sig []byte) (wire.TxWitness, error)) error {
+
+	s.log.Infof("Adding HTLC to sweep batch with fee %v (max %v)", fee,
+		maxFee)
+
+	prevFee := s.sweepFee
+	if s.batchFee != 0 {
+		prevFee = s.batchFee
+	}
+
+	result, err := s.batcher.sweep(ctx, &sweepRequest{
+		swapHash: s.hash,
+		input: &sweep.BatchInput{
+			Htlc:             s.htlc,
+			Outpoint:         htlcOutpoint,
+			Value:            htlcValue,
+			KeyBytes:         s.ReceiverKey,
+			Sequence:         s.htlc.SuccessSequence(),
+			WitnessFunc:      witnessFunc,
+			AddInputEstimate: s.htlc.AddSuccessToEstimator,
+			Destinations:     s.Destinations(),
+			Fee:              fee,
+			MaxFee:           maxFee,
+			PrevTxHash:       s.sweepTxHash,
+			PrevTxFee:        prevFee,
+		},
+		height: s.height,
+		state:  s.stateData(),
+	})
+	if err != nil {
+		return err
+	}
+
+	// If the batch could not be published, the batcher restored our
+	// previous sweep, but kept our preimage marked as revealed.
+	if result.err != nil {
+		if !result.updateTime.IsZero() {
+			s.state = loopdb.StatePreimageRevealed
+			s.lastUpdateTime = result.updateTime
+
+			if err := s.sendUpdate(ctx); err != nil {
+				return err
+			}
+		}
+
+		return result.err
+	}
+
+	// The batcher recorded the batch tx for us, so we only need to update
+	// our in-memory state and announce the new sweep.
+	s.batchFee = result.batchFee
+	if result.updateTime.IsZero() {
+		return nil
+	}
+
+	sweepTxHash := result.txHash
+	s.state = loopdb.StatePreimageRevealed
+	s.sweepTxHash = &sweepTxHash
+	s.sweepFee = result.fee
+	s.sweepTx = nil
+	s.lastUpdateTime = result.updateTime
+
+	return s.sendUpdate(ctx)
+}
+
+// validateSweepDestinations checks that the sweep destinations of a loop out
+// request all receive a share of the swept value and that they don't conflict
+// with the destination address of the request.
+func validateSweepDestinations(request *OutRequest) error {
+	if request.DestAddr != nil {
+		return errors.New("destination address and sweep destinations " +
+			"are mutually exclusive")
+	}
+
+	addrs := make(map[string]struct{}, len(request.SweepDestinations))
+	for _, dest := range request.SweepDestinations {
+		if dest.Addr == nil {
+			return errors.New("sweep destination without address")
+		}
+
+		if dest.Weight == 0 {
+			return fmt.Errorf("sweep destination %v has zero weight",
+				dest.Addr)
+		}
+
+		addr := dest.Addr.String()
+		if _, ok := addrs[addr]; ok {
+			return fmt.Errorf("duplicate sweep destination %v", addr)
+		}
+		addrs[addr] = struct{}{}
+	}
 
 	return nil
 }
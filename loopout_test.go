@@ -8,14 +8,17 @@ import (
 	"time"
 
 	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
 	"github.com/lightninglabs/lndclient"
 	"github.com/lightninglabs/loop/loopdb"
 	"github.com/lightninglabs/loop/sweep"
 	"github.com/lightninglabs/loop/test"
+	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+	"github.com/lightningnetwork/lnd/routing/route"
 	"github.com/stretchr/testify/require"
 )
 
@@ -53,6 +56,8 @@ func TestLoopOutPaymentParameters(t *testing.T) {
 	// Initiate the swap.
 	req := *testRequest
 	req.OutgoingChanSet = loopdb.ChannelSet{2, 3}
+	req.LastHop = &route.Vertex{1, 2, 3}
+	req.PaymentCltvLimit = 500
 
 	initResult, err := newLoopOutSwap(
 		context.Background(), cfg, height, &req,
@@ -94,10 +99,12 @@ func TestLoopOutPaymentParameters(t *testing.T) {
 	}
 
 	// Find the swap payment.
-	var swapPayment test.RouterPaymentChannelMessage
+	var swapPayment, prepayPayment test.RouterPaymentChannelMessage
 	for _, p := range payments {
 		if p.Invoice == swap.SwapInvoice {
 			swapPayment = p
+		} else {
+			prepayPayment = p
 		}
 	}
 
@@ -114,6 +121,16 @@ func TestLoopOutPaymentParameters(t *testing.T) {
 		t.Fatalf("Unexpected outgoing channel set")
 	}
 
+	// Verify that the last hop restriction only applies to the swap
+	// payment and that the cltv limit applies to both payments.
+	require.Equal(t, req.LastHop, swapPayment.LastHopPubkey)
+	require.Nil(t, prepayPayment.LastHopPubkey)
+
+	for _, p := range payments {
+		require.NotNil(t, p.MaxCltv)
+		require.Equal(t, req.PaymentCltvLimit, *p.MaxCltv)
+	}
+
 	// Swap is expected to register for confirmation of the htlc. Assert
 	// this to prevent a blocked channel in the mock.
 	ctx.AssertRegisterConf(false, defaultConfirmations)
@@ -386,10 +403,23 @@ func TestCustomSweepConfTarget(t *testing.T) {
 	// Expect another signing request.
 	<-ctx.Lnd.SignOutputRawChannel
 
+	// The new sweep replaces our previous attempt, so we expect it to be
+	// recorded in the swap's history before it is published.
+	cfg.store.(*storeMock).assertLoopOutState(loopdb.StatePreimageRevealed)
+	status = <-statusChan
+	require.Equal(t, loopdb.StatePreimageRevealed, status.State)
+
 	// We should expect to see another sweep using the higher fee since the
 	// spend hasn't been confirmed yet.
 	sweepTx := assertSweepTx(DefaultSweepConfTarget)
 
+	sweepTxHash := sweepTx.TxHash()
+	require.Equal(t, &sweepTxHash, status.SweepTxHash)
+	require.Equal(
+		t, btcutil.Amount(htlcTx.TxOut[0].Value-sweepTx.TxOut[0].Value),
+		status.SweepFee,
+	)
+
 	// Notify the spend so that the swap reaches its final state.
 	ctx.NotifySpend(sweepTx, 0)
 
@@ -543,7 +573,7 @@ func TestPreimagePush(t *testing.T) {
 	)
 
 	// We expect the sweep tx to have been published.
-	ctx.ReceiveTx()
+	firstSweep := ctx.ReceiveTx()
 
 	// Once we have published an on chain sweep, we expect a preimage to
 	// have been pushed to the server after the sweep.
@@ -556,10 +586,9 @@ func TestPreimagePush(t *testing.T) {
 	// tick the expiry chan again to prompt another sweep.
 	expiryChan <- testTime
 
-	// We expect another signing request for out sweep, and publish of our
-	// sweep transaction.
-	<-ctx.Lnd.SignOutputRawChannel
-	ctx.ReceiveTx()
+	// Our fee didn't rise, so a new sweep couldn't replace our previous
+	// one. We expect the previous sweep to be republished as is.
+	require.Equal(t, firstSweep.TxHash(), ctx.ReceiveTx().TxHash())
 
 	// Since we have not yet been notified of an off chain settle, and we
 	// have attempted to sweep again, we expect another preimage push
@@ -578,7 +607,6 @@ func TestPreimagePush(t *testing.T) {
 	// push. The test's mocked preimage channel is un-buffered, so our test
 	// would hang if we pushed the preimage here.
 	expiryChan <- testTime
-	<-ctx.Lnd.SignOutputRawChannel
 	sweepTx := ctx.ReceiveTx()
 
 	// Finally, we put this swap out of its misery and notify a successful
@@ -593,3 +621,317 @@ func TestPreimagePush(t *testing.T) {
 
 	require.NoError(t, <-errChan)
 }
+
+// TestSweepFeeMultiplier tests the scaling of our sweep fee as the htlc expiry
+// approaches.
+func TestSweepFeeMultiplier(t *testing.T) {
+	const expiry = 1000
+
+	tests := []struct {
+		name       string
+		height     int32
+		multiplier float64
+	}{
+		{
+			name:       "outside of bump window",
+			height:     expiry - SweepFeeBumpDelta - 1,
+			multiplier: 1,
+		},
+		{
+			name:       "start of bump window",
+			height:     expiry - SweepFeeBumpDelta,
+			multiplier: 1,
+		},
+		{
+			name:       "one block into bump window",
+			height:     expiry - SweepFeeBumpDelta + 1,
+			multiplier: 1 + SweepFeeBumpStep,
+		},
+		{
+			name:   "at expiry",
+			height: expiry,
+			multiplier: 1 + float64(SweepFeeBumpDelta)*
+				SweepFeeBumpStep,
+		},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			s := &loopOutSwap{
+				swapKit: swapKit{
+					height: testCase.height,
+				},
+			}
+			s.CltvExpiry = expiry
+
+			require.InDelta(
+				t, testCase.multiplier, s.sweepFeeMultiplier(),
+				1e-9,
+			)
+		})
+	}
+}
+
+// TestSweepReplacementFee tests that a sweep that replaces a previous sweep
+// that we no longer have after a restart pays at least the incremental relay
+// fee more than the previous one, and that our max miner fee is only raised up
+// to the configured multiplier.
+func TestSweepReplacementFee(t *testing.T) {
+	defer test.Guard(t)()
+
+	lnd := test.NewMockLnd()
+	ctx := test.NewContext(t, lnd)
+	server := newServerMock(lnd)
+
+	cfg := newSwapConfig(&lnd.LndServices, newStoreMock(t), server)
+	store := cfg.store.(*storeMock)
+
+	testReq := *testRequest
+	testReq.Expiry = ctx.Lnd.Height + testLoopOutMinOnChainCltvDelta
+
+	initResult, err := newLoopOutSwap(
+		context.Background(), cfg, ctx.Lnd.Height, &testReq,
+	)
+	require.NoError(t, err)
+	s := initResult.swap
+	store.assertLoopOutStored()
+
+	sweeper := &sweep.Sweeper{Lnd: &lnd.LndServices}
+	statusChan := make(chan SwapInfo, 1)
+	s.executeConfig = executeConfig{
+		statusChan:            statusChan,
+		sweeper:               sweeper,
+		maxSweepFeeMultiplier: 1,
+	}
+
+	fee, err := sweeper.GetSweepFee(
+		context.Background(), s.htlc.AddSuccessToEstimator,
+		s.Destinations(), testReq.SweepConfTarget,
+	)
+	require.NoError(t, err)
+
+	weight, err := sweep.GetSweepWeight(
+		s.htlc.AddSuccessToEstimator, s.Destinations(),
+	)
+	require.NoError(t, err)
+
+	// We restarted after publishing a sweep that pays the current fee
+	// estimate, which is also our max miner fee.
+	prevSweepHash := chainhash.Hash{1}
+	s.state = loopdb.StatePreimageRevealed
+	s.sweepTxHash = &prevSweepHash
+	s.sweepFee = fee
+	s.MaxMinerFee = fee
+
+	// sweepHtlc runs a sweep attempt and returns the update that records
+	// the new sweep, or nil if no sweep was published.
+	sweepHtlc := func() *loopdb.SwapStateData {
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- s.sweep(
+				context.Background(), wire.OutPoint{},
+				testReq.Amount,
+			)
+		}()
+
+		select {
+		case err := <-errChan:
+			require.NoError(t, err)
+			return nil
+
+		case <-lnd.SignOutputRawChannel:
+		}
+
+		update := <-store.loopOutUpdateChan
+		<-statusChan
+		ctx.ReceiveTx()
+		require.NoError(t, <-errChan)
+
+		return &update
+	}
+
+	// Close to the expiry, our fee estimate is scaled up. We don't raise
+	// our max miner fee though, so we can't replace our previous sweep.
+	s.height = s.CltvExpiry - 1
+	require.Nil(t, sweepHtlc())
+
+	// Once our max miner fee allows for it, we replace our previous sweep
+	// with the minimum fee increase, even though our fee estimate didn't
+	// rise.
+	s.height = ctx.Lnd.Height
+	s.MaxMinerFee = fee * 2
+
+	update := sweepHtlc()
+	require.NotNil(t, update)
+	require.Equal(t, fee+sweep.IncrementalRelayFee(weight), update.SweepFee)
+}
+
+// TestLoopOutSweepReorg tests that a loop out swap waits for its htlc to
+// confirm again if the htlc tx is reorged out before it is swept, that it
+// reverts to its previous state and sweeps again if its sweep is reorged out
+// before it reaches the safety depth, and that it completes once a sweep is
+// safe.
+func TestLoopOutSweepReorg(t *testing.T) {
+	defer test.Guard(t)()
+
+	lnd := test.NewMockLnd()
+	ctx := test.NewContext(t, lnd)
+	server := newServerMock(lnd)
+
+	cfg := newSwapConfig(&lnd.LndServices, newStoreMock(t), server)
+	store := cfg.store.(*storeMock)
+
+	testReq := *testRequest
+	testReq.Expiry = ctx.Lnd.Height + testLoopOutMinOnChainCltvDelta
+
+	initResult, err := newLoopOutSwap(
+		context.Background(), cfg, ctx.Lnd.Height, &testReq,
+	)
+	require.NoError(t, err)
+	swap := initResult.swap
+
+	blockEpochChan := make(chan interface{})
+	statusChan := make(chan SwapInfo)
+	expiryChan := make(chan time.Time)
+	timerFactory := func(_ time.Duration) <-chan time.Time {
+		return expiryChan
+	}
+
+	errChan := make(chan error)
+	go func() {
+		err := swap.execute(context.Background(), &executeConfig{
+			statusChan:       statusChan,
+			blockEpochChan:   blockEpochChan,
+			timerFactory:     timerFactory,
+			sweeper:          &sweep.Sweeper{Lnd: &lnd.LndServices},
+			reorgSafetyDepth: 3,
+		}, ctx.Lnd.Height)
+		if err != nil {
+			log.Error(err)
+		}
+		errChan <- err
+	}()
+
+	store.assertLoopOutStored()
+	state := <-statusChan
+	require.Equal(t, loopdb.StateInitiated, state.State)
+
+	ctx.AssertPaid(swapInvoiceDesc)(nil)
+	ctx.AssertPaid(prepayInvoiceDesc)(nil)
+
+	htlcTx := wire.NewMsgTx(2)
+	htlcTx.AddTxOut(&wire.TxOut{
+		Value:    int64(swap.AmountRequested),
+		PkScript: swap.htlc.PkScript,
+	})
+
+	ctx.AssertRegisterConf(false, defaultConfirmations)
+	ctx.NotifyConf(htlcTx)
+
+	// sweepHtlc lets the client sweep the confirmed htlc and returns the
+	// sweep tx along with the update that announces it.
+	sweepHtlc := func() (*wire.MsgTx, SwapInfo) {
+		ctx.AssertRegisterSpendNtfn(swap.htlc.PkScript)
+		ctx.AssertTrackPayment()
+		ctx.AssertRegisterConf(true, 3)
+
+		expiryChan <- testTime
+
+		<-ctx.Lnd.SignOutputRawChannel
+		store.assertLoopOutState(loopdb.StatePreimageRevealed)
+		status := <-statusChan
+		require.Equal(t, loopdb.StatePreimageRevealed, status.State)
+
+		sweepTx := ctx.ReceiveTx()
+		<-server.preimagePush
+
+		return sweepTx, status
+	}
+
+	// spendHtlc confirms the sweep tx at the given height and asserts that
+	// the swap succeeds and tracks the sweep up to the safety depth.
+	spendHtlc := func(sweepTx *wire.MsgTx, height int32) {
+		lnd.SpendChannel <- &chainntnfs.SpendDetail{
+			SpendingTx:        sweepTx,
+			SpenderInputIndex: 0,
+			SpendingHeight:    height,
+		}
+
+		// The outcome is announced, but only persisted once the
+		// sweep is safe.
+		status := <-statusChan
+		require.Equal(t, loopdb.StateSuccess, status.State)
+
+		ctx.AssertRegisterConf(true, 3)
+	}
+
+	// The htlc tx doesn't reach the safety depth in time and lnd doesn't
+	// report it as confirmed anymore by the next block, so it was reorged
+	// out before we swept it. We expect the swap to wait for the htlc to
+	// confirm again.
+	ctx.AssertRegisterSpendNtfn(swap.htlc.PkScript)
+	ctx.AssertTrackPayment()
+	ctx.AssertRegisterConf(true, 3)
+
+	blockEpochChan <- ctx.Lnd.Height + 1
+	ctx.AssertRegisterConf(true, 1)
+	blockEpochChan <- ctx.Lnd.Height + 2
+
+	status := <-statusChan
+	require.Equal(t, loopdb.StateInitiated, status.State)
+	require.True(t, status.Reorged)
+
+	ctx.AssertRegisterConf(true, defaultConfirmations)
+	ctx.NotifyConf(htlcTx)
+
+	sweepTx, revealed := sweepHtlc()
+	spendHtlc(sweepTx, ctx.Lnd.Height+1)
+
+	// The sweep doesn't reach the safety depth in time, so we check which
+	// tx spends the htlc. It is spent by the server's timeout tx, so our
+	// sweep was reorged out. We expect the swap to go back to its previous
+	// state.
+	blockEpochChan <- ctx.Lnd.Height + 4
+
+	timeoutTx := wire.NewMsgTx(2)
+	timeoutTx.AddTxIn(&wire.TxIn{})
+
+	ctx.AssertRegisterSpendNtfn(swap.htlc.PkScript)
+	lnd.SpendChannel <- &chainntnfs.SpendDetail{
+		SpendingTx:     timeoutTx,
+		SpendingHeight: ctx.Lnd.Height + 4,
+	}
+
+	status = <-statusChan
+	require.Equal(t, loopdb.StatePreimageRevealed, status.State)
+	require.Equal(t, revealed.Cost, status.Cost)
+	require.True(t, status.Reorged)
+
+	// The client waits for the htlc to confirm again and republishes its
+	// sweep. The fee didn't change, so it republishes the same tx.
+	ctx.AssertRegisterConf(true, defaultConfirmations)
+	ctx.NotifyConf(htlcTx)
+
+	ctx.AssertRegisterSpendNtfn(swap.htlc.PkScript)
+	ctx.AssertTrackPayment()
+	ctx.AssertRegisterConf(true, 3)
+
+	expiryChan <- testTime
+
+	require.Equal(t, sweepTx.TxHash(), ctx.ReceiveTx().TxHash())
+	<-server.preimagePush
+
+	spendHtlc(sweepTx, ctx.Lnd.Height+5)
+
+	// This time, the sweep reaches the safety depth and the swap
+	// completes.
+	ctx.NotifyConf(sweepTx)
+
+	store.assertLoopOutState(loopdb.StateSuccess)
+	status = <-statusChan
+	require.Equal(t, loopdb.StateSuccess, status.State)
+
+	require.NoError(t, <-errChan)
+}
@@ -293,6 +293,68 @@ func (AutoReason) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_014de31d7ac8c57c, []int{4}
 }
 
+type ListSwapsFilter_SwapTypeFilter int32
+
+const (
+	// ANY indicates that swaps of both types are returned.
+	ListSwapsFilter_ANY ListSwapsFilter_SwapTypeFilter = 0
+	// LOOP_OUT indicates that only loop out swaps are returned.
+	ListSwapsFilter_LOOP_OUT ListSwapsFilter_SwapTypeFilter = 1
+	// LOOP_IN indicates that only loop in swaps are returned.
+	ListSwapsFilter_LOOP_IN ListSwapsFilter_SwapTypeFilter = 2
+)
+
+var ListSwapsFilter_SwapTypeFilter_name = map[int32]string{
+	0: "ANY",
+	1: "LOOP_OUT",
+	2: "LOOP_IN",
+}
+
+var ListSwapsFilter_SwapTypeFilter_value = map[string]int32{
+	"ANY":      0,
+	"LOOP_OUT": 1,
+	"LOOP_IN":  2,
+}
+
+func (x ListSwapsFilter_SwapTypeFilter) String() string {
+	return proto.EnumName(ListSwapsFilter_SwapTypeFilter_name, int32(x))
+}
+
+func (ListSwapsFilter_SwapTypeFilter) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{12, 0}
+}
+
+type ListSwapsFilter_StateTypeFilter int32
+
+const (
+	// PENDING indicates swaps that are still in progress.
+	ListSwapsFilter_PENDING ListSwapsFilter_StateTypeFilter = 0
+	// SUCCEEDED indicates swaps that completed successfully.
+	ListSwapsFilter_SUCCEEDED ListSwapsFilter_StateTypeFilter = 1
+	// FAILED indicates swaps that failed.
+	ListSwapsFilter_FAILED ListSwapsFilter_StateTypeFilter = 2
+)
+
+var ListSwapsFilter_StateTypeFilter_name = map[int32]string{
+	0: "PENDING",
+	1: "SUCCEEDED",
+	2: "FAILED",
+}
+
+var ListSwapsFilter_StateTypeFilter_value = map[string]int32{
+	"PENDING":   0,
+	"SUCCEEDED": 1,
+	"FAILED":    2,
+}
+
+func (x ListSwapsFilter_StateTypeFilter) String() string {
+	return proto.EnumName(ListSwapsFilter_StateTypeFilter_name, int32(x))
+}
+
+func (ListSwapsFilter_StateTypeFilter) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{12, 1}
+}
+
 type LoopOutRequest struct {
 	//
 	//Requested swap amount in sat. This does not include the swap and miner fee.
@@ -371,7 +433,32 @@ type LoopOutRequest struct {
 	//initiator part is meant for user interfaces to add their name to give the
 	//full picture of the binary used (loopd, LiT) and the method used for
 	//triggering the swap (loop CLI, autolooper, LiT UI, other 3rd party UI).
-	Initiator            string   `protobuf:"bytes,14,opt,name=initiator,proto3" json:"initiator,omitempty"`
+	Initiator string `protobuf:"bytes,14,opt,name=initiator,proto3" json:"initiator,omitempty"`
+	//
+	//The optional pubkey of the last hop that the off-chain swap payment must be
+	//routed through. If empty, any last hop may be used.
+	LastHop []byte `protobuf:"bytes,15,opt,name=last_hop,json=lastHop,proto3" json:"last_hop,omitempty"`
+	//
+	//The optional maximum total time lock of the routes used for the off-chain
+	//swap and prepay payments. If zero, lnd's default limit is used.
+	PaymentCltvLimit int32 `protobuf:"varint,16,opt,name=payment_cltv_limit,json=paymentCltvLimit,proto3" json:"payment_cltv_limit,omitempty"`
+	//
+	//The optional pubkey of a peer to loop out from. If set, the swap payment is
+	//restricted to the channels that are active with this peer at the time of
+	//the request. Cannot be combined with outgoing_chan_set.
+	OutgoingPeer []byte `protobuf:"bytes,17,opt,name=outgoing_peer,json=outgoingPeer,proto3" json:"outgoing_peer,omitempty"`
+	//
+	//Optional destinations that the swept funds are split across, according to
+	//their weights. The sweep fee is deducted from the destinations in
+	//proportion to their weights. Every additional destination adds an output
+	//to the sweep transaction, which increases the sweep fee beyond the miner
+	//fee that is quoted for a single destination. Cannot be combined with dest.
+	SweepDestinations []*SweepDestination `protobuf:"bytes,18,rep,name=sweep_destinations,json=sweepDestinations,proto3" json:"sweep_destinations,omitempty"`
+	//
+	//The pubkeys of nodes that the off-chain swap payment must not be routed
+	//through. Excluding nodes is not supported by the lnd version that loop is
+	//built against yet, so requests that set this field are rejected.
+	ExcludedNodes        [][]byte `protobuf:"bytes,19,rep,name=excluded_nodes,json=excludedNodes,proto3" json:"excluded_nodes,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -501,6 +588,94 @@ func (m *LoopOutRequest) GetInitiator() string {
 	return ""
 }
 
+func (m *LoopOutRequest) GetLastHop() []byte {
+	if m != nil {
+		return m.LastHop
+	}
+	return nil
+}
+
+func (m *LoopOutRequest) GetPaymentCltvLimit() int32 {
+	if m != nil {
+		return m.PaymentCltvLimit
+	}
+	return 0
+}
+
+func (m *LoopOutRequest) GetOutgoingPeer() []byte {
+	if m != nil {
+		return m.OutgoingPeer
+	}
+	return nil
+}
+
+func (m *LoopOutRequest) GetSweepDestinations() []*SweepDestination {
+	if m != nil {
+		return m.SweepDestinations
+	}
+	return nil
+}
+
+func (m *LoopOutRequest) GetExcludedNodes() [][]byte {
+	if m != nil {
+		return m.ExcludedNodes
+	}
+	return nil
+}
+
+type SweepDestination struct {
+	//
+	//The address that receives a share of the swept funds.
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	//
+	//The weight of this destination. The destination receives the share of
+	//the swept funds that corresponds to its weight relative to the total
+	//weight of all destinations.
+	Weight               uint32   `protobuf:"varint,2,opt,name=weight,proto3" json:"weight,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SweepDestination) Reset()         { *m = SweepDestination{} }
+func (m *SweepDestination) String() string { return proto.CompactTextString(m) }
+func (*SweepDestination) ProtoMessage()    {}
+func (*SweepDestination) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{1}
+}
+
+func (m *SweepDestination) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SweepDestination.Unmarshal(m, b)
+}
+func (m *SweepDestination) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SweepDestination.Marshal(b, m, deterministic)
+}
+func (m *SweepDestination) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SweepDestination.Merge(m, src)
+}
+func (m *SweepDestination) XXX_Size() int {
+	return xxx_messageInfo_SweepDestination.Size(m)
+}
+func (m *SweepDestination) XXX_DiscardUnknown() {
+	xxx_messageInfo_SweepDestination.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SweepDestination proto.InternalMessageInfo
+
+func (m *SweepDestination) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *SweepDestination) GetWeight() uint32 {
+	if m != nil {
+		return m.Weight
+	}
+	return 0
+}
+
 type LoopInRequest struct {
 	//
 	//Requested swap amount in sat. This does not include the swap and miner
@@ -540,7 +715,16 @@ type LoopInRequest struct {
 	//initiator part is meant for user interfaces to add their name to give the
 	//full picture of the binary used (loopd, LiT) and the method used for
 	//triggering the swap (loop CLI, autolooper, LiT UI, other 3rd party UI).
-	Initiator            string   `protobuf:"bytes,8,opt,name=initiator,proto3" json:"initiator,omitempty"`
+	Initiator string `protobuf:"bytes,8,opt,name=initiator,proto3" json:"initiator,omitempty"`
+	//
+	//An optional list of wallet outpoints in the format txid:index that fund
+	//the on-chain htlc. If empty, lnd selects the outpoints. Outpoints cannot
+	//be set for external htlcs.
+	Outpoints []string `protobuf:"bytes,9,rep,name=outpoints,proto3" json:"outpoints,omitempty"`
+	//
+	//An optional address that receives the change of the htlc transaction if
+	//outpoints are set. If empty, a new wallet address is used.
+	ChangeAddress        string   `protobuf:"bytes,10,opt,name=change_address,json=changeAddress,proto3" json:"change_address,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -550,7 +734,7 @@ func (m *LoopInRequest) Reset()         { *m = LoopInRequest{} }
 func (m *LoopInRequest) String() string { return proto.CompactTextString(m) }
 func (*LoopInRequest) ProtoMessage()    {}
 func (*LoopInRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{1}
+	return fileDescriptor_014de31d7ac8c57c, []int{2}
 }
 
 func (m *LoopInRequest) XXX_Unmarshal(b []byte) error {
@@ -627,6 +811,74 @@ func (m *LoopInRequest) GetInitiator() string {
 	return ""
 }
 
+func (m *LoopInRequest) GetOutpoints() []string {
+	if m != nil {
+		return m.Outpoints
+	}
+	return nil
+}
+
+func (m *LoopInRequest) GetChangeAddress() string {
+	if m != nil {
+		return m.ChangeAddress
+	}
+	return ""
+}
+
+type LoopInBatchRequest struct {
+	//
+	//The loop in swaps to initiate. External htlcs and outpoints are not
+	//supported in a batch, and the htlc confirmation targets of the individual
+	//requests are ignored in favor of the confirmation target of the batch.
+	Requests []*LoopInRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	//
+	//The number of blocks that the transaction that funds all htlcs should
+	//confirm within.
+	HtlcConfTarget       int32    `protobuf:"varint,2,opt,name=htlc_conf_target,json=htlcConfTarget,proto3" json:"htlc_conf_target,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LoopInBatchRequest) Reset()         { *m = LoopInBatchRequest{} }
+func (m *LoopInBatchRequest) String() string { return proto.CompactTextString(m) }
+func (*LoopInBatchRequest) ProtoMessage()    {}
+func (*LoopInBatchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{3}
+}
+
+func (m *LoopInBatchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LoopInBatchRequest.Unmarshal(m, b)
+}
+func (m *LoopInBatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LoopInBatchRequest.Marshal(b, m, deterministic)
+}
+func (m *LoopInBatchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LoopInBatchRequest.Merge(m, src)
+}
+func (m *LoopInBatchRequest) XXX_Size() int {
+	return xxx_messageInfo_LoopInBatchRequest.Size(m)
+}
+func (m *LoopInBatchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_LoopInBatchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LoopInBatchRequest proto.InternalMessageInfo
+
+func (m *LoopInBatchRequest) GetRequests() []*LoopInRequest {
+	if m != nil {
+		return m.Requests
+	}
+	return nil
+}
+
+func (m *LoopInBatchRequest) GetHtlcConfTarget() int32 {
+	if m != nil {
+		return m.HtlcConfTarget
+	}
+	return 0
+}
+
 type SwapResponse struct {
 	//
 	//Swap identifier to track status in the update stream that is returned from
@@ -657,7 +909,13 @@ type SwapResponse struct {
 	//Used for both loop-in and loop-out.
 	HtlcAddressP2Wsh string `protobuf:"bytes,5,opt,name=htlc_address_p2wsh,json=htlcAddressP2wsh,proto3" json:"htlc_address_p2wsh,omitempty"`
 	// A human-readable message received from the loop server.
-	ServerMessage        string   `protobuf:"bytes,6,opt,name=server_message,json=serverMessage,proto3" json:"server_message,omitempty"`
+	ServerMessage string `protobuf:"bytes,6,opt,name=server_message,json=serverMessage,proto3" json:"server_message,omitempty"`
+	//
+	//An unsigned psbt that pays the swap amount to the native segwit htlc. It
+	//can be funded and signed by an external wallet and then be published with
+	//LoopInPublishPsbt. This field is only set for loop-in swaps with an
+	//external htlc.
+	HtlcPsbt             []byte   `protobuf:"bytes,7,opt,name=htlc_psbt,json=htlcPsbt,proto3" json:"htlc_psbt,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -667,7 +925,7 @@ func (m *SwapResponse) Reset()         { *m = SwapResponse{} }
 func (m *SwapResponse) String() string { return proto.CompactTextString(m) }
 func (*SwapResponse) ProtoMessage()    {}
 func (*SwapResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{2}
+	return fileDescriptor_014de31d7ac8c57c, []int{4}
 }
 
 func (m *SwapResponse) XXX_Unmarshal(b []byte) error {
@@ -732,6 +990,159 @@ func (m *SwapResponse) GetServerMessage() string {
 	return ""
 }
 
+func (m *SwapResponse) GetHtlcPsbt() []byte {
+	if m != nil {
+		return m.HtlcPsbt
+	}
+	return nil
+}
+
+type LoopInBatchResponse struct {
+	//
+	//The swaps that were initiated, in the order of the requests.
+	Swaps []*SwapResponse `protobuf:"bytes,1,rep,name=swaps,proto3" json:"swaps,omitempty"`
+	//
+	//The id of the transaction that funds the htlcs of all swaps.
+	HtlcTxid             string   `protobuf:"bytes,2,opt,name=htlc_txid,json=htlcTxid,proto3" json:"htlc_txid,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LoopInBatchResponse) Reset()         { *m = LoopInBatchResponse{} }
+func (m *LoopInBatchResponse) String() string { return proto.CompactTextString(m) }
+func (*LoopInBatchResponse) ProtoMessage()    {}
+func (*LoopInBatchResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{5}
+}
+
+func (m *LoopInBatchResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LoopInBatchResponse.Unmarshal(m, b)
+}
+func (m *LoopInBatchResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LoopInBatchResponse.Marshal(b, m, deterministic)
+}
+func (m *LoopInBatchResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LoopInBatchResponse.Merge(m, src)
+}
+func (m *LoopInBatchResponse) XXX_Size() int {
+	return xxx_messageInfo_LoopInBatchResponse.Size(m)
+}
+func (m *LoopInBatchResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_LoopInBatchResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LoopInBatchResponse proto.InternalMessageInfo
+
+func (m *LoopInBatchResponse) GetSwaps() []*SwapResponse {
+	if m != nil {
+		return m.Swaps
+	}
+	return nil
+}
+
+func (m *LoopInBatchResponse) GetHtlcTxid() string {
+	if m != nil {
+		return m.HtlcTxid
+	}
+	return ""
+}
+
+type LoopInPublishPsbtRequest struct {
+	//
+	//The swap identifier which currently is the hash that locks the htlcs.
+	Id []byte `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	//
+	//The signed psbt that funds the swap's htlc. Psbts whose inputs are signed
+	//but not yet finalized are finalized before the transaction is extracted.
+	//The utxo of every input must be included, so that the fee of the
+	//transaction can be added to the on-chain cost of the swap.
+	SignedPsbt           []byte   `protobuf:"bytes,2,opt,name=signed_psbt,json=signedPsbt,proto3" json:"signed_psbt,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LoopInPublishPsbtRequest) Reset()         { *m = LoopInPublishPsbtRequest{} }
+func (m *LoopInPublishPsbtRequest) String() string { return proto.CompactTextString(m) }
+func (*LoopInPublishPsbtRequest) ProtoMessage()    {}
+func (*LoopInPublishPsbtRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{6}
+}
+
+func (m *LoopInPublishPsbtRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LoopInPublishPsbtRequest.Unmarshal(m, b)
+}
+func (m *LoopInPublishPsbtRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LoopInPublishPsbtRequest.Marshal(b, m, deterministic)
+}
+func (m *LoopInPublishPsbtRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LoopInPublishPsbtRequest.Merge(m, src)
+}
+func (m *LoopInPublishPsbtRequest) XXX_Size() int {
+	return xxx_messageInfo_LoopInPublishPsbtRequest.Size(m)
+}
+func (m *LoopInPublishPsbtRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_LoopInPublishPsbtRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LoopInPublishPsbtRequest proto.InternalMessageInfo
+
+func (m *LoopInPublishPsbtRequest) GetId() []byte {
+	if m != nil {
+		return m.Id
+	}
+	return nil
+}
+
+func (m *LoopInPublishPsbtRequest) GetSignedPsbt() []byte {
+	if m != nil {
+		return m.SignedPsbt
+	}
+	return nil
+}
+
+type LoopInPublishPsbtResponse struct {
+	//
+	//The id of the published htlc transaction.
+	HtlcTxid             string   `protobuf:"bytes,1,opt,name=htlc_txid,json=htlcTxid,proto3" json:"htlc_txid,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LoopInPublishPsbtResponse) Reset()         { *m = LoopInPublishPsbtResponse{} }
+func (m *LoopInPublishPsbtResponse) String() string { return proto.CompactTextString(m) }
+func (*LoopInPublishPsbtResponse) ProtoMessage()    {}
+func (*LoopInPublishPsbtResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{7}
+}
+
+func (m *LoopInPublishPsbtResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LoopInPublishPsbtResponse.Unmarshal(m, b)
+}
+func (m *LoopInPublishPsbtResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LoopInPublishPsbtResponse.Marshal(b, m, deterministic)
+}
+func (m *LoopInPublishPsbtResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LoopInPublishPsbtResponse.Merge(m, src)
+}
+func (m *LoopInPublishPsbtResponse) XXX_Size() int {
+	return xxx_messageInfo_LoopInPublishPsbtResponse.Size(m)
+}
+func (m *LoopInPublishPsbtResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_LoopInPublishPsbtResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LoopInPublishPsbtResponse proto.InternalMessageInfo
+
+func (m *LoopInPublishPsbtResponse) GetHtlcTxid() string {
+	if m != nil {
+		return m.HtlcTxid
+	}
+	return ""
+}
+
 type MonitorRequest struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -742,7 +1153,7 @@ func (m *MonitorRequest) Reset()         { *m = MonitorRequest{} }
 func (m *MonitorRequest) String() string { return proto.CompactTextString(m) }
 func (*MonitorRequest) ProtoMessage()    {}
 func (*MonitorRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{3}
+	return fileDescriptor_014de31d7ac8c57c, []int{8}
 }
 
 func (m *MonitorRequest) XXX_Unmarshal(b []byte) error {
@@ -813,7 +1224,45 @@ type SwapStatus struct {
 	// Off-chain routing fees
 	CostOffchain int64 `protobuf:"varint,10,opt,name=cost_offchain,json=costOffchain,proto3" json:"cost_offchain,omitempty"`
 	// An optional label given to the swap on creation.
-	Label                string   `protobuf:"bytes,15,opt,name=label,proto3" json:"label,omitempty"`
+	Label string `protobuf:"bytes,15,opt,name=label,proto3" json:"label,omitempty"`
+	//
+	//The fee paid by the most recently published transaction sweeping the
+	//on-chain htlc. This fee is raised as the htlc expiry approaches, so it
+	//may change with every update of a pending swap.
+	SweepFee int64 `protobuf:"varint,16,opt,name=sweep_fee,json=sweepFee,proto3" json:"sweep_fee,omitempty"`
+	//
+	//Set on the update that is sent when a transaction of the swap was removed
+	//from the chain by a reorg. The swap reverted to the state that it was in
+	//before the transaction confirmed, and the transaction is republished.
+	Reorged bool `protobuf:"varint,17,opt,name=reorged,proto3" json:"reorged,omitempty"`
+	// The optional initiator that was set when the swap was created.
+	Initiator string `protobuf:"bytes,18,opt,name=initiator,proto3" json:"initiator,omitempty"`
+	//
+	//The state transitions of the swap that are stored in the database, in the
+	//order that they happened. This is only set by SwapInfo.
+	Events []*SwapEvent `protobuf:"bytes,19,rep,name=events,proto3" json:"events,omitempty"`
+	// The txid of the confirmed htlc transaction, if known.
+	HtlcTxid string `protobuf:"bytes,20,opt,name=htlc_txid,json=htlcTxid,proto3" json:"htlc_txid,omitempty"`
+	// The outpoint of the confirmed htlc in the format txid:index, if known.
+	HtlcOutpoint string `protobuf:"bytes,21,opt,name=htlc_outpoint,json=htlcOutpoint,proto3" json:"htlc_outpoint,omitempty"`
+	// The height at which the htlc confirmed, or zero if it isn't known.
+	HtlcConfHeight int32 `protobuf:"varint,22,opt,name=htlc_conf_height,json=htlcConfHeight,proto3" json:"htlc_conf_height,omitempty"`
+	//
+	//The txid of the most recently published transaction sweeping the
+	//on-chain htlc. For loop out swaps this is our sweep transaction, for loop
+	//in swaps it is our timeout transaction.
+	SweepTxid string `protobuf:"bytes,23,opt,name=sweep_txid,json=sweepTxid,proto3" json:"sweep_txid,omitempty"`
+	//
+	//The txid of the confirmed transaction that spent the htlc. This is our
+	//sweep or timeout transaction, or the sweep transaction of the server.
+	SpendTxid string `protobuf:"bytes,24,opt,name=spend_txid,json=spendTxid,proto3" json:"spend_txid,omitempty"`
+	//
+	//The height at which the transaction that spent the htlc confirmed, or zero
+	//if it isn't known.
+	SpendConfHeight int32 `protobuf:"varint,25,opt,name=spend_conf_height,json=spendConfHeight,proto3" json:"spend_conf_height,omitempty"`
+	//
+	//Whether the swap completed and has been archived.
+	Archived             bool     `protobuf:"varint,26,opt,name=archived,proto3" json:"archived,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -823,7 +1272,7 @@ func (m *SwapStatus) Reset()         { *m = SwapStatus{} }
 func (m *SwapStatus) String() string { return proto.CompactTextString(m) }
 func (*SwapStatus) ProtoMessage()    {}
 func (*SwapStatus) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{4}
+	return fileDescriptor_014de31d7ac8c57c, []int{9}
 }
 
 func (m *SwapStatus) XXX_Unmarshal(b []byte) error {
@@ -882,120 +1331,512 @@ func (m *SwapStatus) GetState() SwapState {
 
 func (m *SwapStatus) GetFailureReason() FailureReason {
 	if m != nil {
-		return m.FailureReason
+		return m.FailureReason
+	}
+	return FailureReason_FAILURE_REASON_NONE
+}
+
+func (m *SwapStatus) GetInitiationTime() int64 {
+	if m != nil {
+		return m.InitiationTime
+	}
+	return 0
+}
+
+func (m *SwapStatus) GetLastUpdateTime() int64 {
+	if m != nil {
+		return m.LastUpdateTime
+	}
+	return 0
+}
+
+// Deprecated: Do not use.
+func (m *SwapStatus) GetHtlcAddress() string {
+	if m != nil {
+		return m.HtlcAddress
+	}
+	return ""
+}
+
+func (m *SwapStatus) GetHtlcAddressP2Wsh() string {
+	if m != nil {
+		return m.HtlcAddressP2Wsh
+	}
+	return ""
+}
+
+func (m *SwapStatus) GetHtlcAddressNp2Wsh() string {
+	if m != nil {
+		return m.HtlcAddressNp2Wsh
+	}
+	return ""
+}
+
+func (m *SwapStatus) GetCostServer() int64 {
+	if m != nil {
+		return m.CostServer
+	}
+	return 0
+}
+
+func (m *SwapStatus) GetCostOnchain() int64 {
+	if m != nil {
+		return m.CostOnchain
+	}
+	return 0
+}
+
+func (m *SwapStatus) GetCostOffchain() int64 {
+	if m != nil {
+		return m.CostOffchain
+	}
+	return 0
+}
+
+func (m *SwapStatus) GetLabel() string {
+	if m != nil {
+		return m.Label
+	}
+	return ""
+}
+
+func (m *SwapStatus) GetSweepFee() int64 {
+	if m != nil {
+		return m.SweepFee
+	}
+	return 0
+}
+
+func (m *SwapStatus) GetReorged() bool {
+	if m != nil {
+		return m.Reorged
+	}
+	return false
+}
+
+func (m *SwapStatus) GetInitiator() string {
+	if m != nil {
+		return m.Initiator
+	}
+	return ""
+}
+
+func (m *SwapStatus) GetEvents() []*SwapEvent {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+func (m *SwapStatus) GetHtlcTxid() string {
+	if m != nil {
+		return m.HtlcTxid
+	}
+	return ""
+}
+
+func (m *SwapStatus) GetHtlcOutpoint() string {
+	if m != nil {
+		return m.HtlcOutpoint
+	}
+	return ""
+}
+
+func (m *SwapStatus) GetHtlcConfHeight() int32 {
+	if m != nil {
+		return m.HtlcConfHeight
+	}
+	return 0
+}
+
+func (m *SwapStatus) GetSweepTxid() string {
+	if m != nil {
+		return m.SweepTxid
+	}
+	return ""
+}
+
+func (m *SwapStatus) GetSpendTxid() string {
+	if m != nil {
+		return m.SpendTxid
+	}
+	return ""
+}
+
+func (m *SwapStatus) GetSpendConfHeight() int32 {
+	if m != nil {
+		return m.SpendConfHeight
+	}
+	return 0
+}
+
+func (m *SwapStatus) GetArchived() bool {
+	if m != nil {
+		return m.Archived
+	}
+	return false
+}
+
+type SwapEvent struct {
+	//
+	//The state that the swap transitioned to, see State enum.
+	State SwapState `protobuf:"varint,1,opt,name=state,proto3,enum=looprpc.SwapState" json:"state,omitempty"`
+	//
+	//A failure reason for the swap, only set if the swap transitioned to a
+	//failed state.
+	FailureReason FailureReason `protobuf:"varint,2,opt,name=failure_reason,json=failureReason,proto3,enum=looprpc.FailureReason" json:"failure_reason,omitempty"`
+	//
+	//The time of the state transition in unix nanoseconds.
+	Time int64 `protobuf:"varint,3,opt,name=time,proto3" json:"time,omitempty"`
+	// Swap server cost at the time of the state transition.
+	CostServer int64 `protobuf:"varint,4,opt,name=cost_server,json=costServer,proto3" json:"cost_server,omitempty"`
+	// On-chain transaction cost at the time of the state transition.
+	CostOnchain int64 `protobuf:"varint,5,opt,name=cost_onchain,json=costOnchain,proto3" json:"cost_onchain,omitempty"`
+	// Off-chain routing fees at the time of the state transition.
+	CostOffchain int64 `protobuf:"varint,6,opt,name=cost_offchain,json=costOffchain,proto3" json:"cost_offchain,omitempty"`
+	// The txid of the confirmed htlc transaction, if known.
+	HtlcTxid string `protobuf:"bytes,7,opt,name=htlc_txid,json=htlcTxid,proto3" json:"htlc_txid,omitempty"`
+	//
+	//The txid of the most recently published transaction sweeping the
+	//on-chain htlc, if any.
+	SweepTxid string `protobuf:"bytes,8,opt,name=sweep_txid,json=sweepTxid,proto3" json:"sweep_txid,omitempty"`
+	// The fee paid by the sweep transaction.
+	SweepFee int64 `protobuf:"varint,9,opt,name=sweep_fee,json=sweepFee,proto3" json:"sweep_fee,omitempty"`
+	// The outpoint of the confirmed htlc in the format txid:index, if known.
+	HtlcOutpoint string `protobuf:"bytes,10,opt,name=htlc_outpoint,json=htlcOutpoint,proto3" json:"htlc_outpoint,omitempty"`
+	// The height at which the htlc confirmed, or zero if it isn't known.
+	HtlcConfHeight int32 `protobuf:"varint,11,opt,name=htlc_conf_height,json=htlcConfHeight,proto3" json:"htlc_conf_height,omitempty"`
+	// The txid of the confirmed transaction that spent the htlc, if any.
+	SpendTxid string `protobuf:"bytes,12,opt,name=spend_txid,json=spendTxid,proto3" json:"spend_txid,omitempty"`
+	//
+	//The height at which the transaction that spent the htlc confirmed, or zero
+	//if it isn't known.
+	SpendConfHeight      int32    `protobuf:"varint,13,opt,name=spend_conf_height,json=spendConfHeight,proto3" json:"spend_conf_height,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SwapEvent) Reset()         { *m = SwapEvent{} }
+func (m *SwapEvent) String() string { return proto.CompactTextString(m) }
+func (*SwapEvent) ProtoMessage()    {}
+func (*SwapEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{10}
+}
+
+func (m *SwapEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SwapEvent.Unmarshal(m, b)
+}
+func (m *SwapEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SwapEvent.Marshal(b, m, deterministic)
+}
+func (m *SwapEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SwapEvent.Merge(m, src)
+}
+func (m *SwapEvent) XXX_Size() int {
+	return xxx_messageInfo_SwapEvent.Size(m)
+}
+func (m *SwapEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_SwapEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SwapEvent proto.InternalMessageInfo
+
+func (m *SwapEvent) GetState() SwapState {
+	if m != nil {
+		return m.State
+	}
+	return SwapState_INITIATED
+}
+
+func (m *SwapEvent) GetFailureReason() FailureReason {
+	if m != nil {
+		return m.FailureReason
+	}
+	return FailureReason_FAILURE_REASON_NONE
+}
+
+func (m *SwapEvent) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *SwapEvent) GetCostServer() int64 {
+	if m != nil {
+		return m.CostServer
+	}
+	return 0
+}
+
+func (m *SwapEvent) GetCostOnchain() int64 {
+	if m != nil {
+		return m.CostOnchain
+	}
+	return 0
+}
+
+func (m *SwapEvent) GetCostOffchain() int64 {
+	if m != nil {
+		return m.CostOffchain
+	}
+	return 0
+}
+
+func (m *SwapEvent) GetHtlcTxid() string {
+	if m != nil {
+		return m.HtlcTxid
+	}
+	return ""
+}
+
+func (m *SwapEvent) GetSweepTxid() string {
+	if m != nil {
+		return m.SweepTxid
+	}
+	return ""
+}
+
+func (m *SwapEvent) GetSweepFee() int64 {
+	if m != nil {
+		return m.SweepFee
+	}
+	return 0
+}
+
+func (m *SwapEvent) GetHtlcOutpoint() string {
+	if m != nil {
+		return m.HtlcOutpoint
+	}
+	return ""
+}
+
+func (m *SwapEvent) GetHtlcConfHeight() int32 {
+	if m != nil {
+		return m.HtlcConfHeight
+	}
+	return 0
+}
+
+func (m *SwapEvent) GetSpendTxid() string {
+	if m != nil {
+		return m.SpendTxid
+	}
+	return ""
+}
+
+func (m *SwapEvent) GetSpendConfHeight() int32 {
+	if m != nil {
+		return m.SpendConfHeight
+	}
+	return 0
+}
+
+type ListSwapsRequest struct {
+	//
+	//The filter that swaps must match to be returned. If not set, all swaps
+	//are returned.
+	Filter *ListSwapsFilter `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	//
+	//The cursor of the page of swaps to return, as returned in the next_cursor
+	//field of the previous page. If empty, the first page is returned.
+	Cursor string `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	//
+	//The maximum number of swaps to return. If zero, all swaps that match the
+	//filter are returned.
+	MaxSwaps uint32 `protobuf:"varint,3,opt,name=max_swaps,json=maxSwaps,proto3" json:"max_swaps,omitempty"`
+	//
+	//Whether to include swaps that have been archived. By default, only swaps
+	//that haven't been archived are returned.
+	IncludeArchived      bool     `protobuf:"varint,4,opt,name=include_archived,json=includeArchived,proto3" json:"include_archived,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListSwapsRequest) Reset()         { *m = ListSwapsRequest{} }
+func (m *ListSwapsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListSwapsRequest) ProtoMessage()    {}
+func (*ListSwapsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{11}
+}
+
+func (m *ListSwapsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSwapsRequest.Unmarshal(m, b)
+}
+func (m *ListSwapsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSwapsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListSwapsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSwapsRequest.Merge(m, src)
+}
+func (m *ListSwapsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListSwapsRequest.Size(m)
+}
+func (m *ListSwapsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSwapsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSwapsRequest proto.InternalMessageInfo
+
+func (m *ListSwapsRequest) GetFilter() *ListSwapsFilter {
+	if m != nil {
+		return m.Filter
 	}
-	return FailureReason_FAILURE_REASON_NONE
+	return nil
 }
 
-func (m *SwapStatus) GetInitiationTime() int64 {
+func (m *ListSwapsRequest) GetCursor() string {
 	if m != nil {
-		return m.InitiationTime
+		return m.Cursor
 	}
-	return 0
+	return ""
 }
 
-func (m *SwapStatus) GetLastUpdateTime() int64 {
+func (m *ListSwapsRequest) GetMaxSwaps() uint32 {
 	if m != nil {
-		return m.LastUpdateTime
+		return m.MaxSwaps
 	}
 	return 0
 }
 
-// Deprecated: Do not use.
-func (m *SwapStatus) GetHtlcAddress() string {
+func (m *ListSwapsRequest) GetIncludeArchived() bool {
 	if m != nil {
-		return m.HtlcAddress
+		return m.IncludeArchived
 	}
-	return ""
+	return false
 }
 
-func (m *SwapStatus) GetHtlcAddressP2Wsh() string {
-	if m != nil {
-		return m.HtlcAddressP2Wsh
-	}
-	return ""
+type ListSwapsFilter struct {
+	// The type of swaps to return.
+	SwapType ListSwapsFilter_SwapTypeFilter `protobuf:"varint,1,opt,name=swap_type,json=swapType,proto3,enum=looprpc.ListSwapsFilter_SwapTypeFilter" json:"swap_type,omitempty"`
+	//
+	//The types of the current state of swaps to return. If empty, swaps in any
+	//state are returned.
+	StateTypes []ListSwapsFilter_StateTypeFilter `protobuf:"varint,2,rep,packed,name=state_types,json=stateTypes,proto3,enum=looprpc.ListSwapsFilter_StateTypeFilter" json:"state_types,omitempty"`
+	//
+	//Only return swaps that were initiated at or after this time, in
+	//nanoseconds since the unix epoch. Ignored if zero.
+	StartTimeNs int64 `protobuf:"varint,3,opt,name=start_time_ns,json=startTimeNs,proto3" json:"start_time_ns,omitempty"`
+	//
+	//Only return swaps that were initiated before this time, in nanoseconds
+	//since the unix epoch. Ignored if zero.
+	EndTimeNs int64 `protobuf:"varint,4,opt,name=end_time_ns,json=endTimeNs,proto3" json:"end_time_ns,omitempty"`
+	// Only return swaps with this label. Ignored if empty.
+	Label string `protobuf:"bytes,5,opt,name=label,proto3" json:"label,omitempty"`
+	//
+	//Only return loop out swaps that may use this channel for their swap
+	//payment. Ignored if zero.
+	OutgoingChanId uint64 `protobuf:"varint,6,opt,name=outgoing_chan_id,json=outgoingChanId,proto3" json:"outgoing_chan_id,omitempty"`
+	// Only return swaps with this last hop. Ignored if empty.
+	LastHop []byte `protobuf:"bytes,7,opt,name=last_hop,json=lastHop,proto3" json:"last_hop,omitempty"`
+	// Only return swaps with this initiator. Ignored if empty.
+	Initiator            string   `protobuf:"bytes,8,opt,name=initiator,proto3" json:"initiator,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *SwapStatus) GetHtlcAddressNp2Wsh() string {
+func (m *ListSwapsFilter) Reset()         { *m = ListSwapsFilter{} }
+func (m *ListSwapsFilter) String() string { return proto.CompactTextString(m) }
+func (*ListSwapsFilter) ProtoMessage()    {}
+func (*ListSwapsFilter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{12}
+}
+
+func (m *ListSwapsFilter) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSwapsFilter.Unmarshal(m, b)
+}
+func (m *ListSwapsFilter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSwapsFilter.Marshal(b, m, deterministic)
+}
+func (m *ListSwapsFilter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSwapsFilter.Merge(m, src)
+}
+func (m *ListSwapsFilter) XXX_Size() int {
+	return xxx_messageInfo_ListSwapsFilter.Size(m)
+}
+func (m *ListSwapsFilter) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSwapsFilter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSwapsFilter proto.InternalMessageInfo
+
+func (m *ListSwapsFilter) GetSwapType() ListSwapsFilter_SwapTypeFilter {
 	if m != nil {
-		return m.HtlcAddressNp2Wsh
+		return m.SwapType
 	}
-	return ""
+	return ListSwapsFilter_ANY
 }
 
-func (m *SwapStatus) GetCostServer() int64 {
+func (m *ListSwapsFilter) GetStateTypes() []ListSwapsFilter_StateTypeFilter {
 	if m != nil {
-		return m.CostServer
+		return m.StateTypes
 	}
-	return 0
+	return nil
 }
 
-func (m *SwapStatus) GetCostOnchain() int64 {
+func (m *ListSwapsFilter) GetStartTimeNs() int64 {
 	if m != nil {
-		return m.CostOnchain
+		return m.StartTimeNs
 	}
 	return 0
 }
 
-func (m *SwapStatus) GetCostOffchain() int64 {
+func (m *ListSwapsFilter) GetEndTimeNs() int64 {
 	if m != nil {
-		return m.CostOffchain
+		return m.EndTimeNs
 	}
 	return 0
 }
 
-func (m *SwapStatus) GetLabel() string {
+func (m *ListSwapsFilter) GetLabel() string {
 	if m != nil {
 		return m.Label
 	}
 	return ""
 }
 
-type ListSwapsRequest struct {
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *ListSwapsFilter) GetOutgoingChanId() uint64 {
+	if m != nil {
+		return m.OutgoingChanId
+	}
+	return 0
 }
 
-func (m *ListSwapsRequest) Reset()         { *m = ListSwapsRequest{} }
-func (m *ListSwapsRequest) String() string { return proto.CompactTextString(m) }
-func (*ListSwapsRequest) ProtoMessage()    {}
-func (*ListSwapsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{5}
+func (m *ListSwapsFilter) GetLastHop() []byte {
+	if m != nil {
+		return m.LastHop
+	}
+	return nil
 }
 
-func (m *ListSwapsRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ListSwapsRequest.Unmarshal(m, b)
-}
-func (m *ListSwapsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ListSwapsRequest.Marshal(b, m, deterministic)
-}
-func (m *ListSwapsRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ListSwapsRequest.Merge(m, src)
-}
-func (m *ListSwapsRequest) XXX_Size() int {
-	return xxx_messageInfo_ListSwapsRequest.Size(m)
-}
-func (m *ListSwapsRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_ListSwapsRequest.DiscardUnknown(m)
+func (m *ListSwapsFilter) GetInitiator() string {
+	if m != nil {
+		return m.Initiator
+	}
+	return ""
 }
 
-var xxx_messageInfo_ListSwapsRequest proto.InternalMessageInfo
-
 type ListSwapsResponse struct {
 	//
-	//The list of all currently known swaps and their status.
-	Swaps                []*SwapStatus `protobuf:"bytes,1,rep,name=swaps,proto3" json:"swaps,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
-	XXX_unrecognized     []byte        `json:"-"`
-	XXX_sizecache        int32         `json:"-"`
+	//The list of swaps that match the request and their status.
+	Swaps []*SwapStatus `protobuf:"bytes,1,rep,name=swaps,proto3" json:"swaps,omitempty"`
+	//
+	//The cursor of the next page of swaps. It is empty if this is the last
+	//page.
+	NextCursor           string   `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *ListSwapsResponse) Reset()         { *m = ListSwapsResponse{} }
 func (m *ListSwapsResponse) String() string { return proto.CompactTextString(m) }
 func (*ListSwapsResponse) ProtoMessage()    {}
 func (*ListSwapsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{6}
+	return fileDescriptor_014de31d7ac8c57c, []int{13}
 }
 
 func (m *ListSwapsResponse) XXX_Unmarshal(b []byte) error {
@@ -1023,6 +1864,13 @@ func (m *ListSwapsResponse) GetSwaps() []*SwapStatus {
 	return nil
 }
 
+func (m *ListSwapsResponse) GetNextCursor() string {
+	if m != nil {
+		return m.NextCursor
+	}
+	return ""
+}
+
 type SwapInfoRequest struct {
 	//
 	//The swap identifier which currently is the hash that locks the HTLCs. When
@@ -1037,7 +1885,7 @@ func (m *SwapInfoRequest) Reset()         { *m = SwapInfoRequest{} }
 func (m *SwapInfoRequest) String() string { return proto.CompactTextString(m) }
 func (*SwapInfoRequest) ProtoMessage()    {}
 func (*SwapInfoRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{7}
+	return fileDescriptor_014de31d7ac8c57c, []int{14}
 }
 
 func (m *SwapInfoRequest) XXX_Unmarshal(b []byte) error {
@@ -1075,7 +1923,7 @@ func (m *TermsRequest) Reset()         { *m = TermsRequest{} }
 func (m *TermsRequest) String() string { return proto.CompactTextString(m) }
 func (*TermsRequest) ProtoMessage()    {}
 func (*TermsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{8}
+	return fileDescriptor_014de31d7ac8c57c, []int{15}
 }
 
 func (m *TermsRequest) XXX_Unmarshal(b []byte) error {
@@ -1112,7 +1960,7 @@ func (m *InTermsResponse) Reset()         { *m = InTermsResponse{} }
 func (m *InTermsResponse) String() string { return proto.CompactTextString(m) }
 func (*InTermsResponse) ProtoMessage()    {}
 func (*InTermsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{9}
+	return fileDescriptor_014de31d7ac8c57c, []int{16}
 }
 
 func (m *InTermsResponse) XXX_Unmarshal(b []byte) error {
@@ -1167,7 +2015,7 @@ func (m *OutTermsResponse) Reset()         { *m = OutTermsResponse{} }
 func (m *OutTermsResponse) String() string { return proto.CompactTextString(m) }
 func (*OutTermsResponse) ProtoMessage()    {}
 func (*OutTermsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{10}
+	return fileDescriptor_014de31d7ac8c57c, []int{17}
 }
 
 func (m *OutTermsResponse) XXX_Unmarshal(b []byte) error {
@@ -1246,7 +2094,7 @@ func (m *QuoteRequest) Reset()         { *m = QuoteRequest{} }
 func (m *QuoteRequest) String() string { return proto.CompactTextString(m) }
 func (*QuoteRequest) ProtoMessage()    {}
 func (*QuoteRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{11}
+	return fileDescriptor_014de31d7ac8c57c, []int{18}
 }
 
 func (m *QuoteRequest) XXX_Unmarshal(b []byte) error {
@@ -1319,7 +2167,7 @@ func (m *InQuoteResponse) Reset()         { *m = InQuoteResponse{} }
 func (m *InQuoteResponse) String() string { return proto.CompactTextString(m) }
 func (*InQuoteResponse) ProtoMessage()    {}
 func (*InQuoteResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{12}
+	return fileDescriptor_014de31d7ac8c57c, []int{19}
 }
 
 func (m *InQuoteResponse) XXX_Unmarshal(b []byte) error {
@@ -1388,7 +2236,7 @@ func (m *OutQuoteResponse) Reset()         { *m = OutQuoteResponse{} }
 func (m *OutQuoteResponse) String() string { return proto.CompactTextString(m) }
 func (*OutQuoteResponse) ProtoMessage()    {}
 func (*OutQuoteResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{13}
+	return fileDescriptor_014de31d7ac8c57c, []int{20}
 }
 
 func (m *OutQuoteResponse) XXX_Unmarshal(b []byte) error {
@@ -1454,7 +2302,7 @@ func (m *TokensRequest) Reset()         { *m = TokensRequest{} }
 func (m *TokensRequest) String() string { return proto.CompactTextString(m) }
 func (*TokensRequest) ProtoMessage()    {}
 func (*TokensRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{14}
+	return fileDescriptor_014de31d7ac8c57c, []int{21}
 }
 
 func (m *TokensRequest) XXX_Unmarshal(b []byte) error {
@@ -1488,7 +2336,7 @@ func (m *TokensResponse) Reset()         { *m = TokensResponse{} }
 func (m *TokensResponse) String() string { return proto.CompactTextString(m) }
 func (*TokensResponse) ProtoMessage()    {}
 func (*TokensResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{15}
+	return fileDescriptor_014de31d7ac8c57c, []int{22}
 }
 
 func (m *TokensResponse) XXX_Unmarshal(b []byte) error {
@@ -1553,7 +2401,7 @@ func (m *LsatToken) Reset()         { *m = LsatToken{} }
 func (m *LsatToken) String() string { return proto.CompactTextString(m) }
 func (*LsatToken) ProtoMessage()    {}
 func (*LsatToken) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{16}
+	return fileDescriptor_014de31d7ac8c57c, []int{23}
 }
 
 func (m *LsatToken) XXX_Unmarshal(b []byte) error {
@@ -1640,7 +2488,7 @@ func (m *GetLiquidityParamsRequest) Reset()         { *m = GetLiquidityParamsReq
 func (m *GetLiquidityParamsRequest) String() string { return proto.CompactTextString(m) }
 func (*GetLiquidityParamsRequest) ProtoMessage()    {}
 func (*GetLiquidityParamsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{17}
+	return fileDescriptor_014de31d7ac8c57c, []int{24}
 }
 
 func (m *GetLiquidityParamsRequest) XXX_Unmarshal(b []byte) error {
@@ -1739,7 +2587,7 @@ func (m *LiquidityParameters) Reset()         { *m = LiquidityParameters{} }
 func (m *LiquidityParameters) String() string { return proto.CompactTextString(m) }
 func (*LiquidityParameters) ProtoMessage()    {}
 func (*LiquidityParameters) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{18}
+	return fileDescriptor_014de31d7ac8c57c, []int{25}
 }
 
 func (m *LiquidityParameters) XXX_Unmarshal(b []byte) error {
@@ -1897,7 +2745,7 @@ func (m *LiquidityRule) Reset()         { *m = LiquidityRule{} }
 func (m *LiquidityRule) String() string { return proto.CompactTextString(m) }
 func (*LiquidityRule) ProtoMessage()    {}
 func (*LiquidityRule) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{19}
+	return fileDescriptor_014de31d7ac8c57c, []int{26}
 }
 
 func (m *LiquidityRule) XXX_Unmarshal(b []byte) error {
@@ -1969,7 +2817,7 @@ func (m *SetLiquidityParamsRequest) Reset()         { *m = SetLiquidityParamsReq
 func (m *SetLiquidityParamsRequest) String() string { return proto.CompactTextString(m) }
 func (*SetLiquidityParamsRequest) ProtoMessage()    {}
 func (*SetLiquidityParamsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{20}
+	return fileDescriptor_014de31d7ac8c57c, []int{27}
 }
 
 func (m *SetLiquidityParamsRequest) XXX_Unmarshal(b []byte) error {
@@ -2007,7 +2855,7 @@ func (m *SetLiquidityParamsResponse) Reset()         { *m = SetLiquidityParamsRe
 func (m *SetLiquidityParamsResponse) String() string { return proto.CompactTextString(m) }
 func (*SetLiquidityParamsResponse) ProtoMessage()    {}
 func (*SetLiquidityParamsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{21}
+	return fileDescriptor_014de31d7ac8c57c, []int{28}
 }
 
 func (m *SetLiquidityParamsResponse) XXX_Unmarshal(b []byte) error {
@@ -2038,7 +2886,7 @@ func (m *SuggestSwapsRequest) Reset()         { *m = SuggestSwapsRequest{} }
 func (m *SuggestSwapsRequest) String() string { return proto.CompactTextString(m) }
 func (*SuggestSwapsRequest) ProtoMessage()    {}
 func (*SuggestSwapsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{22}
+	return fileDescriptor_014de31d7ac8c57c, []int{29}
 }
 
 func (m *SuggestSwapsRequest) XXX_Unmarshal(b []byte) error {
@@ -2078,7 +2926,7 @@ func (m *Disqualified) Reset()         { *m = Disqualified{} }
 func (m *Disqualified) String() string { return proto.CompactTextString(m) }
 func (*Disqualified) ProtoMessage()    {}
 func (*Disqualified) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{23}
+	return fileDescriptor_014de31d7ac8c57c, []int{30}
 }
 
 func (m *Disqualified) XXX_Unmarshal(b []byte) error {
@@ -2137,7 +2985,7 @@ func (m *SuggestSwapsResponse) Reset()         { *m = SuggestSwapsResponse{} }
 func (m *SuggestSwapsResponse) String() string { return proto.CompactTextString(m) }
 func (*SuggestSwapsResponse) ProtoMessage()    {}
 func (*SuggestSwapsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{24}
+	return fileDescriptor_014de31d7ac8c57c, []int{31}
 }
 
 func (m *SuggestSwapsResponse) XXX_Unmarshal(b []byte) error {
@@ -2172,18 +3020,156 @@ func (m *SuggestSwapsResponse) GetDisqualified() []*Disqualified {
 	return nil
 }
 
+type BackupDatabaseRequest struct {
+	//
+	//The absolute path on the loopd host that the snapshot is written to. The
+	//file must not exist yet.
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	//
+	//If set, no snapshot is written. Instead, the existing snapshot at path is
+	//opened read-only and all of its swaps are deserialized.
+	VerifyOnly           bool     `protobuf:"varint,2,opt,name=verify_only,json=verifyOnly,proto3" json:"verify_only,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BackupDatabaseRequest) Reset()         { *m = BackupDatabaseRequest{} }
+func (m *BackupDatabaseRequest) String() string { return proto.CompactTextString(m) }
+func (*BackupDatabaseRequest) ProtoMessage()    {}
+func (*BackupDatabaseRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{32}
+}
+
+func (m *BackupDatabaseRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BackupDatabaseRequest.Unmarshal(m, b)
+}
+func (m *BackupDatabaseRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BackupDatabaseRequest.Marshal(b, m, deterministic)
+}
+func (m *BackupDatabaseRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackupDatabaseRequest.Merge(m, src)
+}
+func (m *BackupDatabaseRequest) XXX_Size() int {
+	return xxx_messageInfo_BackupDatabaseRequest.Size(m)
+}
+func (m *BackupDatabaseRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackupDatabaseRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BackupDatabaseRequest proto.InternalMessageInfo
+
+func (m *BackupDatabaseRequest) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *BackupDatabaseRequest) GetVerifyOnly() bool {
+	if m != nil {
+		return m.VerifyOnly
+	}
+	return false
+}
+
+type BackupDatabaseResponse struct {
+	// The path of the snapshot.
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// The database backend that the snapshot was taken from.
+	Backend string `protobuf:"bytes,2,opt,name=backend,proto3" json:"backend,omitempty"`
+	// The database version of the snapshot.
+	DbVersion uint32 `protobuf:"varint,3,opt,name=db_version,json=dbVersion,proto3" json:"db_version,omitempty"`
+	// The number of loop out swaps in the snapshot.
+	LoopOutSwaps uint32 `protobuf:"varint,4,opt,name=loop_out_swaps,json=loopOutSwaps,proto3" json:"loop_out_swaps,omitempty"`
+	// The number of loop in swaps in the snapshot.
+	LoopInSwaps          uint32   `protobuf:"varint,5,opt,name=loop_in_swaps,json=loopInSwaps,proto3" json:"loop_in_swaps,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BackupDatabaseResponse) Reset()         { *m = BackupDatabaseResponse{} }
+func (m *BackupDatabaseResponse) String() string { return proto.CompactTextString(m) }
+func (*BackupDatabaseResponse) ProtoMessage()    {}
+func (*BackupDatabaseResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{33}
+}
+
+func (m *BackupDatabaseResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BackupDatabaseResponse.Unmarshal(m, b)
+}
+func (m *BackupDatabaseResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BackupDatabaseResponse.Marshal(b, m, deterministic)
+}
+func (m *BackupDatabaseResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackupDatabaseResponse.Merge(m, src)
+}
+func (m *BackupDatabaseResponse) XXX_Size() int {
+	return xxx_messageInfo_BackupDatabaseResponse.Size(m)
+}
+func (m *BackupDatabaseResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackupDatabaseResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BackupDatabaseResponse proto.InternalMessageInfo
+
+func (m *BackupDatabaseResponse) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *BackupDatabaseResponse) GetBackend() string {
+	if m != nil {
+		return m.Backend
+	}
+	return ""
+}
+
+func (m *BackupDatabaseResponse) GetDbVersion() uint32 {
+	if m != nil {
+		return m.DbVersion
+	}
+	return 0
+}
+
+func (m *BackupDatabaseResponse) GetLoopOutSwaps() uint32 {
+	if m != nil {
+		return m.LoopOutSwaps
+	}
+	return 0
+}
+
+func (m *BackupDatabaseResponse) GetLoopInSwaps() uint32 {
+	if m != nil {
+		return m.LoopInSwaps
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterEnum("looprpc.SwapType", SwapType_name, SwapType_value)
 	proto.RegisterEnum("looprpc.SwapState", SwapState_name, SwapState_value)
 	proto.RegisterEnum("looprpc.FailureReason", FailureReason_name, FailureReason_value)
 	proto.RegisterEnum("looprpc.LiquidityRuleType", LiquidityRuleType_name, LiquidityRuleType_value)
 	proto.RegisterEnum("looprpc.AutoReason", AutoReason_name, AutoReason_value)
+	proto.RegisterEnum("looprpc.ListSwapsFilter_SwapTypeFilter", ListSwapsFilter_SwapTypeFilter_name, ListSwapsFilter_SwapTypeFilter_value)
+	proto.RegisterEnum("looprpc.ListSwapsFilter_StateTypeFilter", ListSwapsFilter_StateTypeFilter_name, ListSwapsFilter_StateTypeFilter_value)
 	proto.RegisterType((*LoopOutRequest)(nil), "looprpc.LoopOutRequest")
+	proto.RegisterType((*SweepDestination)(nil), "looprpc.SweepDestination")
 	proto.RegisterType((*LoopInRequest)(nil), "looprpc.LoopInRequest")
+	proto.RegisterType((*LoopInBatchRequest)(nil), "looprpc.LoopInBatchRequest")
 	proto.RegisterType((*SwapResponse)(nil), "looprpc.SwapResponse")
+	proto.RegisterType((*LoopInBatchResponse)(nil), "looprpc.LoopInBatchResponse")
+	proto.RegisterType((*LoopInPublishPsbtRequest)(nil), "looprpc.LoopInPublishPsbtRequest")
+	proto.RegisterType((*LoopInPublishPsbtResponse)(nil), "looprpc.LoopInPublishPsbtResponse")
 	proto.RegisterType((*MonitorRequest)(nil), "looprpc.MonitorRequest")
 	proto.RegisterType((*SwapStatus)(nil), "looprpc.SwapStatus")
+	proto.RegisterType((*SwapEvent)(nil), "looprpc.SwapEvent")
 	proto.RegisterType((*ListSwapsRequest)(nil), "looprpc.ListSwapsRequest")
+	proto.RegisterType((*ListSwapsFilter)(nil), "looprpc.ListSwapsFilter")
 	proto.RegisterType((*ListSwapsResponse)(nil), "looprpc.ListSwapsResponse")
 	proto.RegisterType((*SwapInfoRequest)(nil), "looprpc.SwapInfoRequest")
 	proto.RegisterType((*TermsRequest)(nil), "looprpc.TermsRequest")
@@ -2203,176 +3189,236 @@ func init() {
 	proto.RegisterType((*SuggestSwapsRequest)(nil), "looprpc.SuggestSwapsRequest")
 	proto.RegisterType((*Disqualified)(nil), "looprpc.Disqualified")
 	proto.RegisterType((*SuggestSwapsResponse)(nil), "looprpc.SuggestSwapsResponse")
+	proto.RegisterType((*BackupDatabaseRequest)(nil), "looprpc.BackupDatabaseRequest")
+	proto.RegisterType((*BackupDatabaseResponse)(nil), "looprpc.BackupDatabaseResponse")
 }
 
 func init() { proto.RegisterFile("client.proto", fileDescriptor_014de31d7ac8c57c) }
 
 var fileDescriptor_014de31d7ac8c57c = []byte{
-	// 2624 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x59, 0x4f, 0x73, 0xe3, 0xc6,
-	0xb1, 0x5f, 0xfe, 0x13, 0xc9, 0x26, 0x48, 0x42, 0xa3, 0x5d, 0x89, 0xa2, 0x65, 0xaf, 0x16, 0xf6,
-	0x3e, 0xcb, 0xb2, 0xbd, 0x7a, 0x96, 0x4f, 0x76, 0xd9, 0xaf, 0x8a, 0xa2, 0xa0, 0x15, 0xd7, 0x12,
-	0x49, 0x83, 0xe4, 0xba, 0xf6, 0xd5, 0xab, 0x42, 0x8d, 0xc8, 0x91, 0x84, 0x32, 0xf1, 0x67, 0x81,
-	0xe1, 0xae, 0x54, 0xae, 0x97, 0x54, 0xa5, 0xe2, 0x73, 0x0e, 0xf9, 0x06, 0xb9, 0xe7, 0x96, 0x5b,
-	0x3e, 0x40, 0x2e, 0x39, 0x25, 0xb9, 0xe5, 0x9a, 0x4b, 0x0e, 0xf9, 0x0e, 0xa9, 0xe9, 0x01, 0x40,
-	0x80, 0x22, 0xe5, 0xe4, 0x90, 0x9b, 0xd8, 0xfd, 0x9b, 0x9e, 0xe9, 0xff, 0xdd, 0x10, 0x28, 0xe3,
-	0xa9, 0xc5, 0x1c, 0xfe, 0xcc, 0xf3, 0x5d, 0xee, 0x92, 0xe2, 0xd4, 0x75, 0x3d, 0xdf, 0x1b, 0x37,
-	0x77, 0xae, 0x5c, 0xf7, 0x6a, 0xca, 0x0e, 0xa8, 0x67, 0x1d, 0x50, 0xc7, 0x71, 0x39, 0xe5, 0x96,
-	0xeb, 0x04, 0x12, 0xa6, 0xfd, 0x36, 0x0f, 0xb5, 0x33, 0xd7, 0xf5, 0x7a, 0x33, 0x6e, 0xb0, 0xd7,
-	0x33, 0x16, 0x70, 0xa2, 0x42, 0x8e, 0xda, 0xbc, 0x91, 0xd9, 0xcd, 0xec, 0xe5, 0x0c, 0xf1, 0x27,
-	0x21, 0x90, 0x9f, 0xb0, 0x80, 0x37, 0xb2, 0xbb, 0x99, 0xbd, 0xb2, 0x81, 0x7f, 0x93, 0x03, 0x78,
-	0x68, 0xd3, 0x1b, 0x33, 0x78, 0x4b, 0x3d, 0xd3, 0x77, 0x67, 0xdc, 0x72, 0xae, 0xcc, 0x4b, 0xc6,
-	0x1a, 0x39, 0x3c, 0xb6, 0x6e, 0xd3, 0x9b, 0xc1, 0x5b, 0xea, 0x19, 0x92, 0x73, 0xc2, 0x18, 0xf9,
-	0x1c, 0x36, 0xc5, 0x01, 0xcf, 0x67, 0x1e, 0xbd, 0x4d, 0x1d, 0xc9, 0xe3, 0x91, 0x0d, 0x9b, 0xde,
-	0xf4, 0x91, 0x99, 0x38, 0xb4, 0x0b, 0x4a, 0x7c, 0x8b, 0x80, 0x16, 0x10, 0x0a, 0xa1, 0x74, 0x81,
-	0xf8, 0x00, 0x6a, 0x09, 0xb1, 0xe2, 0xe1, 0x6b, 0x88, 0x51, 0x62, 0x71, 0x2d, 0x9b, 0x13, 0x0d,
-	0xaa, 0x02, 0x65, 0x5b, 0x0e, 0xf3, 0x51, 0x50, 0x11, 0x41, 0x15, 0x9b, 0xde, 0x9c, 0x0b, 0x9a,
-	0x90, 0xf4, 0x09, 0xa8, 0xc2, 0x66, 0xa6, 0x3b, 0xe3, 0xe6, 0xf8, 0x9a, 0x3a, 0x0e, 0x9b, 0x36,
-	0x4a, 0xbb, 0x99, 0xbd, 0xfc, 0x51, 0xb6, 0x91, 0x31, 0x6a, 0x53, 0x69, 0xa5, 0xb6, 0xe4, 0x90,
-	0x7d, 0x58, 0x77, 0x67, 0xfc, 0xca, 0x15, 0x4a, 0x08, 0xb4, 0x19, 0x30, 0xde, 0xa8, 0xec, 0xe6,
-	0xf6, 0xf2, 0x46, 0x3d, 0x62, 0x08, 0xec, 0x80, 0x71, 0x81, 0x0d, 0xde, 0x32, 0xe6, 0x99, 0x63,
-	0xd7, 0xb9, 0x34, 0x39, 0xf5, 0xaf, 0x18, 0x6f, 0x94, 0x77, 0x33, 0x7b, 0x05, 0xa3, 0x8e, 0x8c,
-	0xb6, 0xeb, 0x5c, 0x0e, 0x91, 0x4c, 0x3e, 0x05, 0x72, 0xcd, 0xa7, 0x63, 0x84, 0x5a, 0xbe, 0x2d,
-	0x9d, 0xd5, 0xa8, 0x22, 0x78, 0x5d, 0x70, 0xda, 0x49, 0x06, 0xf9, 0x12, 0xb6, 0xd1, 0x38, 0xde,
-	0xec, 0x62, 0x6a, 0x8d, 0x91, 0x68, 0x4e, 0x18, 0x9d, 0x4c, 0x2d, 0x87, 0x35, 0x40, 0xbc, 0xde,
-	0xd8, 0x12, 0x80, 0xfe, 0x9c, 0x7f, 0x1c, 0xb2, 0xc9, 0x43, 0x28, 0x4c, 0xe9, 0x05, 0x9b, 0x36,
-	0x14, 0xf4, 0xab, 0xfc, 0x41, 0x76, 0xa0, 0x6c, 0x39, 0x16, 0xb7, 0x28, 0x77, 0xfd, 0x46, 0x0d,
-	0x39, 0x73, 0x82, 0xf6, 0x63, 0x16, 0xaa, 0x22, 0x5e, 0x3a, 0xce, 0xea, 0x70, 0x59, 0x74, 0x5a,
-	0xf6, 0x8e, 0xd3, 0xee, 0xb8, 0x23, 0x77, 0xd7, 0x1d, 0xdb, 0x50, 0x9a, 0xd2, 0x80, 0x9b, 0xd7,
-	0xae, 0x87, 0x11, 0xa2, 0x18, 0x45, 0xf1, 0xfb, 0xd4, 0xf5, 0xc8, 0xfb, 0x50, 0x65, 0x37, 0x9c,
-	0xf9, 0x0e, 0x9d, 0x9a, 0xc2, 0x24, 0x18, 0x16, 0x25, 0x43, 0x89, 0x88, 0xa7, 0x7c, 0x3a, 0x26,
-	0x7b, 0xa0, 0xc6, 0x86, 0x8c, 0x6c, 0xbe, 0x86, 0x66, 0xac, 0x45, 0x66, 0x0c, 0x4d, 0x1e, 0xdb,
-	0xa1, 0xb8, 0xd2, 0x0e, 0xa5, 0x45, 0x3b, 0xfc, 0x3d, 0x03, 0x0a, 0x06, 0x38, 0x0b, 0x3c, 0xd7,
-	0x09, 0x18, 0x21, 0x90, 0xb5, 0x26, 0x68, 0x85, 0x32, 0xc6, 0x4b, 0xd6, 0x9a, 0x08, 0x15, 0xac,
-	0x89, 0x79, 0x71, 0xcb, 0x59, 0x80, 0x1a, 0x2a, 0x46, 0xd1, 0x9a, 0x1c, 0x89, 0x9f, 0xe4, 0x29,
-	0x28, 0xf8, 0x3a, 0x3a, 0x99, 0xf8, 0x2c, 0x08, 0x64, 0x6a, 0xe1, 0xc1, 0x8a, 0xa0, 0xb7, 0x24,
-	0x99, 0x3c, 0x83, 0x8d, 0x24, 0xcc, 0x74, 0xbc, 0xc3, 0xb7, 0xc1, 0x35, 0xda, 0xa3, 0x2c, 0xc3,
-	0x21, 0x44, 0x76, 0x91, 0x41, 0x3e, 0x09, 0xa3, 0x27, 0xc2, 0x4b, 0x78, 0x01, 0xe1, 0x6a, 0x02,
-	0xde, 0x47, 0xf4, 0x53, 0xa8, 0x05, 0xcc, 0x7f, 0xc3, 0x7c, 0xd3, 0x66, 0x41, 0x40, 0xaf, 0x18,
-	0x1a, 0xa8, 0x6c, 0x54, 0x25, 0xf5, 0x5c, 0x12, 0x35, 0x15, 0x6a, 0xe7, 0xae, 0x63, 0x71, 0xd7,
-	0x0f, 0x7d, 0xae, 0xfd, 0x2e, 0x0f, 0x20, 0xb4, 0x1f, 0x70, 0xca, 0x67, 0xc1, 0xd2, 0x8a, 0x21,
-	0xac, 0x91, 0x5d, 0x69, 0x8d, 0xca, 0xa2, 0x35, 0xf2, 0xfc, 0xd6, 0x93, 0x61, 0x50, 0x3b, 0x5c,
-	0x7f, 0x16, 0xd6, 0xae, 0x67, 0xe2, 0x8e, 0xe1, 0xad, 0xc7, 0x0c, 0x64, 0x93, 0x3d, 0x28, 0x04,
-	0x9c, 0x72, 0x59, 0x31, 0x6a, 0x87, 0x24, 0x85, 0x13, 0x6f, 0x61, 0x86, 0x04, 0x90, 0xaf, 0xa1,
-	0x76, 0x49, 0xad, 0xe9, 0xcc, 0x67, 0xa6, 0xcf, 0x68, 0xe0, 0x3a, 0x18, 0xc9, 0xb5, 0xc3, 0xcd,
-	0xf8, 0xc8, 0x89, 0x64, 0x1b, 0xc8, 0x35, 0xaa, 0x97, 0xc9, 0x9f, 0xe4, 0x43, 0xa8, 0x87, 0xae,
-	0x16, 0xf9, 0xc4, 0x2d, 0x3b, 0xaa, 0x3c, 0xb5, 0x39, 0x79, 0x68, 0xd9, 0xe2, 0x45, 0x2a, 0x06,
-	0xe9, 0xcc, 0x9b, 0x50, 0xce, 0x24, 0x52, 0xd6, 0x9f, 0x9a, 0xa0, 0x8f, 0x90, 0x8c, 0xc8, 0x45,
-	0x87, 0x17, 0x97, 0x3b, 0x7c, 0xb9, 0x03, 0x95, 0x15, 0x0e, 0x5c, 0x11, 0x1e, 0xd5, 0x55, 0xe1,
-	0xf1, 0x18, 0x2a, 0x63, 0x37, 0xe0, 0xa6, 0xf4, 0x2f, 0x46, 0x75, 0xce, 0x00, 0x41, 0x1a, 0x20,
-	0x85, 0x3c, 0x01, 0x05, 0x01, 0xae, 0x33, 0xbe, 0xa6, 0x96, 0x83, 0x45, 0x2a, 0x67, 0xe0, 0xa1,
-	0x9e, 0x24, 0x89, 0xe4, 0x93, 0x90, 0xcb, 0x4b, 0x89, 0x01, 0x59, 0x6f, 0x11, 0x13, 0xd2, 0xe6,
-	0x29, 0x55, 0x4f, 0xa4, 0x94, 0x46, 0x40, 0x3d, 0xb3, 0x02, 0x2e, 0xbc, 0x15, 0x44, 0xa1, 0xf4,
-	0x3f, 0xb0, 0x9e, 0xa0, 0x85, 0xc9, 0xf4, 0x11, 0x14, 0x44, 0xf5, 0x08, 0x1a, 0x99, 0xdd, 0xdc,
-	0x5e, 0xe5, 0x70, 0xe3, 0x8e, 0xa3, 0x67, 0x81, 0x21, 0x11, 0xda, 0x13, 0xa8, 0x0b, 0x62, 0xc7,
-	0xb9, 0x74, 0xa3, 0x8a, 0x54, 0x8b, 0x53, 0x51, 0x11, 0x81, 0xa7, 0xd5, 0x40, 0x19, 0x32, 0xdf,
-	0x8e, 0xaf, 0xfc, 0x39, 0xd4, 0x3b, 0x4e, 0x48, 0x09, 0x2f, 0xfc, 0x2f, 0xa8, 0xdb, 0x96, 0x23,
-	0x4b, 0x16, 0xb5, 0xdd, 0x99, 0xc3, 0x43, 0x87, 0x57, 0x6d, 0xcb, 0x11, 0xf2, 0x5b, 0x48, 0x44,
-	0x5c, 0x54, 0xda, 0x42, 0xdc, 0x5a, 0x88, 0x93, 0xd5, 0x4d, 0xe2, 0x5e, 0xe4, 0x4b, 0x19, 0x35,
-	0xfb, 0x22, 0x5f, 0xca, 0xaa, 0xb9, 0x17, 0xf9, 0x52, 0x4e, 0xcd, 0xbf, 0xc8, 0x97, 0xf2, 0x6a,
-	0xe1, 0x45, 0xbe, 0x54, 0x54, 0x4b, 0xda, 0x1f, 0x33, 0xa0, 0xf6, 0x66, 0xfc, 0x3f, 0xfa, 0x04,
-	0x6c, 0x8c, 0x96, 0x63, 0x8e, 0xa7, 0xfc, 0x8d, 0x39, 0x61, 0x53, 0x4e, 0xd1, 0xdd, 0x05, 0x43,
-	0xb1, 0x2d, 0xa7, 0x3d, 0xe5, 0x6f, 0x8e, 0x05, 0x2d, 0x6a, 0x9f, 0x09, 0x54, 0x39, 0x44, 0xd1,
-	0x9b, 0x18, 0xf5, 0x13, 0xea, 0xfc, 0x26, 0x03, 0xca, 0xb7, 0x33, 0x97, 0xb3, 0xd5, 0x2d, 0x01,
-	0x03, 0x6f, 0x5e, 0x87, 0xb3, 0x78, 0x07, 0x8c, 0xe7, 0x35, 0xf8, 0x4e, 0x49, 0xcf, 0x2d, 0x29,
-	0xe9, 0xf7, 0x36, 0xbb, 0xfc, 0xbd, 0xcd, 0x4e, 0xfb, 0x55, 0x46, 0x78, 0x3d, 0x7c, 0x66, 0x68,
-	0xf2, 0x5d, 0x50, 0xa2, 0x26, 0x65, 0x06, 0x34, 0x7a, 0x30, 0x04, 0xb2, 0x4b, 0x0d, 0x28, 0x4e,
-	0x39, 0x98, 0x60, 0x78, 0x63, 0x70, 0x1d, 0x23, 0xc3, 0x29, 0x47, 0xf0, 0xfa, 0x92, 0x15, 0x1e,
-	0x78, 0x17, 0x20, 0x61, 0xcb, 0x02, 0xea, 0x59, 0x1e, 0x27, 0x0c, 0x29, 0x4d, 0x98, 0x57, 0x0b,
-	0xda, 0x9f, 0x64, 0x14, 0xfc, 0xbb, 0x4f, 0xfa, 0x00, 0x6a, 0xf3, 0x61, 0x07, 0x31, 0xb2, 0xbf,
-	0x2a, 0x5e, 0x34, 0xed, 0x08, 0xd4, 0xc7, 0x61, 0x1d, 0x91, 0x73, 0x47, 0xfa, 0xd9, 0x75, 0xc1,
-	0x19, 0x08, 0x46, 0x28, 0x12, 0xe7, 0x13, 0x61, 0x57, 0x7a, 0x6b, 0x33, 0x87, 0x9b, 0x38, 0xec,
-	0xc9, 0x9e, 0x5b, 0x47, 0x7b, 0x4a, 0xfa, 0xb1, 0xf0, 0xed, 0xfd, 0x0a, 0x6a, 0x75, 0xa8, 0x0e,
-	0xdd, 0xef, 0x99, 0x13, 0x27, 0xdb, 0x57, 0x50, 0x8b, 0x08, 0xa1, 0x8a, 0xfb, 0xb0, 0xc6, 0x91,
-	0x12, 0x66, 0xf7, 0xbc, 0x8c, 0x9f, 0x05, 0x94, 0x23, 0xd8, 0x08, 0x11, 0xda, 0xef, 0xb3, 0x50,
-	0x8e, 0xa9, 0x22, 0x48, 0x2e, 0x68, 0xc0, 0x4c, 0x9b, 0x8e, 0xa9, 0xef, 0xba, 0x4e, 0x98, 0xe3,
-	0x8a, 0x20, 0x9e, 0x87, 0x34, 0x51, 0xc2, 0x22, 0x3d, 0xae, 0x69, 0x70, 0x8d, 0xd6, 0x51, 0x8c,
-	0x4a, 0x48, 0x3b, 0xa5, 0xc1, 0x35, 0xf9, 0x08, 0xd4, 0x08, 0xe2, 0xf9, 0xcc, 0xb2, 0x45, 0xe7,
-	0x93, 0xfd, 0xb9, 0x1e, 0xd2, 0xfb, 0x21, 0x59, 0x14, 0x78, 0x99, 0x64, 0xa6, 0x47, 0xad, 0x89,
-	0x69, 0x0b, 0x2b, 0xca, 0x79, 0xb5, 0x26, 0xe9, 0x7d, 0x6a, 0x4d, 0xce, 0x03, 0xca, 0xc9, 0x67,
-	0xf0, 0x28, 0x31, 0xd4, 0x26, 0xe0, 0x32, 0x8b, 0x89, 0x1f, 0x4f, 0xb5, 0xf1, 0x91, 0x27, 0xa0,
-	0x88, 0x8e, 0x61, 0x8e, 0x7d, 0x46, 0x39, 0x9b, 0x84, 0x79, 0x5c, 0x11, 0xb4, 0xb6, 0x24, 0x91,
-	0x06, 0x14, 0xd9, 0x8d, 0x67, 0xf9, 0x6c, 0x82, 0x1d, 0xa3, 0x64, 0x44, 0x3f, 0xc5, 0xe1, 0x80,
-	0xbb, 0x3e, 0xbd, 0x62, 0xa6, 0x43, 0x6d, 0x16, 0x8e, 0x28, 0x95, 0x90, 0xd6, 0xa5, 0x36, 0xd3,
-	0xde, 0x81, 0xed, 0xe7, 0x8c, 0x9f, 0x59, 0xaf, 0x67, 0xd6, 0xc4, 0xe2, 0xb7, 0x7d, 0xea, 0xd3,
-	0x79, 0x15, 0xfc, 0x43, 0x01, 0x36, 0xd2, 0x2c, 0xc6, 0x99, 0x2f, 0x3a, 0x50, 0xc1, 0x9f, 0x4d,
-	0x59, 0xe4, 0x9d, 0x79, 0xc7, 0x8c, 0xc1, 0xc6, 0x6c, 0xca, 0x0c, 0x09, 0x22, 0x5f, 0xc3, 0xce,
-	0x3c, 0xc4, 0x7c, 0xd1, 0x03, 0x03, 0xca, 0x4d, 0x8f, 0xf9, 0xe6, 0x1b, 0xd1, 0xe9, 0xd1, 0xfa,
-	0x98, 0x95, 0x32, 0xda, 0x0c, 0xca, 0x45, 0xc4, 0xf5, 0x99, 0xff, 0x52, 0xb0, 0xc9, 0x87, 0xa0,
-	0x26, 0x47, 0x45, 0xd3, 0xf3, 0x6c, 0xf4, 0x44, 0x3e, 0xae, 0x66, 0xc2, 0x5e, 0x9e, 0x4d, 0x3e,
-	0x05, 0xb1, 0x1f, 0x98, 0x29, 0x0b, 0x7b, 0x76, 0x98, 0xf4, 0x42, 0xc6, 0x7c, 0x69, 0x10, 0xf0,
-	0x2f, 0xa1, 0xb9, 0x7c, 0xd9, 0xc0, 0x53, 0x05, 0x3c, 0xb5, 0xb9, 0x64, 0xe1, 0x10, 0x67, 0xd3,
-	0x1b, 0x85, 0xf0, 0xe0, 0x1a, 0xe2, 0xe7, 0x1b, 0x85, 0xc8, 0x99, 0x8f, 0x60, 0x3d, 0x35, 0xc2,
-	0x22, 0xb0, 0x88, 0xc0, 0x5a, 0x62, 0x8c, 0x8d, 0xd3, 0x6b, 0x71, 0xfc, 0x2f, 0x2d, 0x1f, 0xff,
-	0x9f, 0xc1, 0x46, 0x34, 0xb8, 0x5c, 0xd0, 0xf1, 0xf7, 0xee, 0xe5, 0xa5, 0x19, 0xb0, 0x31, 0x16,
-	0xe5, 0xbc, 0xb1, 0x1e, 0xb2, 0x8e, 0x24, 0x67, 0xc0, 0xc6, 0xa4, 0x09, 0x25, 0x3a, 0xe3, 0xae,
-	0xf0, 0x11, 0x36, 0xe2, 0x92, 0x11, 0xff, 0x16, 0xb2, 0xa2, 0xbf, 0xcd, 0x8b, 0xd9, 0xe4, 0x8a,
-	0xc9, 0x72, 0x51, 0x91, 0xb2, 0x22, 0xd6, 0x11, 0x72, 0xc4, 0x3b, 0xbf, 0x80, 0xed, 0x3b, 0x78,
-	0x4e, 0x7d, 0x8e, 0x2f, 0x50, 0xa4, 0xcd, 0x16, 0x4e, 0x09, 0xb6, 0x78, 0xc6, 0xc7, 0x40, 0x04,
-	0xc7, 0x14, 0x26, 0xb1, 0x1c, 0xf3, 0x72, 0x6a, 0x5d, 0x5d, 0x73, 0x9c, 0x43, 0xf2, 0x46, 0x5d,
-	0x70, 0xce, 0xe9, 0x4d, 0xc7, 0x39, 0x41, 0xf2, 0xb2, 0x4e, 0x57, 0x0b, 0x7d, 0xfe, 0x53, 0x9d,
-	0xae, 0x9e, 0x8a, 0x0d, 0x89, 0xd3, 0xfe, 0x92, 0x81, 0x6a, 0x2a, 0x38, 0xb1, 0x48, 0xc9, 0x3d,
-	0xcd, 0x0c, 0x27, 0x81, 0xbc, 0x51, 0x0e, 0x29, 0x9d, 0x09, 0xd9, 0x84, 0x35, 0x6f, 0x76, 0xf1,
-	0x3d, 0xbb, 0xc5, 0x48, 0x50, 0x8c, 0xf0, 0x17, 0x79, 0x16, 0x8e, 0xa1, 0x59, 0x9c, 0x15, 0x9b,
-	0xcb, 0x23, 0x3f, 0x31, 0x8f, 0x7e, 0x0a, 0xc4, 0x72, 0xc6, 0xae, 0x2d, 0x62, 0x8b, 0x5f, 0xfb,
-	0x2c, 0xb8, 0x76, 0xa7, 0x13, 0x8c, 0xdf, 0xaa, 0xb1, 0x1e, 0x71, 0x86, 0x11, 0x43, 0xc0, 0xe3,
-	0x95, 0x71, 0x0e, 0xcf, 0x4b, 0x78, 0xc4, 0x89, 0xe1, 0xda, 0x2b, 0xd8, 0x1e, 0xac, 0xca, 0x5e,
-	0xf2, 0x15, 0x80, 0x17, 0xe7, 0x2c, 0x6a, 0x58, 0x39, 0xdc, 0xb9, 0xfb, 0xe0, 0x79, 0x5e, 0x1b,
-	0x09, 0xbc, 0xb6, 0x03, 0xcd, 0x65, 0xa2, 0x65, 0x81, 0xd6, 0x1e, 0xc1, 0xc6, 0x60, 0x76, 0x75,
-	0xc5, 0x16, 0x26, 0x35, 0x1f, 0x94, 0x63, 0x2b, 0x78, 0x3d, 0xa3, 0x53, 0xeb, 0xd2, 0x62, 0x93,
-	0x7f, 0xdd, 0xc8, 0xb9, 0x94, 0x91, 0x3f, 0x86, 0xb5, 0x70, 0x24, 0x97, 0x66, 0x9e, 0x0f, 0x77,
-	0xad, 0x19, 0x77, 0xc3, 0x79, 0x3c, 0x84, 0x68, 0x3f, 0x66, 0xe0, 0x61, 0xfa, 0x2d, 0x61, 0x13,
-	0x39, 0x84, 0x52, 0xb4, 0xac, 0x87, 0x85, 0x6a, 0x6b, 0xae, 0x7d, 0xea, 0x7b, 0x86, 0x51, 0x0c,
-	0x37, 0x77, 0xf2, 0x05, 0x28, 0x93, 0x84, 0x02, 0x8d, 0x2c, 0x9e, 0x7b, 0x14, 0x9f, 0x4b, 0x6a,
-	0x67, 0xa4, 0xa0, 0xfb, 0x4f, 0xa1, 0x14, 0xed, 0x22, 0x44, 0x81, 0xd2, 0x59, 0xaf, 0xd7, 0x37,
-	0x7b, 0xa3, 0xa1, 0xfa, 0x80, 0x54, 0xa0, 0x88, 0xbf, 0x3a, 0x5d, 0x35, 0xb3, 0x1f, 0x40, 0x39,
-	0x5e, 0x45, 0x48, 0x15, 0xca, 0x9d, 0x6e, 0x67, 0xd8, 0x69, 0x0d, 0xf5, 0x63, 0xf5, 0x01, 0x79,
-	0x04, 0xeb, 0x7d, 0x43, 0xef, 0x9c, 0xb7, 0x9e, 0xeb, 0xa6, 0xa1, 0xbf, 0xd4, 0x5b, 0x67, 0xfa,
-	0xb1, 0x9a, 0x21, 0x04, 0x6a, 0xa7, 0xc3, 0xb3, 0xb6, 0xd9, 0x1f, 0x1d, 0x9d, 0x75, 0x06, 0xa7,
-	0xfa, 0xb1, 0x9a, 0x15, 0x32, 0x07, 0xa3, 0x76, 0x5b, 0x1f, 0x0c, 0xd4, 0x1c, 0x01, 0x58, 0x3b,
-	0x69, 0x75, 0x04, 0x38, 0x4f, 0x36, 0xa0, 0xde, 0xe9, 0xbe, 0xec, 0x75, 0xda, 0xba, 0x39, 0xd0,
-	0x87, 0x43, 0x41, 0x2c, 0xec, 0xff, 0x23, 0x03, 0xd5, 0xd4, 0x36, 0x43, 0xb6, 0x60, 0x43, 0x1c,
-	0x19, 0x19, 0xe2, 0xa6, 0xd6, 0xa0, 0xd7, 0x35, 0xbb, 0xbd, 0xae, 0xae, 0x3e, 0x20, 0xef, 0xc0,
-	0xd6, 0x02, 0xa3, 0x77, 0x72, 0xd2, 0x3e, 0x6d, 0x89, 0xc7, 0x93, 0x26, 0x6c, 0x2e, 0x30, 0x87,
-	0x9d, 0x73, 0x5d, 0x68, 0x99, 0x25, 0xbb, 0xb0, 0xb3, 0xc0, 0x1b, 0x7c, 0xa7, 0xeb, 0xfd, 0x18,
-	0x91, 0x23, 0x4f, 0xe1, 0xc9, 0x02, 0xa2, 0xd3, 0x1d, 0x8c, 0x4e, 0x4e, 0x3a, 0xed, 0x8e, 0xde,
-	0x1d, 0x9a, 0x2f, 0x5b, 0x67, 0x23, 0x5d, 0xcd, 0x93, 0x1d, 0x68, 0x2c, 0x5e, 0xa2, 0x9f, 0xf7,
-	0x7b, 0x46, 0xcb, 0x78, 0xa5, 0x16, 0xc8, 0xfb, 0xf0, 0xf8, 0x8e, 0x90, 0x76, 0xcf, 0x30, 0xf4,
-	0xf6, 0xd0, 0x6c, 0x9d, 0xf7, 0x46, 0xdd, 0xa1, 0xba, 0xb6, 0x7f, 0x20, 0x36, 0x86, 0x85, 0x84,
-	0x14, 0x26, 0x1b, 0x75, 0xbf, 0xe9, 0xf6, 0xbe, 0xeb, 0xaa, 0x0f, 0x84, 0xe5, 0x87, 0xa7, 0x86,
-	0x3e, 0x38, 0xed, 0x9d, 0x1d, 0xab, 0x99, 0xfd, 0x5f, 0xe6, 0x00, 0xe6, 0xb1, 0x25, 0xac, 0xd3,
-	0x1a, 0x0d, 0x7b, 0xd1, 0x0d, 0xf3, 0x63, 0x1a, 0xbc, 0x97, 0x64, 0x1c, 0x8d, 0x8e, 0x9f, 0xeb,
-	0x43, 0xb3, 0xdb, 0x1b, 0x9a, 0x83, 0x61, 0xcb, 0x18, 0xa2, 0xbb, 0x9a, 0xb0, 0x99, 0xc4, 0x48,
-	0x2b, 0x9c, 0xe8, 0xfa, 0x40, 0xcd, 0x92, 0xf7, 0xa0, 0xb9, 0xe4, 0xbc, 0x7e, 0xd6, 0xea, 0x0f,
-	0xf4, 0x63, 0x35, 0x47, 0xb6, 0xe1, 0x51, 0x92, 0xdf, 0xe9, 0x9a, 0x27, 0x67, 0x9d, 0xe7, 0xa7,
-	0x43, 0x35, 0x4f, 0x1a, 0xf0, 0x30, 0x2d, 0xb6, 0x85, 0x52, 0xd5, 0xc2, 0xe2, 0xa1, 0xf3, 0x4e,
-	0x57, 0x37, 0x90, 0xb5, 0x46, 0x36, 0x81, 0x24, 0x59, 0x7d, 0x43, 0xef, 0xb7, 0x5e, 0xa9, 0x45,
-	0xf2, 0x18, 0xde, 0x49, 0xd2, 0x23, 0x8b, 0x1e, 0xb5, 0xda, 0xdf, 0xf4, 0x4e, 0x4e, 0xd4, 0xd2,
-	0xe2, 0x6d, 0x71, 0x34, 0x97, 0x17, 0x6d, 0x13, 0x45, 0x36, 0x08, 0xbf, 0xa5, 0x18, 0x9d, 0x6f,
-	0x47, 0x9d, 0xe3, 0xce, 0xf0, 0x95, 0xd9, 0xfb, 0x46, 0xad, 0x08, 0xbf, 0x2d, 0xd1, 0x3c, 0x19,
-	0x00, 0xaa, 0x72, 0xf8, 0xd7, 0xb2, 0xfc, 0x68, 0xd0, 0xc6, 0xcf, 0x94, 0xc4, 0x80, 0x62, 0x98,
-	0xa8, 0x64, 0x55, 0xea, 0x36, 0x1f, 0xa5, 0x16, 0xbf, 0xb8, 0x40, 0x6d, 0xfd, 0xe2, 0xcf, 0x7f,
-	0xfb, 0x75, 0x76, 0x5d, 0x53, 0x0e, 0xde, 0x7c, 0x76, 0x20, 0x10, 0x07, 0xee, 0x8c, 0x7f, 0x99,
-	0xd9, 0x27, 0x3d, 0x58, 0x93, 0x1f, 0xa7, 0xc8, 0x66, 0x4a, 0x64, 0xfc, 0xb5, 0x6a, 0x95, 0xc4,
-	0x4d, 0x94, 0xa8, 0x6a, 0x95, 0x58, 0xa2, 0xe5, 0x08, 0x81, 0x5f, 0x40, 0x31, 0xfc, 0xf4, 0x91,
-	0x78, 0x64, 0xfa, 0x63, 0x48, 0x73, 0xd9, 0x76, 0xfa, 0xdf, 0x19, 0xf2, 0xbf, 0x50, 0x8e, 0x17,
-	0x5b, 0xb2, 0x9d, 0x28, 0xcd, 0xe9, 0xb2, 0xda, 0x6c, 0x2e, 0x63, 0xa5, 0x9f, 0x45, 0x6a, 0xf1,
-	0xb3, 0x70, 0xe9, 0x25, 0x23, 0x59, 0x8e, 0xc4, 0xd2, 0x4b, 0x1a, 0xa9, 0xeb, 0x13, 0x7b, 0xf0,
-	0xd2, 0x87, 0x69, 0x4d, 0x14, 0xf9, 0x90, 0x90, 0x94, 0xc8, 0x83, 0x1f, 0xac, 0xc9, 0xff, 0x93,
-	0xff, 0x03, 0x25, 0x74, 0x00, 0xae, 0xa6, 0x64, 0x6e, 0xac, 0xe4, 0xfe, 0xdc, 0x9c, 0x2b, 0xb3,
-	0xb8, 0xc4, 0x2e, 0x91, 0xee, 0xce, 0xf8, 0x01, 0x47, 0x69, 0x17, 0xb1, 0x74, 0x5c, 0x79, 0x12,
-	0xd2, 0x93, 0xcb, 0x63, 0x5a, 0x7a, 0x6a, 0x39, 0xd2, 0x76, 0x51, 0x7a, 0x93, 0x34, 0x52, 0xd2,
-	0x5f, 0x0b, 0xcc, 0xc1, 0x0f, 0xd4, 0xe6, 0x42, 0x83, 0x9a, 0x98, 0x78, 0xd1, 0xe5, 0xf7, 0xea,
-	0x30, 0xb7, 0xda, 0xc2, 0xa7, 0x00, 0x6d, 0x1b, 0x2f, 0xd9, 0x20, 0xeb, 0x89, 0x50, 0x88, 0x35,
-	0x98, 0x4b, 0xbf, 0x57, 0x87, 0xa4, 0xf4, 0xb4, 0x0a, 0x8f, 0x51, 0xfa, 0x36, 0xd9, 0x4a, 0x4a,
-	0x4f, 0x6a, 0xf0, 0x0a, 0xaa, 0xe2, 0x8e, 0x68, 0xe7, 0x09, 0x12, 0x91, 0x9c, 0x5a, 0xac, 0x9a,
-	0x5b, 0x77, 0xe8, 0xe9, 0xec, 0x20, 0x75, 0xbc, 0x22, 0xa0, 0xfc, 0x40, 0x2e, 0x53, 0x84, 0x03,
-	0xb9, 0xbb, 0x0e, 0x10, 0x2d, 0x96, 0xb3, 0x72, 0x57, 0x68, 0xde, 0x3b, 0x59, 0x68, 0x3b, 0x78,
-	0xe1, 0x26, 0x79, 0x88, 0x17, 0x46, 0x80, 0x03, 0x4f, 0xca, 0xff, 0x19, 0x90, 0xc1, 0x7d, 0xb7,
-	0xae, 0x9c, 0x71, 0x9a, 0xef, 0xdf, 0x8b, 0x49, 0x1b, 0x54, 0x5b, 0x7a, 0xb9, 0x48, 0x61, 0x06,
-	0x4a, 0x72, 0x82, 0x20, 0x73, 0x5d, 0x96, 0x0c, 0x39, 0xcd, 0x77, 0x57, 0x70, 0xc3, 0xdb, 0x1a,
-	0x78, 0x1b, 0x21, 0xaa, 0xb8, 0x4d, 0xcc, 0xb5, 0x07, 0x81, 0x84, 0x5d, 0xac, 0xe1, 0xff, 0x53,
-	0x3e, 0xff, 0x67, 0x00, 0x00, 0x00, 0xff, 0xff, 0xe6, 0x11, 0x1a, 0x17, 0x86, 0x19, 0x00, 0x00,
+	// 3548 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x7a, 0xdd, 0x6e, 0xe3, 0x58,
+	0x72, 0x7f, 0xeb, 0xcb, 0x92, 0x4a, 0x5f, 0xf4, 0x71, 0xb7, 0x5b, 0x56, 0x7b, 0xba, 0xdd, 0x9c,
+	0xed, 0xff, 0x78, 0x3c, 0x3b, 0xed, 0x5d, 0xef, 0xff, 0x22, 0x33, 0xd8, 0xbd, 0x50, 0xdb, 0x72,
+	0x5b, 0x3d, 0xb6, 0xa4, 0xa5, 0xe4, 0x5e, 0x74, 0x10, 0x80, 0xa0, 0xc5, 0x63, 0x9b, 0x18, 0x89,
+	0x64, 0x93, 0x47, 0x6e, 0x1b, 0x8b, 0x24, 0x40, 0x90, 0xe4, 0x2a, 0x40, 0x2e, 0xf2, 0x06, 0x41,
+	0x80, 0x5c, 0xee, 0x03, 0xe4, 0x01, 0x02, 0x04, 0xb9, 0x08, 0x92, 0x3c, 0xc2, 0xde, 0xe6, 0x1d,
+	0x82, 0xaa, 0x73, 0x48, 0x91, 0xb2, 0xe4, 0x99, 0xb9, 0xc8, 0x95, 0xc5, 0xaa, 0x1f, 0xab, 0xce,
+	0xa9, 0x53, 0x55, 0xa7, 0xaa, 0x68, 0xa8, 0x8e, 0x27, 0x0e, 0x77, 0xc5, 0x6b, 0x3f, 0xf0, 0x84,
+	0xc7, 0x8a, 0x13, 0xcf, 0xf3, 0x03, 0x7f, 0xdc, 0xda, 0xbe, 0xf2, 0xbc, 0xab, 0x09, 0xdf, 0xb7,
+	0x7c, 0x67, 0xdf, 0x72, 0x5d, 0x4f, 0x58, 0xc2, 0xf1, 0xdc, 0x50, 0xc2, 0xf4, 0xbf, 0x5b, 0x83,
+	0xfa, 0xa9, 0xe7, 0xf9, 0xfd, 0x99, 0x30, 0xf8, 0xc7, 0x19, 0x0f, 0x05, 0xd3, 0x20, 0x67, 0x4d,
+	0x45, 0x33, 0xb3, 0x93, 0xd9, 0xcd, 0x19, 0xf8, 0x93, 0x31, 0xc8, 0xdb, 0x3c, 0x14, 0xcd, 0xec,
+	0x4e, 0x66, 0xb7, 0x6c, 0xd0, 0x6f, 0xb6, 0x0f, 0x8f, 0xa7, 0xd6, 0xad, 0x19, 0x7e, 0xb2, 0x7c,
+	0x33, 0xf0, 0x66, 0xc2, 0x71, 0xaf, 0xcc, 0x4b, 0xce, 0x9b, 0x39, 0x7a, 0x6d, 0x7d, 0x6a, 0xdd,
+	0x0e, 0x3f, 0x59, 0xbe, 0x21, 0x39, 0xc7, 0x9c, 0xb3, 0x5f, 0xc1, 0x26, 0xbe, 0xe0, 0x07, 0xdc,
+	0xb7, 0xee, 0x52, 0xaf, 0xe4, 0xe9, 0x95, 0x8d, 0xa9, 0x75, 0x3b, 0x20, 0x66, 0xe2, 0xa5, 0x1d,
+	0xa8, 0xc6, 0x5a, 0x10, 0x5a, 0x20, 0x28, 0x28, 0xe9, 0x88, 0xf8, 0x19, 0xd4, 0x13, 0x62, 0x71,
+	0xe1, 0x6b, 0x84, 0xa9, 0xc6, 0xe2, 0xda, 0x53, 0xc1, 0x74, 0xa8, 0x21, 0x6a, 0xea, 0xb8, 0x3c,
+	0x20, 0x41, 0x45, 0x02, 0x55, 0xa6, 0xd6, 0xed, 0x19, 0xd2, 0x50, 0xd2, 0xcf, 0x41, 0x43, 0x9b,
+	0x99, 0xde, 0x4c, 0x98, 0xe3, 0x6b, 0xcb, 0x75, 0xf9, 0xa4, 0x59, 0xda, 0xc9, 0xec, 0xe6, 0xdf,
+	0x64, 0x9b, 0x19, 0xa3, 0x3e, 0x91, 0x56, 0x3a, 0x94, 0x1c, 0xb6, 0x07, 0xeb, 0xde, 0x4c, 0x5c,
+	0x79, 0xb8, 0x09, 0x44, 0x9b, 0x21, 0x17, 0xcd, 0xca, 0x4e, 0x6e, 0x37, 0x6f, 0x34, 0x22, 0x06,
+	0x62, 0x87, 0x5c, 0x20, 0x36, 0xfc, 0xc4, 0xb9, 0x6f, 0x8e, 0x3d, 0xf7, 0xd2, 0x14, 0x56, 0x70,
+	0xc5, 0x45, 0xb3, 0xbc, 0x93, 0xd9, 0x2d, 0x18, 0x0d, 0x62, 0x1c, 0x7a, 0xee, 0xe5, 0x88, 0xc8,
+	0xec, 0x6b, 0x60, 0xd7, 0x62, 0x32, 0x26, 0xa8, 0x13, 0x4c, 0xe5, 0x61, 0x35, 0x6b, 0x04, 0x5e,
+	0x47, 0xce, 0x61, 0x92, 0xc1, 0xbe, 0x85, 0x2d, 0x32, 0x8e, 0x3f, 0xbb, 0x98, 0x38, 0x63, 0x22,
+	0x9a, 0x36, 0xb7, 0xec, 0x89, 0xe3, 0xf2, 0x26, 0xe0, 0xea, 0x8d, 0xa7, 0x08, 0x18, 0xcc, 0xf9,
+	0x47, 0x8a, 0xcd, 0x1e, 0x43, 0x61, 0x62, 0x5d, 0xf0, 0x49, 0xb3, 0x4a, 0xe7, 0x2a, 0x1f, 0xd8,
+	0x36, 0x94, 0x1d, 0xd7, 0x11, 0x8e, 0x25, 0xbc, 0xa0, 0x59, 0x27, 0xce, 0x9c, 0xc0, 0xb6, 0xa0,
+	0x34, 0xb1, 0x42, 0x61, 0x5e, 0x7b, 0x7e, 0xb3, 0xb1, 0x93, 0xd9, 0xad, 0x1a, 0x45, 0x7c, 0x3e,
+	0xf1, 0x7c, 0xf6, 0x73, 0x60, 0xbe, 0x75, 0x37, 0xe5, 0xae, 0x30, 0xc7, 0x13, 0x71, 0x63, 0x4e,
+	0x9c, 0xa9, 0x23, 0x9a, 0x1a, 0xad, 0x5c, 0x53, 0x9c, 0xc3, 0x89, 0xb8, 0x39, 0x45, 0x3a, 0xfb,
+	0x1c, 0x6a, 0xb1, 0xfd, 0x7c, 0xce, 0x83, 0xe6, 0x3a, 0x49, 0xab, 0x46, 0xc4, 0x01, 0xe7, 0x01,
+	0x3b, 0x01, 0x26, 0x0d, 0x87, 0x2e, 0xe7, 0xb8, 0xca, 0x18, 0x6c, 0x27, 0xb7, 0x5b, 0x39, 0xd8,
+	0x7a, 0xad, 0x3c, 0xfc, 0xf5, 0x10, 0x21, 0x47, 0x73, 0x84, 0xb1, 0x1e, 0x2e, 0x50, 0x42, 0xf6,
+	0x0a, 0xea, 0xfc, 0x76, 0x3c, 0x99, 0xd9, 0xdc, 0x36, 0x5d, 0xcf, 0xe6, 0x61, 0x73, 0x63, 0x27,
+	0xb7, 0x5b, 0x35, 0x6a, 0x11, 0xb5, 0x87, 0x44, 0xfd, 0x08, 0xb4, 0x45, 0x69, 0xac, 0x09, 0x45,
+	0xcb, 0xb6, 0x03, 0x1e, 0x86, 0x14, 0x13, 0x65, 0x23, 0x7a, 0x64, 0x9b, 0xb0, 0xf6, 0x89, 0x3b,
+	0x57, 0xd7, 0x32, 0x32, 0x6a, 0x86, 0x7a, 0xd2, 0xff, 0x2d, 0x0b, 0x35, 0x0c, 0xaa, 0xae, 0xbb,
+	0x3a, 0xa6, 0x16, 0x3d, 0x3b, 0x7b, 0xcf, 0xb3, 0xef, 0xf9, 0x6c, 0xee, 0xbe, 0xcf, 0x26, 0x8f,
+	0x23, 0x9f, 0x3e, 0x8e, 0xcf, 0xa1, 0xc6, 0x6f, 0x05, 0x0f, 0x5c, 0x6b, 0x62, 0xa2, 0xdf, 0x50,
+	0xec, 0x94, 0x8c, 0x6a, 0x44, 0x3c, 0x11, 0x93, 0x31, 0xdb, 0x05, 0x2d, 0xf6, 0xb6, 0xc8, 0x31,
+	0xd7, 0xe8, 0xc4, 0xea, 0x91, 0xaf, 0x29, 0xbf, 0x8c, 0x9d, 0xa5, 0xb8, 0xd2, 0x59, 0x4a, 0x8b,
+	0xce, 0xb2, 0x0d, 0x65, 0x6f, 0x26, 0x7c, 0xcf, 0x71, 0x45, 0xd8, 0x2c, 0xef, 0xe4, 0x90, 0x1b,
+	0x13, 0xf0, 0x48, 0x30, 0x70, 0xae, 0xb8, 0x19, 0x99, 0x17, 0x48, 0x40, 0x4d, 0x52, 0xdb, 0x92,
+	0xa8, 0x07, 0xc0, 0xa4, 0x2d, 0xdf, 0x58, 0x62, 0x7c, 0x1d, 0x19, 0xf4, 0x00, 0x4a, 0x81, 0xfc,
+	0x89, 0xa7, 0x82, 0xfe, 0xb0, 0x19, 0xfb, 0x43, 0xca, 0xf4, 0x46, 0x8c, 0x5b, 0xba, 0xd9, 0xec,
+	0xb2, 0xcd, 0xea, 0x7f, 0x9b, 0x85, 0x2a, 0xa5, 0x2f, 0x1e, 0xfa, 0x9e, 0x1b, 0x72, 0xc6, 0x20,
+	0xeb, 0xd8, 0xf2, 0xf8, 0x29, 0x1b, 0x64, 0x1d, 0x1b, 0x6d, 0xef, 0xd8, 0xe6, 0xc5, 0x9d, 0xe0,
+	0x21, 0x1d, 0x4d, 0xd5, 0x28, 0x3a, 0xf6, 0x1b, 0x7c, 0x64, 0xaf, 0xa0, 0x4a, 0x9a, 0xa2, 0x8d,
+	0x65, 0xe3, 0x17, 0x2b, 0x48, 0x57, 0x5b, 0x63, 0xaf, 0x61, 0x23, 0x09, 0x33, 0x5d, 0xff, 0xe0,
+	0x53, 0x78, 0x4d, 0x07, 0x59, 0x36, 0xd6, 0x13, 0xc8, 0x1e, 0x31, 0x30, 0xc2, 0x52, 0x78, 0x09,
+	0x2f, 0x10, 0x5c, 0x4b, 0xc0, 0x07, 0x84, 0x7e, 0x05, 0xf5, 0x90, 0x07, 0x37, 0x3c, 0x30, 0xa7,
+	0x3c, 0x0c, 0xad, 0x2b, 0x4e, 0x27, 0x5b, 0x36, 0x6a, 0x92, 0x7a, 0x26, 0x89, 0xec, 0x19, 0x94,
+	0x49, 0xa8, 0x1f, 0x5e, 0x08, 0x3a, 0xdc, 0xaa, 0x51, 0x42, 0xc2, 0x20, 0xbc, 0x10, 0xba, 0x09,
+	0x1b, 0x29, 0xe3, 0x2b, 0x73, 0x7c, 0x05, 0x05, 0x74, 0xdc, 0xc8, 0xf4, 0x4f, 0x12, 0xa1, 0x38,
+	0x37, 0x9a, 0x21, 0x31, 0xb1, 0x02, 0x71, 0xeb, 0xd8, 0xea, 0x0a, 0x21, 0x05, 0xa3, 0x5b, 0xc7,
+	0xd6, 0xbf, 0x83, 0xa6, 0x54, 0x40, 0x09, 0x2a, 0xbc, 0x46, 0xad, 0xd1, 0x19, 0xd7, 0x63, 0xa3,
+	0x57, 0xc9, 0xe0, 0x2f, 0xa0, 0x12, 0x3a, 0x57, 0x2e, 0xb7, 0xe5, 0x5a, 0xb3, 0xc4, 0x00, 0x49,
+	0xa2, 0xd5, 0xfe, 0x09, 0x6c, 0x2d, 0x11, 0xa6, 0xd6, 0x9c, 0x5a, 0x46, 0x66, 0x61, 0x19, 0x1a,
+	0xd4, 0xcf, 0x3c, 0xd7, 0x11, 0x5e, 0xa0, 0x94, 0xeb, 0xff, 0x5c, 0x04, 0xc0, 0xdd, 0x0c, 0x85,
+	0x25, 0x66, 0xe1, 0xd2, 0x4b, 0x31, 0xeb, 0xd8, 0x89, 0x93, 0x5d, 0x74, 0x89, 0xca, 0xa2, 0x4b,
+	0xe4, 0xc5, 0x9d, 0x2f, 0x83, 0xb8, 0x7e, 0xb0, 0x9e, 0xb2, 0xd8, 0xe8, 0xce, 0xe7, 0x06, 0xb1,
+	0xd9, 0x2e, 0x14, 0x42, 0x61, 0x09, 0x79, 0x29, 0xd6, 0x0f, 0x58, 0x0a, 0x87, 0x6b, 0x41, 0xb3,
+	0xe2, 0x1f, 0xf6, 0x1b, 0xa8, 0x5f, 0x5a, 0xce, 0x64, 0x16, 0x70, 0x33, 0xe0, 0x56, 0xe8, 0xb9,
+	0x94, 0xac, 0xeb, 0x89, 0x38, 0x38, 0x96, 0x6c, 0x83, 0xb8, 0x46, 0xed, 0x32, 0xf9, 0xc8, 0xbe,
+	0x80, 0x86, 0x0a, 0x54, 0xbc, 0x32, 0x84, 0x33, 0x8d, 0x2e, 0xd7, 0xfa, 0x9c, 0x3c, 0x72, 0xa6,
+	0xb8, 0x22, 0x8d, 0x52, 0xcc, 0xcc, 0xb7, 0x2d, 0xc1, 0x25, 0x52, 0x5e, 0xb1, 0x75, 0xa4, 0x9f,
+	0x13, 0x99, 0x90, 0x8b, 0x5e, 0x5f, 0x5c, 0xee, 0xf5, 0xcb, 0xbd, 0xb8, 0xba, 0xc2, 0x8b, 0x57,
+	0xc4, 0x48, 0x6d, 0x55, 0x8c, 0xbc, 0x80, 0xca, 0xd8, 0x0b, 0x85, 0x29, 0x9d, 0x9c, 0x72, 0x52,
+	0xce, 0x00, 0x24, 0x0d, 0x89, 0xc2, 0x5e, 0x42, 0x95, 0x00, 0x9e, 0x3b, 0xbe, 0xb6, 0x1c, 0x97,
+	0xee, 0xe1, 0x9c, 0x41, 0x2f, 0xf5, 0x25, 0x09, 0x53, 0xa7, 0x84, 0x5c, 0x5e, 0x4a, 0x0c, 0xc8,
+	0x92, 0x82, 0x30, 0x8a, 0x36, 0x4f, 0x88, 0x8d, 0x64, 0x42, 0x7c, 0x06, 0x65, 0x79, 0x63, 0x61,
+	0xc2, 0xd6, 0xe8, 0xb5, 0x12, 0x11, 0x30, 0x5b, 0x37, 0xa1, 0x18, 0x70, 0x2f, 0xb8, 0xe2, 0x36,
+	0xdd, 0x76, 0x25, 0x23, 0x7a, 0x4c, 0xe7, 0x51, 0xb6, 0x98, 0x47, 0xf7, 0x60, 0x8d, 0xdf, 0x70,
+	0x57, 0xc8, 0x4b, 0xab, 0xb2, 0xe0, 0x15, 0x1d, 0x64, 0x19, 0x0a, 0x91, 0x76, 0xf3, 0xc7, 0x69,
+	0x37, 0xc7, 0x8d, 0x11, 0x33, 0x4a, 0xc2, 0xcd, 0x27, 0x04, 0xa0, 0x63, 0xeb, 0x2b, 0x5a, 0x3a,
+	0x4d, 0x5e, 0xcb, 0xfb, 0x6d, 0x33, 0x9d, 0x26, 0x4f, 0x88, 0xca, 0x3e, 0x03, 0x90, 0x9b, 0x25,
+	0x65, 0x4f, 0xe5, 0xb2, 0x89, 0x42, 0xda, 0x90, 0xed, 0x73, 0xd7, 0x96, 0xec, 0xa6, 0x62, 0x23,
+	0x85, 0xd8, 0x58, 0x15, 0x11, 0x3b, 0xa9, 0x68, 0x4b, 0x55, 0x45, 0xc8, 0x48, 0x68, 0x6a, 0x41,
+	0xc9, 0x0a, 0xc6, 0xd7, 0xce, 0x0d, 0xb7, 0x9b, 0x2d, 0x32, 0x5d, 0xfc, 0xac, 0xff, 0x31, 0x07,
+	0xe5, 0xd8, 0x0e, 0xf3, 0x00, 0xca, 0xfc, 0xf4, 0x00, 0xca, 0xfe, 0x94, 0x00, 0x62, 0x90, 0xa7,
+	0x58, 0x90, 0xb7, 0x32, 0xfd, 0x5e, 0x74, 0xbe, 0xfc, 0x0f, 0x3a, 0x5f, 0xe1, 0x47, 0x38, 0xdf,
+	0xda, 0x12, 0xe7, 0x4b, 0x9d, 0x72, 0x71, 0xe1, 0x94, 0xd3, 0xc7, 0x52, 0x5a, 0x3c, 0x96, 0x94,
+	0x8b, 0x96, 0x17, 0x5c, 0xf4, 0x9e, 0x87, 0xc0, 0x8f, 0xf4, 0x90, 0xca, 0x4a, 0x0f, 0x99, 0xbb,
+	0x40, 0xf5, 0x47, 0xb9, 0x40, 0x6d, 0xa9, 0x0b, 0xe8, 0xff, 0x94, 0x01, 0xed, 0xd4, 0x09, 0x05,
+	0x9e, 0x63, 0x18, 0x5d, 0x11, 0xbf, 0x80, 0xb5, 0x4b, 0x67, 0x22, 0x78, 0x40, 0xc7, 0x5d, 0x39,
+	0x68, 0xce, 0x8b, 0x80, 0x08, 0x7a, 0x4c, 0x7c, 0x43, 0xe1, 0xb0, 0x66, 0x1b, 0xcf, 0x82, 0xd0,
+	0x0b, 0xd4, 0x55, 0xa4, 0x9e, 0xd0, 0x2a, 0x51, 0x3d, 0x26, 0xaf, 0xf3, 0x9a, 0x51, 0x52, 0xc5,
+	0x58, 0xc8, 0xbe, 0x04, 0xcd, 0x71, 0xa9, 0x4e, 0x34, 0x63, 0x37, 0xcc, 0x93, 0x1b, 0x36, 0x14,
+	0xbd, 0x1d, 0x79, 0xe3, 0x7f, 0xe4, 0xa0, 0xb1, 0xa0, 0x9b, 0x1d, 0xa1, 0xc5, 0x2d, 0xdf, 0xa4,
+	0x0b, 0x40, 0xfa, 0xe5, 0x17, 0xab, 0x16, 0x1a, 0x5f, 0x08, 0x6a, 0xdd, 0xa5, 0x50, 0x3d, 0xb3,
+	0x2e, 0x54, 0xc8, 0x71, 0x49, 0x0c, 0xd6, 0x14, 0xb9, 0xdd, 0xfa, 0xc1, 0xee, 0x6a, 0x39, 0x88,
+	0x4d, 0x08, 0x82, 0x30, 0x22, 0x84, 0x58, 0x5a, 0x86, 0xc2, 0x0a, 0x04, 0x65, 0x73, 0xd3, 0x0d,
+	0xa3, 0xd2, 0x92, 0x88, 0x98, 0xcb, 0x7b, 0x21, 0x7b, 0x0e, 0x15, 0x3a, 0x38, 0x85, 0x90, 0xbe,
+	0x5c, 0xc6, 0x93, 0x93, 0xfc, 0x38, 0xff, 0x15, 0x92, 0xf9, 0x6f, 0x17, 0xb4, 0x74, 0x5b, 0xe4,
+	0xd8, 0xe4, 0xc0, 0x79, 0xa3, 0x9e, 0xec, 0x8a, 0xba, 0x76, 0xaa, 0x74, 0x2d, 0xa6, 0x4b, 0xd7,
+	0x07, 0xab, 0x4a, 0xfd, 0xff, 0x43, 0x3d, 0x6d, 0x23, 0x56, 0x84, 0x5c, 0xbb, 0xf7, 0x41, 0x7b,
+	0xc4, 0xaa, 0x50, 0x3a, 0xed, 0xf7, 0x07, 0x66, 0xff, 0x7c, 0xa4, 0x65, 0x58, 0x05, 0x8a, 0xf4,
+	0xd4, 0xed, 0x69, 0x59, 0xfd, 0x1b, 0x68, 0x2c, 0x58, 0x04, 0xf9, 0x83, 0x4e, 0xef, 0xa8, 0xdb,
+	0x7b, 0xab, 0x3d, 0x62, 0x35, 0x28, 0x0f, 0xcf, 0x0f, 0x0f, 0x3b, 0x9d, 0xa3, 0xce, 0x91, 0x96,
+	0x61, 0x00, 0x6b, 0xc7, 0xed, 0xee, 0x69, 0xe7, 0x48, 0xcb, 0xea, 0x26, 0xac, 0x27, 0x1c, 0x4f,
+	0x95, 0x13, 0x5f, 0xa6, 0x4b, 0xa0, 0x8d, 0x7b, 0x79, 0x66, 0x16, 0x46, 0x05, 0xd0, 0x0b, 0xa8,
+	0xb8, 0xfc, 0x56, 0x98, 0x29, 0xbf, 0x03, 0x24, 0x1d, 0x12, 0x45, 0x7f, 0x09, 0x0d, 0x7c, 0xab,
+	0xeb, 0x5e, 0x7a, 0x2b, 0x6a, 0x1f, 0xbd, 0x0e, 0xd5, 0x11, 0x0f, 0xa6, 0x91, 0xe3, 0xeb, 0x7f,
+	0x09, 0x8d, 0xae, 0xab, 0x28, 0x6a, 0x45, 0xff, 0x0f, 0x1a, 0x53, 0xc7, 0x95, 0x1d, 0x85, 0x35,
+	0xf5, 0x66, 0xae, 0x50, 0xe9, 0xa5, 0x36, 0x75, 0x5c, 0x94, 0xdf, 0x26, 0x22, 0xe1, 0xac, 0xdb,
+	0x14, 0x6e, 0x4d, 0xe1, 0xac, 0xdb, 0x39, 0xee, 0x5d, 0xbe, 0x94, 0xd1, 0xb2, 0xef, 0xf2, 0xa5,
+	0xac, 0x96, 0x7b, 0x97, 0x2f, 0xe5, 0xb4, 0xfc, 0xbb, 0x7c, 0x29, 0xaf, 0x15, 0xde, 0xe5, 0x4b,
+	0x45, 0xad, 0xa4, 0xff, 0x7b, 0x06, 0xb4, 0xfe, 0x4c, 0xfc, 0x9f, 0x2e, 0x81, 0x9a, 0x7b, 0xc7,
+	0x95, 0xed, 0xa4, 0xcd, 0x27, 0xc2, 0x22, 0x6f, 0x28, 0x18, 0xd5, 0xa9, 0xe3, 0x62, 0x2b, 0x79,
+	0x84, 0xb4, 0x68, 0x04, 0x90, 0x40, 0x95, 0x15, 0xca, 0xba, 0x8d, 0x51, 0x3f, 0xb0, 0x9d, 0x7f,
+	0xcc, 0x40, 0xf5, 0xb7, 0x33, 0x4f, 0xf0, 0xd5, 0x1d, 0x1b, 0x25, 0xf7, 0xc5, 0xce, 0x01, 0xc6,
+	0xf3, 0x16, 0xe9, 0x5e, 0xc7, 0x95, 0x5b, 0xd2, 0x71, 0x3d, 0xd8, 0xb0, 0xe7, 0x1f, 0x6c, 0xd8,
+	0xf5, 0xbf, 0xcf, 0xe0, 0xa9, 0xab, 0x65, 0x2a, 0x93, 0xef, 0x40, 0x35, 0xea, 0x21, 0xcd, 0xd0,
+	0x8a, 0x16, 0x0c, 0xa1, 0x6c, 0x22, 0x87, 0x16, 0x4d, 0x6a, 0x64, 0x81, 0x2f, 0x8b, 0xe2, 0x18,
+	0xa9, 0x26, 0x35, 0xc8, 0x53, 0xf5, 0xb2, 0x7a, 0xe1, 0x33, 0x80, 0x84, 0x2d, 0x0b, 0xb4, 0xcf,
+	0xf2, 0x38, 0x61, 0x48, 0x69, 0xc2, 0xbc, 0x56, 0xd0, 0xff, 0x53, 0x7a, 0xc1, 0x4f, 0x5d, 0xd2,
+	0xcf, 0xa0, 0x3e, 0x1f, 0xd8, 0x10, 0x46, 0xb6, 0xbf, 0x55, 0x3f, 0x9a, 0xd8, 0x20, 0xea, 0x2b,
+	0x55, 0x28, 0xc6, 0xb7, 0x55, 0x62, 0xd9, 0x0d, 0xe4, 0x0c, 0xd5, 0xad, 0x85, 0x60, 0x9a, 0xb1,
+	0xa0, 0x5d, 0xd5, 0x08, 0x82, 0x06, 0x56, 0xb2, 0x25, 0x6e, 0x90, 0x3d, 0x25, 0x1d, 0x1b, 0xfb,
+	0x1f, 0xd8, 0xa0, 0xde, 0x80, 0xda, 0xc8, 0xfb, 0x9e, 0xbb, 0x71, 0xb0, 0xfd, 0x1a, 0xea, 0x11,
+	0x41, 0x6d, 0x71, 0x0f, 0xd6, 0x04, 0x51, 0x54, 0xf8, 0xcf, 0xcb, 0x8c, 0xd3, 0xd0, 0x12, 0x04,
+	0x36, 0x14, 0x42, 0xff, 0x97, 0x2c, 0x94, 0x63, 0x2a, 0x3a, 0xc9, 0x85, 0x15, 0x72, 0x73, 0x6a,
+	0x8d, 0xad, 0xc0, 0xf3, 0x5c, 0x15, 0xe3, 0x55, 0x24, 0x9e, 0x29, 0x1a, 0x96, 0x09, 0xd1, 0x3e,
+	0xae, 0xad, 0xf0, 0x5a, 0xb5, 0x3a, 0x15, 0x45, 0x3b, 0xb1, 0xc2, 0x6b, 0xbc, 0x92, 0x22, 0x88,
+	0x1f, 0x70, 0x67, 0x8a, 0xfd, 0x9d, 0xec, 0x42, 0x1b, 0x8a, 0x3e, 0x50, 0x64, 0xcc, 0xc9, 0x32,
+	0xc8, 0x4c, 0xdf, 0x72, 0x6c, 0x73, 0x1a, 0x5a, 0xd2, 0x32, 0x39, 0xa3, 0x2e, 0xe9, 0x03, 0xcb,
+	0xb1, 0xcf, 0x42, 0x4b, 0xb0, 0x5f, 0xc2, 0x93, 0xc4, 0x60, 0x2e, 0x01, 0x97, 0x51, 0xcc, 0x82,
+	0x78, 0x32, 0x17, 0xbf, 0xf2, 0x12, 0xaa, 0x74, 0x45, 0x8c, 0x03, 0x6e, 0x09, 0x6e, 0xab, 0x38,
+	0xae, 0x20, 0xed, 0x50, 0x92, 0xb0, 0xec, 0xe5, 0xb7, 0xbe, 0x13, 0x70, 0x59, 0xaa, 0x94, 0x8c,
+	0xe8, 0x11, 0x5f, 0x0e, 0x85, 0x17, 0x58, 0x57, 0xdc, 0x74, 0xad, 0x29, 0x57, 0xb9, 0xbe, 0xa2,
+	0x68, 0x3d, 0x6b, 0xca, 0xf5, 0x67, 0xb0, 0xf5, 0x96, 0x8b, 0x53, 0xe7, 0xe3, 0xcc, 0xb1, 0x1d,
+	0x71, 0x37, 0xb0, 0x02, 0x6b, 0x9e, 0x05, 0xff, 0xb5, 0x00, 0x1b, 0x69, 0x16, 0x17, 0x3c, 0xc0,
+	0x16, 0xa3, 0x10, 0xcc, 0x26, 0x7c, 0xc9, 0x68, 0x20, 0x02, 0x1b, 0xb3, 0x09, 0x37, 0x24, 0x88,
+	0xfd, 0x06, 0xb6, 0xe7, 0x2e, 0x16, 0xe0, 0x0d, 0x1b, 0x5a, 0xc2, 0xf4, 0x79, 0x60, 0xde, 0x60,
+	0x2b, 0xd7, 0xcc, 0x46, 0x51, 0x29, 0xbd, 0xcd, 0xb0, 0x04, 0x7a, 0xdc, 0x80, 0x07, 0xef, 0x91,
+	0xcd, 0xbe, 0x00, 0x2d, 0x39, 0xc9, 0x31, 0x7d, 0x7f, 0x4a, 0x27, 0x91, 0x8f, 0xb3, 0x19, 0xda,
+	0xcb, 0x9f, 0xb2, 0xaf, 0x01, 0x67, 0x9c, 0x66, 0xca, 0xc2, 0xfe, 0x54, 0x05, 0x3d, 0xca, 0x98,
+	0x0f, 0x3e, 0x11, 0xfe, 0x2d, 0xb4, 0x96, 0x0f, 0x4c, 0xe9, 0xad, 0x02, 0xbd, 0xb5, 0xb9, 0x64,
+	0x68, 0x8a, 0xef, 0xa6, 0xa7, 0xa2, 0x78, 0x82, 0xf2, 0x12, 0x9e, 0x4f, 0x45, 0x31, 0x66, 0xbe,
+	0x84, 0xf5, 0xd4, 0x84, 0x89, 0x80, 0x45, 0x79, 0x5b, 0x27, 0xa6, 0x4c, 0x71, 0x78, 0x2d, 0x8e,
+	0x30, 0x4b, 0xcb, 0x47, 0x98, 0xaf, 0x61, 0x23, 0x2a, 0xac, 0x2f, 0xac, 0xf1, 0xf7, 0xde, 0xe5,
+	0xa5, 0x19, 0xf2, 0x31, 0x25, 0xe5, 0xbc, 0xb1, 0xae, 0x58, 0x6f, 0x24, 0x67, 0xc8, 0xc7, 0x54,
+	0xdc, 0xcf, 0x84, 0x87, 0x67, 0xd4, 0x04, 0x55, 0xdc, 0xab, 0x67, 0x94, 0x15, 0xfd, 0x36, 0x2f,
+	0x66, 0xf6, 0x15, 0x97, 0xe9, 0xa2, 0x22, 0x65, 0x45, 0xac, 0x37, 0xc4, 0xc1, 0x75, 0x7e, 0x03,
+	0x5b, 0xf7, 0xf0, 0x54, 0xe9, 0xe0, 0x0a, 0xaa, 0xd2, 0x66, 0x0b, 0x6f, 0x21, 0x1b, 0x97, 0xf1,
+	0x15, 0x30, 0xe4, 0x98, 0x68, 0x12, 0xc7, 0x35, 0x2f, 0x27, 0x71, 0x35, 0x9a, 0x37, 0x1a, 0xc8,
+	0x39, 0xb3, 0x6e, 0xbb, 0xee, 0x31, 0x91, 0x97, 0xdd, 0x74, 0x75, 0x75, 0xe6, 0x3f, 0x74, 0xd3,
+	0x35, 0x52, 0xbe, 0x21, 0x71, 0xfa, 0x7f, 0x67, 0xa0, 0x96, 0x72, 0x4e, 0x4a, 0x52, 0x72, 0xd6,
+	0x6c, 0xaa, 0x4a, 0x20, 0x6f, 0x94, 0x15, 0xa5, 0x6b, 0x63, 0x1d, 0xeb, 0xcf, 0x2e, 0xbe, 0xe7,
+	0x77, 0xe4, 0x09, 0x55, 0x43, 0x3d, 0xb1, 0xd7, 0x6a, 0xce, 0x20, 0x7b, 0x99, 0xd6, 0x72, 0xcf,
+	0x4f, 0x0c, 0x1c, 0xbe, 0x06, 0xe6, 0xb8, 0x63, 0x6f, 0x8a, 0xbe, 0x25, 0xae, 0x03, 0x1e, 0x5e,
+	0x7b, 0x13, 0x5b, 0x15, 0xc0, 0xeb, 0x11, 0x67, 0x14, 0x31, 0x10, 0x1e, 0xd7, 0x77, 0x73, 0x78,
+	0x5e, 0xc2, 0x23, 0x4e, 0x0c, 0xd7, 0x3f, 0xc0, 0xd6, 0x70, 0x55, 0xf4, 0xb2, 0x5f, 0x03, 0xf8,
+	0x71, 0xcc, 0xaa, 0x02, 0x7e, 0xfb, 0xfe, 0x82, 0xe7, 0x71, 0x6d, 0x24, 0xf0, 0xfa, 0x36, 0xb4,
+	0x96, 0x89, 0x96, 0x09, 0x5a, 0x7f, 0x02, 0x1b, 0xc3, 0xd9, 0xd5, 0x15, 0x4f, 0xf7, 0x0b, 0x7a,
+	0x00, 0xd5, 0x23, 0x27, 0xfc, 0x38, 0xb3, 0x26, 0xce, 0xa5, 0xc3, 0xed, 0x1f, 0x6f, 0xe4, 0x5c,
+	0xca, 0xc8, 0x5f, 0xc1, 0x5a, 0xaa, 0x65, 0x9c, 0x57, 0x7f, 0xed, 0x99, 0xf0, 0x54, 0xbf, 0xa8,
+	0x20, 0xfa, 0xdf, 0x64, 0xe0, 0x71, 0x7a, 0x2d, 0xea, 0x12, 0x39, 0x80, 0x52, 0xf4, 0xc1, 0x41,
+	0x25, 0xaa, 0xa7, 0xa9, 0x19, 0xe6, 0xfc, 0x9b, 0x8c, 0x51, 0x54, 0x5f, 0x1f, 0xd8, 0x37, 0x50,
+	0xb5, 0x13, 0x1b, 0x68, 0x66, 0x17, 0x06, 0x70, 0xc9, 0xdd, 0x19, 0x29, 0xa8, 0x7e, 0x0a, 0x4f,
+	0x30, 0xe8, 0x66, 0xfe, 0x91, 0x25, 0x2c, 0xbc, 0x6e, 0xa2, 0x73, 0x60, 0x90, 0xf7, 0x2d, 0x71,
+	0xad, 0x86, 0x62, 0xf4, 0x1b, 0x8b, 0x9d, 0x1b, 0x1e, 0x38, 0x97, 0x77, 0xa6, 0xe7, 0x4e, 0xee,
+	0x68, 0x9b, 0x25, 0x03, 0x24, 0xa9, 0xef, 0x4e, 0xee, 0xf4, 0x3f, 0x64, 0x60, 0x73, 0x51, 0x5c,
+	0x3c, 0x2c, 0xbd, 0x2f, 0xaf, 0x09, 0x45, 0xcc, 0x05, 0xdc, 0x8d, 0x46, 0x80, 0xd1, 0x23, 0x1e,
+	0x81, 0x7d, 0x61, 0xde, 0xf0, 0x20, 0x74, 0x3c, 0x57, 0x39, 0x5e, 0xd9, 0xbe, 0x78, 0x2f, 0x09,
+	0x98, 0xc9, 0xe2, 0xaf, 0x32, 0xb2, 0xe0, 0x96, 0xce, 0x56, 0x55, 0x16, 0x91, 0x0d, 0x9a, 0x0e,
+	0x35, 0x42, 0xa9, 0x90, 0x0c, 0x29, 0x28, 0x6a, 0x46, 0x65, 0x42, 0xe3, 0x40, 0xc2, 0xec, 0xbd,
+	0x82, 0x52, 0xd4, 0x37, 0xa4, 0x1a, 0x85, 0x47, 0xc9, 0x46, 0x21, 0xb3, 0x17, 0x42, 0x39, 0x2a,
+	0xe1, 0x39, 0x76, 0x05, 0xdd, 0x5e, 0x77, 0xd4, 0x6d, 0x8f, 0x3a, 0x47, 0xda, 0x23, 0xf6, 0x04,
+	0xd6, 0x07, 0x46, 0xa7, 0x7b, 0xd6, 0x7e, 0xdb, 0x31, 0x8d, 0xce, 0xfb, 0x4e, 0xfb, 0x94, 0x9a,
+	0x05, 0x06, 0xf5, 0x93, 0xd1, 0xe9, 0xa1, 0x39, 0x38, 0x7f, 0x73, 0xda, 0x1d, 0x9e, 0x60, 0xd3,
+	0x80, 0x32, 0xa9, 0x9f, 0x18, 0x0e, 0xb5, 0x5c, 0xa2, 0x9b, 0xc8, 0xb3, 0x0d, 0x68, 0x74, 0x7b,
+	0xef, 0xfb, 0xdd, 0xc3, 0x8e, 0x39, 0xec, 0x8c, 0x46, 0x48, 0x2c, 0xec, 0xfd, 0x4f, 0x06, 0x6a,
+	0xa9, 0x69, 0x03, 0x7b, 0x0a, 0x1b, 0xf8, 0xca, 0xb9, 0x81, 0x9a, 0xda, 0xc3, 0x7e, 0xcf, 0xec,
+	0xf5, 0x7b, 0x1d, 0xed, 0x11, 0x7b, 0x06, 0x4f, 0x17, 0x18, 0xfd, 0xe3, 0xe3, 0xc3, 0x93, 0x36,
+	0x2e, 0x9e, 0xb5, 0x60, 0x73, 0x81, 0x39, 0xea, 0x9e, 0x75, 0x70, 0x97, 0x59, 0xb6, 0x03, 0xdb,
+	0x0b, 0xbc, 0xe1, 0xef, 0x3a, 0x9d, 0x41, 0x8c, 0xc8, 0xb1, 0x57, 0xf0, 0x72, 0x01, 0xd1, 0xed,
+	0x0d, 0xcf, 0x8f, 0x8f, 0xbb, 0x87, 0xdd, 0x4e, 0x6f, 0x64, 0xbe, 0x6f, 0x9f, 0x9e, 0x77, 0xb4,
+	0x3c, 0xdb, 0x86, 0xe6, 0xa2, 0x92, 0xce, 0xd9, 0xa0, 0x6f, 0xb4, 0x8d, 0x0f, 0x5a, 0x81, 0x7d,
+	0x0e, 0x2f, 0xee, 0x09, 0x39, 0xec, 0x1b, 0x46, 0xe7, 0x70, 0x64, 0xb6, 0xcf, 0xfa, 0xe7, 0xbd,
+	0x91, 0xb6, 0xb6, 0xb7, 0x0f, 0xeb, 0x71, 0xe4, 0x46, 0x09, 0x09, 0x4d, 0x76, 0xde, 0xfb, 0xae,
+	0xd7, 0xff, 0x5d, 0x4f, 0xf6, 0x63, 0xa3, 0x13, 0xa3, 0x33, 0x3c, 0xe9, 0x9f, 0x1e, 0x69, 0x99,
+	0xbd, 0xbf, 0xce, 0x01, 0xcc, 0x63, 0x0b, 0xad, 0xd3, 0x3e, 0x1f, 0xf5, 0x23, 0x0d, 0xf3, 0xd7,
+	0x74, 0x78, 0x9e, 0x64, 0xbc, 0x39, 0x3f, 0x7a, 0xdb, 0x19, 0x99, 0xbd, 0xfe, 0xc8, 0x1c, 0x8e,
+	0xda, 0xc6, 0x88, 0x8e, 0xab, 0x05, 0x9b, 0x49, 0x8c, 0xb4, 0xc2, 0x71, 0xa7, 0x33, 0xd4, 0xb2,
+	0xec, 0x39, 0xb4, 0x96, 0xbc, 0xdf, 0x39, 0x6d, 0x0f, 0x86, 0x9d, 0x23, 0x2d, 0xc7, 0xb6, 0xe0,
+	0x49, 0x92, 0xdf, 0xed, 0x99, 0xc7, 0xa7, 0xdd, 0xb7, 0x27, 0x23, 0x2d, 0xcf, 0x9a, 0xf0, 0x38,
+	0x2d, 0xb6, 0x4d, 0x52, 0xb5, 0xc2, 0xe2, 0x4b, 0x67, 0xdd, 0x5e, 0xc7, 0x20, 0xd6, 0x1a, 0xdb,
+	0x04, 0x96, 0x64, 0x0d, 0x8c, 0xce, 0xa0, 0xfd, 0x41, 0x2b, 0xb2, 0x17, 0xf0, 0x2c, 0x49, 0x8f,
+	0x2c, 0xfa, 0xa6, 0x7d, 0xf8, 0x5d, 0xff, 0xf8, 0x58, 0x2b, 0x2d, 0x6a, 0x8b, 0xbd, 0xb9, 0xbc,
+	0x68, 0x9b, 0xc8, 0xb3, 0x01, 0xcf, 0x2d, 0xc5, 0xe8, 0xfe, 0xf6, 0xbc, 0x7b, 0xd4, 0x1d, 0x7d,
+	0x30, 0xfb, 0xdf, 0x69, 0x15, 0x3c, 0xb7, 0x25, 0x3b, 0x4f, 0x3a, 0x80, 0x56, 0x3d, 0xf8, 0x43,
+	0x55, 0x4e, 0xc5, 0x0f, 0xe9, 0x53, 0x33, 0x33, 0xa0, 0xa8, 0x12, 0x15, 0x5b, 0x95, 0xba, 0x5a,
+	0xcb, 0x3f, 0x0e, 0xe8, 0x4f, 0xff, 0xea, 0xbf, 0xfe, 0xf8, 0x0f, 0xd9, 0x75, 0xbd, 0xba, 0x7f,
+	0xf3, 0xcb, 0x7d, 0x44, 0xec, 0x7b, 0x33, 0xf1, 0x6d, 0x66, 0x8f, 0xf5, 0x61, 0x4d, 0x0e, 0xf1,
+	0xd9, 0x8a, 0x2f, 0x3a, 0xab, 0x24, 0x6e, 0x92, 0x44, 0x4d, 0xaf, 0xc4, 0x12, 0x1d, 0x17, 0x05,
+	0x5e, 0x42, 0x25, 0xf1, 0x0d, 0x83, 0x3d, 0x5b, 0x90, 0x9a, 0xfc, 0xac, 0xd4, 0xda, 0x5e, 0xce,
+	0x54, 0x1a, 0xb6, 0x49, 0xc3, 0xa6, 0xbe, 0x9e, 0xd0, 0xb0, 0x7f, 0x81, 0x10, 0xd4, 0xf3, 0x09,
+	0xd6, 0xef, 0x7d, 0x7d, 0x60, 0x2f, 0x17, 0x04, 0xde, 0xff, 0xcc, 0xd1, 0xd2, 0x1f, 0x82, 0x28,
+	0xcd, 0xcf, 0x48, 0xf3, 0x13, 0x5d, 0x4b, 0x6a, 0xc6, 0xaf, 0x20, 0xa8, 0xf8, 0x1b, 0x28, 0xaa,
+	0x8f, 0x17, 0x89, 0x53, 0x48, 0x7f, 0xce, 0x68, 0x2d, 0x9b, 0x4f, 0xfc, 0x22, 0xc3, 0xfe, 0x14,
+	0xca, 0xf1, 0x68, 0x83, 0x6d, 0xdd, 0x9f, 0x25, 0x45, 0xaf, 0xb7, 0x96, 0xb1, 0xd2, 0x76, 0x67,
+	0xf5, 0x78, 0x6d, 0x72, 0xec, 0x71, 0x0e, 0xa5, 0x68, 0xaa, 0xc1, 0x9a, 0x29, 0xf5, 0x89, 0x41,
+	0xc7, 0xd2, 0x85, 0xe9, 0x2d, 0x12, 0xf9, 0x98, 0xb1, 0x94, 0xc8, 0xfd, 0xdf, 0x3b, 0xf6, 0x9f,
+	0xb3, 0x3f, 0x83, 0xaa, 0xf2, 0x30, 0x9a, 0x3d, 0xb0, 0xb9, 0x37, 0x24, 0x07, 0x24, 0xad, 0xf9,
+	0x66, 0x16, 0xa7, 0x14, 0x4b, 0xa4, 0x7b, 0x33, 0xb1, 0x2f, 0x48, 0xda, 0x45, 0x2c, 0x9d, 0x7a,
+	0xda, 0x84, 0xf4, 0xe4, 0x74, 0x20, 0x2d, 0x3d, 0xd5, 0xfd, 0xea, 0x3b, 0x24, 0xbd, 0xc5, 0x9a,
+	0x29, 0xe9, 0x1f, 0x11, 0xb3, 0xff, 0x7b, 0x6b, 0x2a, 0x70, 0x07, 0x75, 0x6c, 0x69, 0xe8, 0xb0,
+	0x1f, 0xdc, 0xc3, 0xdc, 0x6a, 0x0b, 0xb3, 0x1e, 0x7d, 0x8b, 0x94, 0x6c, 0xb0, 0x94, 0x27, 0x46,
+	0x3b, 0x98, 0x4b, 0x7f, 0x70, 0x0f, 0x49, 0xe9, 0xe9, 0x2d, 0xbc, 0x20, 0xe9, 0x5b, 0xec, 0x69,
+	0x52, 0x7a, 0x72, 0x07, 0x1f, 0xa0, 0x86, 0x3a, 0xa2, 0xa6, 0x36, 0x4c, 0x84, 0x6a, 0xaa, 0x73,
+	0x6e, 0x3d, 0xbd, 0x47, 0x4f, 0x87, 0x3f, 0x6b, 0x90, 0x8a, 0xd0, 0x12, 0xfb, 0xb2, 0x5b, 0x66,
+	0x02, 0xd8, 0xfd, 0x7e, 0x8f, 0xcd, 0x63, 0x64, 0x65, 0x33, 0xd8, 0x7a, 0xb0, 0x74, 0x8c, 0x62,
+	0x97, 0x3d, 0x26, 0x85, 0x11, 0x60, 0xdf, 0x97, 0xf2, 0xff, 0x02, 0xd8, 0xf0, 0x21, 0xad, 0x2b,
+	0x8b, 0xd8, 0xd6, 0xe7, 0x0f, 0x62, 0xd2, 0x06, 0xd5, 0x97, 0x2a, 0xc7, 0x10, 0xe6, 0x50, 0x4d,
+	0x96, 0x88, 0x6c, 0xbe, 0x97, 0x25, 0x55, 0x6c, 0xeb, 0xb3, 0x15, 0x5c, 0xa5, 0xad, 0x49, 0xda,
+	0x18, 0xa3, 0x64, 0x81, 0x8d, 0xcb, 0x7e, 0x28, 0x61, 0xec, 0x23, 0xd4, 0xd3, 0x35, 0x1b, 0x7b,
+	0x1e, 0x8b, 0x5a, 0x5a, 0x1b, 0xb6, 0x5e, 0xac, 0xe4, 0x2b, 0x65, 0xcf, 0x49, 0x59, 0x53, 0xdf,
+	0x40, 0x65, 0xb6, 0xe2, 0xee, 0x5f, 0x10, 0xf8, 0xdb, 0xcc, 0xde, 0xc5, 0x1a, 0xfd, 0x9f, 0xd1,
+	0xaf, 0xfe, 0x77, 0x00, 0x7d, 0xf7, 0x56, 0x00, 0x9e, 0x24, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -2399,15 +3445,32 @@ type SwapClientClient interface {
 	//point onwards, progress can be tracked via the SwapStatus stream
 	//that is returned from Monitor().
 	LoopIn(ctx context.Context, in *LoopInRequest, opts ...grpc.CallOption) (*SwapResponse, error)
+	// loop: `batchin`
+	//LoopInBatch initiates multiple loop in swaps and funds all of their htlcs
+	//in a single on-chain transaction. The call returns after the swaps have
+	//been set up with the swap server and the funding transaction has been
+	//published. From that point onwards, progress of the individual swaps can
+	//be tracked via the SwapStatus stream that is returned from Monitor().
+	LoopInBatch(ctx context.Context, in *LoopInBatchRequest, opts ...grpc.CallOption) (*LoopInBatchResponse, error)
+	// loop: `publishpsbt`
+	//LoopInPublishPsbt publishes the htlc transaction of a loop in swap with an
+	//external htlc from a signed psbt. The transaction must pay exactly the swap
+	//amount to the swap's htlc. Its id is recorded with the swap before the
+	//transaction is published. If the transaction can't be published, it is not
+	//kept with the swap. Until the htlc confirms, a recorded transaction can be
+	//replaced by publishing another psbt.
+	LoopInPublishPsbt(ctx context.Context, in *LoopInPublishPsbtRequest, opts ...grpc.CallOption) (*LoopInPublishPsbtResponse, error)
 	// loop: `monitor`
 	//Monitor will return a stream of swap updates for currently active swaps.
 	Monitor(ctx context.Context, in *MonitorRequest, opts ...grpc.CallOption) (SwapClient_MonitorClient, error)
 	// loop: `listswaps`
 	//ListSwaps returns a list of all currently known swaps and their current
-	//status.
+	//status. The swaps can be filtered and are returned in pages, ordered by
+	//their initiation time.
 	ListSwaps(ctx context.Context, in *ListSwapsRequest, opts ...grpc.CallOption) (*ListSwapsResponse, error)
 	// loop: `swapinfo`
-	//SwapInfo returns all known details about a single swap.
+	//SwapInfo returns all known details about a single swap, including the
+	//full history of its state transitions.
 	SwapInfo(ctx context.Context, in *SwapInfoRequest, opts ...grpc.CallOption) (*SwapStatus, error)
 	// loop: `terms`
 	//LoopOutTerms returns the terms that the server enforces for a loop out swap.
@@ -2442,6 +3505,12 @@ type SwapClientClient interface {
 	//Note that only loop out suggestions are currently supported.
 	//[EXPERIMENTAL]: endpoint is subject to change.
 	SuggestSwaps(ctx context.Context, in *SuggestSwapsRequest, opts ...grpc.CallOption) (*SuggestSwapsResponse, error)
+	// loop: `backupdb`
+	//BackupDatabase writes a consistent snapshot of the swap database to a new
+	//file on the loopd host while swaps continue to run. The snapshot includes
+	//the database metadata and is verified after it was written. In
+	//verification mode, an existing snapshot is verified instead.
+	BackupDatabase(ctx context.Context, in *BackupDatabaseRequest, opts ...grpc.CallOption) (*BackupDatabaseResponse, error)
 }
 
 type swapClientClient struct {
@@ -2470,6 +3539,24 @@ func (c *swapClientClient) LoopIn(ctx context.Context, in *LoopInRequest, opts .
 	return out, nil
 }
 
+func (c *swapClientClient) LoopInBatch(ctx context.Context, in *LoopInBatchRequest, opts ...grpc.CallOption) (*LoopInBatchResponse, error) {
+	out := new(LoopInBatchResponse)
+	err := c.cc.Invoke(ctx, "/looprpc.SwapClient/LoopInBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swapClientClient) LoopInPublishPsbt(ctx context.Context, in *LoopInPublishPsbtRequest, opts ...grpc.CallOption) (*LoopInPublishPsbtResponse, error) {
+	out := new(LoopInPublishPsbtResponse)
+	err := c.cc.Invoke(ctx, "/looprpc.SwapClient/LoopInPublishPsbt", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *swapClientClient) Monitor(ctx context.Context, in *MonitorRequest, opts ...grpc.CallOption) (SwapClient_MonitorClient, error) {
 	stream, err := c.cc.NewStream(ctx, &_SwapClient_serviceDesc.Streams[0], "/looprpc.SwapClient/Monitor", opts...)
 	if err != nil {
@@ -2592,6 +3679,15 @@ func (c *swapClientClient) SuggestSwaps(ctx context.Context, in *SuggestSwapsReq
 	return out, nil
 }
 
+func (c *swapClientClient) BackupDatabase(ctx context.Context, in *BackupDatabaseRequest, opts ...grpc.CallOption) (*BackupDatabaseResponse, error) {
+	out := new(BackupDatabaseResponse)
+	err := c.cc.Invoke(ctx, "/looprpc.SwapClient/BackupDatabase", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SwapClientServer is the server API for SwapClient service.
 type SwapClientServer interface {
 	// loop: `out`
@@ -2606,15 +3702,32 @@ type SwapClientServer interface {
 	//point onwards, progress can be tracked via the SwapStatus stream
 	//that is returned from Monitor().
 	LoopIn(context.Context, *LoopInRequest) (*SwapResponse, error)
+	// loop: `batchin`
+	//LoopInBatch initiates multiple loop in swaps and funds all of their htlcs
+	//in a single on-chain transaction. The call returns after the swaps have
+	//been set up with the swap server and the funding transaction has been
+	//published. From that point onwards, progress of the individual swaps can
+	//be tracked via the SwapStatus stream that is returned from Monitor().
+	LoopInBatch(context.Context, *LoopInBatchRequest) (*LoopInBatchResponse, error)
+	// loop: `publishpsbt`
+	//LoopInPublishPsbt publishes the htlc transaction of a loop in swap with an
+	//external htlc from a signed psbt. The transaction must pay exactly the swap
+	//amount to the swap's htlc. Its id is recorded with the swap before the
+	//transaction is published. If the transaction can't be published, it is not
+	//kept with the swap. Until the htlc confirms, a recorded transaction can be
+	//replaced by publishing another psbt.
+	LoopInPublishPsbt(context.Context, *LoopInPublishPsbtRequest) (*LoopInPublishPsbtResponse, error)
 	// loop: `monitor`
 	//Monitor will return a stream of swap updates for currently active swaps.
 	Monitor(*MonitorRequest, SwapClient_MonitorServer) error
 	// loop: `listswaps`
 	//ListSwaps returns a list of all currently known swaps and their current
-	//status.
+	//status. The swaps can be filtered and are returned in pages, ordered by
+	//their initiation time.
 	ListSwaps(context.Context, *ListSwapsRequest) (*ListSwapsResponse, error)
 	// loop: `swapinfo`
-	//SwapInfo returns all known details about a single swap.
+	//SwapInfo returns all known details about a single swap, including the
+	//full history of its state transitions.
 	SwapInfo(context.Context, *SwapInfoRequest) (*SwapStatus, error)
 	// loop: `terms`
 	//LoopOutTerms returns the terms that the server enforces for a loop out swap.
@@ -2649,6 +3762,12 @@ type SwapClientServer interface {
 	//Note that only loop out suggestions are currently supported.
 	//[EXPERIMENTAL]: endpoint is subject to change.
 	SuggestSwaps(context.Context, *SuggestSwapsRequest) (*SuggestSwapsResponse, error)
+	// loop: `backupdb`
+	//BackupDatabase writes a consistent snapshot of the swap database to a new
+	//file on the loopd host while swaps continue to run. The snapshot includes
+	//the database metadata and is verified after it was written. In
+	//verification mode, an existing snapshot is verified instead.
+	BackupDatabase(context.Context, *BackupDatabaseRequest) (*BackupDatabaseResponse, error)
 }
 
 // UnimplementedSwapClientServer can be embedded to have forward compatible implementations.
@@ -2661,6 +3780,12 @@ func (*UnimplementedSwapClientServer) LoopOut(ctx context.Context, req *LoopOutR
 func (*UnimplementedSwapClientServer) LoopIn(ctx context.Context, req *LoopInRequest) (*SwapResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method LoopIn not implemented")
 }
+func (*UnimplementedSwapClientServer) LoopInBatch(ctx context.Context, req *LoopInBatchRequest) (*LoopInBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LoopInBatch not implemented")
+}
+func (*UnimplementedSwapClientServer) LoopInPublishPsbt(ctx context.Context, req *LoopInPublishPsbtRequest) (*LoopInPublishPsbtResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LoopInPublishPsbt not implemented")
+}
 func (*UnimplementedSwapClientServer) Monitor(req *MonitorRequest, srv SwapClient_MonitorServer) error {
 	return status.Errorf(codes.Unimplemented, "method Monitor not implemented")
 }
@@ -2694,6 +3819,9 @@ func (*UnimplementedSwapClientServer) SetLiquidityParams(ctx context.Context, re
 func (*UnimplementedSwapClientServer) SuggestSwaps(ctx context.Context, req *SuggestSwapsRequest) (*SuggestSwapsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SuggestSwaps not implemented")
 }
+func (*UnimplementedSwapClientServer) BackupDatabase(ctx context.Context, req *BackupDatabaseRequest) (*BackupDatabaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BackupDatabase not implemented")
+}
 
 func RegisterSwapClientServer(s *grpc.Server, srv SwapClientServer) {
 	s.RegisterService(&_SwapClient_serviceDesc, srv)
@@ -2735,6 +3863,42 @@ func _SwapClient_LoopIn_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SwapClient_LoopInBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoopInBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapClientServer).LoopInBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/looprpc.SwapClient/LoopInBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapClientServer).LoopInBatch(ctx, req.(*LoopInBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwapClient_LoopInPublishPsbt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoopInPublishPsbtRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapClientServer).LoopInPublishPsbt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/looprpc.SwapClient/LoopInPublishPsbt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapClientServer).LoopInPublishPsbt(ctx, req.(*LoopInPublishPsbtRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SwapClient_Monitor_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(MonitorRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -2936,6 +4100,24 @@ func _SwapClient_SuggestSwaps_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SwapClient_BackupDatabase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BackupDatabaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapClientServer).BackupDatabase(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/looprpc.SwapClient/BackupDatabase",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapClientServer).BackupDatabase(ctx, req.(*BackupDatabaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _SwapClient_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "looprpc.SwapClient",
 	HandlerType: (*SwapClientServer)(nil),
@@ -2948,6 +4130,14 @@ var _SwapClient_serviceDesc = grpc.ServiceDesc{
 			MethodName: "LoopIn",
 			Handler:    _SwapClient_LoopIn_Handler,
 		},
+		{
+			MethodName: "LoopInBatch",
+			Handler:    _SwapClient_LoopInBatch_Handler,
+		},
+		{
+			MethodName: "LoopInPublishPsbt",
+			Handler:    _SwapClient_LoopInPublishPsbt_Handler,
+		},
 		{
 			MethodName: "ListSwaps",
 			Handler:    _SwapClient_ListSwaps_Handler,
@@ -2988,6 +4178,10 @@ var _SwapClient_serviceDesc = grpc.ServiceDesc{
 			MethodName: "SuggestSwaps",
 			Handler:    _SwapClient_SuggestSwaps_Handler,
 		},
+		{
+			MethodName: "BackupDatabase",
+			Handler:    _SwapClient_BackupDatabase_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -49,8 +49,8 @@ const (
 
 	// manualRetryHint is the error text we return to tell the user how a
 	// token payment can be retried if the payment fails.
-	manualRetryHint = "consider removing pending token file if error " +
-		"persists. use 'listauth' command to find out token file name"
+	manualRetryHint = "consider removing pending token with 'loopd " +
+		"removependingtoken' if error persists"
 )
 
 var (
@@ -222,7 +222,7 @@ func (i *Interceptor) newInterceptContext(ctx context.Context,
 	// payment just yet, since we don't even know if a token is required for
 	// this call. We also never send a pending payment to the server since
 	// we know it's not valid.
-	case !iCtx.token.isPending():
+	case !iCtx.token.IsPending():
 		if err = i.addLsatCredentials(iCtx); err != nil {
 			log.Errorf("Adding macaroon to request failed: %v", err)
 			return nil, fmt.Errorf("adding macaroon failed: %v",
@@ -244,7 +244,7 @@ func (i *Interceptor) newInterceptContext(ctx context.Context,
 func (i *Interceptor) handlePayment(iCtx *interceptContext) error {
 	switch {
 	// Resume/track a pending payment if it was interrupted for some reason.
-	case iCtx.token != nil && iCtx.token.isPending():
+	case iCtx.token != nil && iCtx.token.IsPending():
 		log.Infof("Payment of LSAT token is required, resuming/" +
 			"tracking previous payment from pending LSAT token")
 		err := i.trackPayment(iCtx.mainCtx, iCtx.token)
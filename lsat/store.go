@@ -7,6 +7,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/lightningnetwork/lnd/lntypes"
 )
 
 var (
@@ -22,9 +24,9 @@ var (
 	// token until it was successfully paid for.
 	storeFileNamePending = "lsat.token.pending"
 
-	// errNoReplace is the error that is returned if a new token is
+	// ErrNoReplace is the error that is returned if a new token is
 	// being written to a store that already contains a paid token.
-	errNoReplace = errors.New("won't replace existing paid token with " +
+	ErrNoReplace = errors.New("won't replace existing paid token with " +
 		"new token. " + manualRetryHint)
 )
 
@@ -135,7 +137,7 @@ func (f *FileStore) AllTokens() (map[string]*Token, error) {
 // NOTE: This is part of the Store interface.
 func (f *FileStore) StoreToken(newToken *Token) error {
 	// Serialize the token first, before we rename anything.
-	bytes, err := serializeToken(newToken)
+	bytes, err := SerializeToken(newToken)
 	if err != nil {
 		return err
 	}
@@ -149,7 +151,7 @@ func (f *FileStore) StoreToken(newToken *Token) error {
 	case err == ErrNoToken:
 		// What's the target file name we are going to write?
 		newFileName := f.fileName
-		if newToken.isPending() {
+		if newToken.IsPending() {
 			newFileName = f.fileNamePending
 		}
 		return ioutil.WriteFile(newFileName, bytes, 0600)
@@ -159,7 +161,7 @@ func (f *FileStore) StoreToken(newToken *Token) error {
 		return err
 
 	// Replace a pending token with a paid one.
-	case currentToken.isPending() && !newToken.isPending():
+	case currentToken.IsPending() && !newToken.IsPending():
 		// Make sure we replace the the same token, just with a
 		// different state.
 		if currentToken.PaymentHash != newToken.PaymentHash {
@@ -186,8 +188,28 @@ func (f *FileStore) StoreToken(newToken *Token) error {
 	// TODO(guggero): Once tokens expire, this logic has to be adapted
 	//  accordingly.
 	default:
-		return errNoReplace
+		return ErrNoReplace
+	}
+}
+
+// RemovePendingToken removes the current token from the store if its payment
+// is still pending, so that a new token is paid for. It returns the payment
+// hash of the removed token.
+func (f *FileStore) RemovePendingToken() (lntypes.Hash, error) {
+	token, err := f.currentToken()
+	if err != nil {
+		return lntypes.Hash{}, err
 	}
+
+	if !token.IsPending() {
+		return lntypes.Hash{}, errors.New("current token is paid")
+	}
+
+	if err := os.Remove(f.fileNamePending); err != nil {
+		return lntypes.Hash{}, err
+	}
+
+	return token.PaymentHash, nil
 }
 
 // readTokenFile reads a single token from a file and returns it deserialized.
@@ -196,7 +218,7 @@ func readTokenFile(tokenFile string) (*Token, error) {
 	if err != nil {
 		return nil, err
 	}
-	return deserializeToken(bytes)
+	return DeserializeToken(bytes)
 }
 
 // fileExists returns true if the file exists, and false otherwise.
@@ -116,16 +116,73 @@ func TestFileStore(t *testing.T) {
 
 	// Make sure we can't replace the existing paid token with a pending.
 	err = store.StoreToken(pendingToken)
-	if err != errNoReplace {
+	if err != ErrNoReplace {
 		t.Fatalf("unexpected error. got %v, expected %v", err,
-			errNoReplace)
+			ErrNoReplace)
 	}
 
 	// Make sure we can also not overwrite the existing paid token with a
 	// new paid one.
 	err = store.StoreToken(paidToken)
-	if err != errNoReplace {
+	if err != ErrNoReplace {
 		t.Fatalf("unexpected error. got %v, expected %v", err,
-			errNoReplace)
+			ErrNoReplace)
+	}
+}
+
+// TestFileStoreRemovePendingToken tests that only a pending token can be
+// removed from the file based store.
+func TestFileStoreRemovePendingToken(t *testing.T) {
+	t.Parallel()
+
+	tempDirName, err := ioutil.TempDir("", "lsatstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDirName)
+
+	store, err := NewFileStore(tempDirName)
+	if err != nil {
+		t.Fatalf("could not create test store: %v", err)
+	}
+
+	// Without any token, there is nothing to remove.
+	_, err = store.RemovePendingToken()
+	if err != ErrNoToken {
+		t.Fatalf("unexpected error. got %v, expected %v", err,
+			ErrNoToken)
+	}
+
+	pendingToken := &Token{
+		Preimage: zeroPreimage,
+		baseMac:  makeMac(),
+	}
+	if err := store.StoreToken(pendingToken); err != nil {
+		t.Fatalf("could not save pending token: %v", err)
+	}
+
+	hash, err := store.RemovePendingToken()
+	if err != nil {
+		t.Fatalf("could not remove pending token: %v", err)
+	}
+	if hash != pendingToken.PaymentHash {
+		t.Fatalf("unexpected hash. got %v, expected %v", hash,
+			pendingToken.PaymentHash)
+	}
+	if fileExists(filepath.Join(tempDirName, storeFileNamePending)) {
+		t.Fatalf("expected file %s/%s to be removed but it wasn't",
+			tempDirName, storeFileNamePending)
+	}
+
+	// A paid token must not be removed.
+	paidToken := &Token{
+		Preimage: lntypes.Preimage{1, 2, 3, 4, 5},
+		baseMac:  makeMac(),
+	}
+	if err := store.StoreToken(paidToken); err != nil {
+		t.Fatalf("could not save paid token: %v", err)
+	}
+	if _, err := store.RemovePendingToken(); err == nil {
+		t.Fatalf("expected paid token not to be removed")
 	}
 }
@@ -96,14 +96,14 @@ func (t *Token) IsValid() bool {
 	return true
 }
 
-// isPending returns true if the payment for the LSAT is still in flight and we
+// IsPending returns true if the payment for the LSAT is still in flight and we
 // haven't received the preimage yet.
-func (t *Token) isPending() bool {
+func (t *Token) IsPending() bool {
 	return t.Preimage == zeroPreimage
 }
 
-// serializeToken returns a byte-serialized representation of the token.
-func serializeToken(t *Token) ([]byte, error) {
+// SerializeToken returns a byte-serialized representation of the token.
+func SerializeToken(t *Token) ([]byte, error) {
 	var b bytes.Buffer
 
 	baseMacBytes, err := t.baseMac.MarshalBinary()
@@ -144,8 +144,8 @@ func serializeToken(t *Token) ([]byte, error) {
 	return b.Bytes(), nil
 }
 
-// deserializeToken constructs a token by reading it from a byte slice.
-func deserializeToken(value []byte) (*Token, error) {
+// DeserializeToken constructs a token by reading it from a byte slice.
+func DeserializeToken(value []byte) (*Token, error) {
 	r := bytes.NewReader(value)
 
 	var macLen uint32
@@ -0,0 +1,364 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/lndclient"
+	"github.com/lightninglabs/loop/labels"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightninglabs/loop/sweep"
+	"github.com/lightningnetwork/lnd/chainntnfs"
+	"github.com/lightningnetwork/lnd/input"
+)
+
+var (
+	// ErrSwapCompleted is returned when recovery is requested for a swap
+	// that completed successfully and therefore has no htlc left to
+	// sweep.
+	ErrSwapCompleted = errors.New("swap already completed")
+)
+
+// RecoveryRequest contains the parameters of the sweep that recovers the funds
+// locked in a swap htlc without the help of the swap server.
+type RecoveryRequest struct {
+	// DestAddr is the address that the htlc is swept to. If nil, loop out
+	// htlcs are swept to the destinations that are recorded with the
+	// swap, and loop in htlcs to a new address of the lnd wallet.
+	DestAddr btcutil.Address
+
+	// SweepConfTarget is the confirmation target that the fee of the sweep
+	// is estimated for.
+	SweepConfTarget int32
+
+	// Publish indicates whether the sweep is published. If false, the
+	// sweep is only created and signed.
+	Publish bool
+}
+
+// RecoveryResult describes the sweep that recovers the funds locked in a swap
+// htlc.
+type RecoveryResult struct {
+	// HtlcOutpoint is the outpoint of the htlc that is swept.
+	HtlcOutpoint wire.OutPoint
+
+	// HtlcValue is the value of the htlc that is swept.
+	HtlcValue btcutil.Amount
+
+	// SweepTx is the signed sweep tx.
+	SweepTx *wire.MsgTx
+
+	// Fee is the fee that the sweep tx pays.
+	Fee btcutil.Amount
+
+	// Published indicates whether the sweep tx was published.
+	Published bool
+}
+
+// RecoverLoopOut creates and optionally publishes a tx that sweeps the htlc of
+// a loop out swap using the swap preimage. It only requires the swap data and
+// an lnd connection, so that funds can be recovered if loopd can't run.
+func RecoverLoopOut(ctx context.Context, lnd *lndclient.LndServices,
+	swp *loopdb.LoopOut, req *RecoveryRequest) (*RecoveryResult, error) {
+
+	if swp.State().State.Type() == loopdb.StateTypeSuccess {
+		return nil, ErrSwapCompleted
+	}
+
+	contract := swp.Contract
+	htlc, err := swap.NewHtlc(
+		GetHtlcScriptVersion(contract.ProtocolVersion),
+		contract.CltvExpiry, contract.SenderKey, contract.ReceiverKey,
+		swp.Hash, swap.HtlcP2WSH, lnd.ChainParams,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	htlc, outpoint, value, err := findHtlc(
+		ctx, lnd, &swp.Loop, contract.InitiationHeight, htlc,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	height, err := getBlockHeight(ctx, lnd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Once the htlc has expired, the server may time it out, so we can
+	// only try to be faster.
+	if height >= contract.CltvExpiry {
+		log.Warnf("Htlc of swap %v expired at height %v, the server "+
+			"may already have swept it", swp.Hash,
+			contract.CltvExpiry)
+	}
+
+	destinations := contract.Destinations()
+	if req.DestAddr != nil {
+		destinations = []loopdb.SweepDestination{
+			{Addr: req.DestAddr, Weight: 1},
+		}
+	}
+
+	witnessFunc := func(sig []byte) (wire.TxWitness, error) {
+		return htlc.GenSuccessWitness(sig, contract.Preimage)
+	}
+
+	return sweepRecoveredHtlc(
+		ctx, lnd, req, &recoveredHtlc{
+			htlc:             htlc,
+			outpoint:         *outpoint,
+			value:            value,
+			height:           height,
+			sequence:         htlc.SuccessSequence(),
+			keyBytes:         contract.ReceiverKey,
+			witnessFunc:      witnessFunc,
+			addInputEstimate: htlc.AddSuccessToEstimator,
+			destinations:     destinations,
+			label: labels.LoopOutSweepSuccess(
+				swap.ShortHash(&swp.Hash),
+			),
+		},
+	)
+}
+
+// RecoverLoopIn creates and optionally publishes a tx that sweeps the expired
+// htlc of a loop in swap back to us. It only requires the swap data and an lnd
+// connection, so that funds can be recovered if loopd can't run.
+func RecoverLoopIn(ctx context.Context, lnd *lndclient.LndServices,
+	swp *loopdb.LoopIn, req *RecoveryRequest) (*RecoveryResult, error) {
+
+	if swp.State().State.Type() == loopdb.StateTypeSuccess {
+		return nil, ErrSwapCompleted
+	}
+
+	// Loop in htlcs may be published to either of the output types, so
+	// we look for both of them.
+	contract := swp.Contract
+	var htlcs []*swap.Htlc
+	for _, outputType := range []swap.HtlcOutputType{
+		swap.HtlcP2WSH, swap.HtlcNP2WSH,
+	} {
+		htlc, err := swap.NewHtlc(
+			GetHtlcScriptVersion(contract.ProtocolVersion),
+			contract.CltvExpiry, contract.SenderKey,
+			contract.ReceiverKey, swp.Hash, outputType,
+			lnd.ChainParams,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		htlcs = append(htlcs, htlc)
+	}
+
+	htlc, outpoint, value, err := findHtlc(
+		ctx, lnd, &swp.Loop, contract.InitiationHeight, htlcs...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	height, err := getBlockHeight(ctx, lnd)
+	if err != nil {
+		return nil, err
+	}
+
+	if height < contract.CltvExpiry {
+		return nil, fmt.Errorf("htlc expires at height %v, current "+
+			"height is %v", contract.CltvExpiry, height)
+	}
+
+	destAddr := req.DestAddr
+	if destAddr == nil {
+		destAddr, err = lnd.WalletKit.NextAddr(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	witnessFunc := func(sig []byte) (wire.TxWitness, error) {
+		return htlc.GenTimeoutWitness(sig), nil
+	}
+
+	return sweepRecoveredHtlc(
+		ctx, lnd, req, &recoveredHtlc{
+			htlc:             htlc,
+			outpoint:         *outpoint,
+			value:            value,
+			height:           height,
+			keyBytes:         contract.SenderKey,
+			witnessFunc:      witnessFunc,
+			addInputEstimate: htlc.AddTimeoutToEstimator,
+			destinations: []loopdb.SweepDestination{
+				{Addr: destAddr, Weight: 1},
+			},
+			label: labels.LoopInSweepTimeout(
+				swap.ShortHash(&swp.Hash),
+			),
+		},
+	)
+}
+
+// getBlockHeight returns the current block height of lnd.
+func getBlockHeight(ctx context.Context, lnd *lndclient.LndServices) (int32,
+	error) {
+
+	info, err := lnd.Client.GetInfo(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(info.BlockHeight), nil
+}
+
+// findHtlc waits for a confirmed output of one of the htlcs provided and
+// returns the htlc that was found along with its outpoint and value. The last
+// htlc tx that is recorded for the swap is used as a hint if available.
+func findHtlc(ctx context.Context, lnd *lndclient.LndServices,
+	swp *loopdb.Loop, heightHint int32, htlcs ...*swap.Htlc) (*swap.Htlc,
+	*wire.OutPoint, btcutil.Amount, error) {
+
+	var htlcTxHash *chainhash.Hash
+	for _, event := range swp.Events {
+		if event.HtlcTxHash != nil {
+			htlcTxHash = event.HtlcTxHash
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type htlcConf struct {
+		htlc *swap.Htlc
+		conf *chainntnfs.TxConfirmation
+	}
+
+	confChan := make(chan htlcConf)
+	errChan := make(chan error)
+
+	for _, htlc := range htlcs {
+		htlc := htlc
+
+		htlcConfChan, htlcErrChan, err :=
+			lnd.ChainNotifier.RegisterConfirmationsNtfn(
+				ctx, htlcTxHash, htlc.PkScript, 1, heightHint,
+			)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+
+		go func() {
+			select {
+			case conf := <-htlcConfChan:
+				select {
+				case confChan <- htlcConf{htlc, conf}:
+				case <-ctx.Done():
+				}
+
+			case err := <-htlcErrChan:
+				select {
+				case errChan <- err:
+				case <-ctx.Done():
+				}
+
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	log.Infof("Waiting for htlc tx to be found (hh=%v, txid=%v)",
+		heightHint, htlcTxHash)
+
+	select {
+	case result := <-confChan:
+		outpoint, value, err := swap.GetScriptOutput(
+			result.conf.Tx, result.htlc.PkScript,
+		)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+
+		return result.htlc, outpoint, value, nil
+
+	case err := <-errChan:
+		return nil, nil, 0, err
+
+	case <-ctx.Done():
+		return nil, nil, 0, fmt.Errorf("htlc tx not found: %v",
+			ctx.Err())
+	}
+}
+
+// recoveredHtlc contains everything that is needed to sweep a recovered htlc.
+type recoveredHtlc struct {
+	htlc             *swap.Htlc
+	outpoint         wire.OutPoint
+	value            btcutil.Amount
+	height           int32
+	sequence         uint32
+	keyBytes         [33]byte
+	witnessFunc      func(sig []byte) (wire.TxWitness, error)
+	addInputEstimate func(*input.TxWeightEstimator)
+	destinations     []loopdb.SweepDestination
+	label            string
+}
+
+// sweepRecoveredHtlc creates, signs and optionally publishes the sweep of a
+// recovered htlc.
+func sweepRecoveredHtlc(ctx context.Context, lnd *lndclient.LndServices,
+	req *RecoveryRequest, htlc *recoveredHtlc) (*RecoveryResult, error) {
+
+	sweeper := &sweep.Sweeper{Lnd: lnd}
+
+	fee, err := sweeper.GetSweepFee(
+		ctx, htlc.addInputEstimate, htlc.destinations,
+		req.SweepConfTarget,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if fee >= htlc.value {
+		return nil, fmt.Errorf("sweep fee %v exceeds htlc value %v",
+			fee, htlc.value)
+	}
+
+	sweepTx, err := sweeper.CreateSweepTx(
+		ctx, htlc.height, htlc.sequence, htlc.htlc, htlc.outpoint,
+		htlc.keyBytes, htlc.witnessFunc, htlc.value, fee,
+		htlc.destinations,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RecoveryResult{
+		HtlcOutpoint: htlc.outpoint,
+		HtlcValue:    htlc.value,
+		SweepTx:      sweepTx,
+		Fee:          fee,
+	}
+
+	if !req.Publish {
+		return result, nil
+	}
+
+	log.Infof("Publishing recovery sweep %v of htlc %v with fee %v",
+		sweepTx.TxHash(), htlc.outpoint, fee)
+
+	err = lnd.WalletKit.PublishTransaction(ctx, sweepTx, htlc.label)
+	if err != nil {
+		return nil, err
+	}
+	result.Published = true
+
+	return result, nil
+}
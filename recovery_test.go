@@ -0,0 +1,201 @@
+package loop
+
+import (
+	"context"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightninglabs/loop/test"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/stretchr/testify/require"
+)
+
+// recoveryResult contains the return values of a recovery call.
+type recoveryResult struct {
+	result *RecoveryResult
+	err    error
+}
+
+// testRecoveryContract returns a swap contract that expires at the height
+// provided.
+func testRecoveryContract(cltvExpiry int32) loopdb.SwapContract {
+	_, senderKey := test.CreateKey(1)
+	_, receiverKey := test.CreateKey(2)
+
+	contract := loopdb.SwapContract{
+		Preimage:         lntypes.Preimage{1, 2, 3},
+		AmountRequested:  100000,
+		CltvExpiry:       cltvExpiry,
+		InitiationHeight: 500,
+		ProtocolVersion:  loopdb.ProtocolVersionHtlcV2,
+	}
+	copy(contract.SenderKey[:], senderKey.SerializeCompressed())
+	copy(contract.ReceiverKey[:], receiverKey.SerializeCompressed())
+
+	return contract
+}
+
+// confirmHtlc confirms a tx that pays to the htlc provided and returns its
+// outpoint.
+func confirmHtlc(ctx *test.Context, htlc *swap.Htlc) wire.OutPoint {
+	htlcTx := wire.NewMsgTx(2)
+	htlcTx.AddTxOut(&wire.TxOut{Value: 5000})
+	htlcTx.AddTxOut(&wire.TxOut{
+		Value:    100000,
+		PkScript: htlc.PkScript,
+	})
+	ctx.NotifyConf(htlcTx)
+
+	return wire.OutPoint{Hash: htlcTx.TxHash(), Index: 1}
+}
+
+// TestRecoverLoopOut tests that the htlc of a loop out swap is swept to the
+// swap destination using the preimage.
+func TestRecoverLoopOut(t *testing.T) {
+	defer test.Guard(t)()
+
+	lnd := test.NewMockLnd()
+	ctx := test.NewContext(t, lnd)
+
+	destAddr := test.GetDestAddr(t, 0)
+	swp := &loopdb.LoopOut{
+		Contract: &loopdb.LoopOutContract{
+			SwapContract: testRecoveryContract(744),
+			DestAddr:     destAddr,
+		},
+	}
+	swp.Hash = swp.Contract.Preimage.Hash()
+
+	htlc, err := swap.NewHtlc(
+		swap.HtlcV2, 744, swp.Contract.SenderKey,
+		swp.Contract.ReceiverKey, swp.Hash, swap.HtlcP2WSH,
+		lnd.ChainParams,
+	)
+	require.NoError(t, err)
+
+	resultChan := make(chan recoveryResult)
+	go func() {
+		result, err := RecoverLoopOut(
+			context.Background(), &lnd.LndServices, swp,
+			&RecoveryRequest{
+				SweepConfTarget: 6,
+				Publish:         true,
+			},
+		)
+		resultChan <- recoveryResult{result, err}
+	}()
+
+	// Without a recorded htlc tx, we look for the htlc script only.
+	ctx.AssertRegisterConf(false, 1)
+	outpoint := confirmHtlc(&ctx, htlc)
+
+	signReq := <-lnd.SignOutputRawChannel
+	require.Equal(
+		t, swp.Contract.ReceiverKey[:],
+		signReq.SignDescriptors[0].KeyDesc.PubKey.SerializeCompressed(),
+	)
+
+	sweepTx := ctx.ReceiveTx()
+
+	result := <-resultChan
+	require.NoError(t, result.err)
+	require.True(t, result.result.Published)
+	require.Equal(t, outpoint, result.result.HtlcOutpoint)
+	require.Equal(t, sweepTx.TxHash(), result.result.SweepTx.TxHash())
+
+	require.Len(t, sweepTx.TxIn, 1)
+	require.Equal(t, outpoint, sweepTx.TxIn[0].PreviousOutPoint)
+	require.Equal(t, htlc.SuccessSequence(), sweepTx.TxIn[0].Sequence)
+
+	destScript, err := swap.PayToAddrScript(destAddr)
+	require.NoError(t, err)
+	require.Len(t, sweepTx.TxOut, 1)
+	require.Equal(t, destScript, sweepTx.TxOut[0].PkScript)
+	require.Equal(
+		t, int64(100000-result.result.Fee), sweepTx.TxOut[0].Value,
+	)
+}
+
+// TestRecoverLoopIn tests that the expired htlc of a loop in swap is swept
+// back to the wallet, and that htlcs that have not expired yet are not swept.
+func TestRecoverLoopIn(t *testing.T) {
+	defer test.Guard(t)()
+
+	// The mock lnd is at height 600.
+	t.Run("expired", func(t *testing.T) {
+		testRecoverLoopIn(t, 600)
+	})
+
+	t.Run("not expired", func(t *testing.T) {
+		testRecoverLoopIn(t, 601)
+	})
+}
+
+func testRecoverLoopIn(t *testing.T, cltvExpiry int32) {
+	lnd := test.NewMockLnd()
+	ctx := test.NewContext(t, lnd)
+
+	swp := &loopdb.LoopIn{
+		Contract: &loopdb.LoopInContract{
+			SwapContract: testRecoveryContract(cltvExpiry),
+		},
+		Loop: loopdb.Loop{
+			Events: []*loopdb.LoopEvent{{
+				SwapStateData: loopdb.SwapStateData{
+					State:      loopdb.StateHtlcPublished,
+					HtlcTxHash: &chainhash.Hash{9},
+				},
+			}},
+		},
+	}
+	swp.Hash = swp.Contract.Preimage.Hash()
+
+	htlc, err := swap.NewHtlc(
+		swap.HtlcV2, cltvExpiry, swp.Contract.SenderKey,
+		swp.Contract.ReceiverKey, swp.Hash, swap.HtlcNP2WSH,
+		lnd.ChainParams,
+	)
+	require.NoError(t, err)
+
+	resultChan := make(chan recoveryResult)
+	go func() {
+		result, err := RecoverLoopIn(
+			context.Background(), &lnd.LndServices, swp,
+			&RecoveryRequest{SweepConfTarget: 6},
+		)
+		resultChan <- recoveryResult{result, err}
+	}()
+
+	// We look for the recorded htlc tx with both htlc output types and
+	// find the htlc in a np2wsh output.
+	ctx.AssertRegisterConf(true, 1)
+	ctx.AssertRegisterConf(true, 1)
+	outpoint := confirmHtlc(&ctx, htlc)
+
+	if cltvExpiry > 600 {
+		result := <-resultChan
+		require.Error(t, result.err)
+		return
+	}
+
+	signReq := <-lnd.SignOutputRawChannel
+	require.Equal(
+		t, swp.Contract.SenderKey[:],
+		signReq.SignDescriptors[0].KeyDesc.PubKey.SerializeCompressed(),
+	)
+
+	result := <-resultChan
+	require.NoError(t, result.err)
+	require.False(t, result.result.Published)
+	require.Equal(t, outpoint, result.result.HtlcOutpoint)
+
+	sweepTx := result.result.SweepTx
+	require.Equal(t, outpoint, sweepTx.TxIn[0].PreviousOutPoint)
+	require.Equal(t, uint32(600), sweepTx.LockTime)
+	require.Equal(
+		t, int64(100000-result.result.Fee), sweepTx.TxOut[0].Value,
+	)
+}
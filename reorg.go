@@ -0,0 +1,299 @@
+package loop
+
+import (
+	"context"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/chainntnfs"
+)
+
+// confResult is the outcome of tracking a tx up to the reorg safety depth.
+type confResult struct {
+	// safe is true if the tx reached the safety depth and false if it was
+	// reorged out.
+	safe bool
+
+	// err is set if tracking the tx failed.
+	err error
+}
+
+// recheckFunc asks lnd whether a tracked tx is still confirmed. The returned
+// channel receives true if it is and false if it was replaced by a
+// conflicting tx. If it doesn't receive anything by the next block, the tx is
+// considered to be reorged out.
+type recheckFunc func(ctx context.Context) (<-chan bool, <-chan error,
+	error)
+
+// confTracker follows a confirmed tx until it is buried under the reorg
+// safety depth.
+//
+// lnd doesn't notify us of reorgs, but it keeps our confirmation registration
+// alive across them. A tx that didn't reach the safety depth by the height at
+// which it should have may therefore just be delayed because we are catching
+// up on blocks or lnd is lagging behind. In that case we ask lnd again whether
+// the tx is confirmed. If lnd reports a conflicting tx, or doesn't report
+// anything until the next block, the tx is considered to be reorged out.
+//
+// The tracker doesn't receive blocks itself, they are handed to it by the
+// swap that uses it. All methods are safe to call on a nil tracker, which
+// never delivers a result.
+type confTracker struct {
+	// heights receives the most recent block height.
+	heights chan int32
+
+	// result receives the outcome of tracking the tx.
+	result chan confResult
+
+	// cancel stops tracking the tx.
+	cancel func()
+}
+
+// newConfTracker starts tracking the given confirmed tx up to the reorg
+// safety depth. The tx must pay to the given pk script and have confirmed at
+// the given height.
+func (s *swapKit) newConfTracker(ctx context.Context, depth uint32,
+	desc string, txHash chainhash.Hash, pkScript []byte, confHeight int32,
+	recheck recheckFunc) (*confTracker, error) {
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	confChan, errChan, err := s.lnd.ChainNotifier.RegisterConfirmationsNtfn(
+		ctx, &txHash, pkScript, int32(depth), confHeight,
+	)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	t := &confTracker{
+		heights: make(chan int32, 1),
+		result:  make(chan confResult, 1),
+		cancel:  cancel,
+	}
+
+	safeHeight := confHeight + int32(depth) - 1
+
+	s.log.Infof("Waiting for %v %v to reach safety depth at height %v",
+		desc, txHash, safeHeight)
+
+	go func() {
+		var (
+			recheckChan    <-chan bool
+			recheckErrChan <-chan error
+			recheckHeight  int32
+		)
+
+		for {
+			select {
+			case <-confChan:
+				s.log.Infof("%v %v reached safety depth", desc,
+					txHash)
+
+				t.result <- confResult{safe: true}
+				return
+
+			case err := <-errChan:
+				t.result <- confResult{err: err}
+				return
+
+			case height := <-t.heights:
+				if height <= safeHeight ||
+					height <= recheckHeight {
+
+					continue
+				}
+
+				if recheckChan != nil {
+					s.log.Warnf("%v %v is no longer confirmed, "+
+						"it was reorged out", desc, txHash)
+
+					t.result <- confResult{}
+					return
+				}
+
+				s.log.Infof("%v %v didn't reach safety depth at "+
+					"height %v yet, checking whether it is "+
+					"still confirmed", desc, txHash,
+					safeHeight)
+
+				var err error
+				recheckChan, recheckErrChan, err = recheck(ctx)
+				if err != nil {
+					t.result <- confResult{err: err}
+					return
+				}
+				recheckHeight = height
+
+			case err := <-recheckErrChan:
+				t.result <- confResult{err: err}
+				return
+
+			case confirmed := <-recheckChan:
+				if !confirmed {
+					t.result <- confResult{}
+					return
+				}
+
+				s.log.Infof("%v %v is still confirmed, waiting "+
+					"for safety depth", desc, txHash)
+
+				recheckChan, recheckErrChan = nil, nil
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return t, nil
+}
+
+// notifyHeight hands a new block height to the tracker.
+func (t *confTracker) notifyHeight(height int32) {
+	if t == nil {
+		return
+	}
+
+	// Replace a height that the tracker didn't pick up yet. We are the
+	// only sender, so the send never blocks.
+	select {
+	case <-t.heights:
+	default:
+	}
+	t.heights <- height
+}
+
+// results returns the channel that receives the outcome of tracking the tx.
+func (t *confTracker) results() <-chan confResult {
+	if t == nil {
+		return nil
+	}
+
+	return t.result
+}
+
+// stop stops tracking the tx.
+func (t *confTracker) stop() {
+	if t == nil {
+		return
+	}
+
+	t.cancel()
+}
+
+// waitForSafeConf waits until the tx that spent the htlc is buried under the
+// reorg safety depth. It returns false if the tx was removed from the chain by
+// a reorg before that.
+//
+// If the tx doesn't reach the safety depth in time, we ask lnd which tx
+// currently spends the htlc. If it is our tx, it may just be delayed and we
+// keep waiting. If it is a different tx, or the htlc is unspent, our tx is
+// considered to be reorged out. The swap then goes back to publishing its
+// spend, which confirms the htlc and its spend again if they are still on
+// the chain.
+func (s *swapKit) waitForSafeConf(ctx context.Context, cfg *executeConfig,
+	htlcOutpoint *wire.OutPoint, htlcPkScript []byte,
+	spend *chainntnfs.SpendDetail) (bool, error) {
+
+	if cfg.reorgSafetyDepth <= 1 {
+		return true, nil
+	}
+
+	tx := spend.SpendingTx
+	txHash := tx.TxHash()
+	recheck := func(ctx context.Context) (<-chan bool, <-chan error,
+		error) {
+
+		notifier := s.lnd.ChainNotifier
+		spendChan, errChan, err := notifier.RegisterSpendNtfn(
+			ctx, htlcOutpoint, htlcPkScript, s.htlcConfHeight,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		stillSpent := make(chan bool, 1)
+		go func() {
+			select {
+			case detail := <-spendChan:
+				spenderHash := detail.SpendingTx.TxHash()
+				if spenderHash != txHash {
+					s.log.Warnf("Htlc is now spent by tx %v",
+						spenderHash)
+				}
+
+				stillSpent <- spenderHash == txHash
+
+			case <-ctx.Done():
+			}
+		}()
+
+		return stillSpent, errChan, nil
+	}
+
+	tracker, err := s.newConfTracker(
+		ctx, cfg.reorgSafetyDepth, "htlc spend", txHash,
+		tx.TxOut[0].PkScript, spend.SpendingHeight, recheck,
+	)
+	if err != nil {
+		return false, err
+	}
+	defer tracker.stop()
+
+	for {
+		select {
+		case notification := <-cfg.blockEpochChan:
+			s.height = notification.(int32)
+			tracker.notifyHeight(s.height)
+
+		case result := <-tracker.results():
+			return result.safe, result.err
+
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
+
+// trackHtlcConf tracks the confirmed htlc tx up to the reorg safety depth
+// while we wait for the htlc to be spent. Once the htlc is spent, the spend is
+// tracked instead, which can't be safe before the htlc tx is. It returns nil
+// if reorg tracking is disabled.
+func (s *swapKit) trackHtlcConf(ctx context.Context, cfg *executeConfig,
+	htlcTx *wire.MsgTx, htlcPkScript []byte) (*confTracker, error) {
+
+	if cfg.reorgSafetyDepth <= 1 {
+		return nil, nil
+	}
+
+	txHash := htlcTx.TxHash()
+	recheck := func(ctx context.Context) (<-chan bool, <-chan error,
+		error) {
+
+		notifier := s.lnd.ChainNotifier
+		confChan, errChan, err := notifier.RegisterConfirmationsNtfn(
+			ctx, &txHash, htlcPkScript, 1, s.htlcConfHeight,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		confirmed := make(chan bool, 1)
+		go func() {
+			select {
+			case <-confChan:
+				confirmed <- true
+
+			case <-ctx.Done():
+			}
+		}()
+
+		return confirmed, errChan, nil
+	}
+
+	return s.newConfTracker(
+		ctx, cfg.reorgSafetyDepth, "htlc tx", txHash, htlcPkScript,
+		s.htlcConfHeight, recheck,
+	)
+}
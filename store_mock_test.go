@@ -151,6 +151,28 @@ func (s *storeMock) UpdateLoopOut(hash lntypes.Hash, time time.Time,
 	return nil
 }
 
+// UpdateLoopOuts stores a new event for each of the target loop out swaps.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) UpdateLoopOuts(time time.Time,
+	updates map[lntypes.Hash]loopdb.SwapStateData) error {
+
+	for hash := range updates {
+		if _, ok := s.loopOutUpdates[hash]; !ok {
+			return errors.New("swap does not exists")
+		}
+	}
+
+	for hash, state := range updates {
+		err := s.UpdateLoopOut(hash, time, state)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // UpdateLoopIn stores a new event for a target loop in swap. This appends to
 // the event log for a particular swap as it goes through the various stages in
 // its lifetime.
@@ -171,6 +193,65 @@ func (s *storeMock) UpdateLoopIn(hash lntypes.Hash, time time.Time,
 	return nil
 }
 
+// UpdateLoopIns stores a new event for each of the target loop in swaps.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) UpdateLoopIns(time time.Time,
+	updates map[lntypes.Hash]loopdb.SwapStateData) error {
+
+	for hash := range updates {
+		if _, ok := s.loopInUpdates[hash]; !ok {
+			return errors.New("swap does not exists")
+		}
+	}
+
+	for hash, state := range updates {
+		err := s.UpdateLoopIn(hash, time, state)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// QuerySwaps returns all swaps in the store as a single page, the query is
+// not applied.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) QuerySwaps(_ *loopdb.SwapQuery) (*loopdb.SwapPage,
+	error) {
+
+	loopOuts, err := s.FetchLoopOutSwaps()
+	if err != nil {
+		return nil, err
+	}
+
+	loopIns, err := s.FetchLoopInSwaps()
+	if err != nil {
+		return nil, err
+	}
+
+	return &loopdb.SwapPage{
+		LoopOuts: loopOuts,
+		LoopIns:  loopIns,
+	}, nil
+}
+
+// ArchiveSwaps is not supported by the mock store.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) ArchiveSwaps(_ []lntypes.Hash) error {
+	return errors.New("archiving not supported")
+}
+
+// Snapshot is not supported by the mock store.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) Snapshot(_ string) error {
+	return errors.New("snapshot not supported")
+}
+
 func (s *storeMock) Close() error {
 	return nil
 }
@@ -200,13 +281,17 @@ func (s *storeMock) assertLoopOutStored() {
 	}
 }
 
-func (s *storeMock) assertLoopOutState(expectedState loopdb.SwapState) {
+func (s *storeMock) assertLoopOutState(
+	expectedState loopdb.SwapState) loopdb.SwapStateData {
+
 	s.t.Helper()
 
 	state := <-s.loopOutUpdateChan
 	if state.State != expectedState {
 		s.t.Fatalf("expected state %v, got %v", expectedState, state)
 	}
+
+	return state
 }
 
 func (s *storeMock) assertLoopInStored() {
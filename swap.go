@@ -4,9 +4,12 @@ import (
 	"context"
 	"time"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/lightninglabs/lndclient"
 	"github.com/lightninglabs/loop/loopdb"
 	"github.com/lightninglabs/loop/swap"
+	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/lntypes"
 )
 
@@ -27,6 +30,23 @@ type swapKit struct {
 
 	swapType swap.Type
 
+	// reorged is set while we announce that the swap reverted to an
+	// earlier state because of a reorg.
+	reorged bool
+
+	// htlcOutpoint is the outpoint of the confirmed htlc.
+	htlcOutpoint *wire.OutPoint
+
+	// htlcConfHeight is the height at which the htlc confirmed.
+	htlcConfHeight int32
+
+	// spendTxHash is the tx id of the confirmed tx that spent the htlc.
+	spendTxHash *chainhash.Hash
+
+	// spendConfHeight is the height at which the tx that spent the htlc
+	// confirmed.
+	spendConfHeight int32
+
 	swapConfig
 }
 
@@ -85,13 +105,39 @@ func (s *swapKit) swapInfo() *SwapInfo {
 		SwapHash:     s.hash,
 		SwapType:     s.swapType,
 		LastUpdate:   s.lastUpdateTime,
+		Reorged:      s.reorged,
 		SwapStateData: loopdb.SwapStateData{
-			State: s.state,
-			Cost:  s.cost,
+			State:           s.state,
+			Cost:            s.cost,
+			HtlcOutpoint:    s.htlcOutpoint,
+			HtlcConfHeight:  s.htlcConfHeight,
+			SpendTxHash:     s.spendTxHash,
+			SpendConfHeight: s.spendConfHeight,
 		},
 	}
 }
 
+// restoreOnChainState restores the on-chain details of the swap that were
+// recorded with its last update.
+func (s *swapKit) restoreOnChainState(lastUpdate *loopdb.LoopEvent) {
+	s.htlcOutpoint = lastUpdate.HtlcOutpoint
+	s.htlcConfHeight = lastUpdate.HtlcConfHeight
+	s.spendTxHash = lastUpdate.SpendTxHash
+	s.spendConfHeight = lastUpdate.SpendConfHeight
+}
+
+// recordHtlcSpend records the confirmed tx that spent the htlc, or clears it
+// if nil is passed because the spend was reorged out.
+func (s *swapKit) recordHtlcSpend(spend *chainntnfs.SpendDetail) {
+	if spend == nil {
+		s.spendTxHash, s.spendConfHeight = nil, 0
+		return
+	}
+
+	s.spendTxHash = spend.SpenderTxHash
+	s.spendConfHeight = spend.SpendingHeight
+}
+
 type genericSwap interface {
 	execute(mainCtx context.Context, cfg *executeConfig,
 		height int32) error
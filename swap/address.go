@@ -0,0 +1,244 @@
+package swap
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/bech32"
+)
+
+const (
+	// taprootWitnessVersion is the witness version of pay-to-taproot
+	// outputs.
+	taprootWitnessVersion = 1
+
+	// taprootProgramLen is the length of the witness program of a
+	// pay-to-taproot output.
+	taprootProgramLen = 32
+
+	// P2TRSize is the size of a pay-to-taproot output script: OP_1
+	// OP_DATA_32 <32-byte output key>.
+	P2TRSize = 1 + 1 + taprootProgramLen
+
+	// bech32mConst is the constant that the bech32m checksum of an
+	// address is xor'ed with, as defined in BIP-350.
+	bech32mConst = 0x2bc830a3
+
+	// bech32Charset is the character set used to encode bech32 data.
+	bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+// AddressTaproot is a pay-to-taproot address as defined in BIP-341. It is
+// encoded using bech32m as defined in BIP-350.
+type AddressTaproot struct {
+	hrp            string
+	witnessProgram [taprootProgramLen]byte
+}
+
+// A compile time check to ensure that AddressTaproot implements the address
+// interface.
+var _ btcutil.Address = (*AddressTaproot)(nil)
+
+// NewAddressTaproot returns a new pay-to-taproot address for the 32 byte
+// output key provided.
+func NewAddressTaproot(witnessProgram []byte,
+	params *chaincfg.Params) (*AddressTaproot, error) {
+
+	if len(witnessProgram) != taprootProgramLen {
+		return nil, fmt.Errorf("witness program must be %v bytes for "+
+			"p2tr, got %v", taprootProgramLen, len(witnessProgram))
+	}
+
+	addr := &AddressTaproot{
+		hrp: strings.ToLower(params.Bech32HRPSegwit),
+	}
+	copy(addr.witnessProgram[:], witnessProgram)
+
+	return addr, nil
+}
+
+// EncodeAddress returns the bech32m string encoding of the address.
+//
+// NOTE: This is part of the btcutil.Address interface.
+func (a *AddressTaproot) EncodeAddress() string {
+	converted, err := bech32.ConvertBits(a.witnessProgram[:], 8, 5, true)
+	if err != nil {
+		return ""
+	}
+
+	data := append([]byte{taprootWitnessVersion}, converted...)
+	checksum := bech32mChecksum(a.hrp, data)
+
+	var sb strings.Builder
+	sb.WriteString(a.hrp)
+	sb.WriteByte('1')
+	for _, b := range append(data, checksum...) {
+		sb.WriteByte(bech32Charset[b])
+	}
+
+	return sb.String()
+}
+
+// ScriptAddress returns the witness program of the address.
+//
+// NOTE: This is part of the btcutil.Address interface.
+func (a *AddressTaproot) ScriptAddress() []byte {
+	return a.witnessProgram[:]
+}
+
+// IsForNet returns whether the address is associated with the network
+// provided.
+//
+// NOTE: This is part of the btcutil.Address interface.
+func (a *AddressTaproot) IsForNet(params *chaincfg.Params) bool {
+	return a.hrp == strings.ToLower(params.Bech32HRPSegwit)
+}
+
+// String returns the bech32m string encoding of the address.
+//
+// NOTE: This is part of the btcutil.Address interface.
+func (a *AddressTaproot) String() string {
+	return a.EncodeAddress()
+}
+
+// DecodeAddress decodes the string encoding of an address. In addition to
+// the address types supported by btcutil, it decodes pay-to-taproot
+// addresses.
+func DecodeAddress(addr string, params *chaincfg.Params) (btcutil.Address,
+	error) {
+
+	decoded, err := btcutil.DecodeAddress(addr, params)
+	if err == nil {
+		return decoded, nil
+	}
+
+	// If btcutil fails to decode the address, check whether it is a
+	// taproot address. If it isn't, we return the original error because
+	// it is more descriptive for all other address types.
+	taprootAddr, taprootErr := decodeTaprootAddress(addr, params)
+	if taprootErr != nil {
+		return nil, err
+	}
+
+	return taprootAddr, nil
+}
+
+// PayToAddrScript returns the output script paying to the address provided.
+// In addition to the address types supported by txscript, it creates
+// pay-to-taproot scripts.
+func PayToAddrScript(addr btcutil.Address) ([]byte, error) {
+	taprootAddr, ok := addr.(*AddressTaproot)
+	if !ok {
+		return txscript.PayToAddrScript(addr)
+	}
+
+	return txscript.NewScriptBuilder().
+		AddOp(txscript.OP_1).
+		AddData(taprootAddr.witnessProgram[:]).
+		Script()
+}
+
+// decodeTaprootAddress decodes a bech32m encoded pay-to-taproot address.
+func decodeTaprootAddress(addr string,
+	params *chaincfg.Params) (*AddressTaproot, error) {
+
+	// Mixed case addresses are invalid, all other addresses are
+	// processed in lower case.
+	if strings.ToLower(addr) != addr && strings.ToUpper(addr) != addr {
+		return nil, errors.New("mixed case address")
+	}
+	addr = strings.ToLower(addr)
+
+	if len(addr) > 90 {
+		return nil, errors.New("address too long")
+	}
+
+	// The human readable part is separated from the data by the last
+	// occurrence of '1'. The data part includes a 6 character checksum.
+	sep := strings.LastIndexByte(addr, '1')
+	if sep < 1 || sep+7 > len(addr) {
+		return nil, errors.New("invalid separator position")
+	}
+
+	hrp := addr[:sep]
+	if hrp != strings.ToLower(params.Bech32HRPSegwit) {
+		return nil, fmt.Errorf("address %v is not for network %v",
+			addr, params.Name)
+	}
+
+	data := make([]byte, 0, len(addr)-sep-1)
+	for _, c := range addr[sep+1:] {
+		idx := strings.IndexRune(bech32Charset, c)
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid character %q", c)
+		}
+		data = append(data, byte(idx))
+	}
+
+	if bech32Polymod(hrp, data) != bech32mConst {
+		return nil, errors.New("invalid bech32m checksum")
+	}
+
+	// Strip the checksum and check that this is a witness v1 address.
+	data = data[:len(data)-6]
+	if len(data) == 0 || data[0] != taprootWitnessVersion {
+		return nil, errors.New("not a taproot address")
+	}
+
+	program, err := bech32.ConvertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewAddressTaproot(program, params)
+}
+
+// bech32mChecksum returns the 6 character bech32m checksum of the data
+// provided.
+func bech32mChecksum(hrp string, data []byte) []byte {
+	values := make([]byte, len(data)+6)
+	copy(values, data)
+	polymod := bech32Polymod(hrp, values) ^ bech32mConst
+
+	checksum := make([]byte, 6)
+	for i := range checksum {
+		checksum[i] = byte((polymod >> uint(5*(5-i))) & 31)
+	}
+
+	return checksum
+}
+
+// bech32Polymod calculates the BCH checksum polynomial of the expanded human
+// readable part and the data provided.
+func bech32Polymod(hrp string, data []byte) uint32 {
+	gen := [5]uint32{
+		0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3,
+	}
+
+	values := make([]byte, 0, 2*len(hrp)+1+len(data))
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, hrp[i]>>5)
+	}
+	values = append(values, 0)
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, hrp[i]&31)
+	}
+	values = append(values, data...)
+
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+
+	return chk
+}
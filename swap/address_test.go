@@ -0,0 +1,94 @@
+package swap
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTaprootAddress tests decoding and encoding of taproot addresses using
+// the test vectors of BIP-350.
+func TestTaprootAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		addr     string
+		params   *chaincfg.Params
+		pkScript string
+		valid    bool
+	}{
+		{
+			name:   "mainnet",
+			addr:   "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0",
+			params: &chaincfg.MainNetParams,
+			pkScript: "512079be667ef9dcbbac55a06295ce870b07029bfcdb2" +
+				"dce28d959f2815b16f81798",
+			valid: true,
+		},
+		{
+			name:   "testnet",
+			addr:   "tb1pqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesf3hn0c",
+			params: &chaincfg.TestNet3Params,
+			pkScript: "5120000000c4a5cad46221b2a187905e5266362b99d5e" +
+				"91c6ce24d165dab93e86433",
+			valid: true,
+		},
+		{
+			name:   "wrong network",
+			addr:   "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0",
+			params: &chaincfg.TestNet3Params,
+		},
+		{
+			name:   "bech32 instead of bech32m checksum",
+			addr:   "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd",
+			params: &chaincfg.MainNetParams,
+		},
+		{
+			name:   "mixed case",
+			addr:   "tb1pqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesf3hn0C",
+			params: &chaincfg.TestNet3Params,
+		},
+		{
+			name:   "invalid program length",
+			addr:   "bc1pw5dgrnzv",
+			params: &chaincfg.MainNetParams,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			addr, err := DecodeAddress(test.addr, test.params)
+			if !test.valid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			require.IsType(t, &AddressTaproot{}, addr)
+			require.True(t, addr.IsForNet(test.params))
+			require.Equal(t, test.addr, addr.String())
+
+			pkScript, err := PayToAddrScript(addr)
+			require.NoError(t, err)
+			require.Equal(t, test.pkScript, hex.EncodeToString(pkScript))
+			require.Len(t, pkScript, P2TRSize)
+		})
+	}
+}
+
+// TestDecodeAddressFallback tests that addresses that are supported by
+// btcutil are decoded by btcutil.
+func TestDecodeAddressFallback(t *testing.T) {
+	const addr = "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"
+
+	decoded, err := DecodeAddress(addr, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	require.IsType(t, &btcutil.AddressWitnessPubKeyHash{}, decoded)
+
+	_, err = DecodeAddress("invalid", &chaincfg.MainNetParams)
+	require.Error(t, err)
+}
@@ -1,9 +1,31 @@
 package swap
 
+import (
+	"encoding/hex"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
 var (
 	// KeyFamily is the key family used to generate keys that allow
 	// spending of the htlc.
 	//
 	// TODO(joost): decide on actual value
 	KeyFamily = int32(99)
+
+	// BackupKeyFamily is the key family of the key that swap backups are
+	// encrypted with.
+	BackupKeyFamily = int32(100)
+
+	// numsKey is the compressed encoding of the point suggested by BIP-341
+	// for which nobody knows the discrete logarithm.
+	numsKey, _ = hex.DecodeString(
+		"0250929b74c1a04954b78b4b6035e97a5e078a5a0f28ec96d547bfee9ace803ac0",
+	)
 )
+
+// NUMSKey returns the point suggested by BIP-341 for which nobody knows the
+// discrete logarithm.
+func NUMSKey() (*btcec.PublicKey, error) {
+	return btcec.ParsePubKey(numsKey, btcec.S256())
+}
@@ -0,0 +1,304 @@
+package sweep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/lndclient"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightningnetwork/lnd/input"
+	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/lightningnetwork/lnd/lnwallet"
+)
+
+var (
+	// ErrNoBatchInputs is returned when a batch sweep tx is requested
+	// without any inputs.
+	ErrNoBatchInputs = errors.New("no inputs to batch")
+
+	// ErrBatchFeeTooLow is returned when a batch sweep tx can't pay the
+	// fee that is required to replace the previous sweep txes of its
+	// inputs without exceeding the maximum fee of an input.
+	ErrBatchFeeTooLow = errors.New("batch can't pay the fee required " +
+		"to replace previous sweeps")
+)
+
+// BatchInput describes a single htlc that is swept as part of a batch sweep
+// tx.
+type BatchInput struct {
+	// Htlc is the htlc that is spent by this input.
+	Htlc *swap.Htlc
+
+	// Outpoint is the outpoint of the htlc.
+	Outpoint wire.OutPoint
+
+	// Value is the value of the htlc output.
+	Value btcutil.Amount
+
+	// KeyBytes is the key that signs the htlc spend.
+	KeyBytes [33]byte
+
+	// Sequence is the sequence to spend the htlc with.
+	Sequence uint32
+
+	// WitnessFunc creates the witness for this input from its signature.
+	WitnessFunc func(sig []byte) (wire.TxWitness, error)
+
+	// AddInputEstimate adds the weight of this input to a weight
+	// estimator.
+	AddInputEstimate func(*input.TxWeightEstimator)
+
+	// Destinations are the addresses that receive the value of the htlc,
+	// split according to their weights. Inputs that share a destination
+	// address are paid out in a single output.
+	Destinations []loopdb.SweepDestination
+
+	// Fee is the fee that this input would pay if it was swept on its own.
+	Fee btcutil.Amount
+
+	// MaxFee is the maximum fee that this input pays.
+	MaxFee btcutil.Amount
+
+	// PrevTxHash is the hash of the most recent sweep tx that spent the
+	// htlc, which is replaced by the batch. It is nil if the htlc wasn't
+	// swept before.
+	PrevTxHash *chainhash.Hash
+
+	// PrevTxFee is the total fee of the tx with PrevTxHash.
+	PrevTxFee btcutil.Amount
+}
+
+// CreateBatchSweepTx creates a single tx that sweeps all of the given htlcs.
+// Because the inputs share the tx overhead and possibly their outputs, the
+// batch is cheaper than sweeping every htlc on its own. Every input pays the
+// share of its individual fee that corresponds to the weight saved by the
+// batch. If the batch replaces previous sweep txes of its inputs, its total
+// fee is raised to the fees of all replaced txes plus the incremental relay
+// fee for the size of the batch, as required by BIP125. The increase is split
+// between the inputs in proportion to their fees. The fee paid by every input
+// is returned along with the tx, in the order of the inputs.
+func (s *Sweeper) CreateBatchSweepTx(ctx context.Context, height int32,
+	inputs []*BatchInput) (*wire.MsgTx, []btcutil.Amount, error) {
+
+	if len(inputs) == 0 {
+		return nil, nil, ErrNoBatchInputs
+	}
+
+	// Calculate the weight of the batch and the sum of the weights that
+	// the inputs would have when swept individually.
+	var (
+		batchEstimate     input.TxWeightEstimator
+		individualWeights int64
+		destinations      = make(map[string]int)
+	)
+	for _, in := range inputs {
+		var individualEstimate input.TxWeightEstimator
+		for _, dest := range in.Destinations {
+			err := AddOutputEstimate(&individualEstimate, dest.Addr)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		in.AddInputEstimate(&individualEstimate)
+		individualWeights += int64(individualEstimate.Weight())
+
+		in.AddInputEstimate(&batchEstimate)
+
+		for _, dest := range in.Destinations {
+			addr := dest.Addr.String()
+			if _, ok := destinations[addr]; ok {
+				continue
+			}
+			destinations[addr] = len(destinations)
+
+			err := AddOutputEstimate(&batchEstimate, dest.Addr)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	batchWeight := int64(batchEstimate.Weight())
+
+	fees := make([]btcutil.Amount, len(inputs))
+	for i, in := range inputs {
+		fees[i] = in.Fee * btcutil.Amount(batchWeight) /
+			btcutil.Amount(individualWeights)
+	}
+
+	sweepTx, err := composeBatchTx(height, inputs, destinations, fees)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// If the batch is identical to the previous sweep tx of all of its
+	// inputs, it is only republished and doesn't need to pay for a
+	// replacement. The tx hash doesn't cover the witnesses, so we can
+	// check this before signing.
+	sweepTxHash := sweepTx.TxHash()
+	replacedFees := make(map[chainhash.Hash]btcutil.Amount)
+	for _, in := range inputs {
+		if in.PrevTxHash == nil || *in.PrevTxHash == sweepTxHash {
+			continue
+		}
+
+		if in.PrevTxFee > replacedFees[*in.PrevTxHash] {
+			replacedFees[*in.PrevTxHash] = in.PrevTxFee
+		}
+	}
+
+	if len(replacedFees) > 0 {
+		minFee := IncrementalRelayFee(batchWeight)
+		for _, fee := range replacedFees {
+			minFee += fee
+		}
+
+		fees, err = raiseBatchFees(inputs, fees, minFee)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		sweepTx, err = composeBatchTx(height, inputs, destinations, fees)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Generate a signature for every swap htlc input.
+	signDescs := make([]*lndclient.SignDescriptor, len(inputs))
+	for i, in := range inputs {
+		key, err := btcec.ParsePubKey(in.KeyBytes[:], btcec.S256())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		signDescs[i] = &lndclient.SignDescriptor{
+			WitnessScript: in.Htlc.Script(),
+			Output: &wire.TxOut{
+				Value:    int64(in.Value),
+				PkScript: in.Htlc.PkScript,
+			},
+			HashType:   txscript.SigHashAll,
+			InputIndex: i,
+			KeyDesc: keychain.KeyDescriptor{
+				PubKey: key,
+			},
+		}
+	}
+
+	rawSigs, err := s.Lnd.Signer.SignOutputRaw(ctx, sweepTx, signDescs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("signing: %v", err)
+	}
+	if len(rawSigs) != len(inputs) {
+		return nil, nil, fmt.Errorf("expected %v signatures, got %v",
+			len(inputs), len(rawSigs))
+	}
+
+	// Add witness stacks to the tx inputs.
+	for i, in := range inputs {
+		sweepTx.TxIn[i].Witness, err = in.WitnessFunc(rawSigs[i])
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return sweepTx, fees, nil
+}
+
+// raiseBatchFees raises the total of the given input fees to at least minFee.
+// The increase is split between the inputs in proportion to their fees, and
+// any rounding remainder is paid by the first input. ErrBatchFeeTooLow is
+// returned if an input would exceed its maximum fee.
+func raiseBatchFees(inputs []*BatchInput, fees []btcutil.Amount,
+	minFee btcutil.Amount) ([]btcutil.Amount, error) {
+
+	var total btcutil.Amount
+	for _, fee := range fees {
+		total += fee
+	}
+	if total >= minFee {
+		return fees, nil
+	}
+
+	var (
+		increase = minFee - total
+		raised   = make([]btcutil.Amount, len(fees))
+		added    btcutil.Amount
+	)
+	for i, fee := range fees {
+		share := increase / btcutil.Amount(len(fees))
+		if total > 0 {
+			share = increase * fee / total
+		}
+
+		raised[i] = fee + share
+		added += share
+	}
+	raised[0] += increase - added
+
+	for i, in := range inputs {
+		if raised[i] > in.MaxFee {
+			return nil, ErrBatchFeeTooLow
+		}
+	}
+
+	return raised, nil
+}
+
+// composeBatchTx creates the unsigned batch sweep tx in which every input
+// pays the given fee. The value of every input minus its fee is split between
+// its destinations, which are paid out in the outputs with the given indices.
+func composeBatchTx(height int32, inputs []*BatchInput,
+	destinations map[string]int, fees []btcutil.Amount) (*wire.MsgTx,
+	error) {
+
+	sweepTx := wire.NewMsgTx(2)
+
+	sweepTx.LockTime = uint32(height)
+
+	outputs := make([]*wire.TxOut, len(destinations))
+	for i, in := range inputs {
+		// Add HTLC input.
+		sweepTx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: in.Outpoint,
+			SignatureScript:  in.Htlc.SigScript,
+			Sequence:         in.Sequence,
+		})
+
+		// Add the value of the htlc minus its fee to the outputs of its
+		// destination addresses.
+		values := SplitValue(in.Value-fees[i], in.Destinations)
+		for j, dest := range in.Destinations {
+			idx := destinations[dest.Addr.String()]
+			if outputs[idx] == nil {
+				pkScript, err := swap.PayToAddrScript(dest.Addr)
+				if err != nil {
+					return nil, err
+				}
+
+				outputs[idx] = &wire.TxOut{
+					PkScript: pkScript,
+				}
+			}
+			outputs[idx].Value += int64(values[j])
+		}
+	}
+
+	for _, out := range outputs {
+		if btcutil.Amount(out.Value) < lnwallet.DefaultDustLimit() {
+			return nil, fmt.Errorf("batch output of %v is dust",
+				btcutil.Amount(out.Value))
+		}
+
+		sweepTx.AddTxOut(out)
+	}
+
+	return sweepTx, nil
+}
@@ -3,15 +3,19 @@ package sweep
 import (
 	"context"
 	"fmt"
+	"math/big"
 
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
 	"github.com/lightninglabs/lndclient"
+	"github.com/lightninglabs/loop/loopdb"
 	"github.com/lightninglabs/loop/swap"
 	"github.com/lightningnetwork/lnd/input"
 	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 )
 
 // Sweeper creates htlc sweep txes.
@@ -26,7 +30,7 @@ func (s *Sweeper) CreateSweepTx(
 	keyBytes [33]byte,
 	witnessFunc func(sig []byte) (wire.TxWitness, error),
 	amount, fee btcutil.Amount,
-	destAddr btcutil.Address) (*wire.MsgTx, error) {
+	destinations []loopdb.SweepDestination) (*wire.MsgTx, error) {
 
 	// Compose tx.
 	sweepTx := wire.NewMsgTx(2)
@@ -40,17 +44,28 @@ func (s *Sweeper) CreateSweepTx(
 		Sequence:         sequence,
 	})
 
-	// Add output for the destination address.
-	sweepPkScript, err := txscript.PayToAddrScript(destAddr)
-	if err != nil {
-		return nil, err
+	// Add an output for every destination address, deducting the fee
+	// from the destinations according to their weights.
+	values := SplitValue(amount-fee, destinations)
+	for i, dest := range destinations {
+		if len(destinations) > 1 &&
+			values[i] < lnwallet.DefaultDustLimit() {
+
+			return nil, fmt.Errorf("sweep output of %v to %v is "+
+				"dust", values[i], dest.Addr)
+		}
+
+		sweepPkScript, err := swap.PayToAddrScript(dest.Addr)
+		if err != nil {
+			return nil, err
+		}
+
+		sweepTx.AddTxOut(&wire.TxOut{
+			PkScript: sweepPkScript,
+			Value:    int64(values[i]),
+		})
 	}
 
-	sweepTx.AddTxOut(&wire.TxOut{
-		PkScript: sweepPkScript,
-		Value:    int64(amount - fee),
-	})
-
 	// Generate a signature for the swap htlc transaction.
 
 	key, err := btcec.ParsePubKey(keyBytes[:], btcec.S256())
@@ -87,12 +102,12 @@ func (s *Sweeper) CreateSweepTx(
 	return sweepTx, nil
 }
 
-// GetSweepFee calculates the required tx fee to spend to P2WKH. It takes a
-// function that is expected to add the weight of the input to the weight
-// estimator.
+// GetSweepFee calculates the required tx fee to spend to the destinations
+// provided. It takes a function that is expected to add the weight of the
+// input to the weight estimator.
 func (s *Sweeper) GetSweepFee(ctx context.Context,
 	addInputEstimate func(*input.TxWeightEstimator),
-	destAddr btcutil.Address, sweepConfTarget int32) (
+	destinations []loopdb.SweepDestination, sweepConfTarget int32) (
 	btcutil.Amount, error) {
 
 	// Get fee estimate from lnd.
@@ -102,7 +117,46 @@ func (s *Sweeper) GetSweepFee(ctx context.Context,
 	}
 
 	// Calculate weight for this tx.
+	weight, err := GetSweepWeight(addInputEstimate, destinations)
+	if err != nil {
+		return 0, err
+	}
+
+	return feeRate.FeeForWeight(weight), nil
+}
+
+// GetSweepWeight estimates the weight of a tx that spends a single input to
+// the destinations provided. It takes a function that is expected to add the
+// weight of the input to the weight estimator.
+func GetSweepWeight(addInputEstimate func(*input.TxWeightEstimator),
+	destinations []loopdb.SweepDestination) (int64, error) {
+
 	var weightEstimate input.TxWeightEstimator
+	for _, dest := range destinations {
+		err := AddOutputEstimate(&weightEstimate, dest.Addr)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	addInputEstimate(&weightEstimate)
+
+	return int64(weightEstimate.Weight()), nil
+}
+
+// IncrementalRelayFee returns the minimum amount by which a tx with the given
+// weight has to raise the fee of the tx that it replaces to be accepted into
+// the mempool. It is based on the default incremental relay fee rate of
+// bitcoind, which is equal to the minimum relay fee rate.
+func IncrementalRelayFee(weight int64) btcutil.Amount {
+	return chainfee.FeePerKwFloor.FeeForWeight(weight)
+}
+
+// AddOutputEstimate adds the weight of an output paying to the given address
+// to the weight estimator.
+func AddOutputEstimate(weightEstimate *input.TxWeightEstimator,
+	destAddr btcutil.Address) error {
+
 	switch destAddr.(type) {
 	case *btcutil.AddressWitnessScriptHash:
 		weightEstimate.AddP2WSHOutput()
@@ -112,12 +166,46 @@ func (s *Sweeper) GetSweepFee(ctx context.Context,
 		weightEstimate.AddP2SHOutput()
 	case *btcutil.AddressPubKeyHash:
 		weightEstimate.AddP2PKHOutput()
+	case *swap.AddressTaproot:
+		weightEstimate.AddTxOutput(&wire.TxOut{
+			PkScript: make([]byte, swap.P2TRSize),
+		})
 	default:
-		return 0, fmt.Errorf("unknown address type %T", destAddr)
+		return fmt.Errorf("unknown address type %T", destAddr)
 	}
 
-	addInputEstimate(&weightEstimate)
-	weight := weightEstimate.Weight()
+	return nil
+}
+
+// SplitValue splits a value across the destinations provided according to
+// their weights. The remainder of the division is added to the last
+// destination, so that the values always add up to the value provided.
+func SplitValue(value btcutil.Amount,
+	destinations []loopdb.SweepDestination) []btcutil.Amount {
+
+	var totalWeight int64
+	for _, dest := range destinations {
+		totalWeight += int64(dest.Weight)
+	}
+
+	values := make([]btcutil.Amount, len(destinations))
+	remaining := value
+	for i, dest := range destinations {
+		if i == len(destinations)-1 {
+			values[i] = remaining
+			break
+		}
+
+		// Use big integers so that large weights don't overflow the
+		// multiplication.
+		share := new(big.Int).Mul(
+			big.NewInt(int64(value)), big.NewInt(int64(dest.Weight)),
+		)
+		share.Quo(share, big.NewInt(totalWeight))
+
+		values[i] = btcutil.Amount(share.Int64())
+		remaining -= values[i]
+	}
 
-	return feeRate.FeeForWeight(int64(weight)), nil
+	return values
 }
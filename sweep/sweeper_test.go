@@ -0,0 +1,58 @@
+package sweep
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSplitValue tests splitting a value across weighted destinations.
+func TestSplitValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    btcutil.Amount
+		weights  []uint32
+		expected []btcutil.Amount
+	}{
+		{
+			name:     "single destination",
+			value:    1000,
+			weights:  []uint32{5},
+			expected: []btcutil.Amount{1000},
+		},
+		{
+			name:     "even split",
+			value:    1000,
+			weights:  []uint32{1, 1},
+			expected: []btcutil.Amount{500, 500},
+		},
+		{
+			name:     "remainder to last destination",
+			value:    1000,
+			weights:  []uint32{1, 1, 1},
+			expected: []btcutil.Amount{333, 333, 334},
+		},
+		{
+			name:     "large weights",
+			value:    btcutil.MaxSatoshi,
+			weights:  []uint32{1<<32 - 1, 1<<32 - 1},
+			expected: []btcutil.Amount{btcutil.MaxSatoshi / 2, btcutil.MaxSatoshi / 2},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			dests := make([]loopdb.SweepDestination, len(test.weights))
+			for i, weight := range test.weights {
+				dests[i].Weight = weight
+			}
+
+			values := SplitValue(test.value, dests)
+			require.Equal(t, test.expected, values)
+		})
+	}
+}
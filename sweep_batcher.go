@@ -0,0 +1,332 @@
+package loop
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/lndclient"
+	"github.com/lightninglabs/loop/labels"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightninglabs/loop/sweep"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+var (
+	// BatchSweepDelay is the time that the sweep batcher waits for more
+	// sweep requests after it received the first request of a new batch.
+	// Because all swaps attempt to sweep a fixed delay after a new block
+	// arrived, this delay only needs to cover the spread between them.
+	BatchSweepDelay = 5 * time.Second
+
+	// BatchSweepDeadlineDelta is the delta of blocks from a Loop Out
+	// swap's expiration height at which the swap no longer joins sweep
+	// batches and sweeps its htlc individually. Within this delta, the
+	// fee of the swap's sweep is bumped with every block, which we don't
+	// want to impose on the other swaps of a batch.
+	BatchSweepDeadlineDelta = SweepFeeBumpDelta
+)
+
+// sweepRequest is a request to sweep a loop out htlc as part of a batch.
+type sweepRequest struct {
+	// swapHash is the hash of the swap that requests the sweep.
+	swapHash lntypes.Hash
+
+	// input describes the htlc that is swept.
+	input *sweep.BatchInput
+
+	// height is the current block height known to the swap.
+	height int32
+
+	// state is the current state of the requesting swap. The batcher
+	// records the batch sweep on top of it before publishing the batch.
+	state loopdb.SwapStateData
+
+	// resultChan receives the result of the request. It is buffered, so
+	// that the batcher doesn't block on swaps that stopped waiting.
+	resultChan chan *sweepResult
+}
+
+// sweepResult is the result of a sweep request. It is handed back to the
+// requesting swap, which updates its own state accordingly.
+type sweepResult struct {
+	// txHash is the hash of the batch tx.
+	txHash chainhash.Hash
+
+	// fee is the fee that is paid for the htlc of the request.
+	fee btcutil.Amount
+
+	// batchFee is the total fee of the batch tx.
+	batchFee btcutil.Amount
+
+	// updateTime is the time at which the batch tx was recorded for the
+	// swap. It is zero if the swap had already recorded the batch tx
+	// before. If the batch could not be published, it is the time at
+	// which the swap was marked as having revealed its preimage.
+	updateTime time.Time
+
+	// err is set if the batch was not published.
+	err error
+}
+
+// sweepBatcher collects loop out htlcs that are ready to be swept and sweeps
+// them in a single transaction.
+type sweepBatcher struct {
+	lnd *lndclient.LndServices
+
+	store loopdb.SwapStore
+
+	sweeper *sweep.Sweeper
+
+	timerFactory func(d time.Duration) <-chan time.Time
+
+	requests chan *sweepRequest
+}
+
+// newSweepBatcher returns a new sweep batcher.
+func newSweepBatcher(lnd *lndclient.LndServices, store loopdb.SwapStore,
+	sweeper *sweep.Sweeper,
+	timerFactory func(d time.Duration) <-chan time.Time) *sweepBatcher {
+
+	return &sweepBatcher{
+		lnd:          lnd,
+		store:        store,
+		sweeper:      sweeper,
+		timerFactory: timerFactory,
+		requests:     make(chan *sweepRequest),
+	}
+}
+
+// run collects sweep requests and publishes them as a batch once
+// BatchSweepDelay has passed since the first request of the batch. It
+// returns when the context is canceled.
+func (b *sweepBatcher) run(ctx context.Context) error {
+	var (
+		batch []*sweepRequest
+		timer <-chan time.Time
+	)
+
+	for {
+		select {
+		case req := <-b.requests:
+			if len(batch) == 0 {
+				timer = b.timerFactory(BatchSweepDelay)
+			}
+			batch = append(batch, req)
+
+		case <-timer:
+			b.publishBatch(ctx, batch)
+
+			batch = nil
+			timer = nil
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// sweep adds the given request to the next batch and waits for the batch to
+// be published. An error is only returned if the context is canceled, the
+// outcome of the batch is reported in the result.
+func (b *sweepBatcher) sweep(ctx context.Context, req *sweepRequest) (
+	*sweepResult, error) {
+
+	req.resultChan = make(chan *sweepResult, 1)
+
+	select {
+	case b.requests <- req:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case result := <-req.resultChan:
+		return result, nil
+
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// publishBatch creates a single sweep tx for all requests of the batch,
+// records the sweep for every swap and publishes the tx. The result is
+// delivered to every request.
+func (b *sweepBatcher) publishBatch(ctx context.Context,
+	batch []*sweepRequest) {
+
+	sendErr := func(err error) {
+		for _, req := range batch {
+			req.resultChan <- &sweepResult{err: err}
+		}
+	}
+
+	// Order the requests by swap, so that the same set of htlcs results in
+	// the same tx and can be republished without replacing it.
+	sort.Slice(batch, func(i, j int) bool {
+		return bytes.Compare(
+			batch[i].swapHash[:], batch[j].swapHash[:],
+		) < 0
+	})
+
+	var height int32
+	inputs := make([]*sweep.BatchInput, len(batch))
+	for i, req := range batch {
+		inputs[i] = req.input
+
+		if req.height > height {
+			height = req.height
+		}
+	}
+
+	sweepTx, fees, err := b.sweeper.CreateBatchSweepTx(ctx, height, inputs)
+	if err != nil {
+		sendErr(err)
+		return
+	}
+
+	var batchFee btcutil.Amount
+	for _, fee := range fees {
+		batchFee += fee
+	}
+
+	// Before publishing the tx, record the sweep for all swaps in a single
+	// db transaction, so that none of them can miss that its preimage was
+	// revealed. If this fails, we don't publish the batch at all. If the
+	// batch can't be published, the record is rolled back. The swaps
+	// themselves only update their in-memory state from the result of
+	// their request.
+	var (
+		sweepTxHash = sweepTx.TxHash()
+		updateTime  = time.Now()
+		updates     = make(map[lntypes.Hash]loopdb.SwapStateData)
+	)
+	for i, req := range batch {
+		if req.state.State == loopdb.StatePreimageRevealed &&
+			req.state.SweepTxHash != nil &&
+			*req.state.SweepTxHash == sweepTxHash {
+
+			continue
+		}
+
+		state := req.state
+		state.State = loopdb.StatePreimageRevealed
+		state.SweepTxHash = &sweepTxHash
+		state.SweepFee = fees[i]
+		updates[req.swapHash] = state
+	}
+
+	if len(updates) > 0 {
+		err := b.store.UpdateLoopOuts(updateTime, updates)
+		if err != nil {
+			log.Errorf("Could not record batch sweep %v: %v",
+				sweepTxHash, err)
+
+			sendErr(err)
+			return
+		}
+	}
+
+	log.Infof("Publishing batch sweep %v for %v swaps with fee %v",
+		sweepTxHash, len(batch), batchFee)
+
+	err = b.lnd.WalletKit.PublishTransaction(
+		ctx, sweepTx, labels.LoopOutBatchSweepSuccess(len(batch)),
+	)
+	if err != nil {
+		log.Warnf("Publish batch sweep %v: %v", sweepTxHash, err)
+
+		b.rollbackBatch(batch, updates, err)
+		return
+	}
+
+	for i, req := range batch {
+		result := &sweepResult{
+			txHash:   sweepTxHash,
+			fee:      fees[i],
+			batchFee: batchFee,
+		}
+		if _, ok := updates[req.swapHash]; ok {
+			result.updateTime = updateTime
+		}
+
+		req.resultChan <- result
+	}
+}
+
+// rollbackBatch restores the previous sweep tx of all swaps that recorded a
+// batch that could not be published, and delivers the publish error to all
+// requests. The swaps stay marked as having revealed their preimage, because
+// the batch may have reached the network regardless of the error.
+func (b *sweepBatcher) rollbackBatch(batch []*sweepRequest,
+	updates map[lntypes.Hash]loopdb.SwapStateData, publishErr error) {
+
+	var (
+		updateTime = time.Now()
+		rollbacks  = make(map[lntypes.Hash]loopdb.SwapStateData)
+	)
+	for _, req := range batch {
+		if _, ok := updates[req.swapHash]; !ok {
+			continue
+		}
+
+		state := req.state
+		state.State = loopdb.StatePreimageRevealed
+		rollbacks[req.swapHash] = state
+	}
+
+	if len(rollbacks) > 0 {
+		err := b.store.UpdateLoopOuts(updateTime, rollbacks)
+		if err != nil {
+			log.Errorf("Could not roll back batch sweep: %v", err)
+		}
+	}
+
+	for _, req := range batch {
+		result := &sweepResult{err: publishErr}
+		if _, ok := rollbacks[req.swapHash]; ok {
+			result.updateTime = updateTime
+		}
+
+		req.resultChan <- result
+	}
+}
+
+// batchSweepFees returns the total fee of every batch sweep tx that is the
+// most recent sweep of multiple pending swaps. The fee of a batch is the sum
+// of the fee shares that its swaps recorded. Swaps use it to restore the fee
+// that they need to pay to replace their batch after a restart.
+func batchSweepFees(
+	swaps []*loopdb.LoopOut) map[chainhash.Hash]btcutil.Amount {
+
+	var (
+		fees  = make(map[chainhash.Hash]btcutil.Amount)
+		sizes = make(map[chainhash.Hash]int)
+	)
+	for _, swp := range swaps {
+		if swp.State().State.Type() != loopdb.StateTypePending {
+			continue
+		}
+
+		lastUpdate := swp.LastUpdate()
+		if lastUpdate == nil || lastUpdate.SweepTxHash == nil {
+			continue
+		}
+
+		fees[*lastUpdate.SweepTxHash] += lastUpdate.SweepFee
+		sizes[*lastUpdate.SweepTxHash]++
+	}
+
+	// A sweep tx that is recorded by a single swap is indistinguishable
+	// from an individual sweep, whose fee the swap already knows.
+	for hash, size := range sizes {
+		if size < 2 {
+			delete(fees, hash)
+		}
+	}
+
+	return fees
+}
@@ -0,0 +1,351 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightninglabs/loop/sweep"
+	"github.com/lightninglabs/loop/test"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/stretchr/testify/require"
+)
+
+// batcherTestContext contains a sweep batcher that is run against a mock lnd.
+type batcherTestContext struct {
+	t       *testing.T
+	lnd     *test.LndMockServices
+	store   *storeMock
+	batcher *sweepBatcher
+	timer   chan time.Time
+	cancel  func()
+	runErr  chan error
+}
+
+// newBatcherTestContext starts a sweep batcher with a mock lnd.
+func newBatcherTestContext(t *testing.T) *batcherTestContext {
+	lnd := test.NewMockLnd()
+	store := newStoreMock(t)
+	timer := make(chan time.Time)
+
+	batcher := newSweepBatcher(
+		&lnd.LndServices, store, &sweep.Sweeper{Lnd: &lnd.LndServices},
+		func(time.Duration) <-chan time.Time {
+			return timer
+		},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error)
+	go func() {
+		runErr <- batcher.run(ctx)
+	}()
+
+	return &batcherTestContext{
+		t:       t,
+		lnd:     lnd,
+		store:   store,
+		batcher: batcher,
+		timer:   timer,
+		cancel:  cancel,
+		runErr:  runErr,
+	}
+}
+
+// stop stops the batcher and asserts that it exits as expected.
+func (c *batcherTestContext) stop() {
+	c.cancel()
+	require.Equal(c.t, context.Canceled, <-c.runErr)
+}
+
+// addRequest delivers a sweep request to the batcher without waiting for its
+// result.
+func (c *batcherTestContext) addRequest(req *sweepRequest) {
+	req.resultChan = make(chan *sweepResult, 1)
+
+	select {
+	case c.batcher.requests <- req:
+	case <-time.After(test.Timeout):
+		c.t.Fatal("sweep request not received")
+	}
+}
+
+// testSweepRequest returns a sweep request for a test htlc.
+func testSweepRequest(t *testing.T, index int32, value btcutil.Amount,
+	destAddr btcutil.Address) *sweepRequest {
+
+	_, senderKey := test.CreateKey(index)
+	_, receiverKey := test.CreateKey(index + 1)
+
+	var sender, receiver [33]byte
+	copy(sender[:], senderKey.SerializeCompressed())
+	copy(receiver[:], receiverKey.SerializeCompressed())
+
+	preimage := lntypes.Preimage{byte(index)}
+	hash := preimage.Hash()
+	htlc, err := swap.NewHtlc(
+		swap.HtlcV2, 1000, sender, receiver, hash, swap.HtlcP2WSH,
+		&chaincfg.TestNet3Params,
+	)
+	require.NoError(t, err)
+
+	return &sweepRequest{
+		swapHash: hash,
+		input: &sweep.BatchInput{
+			Htlc:     htlc,
+			Outpoint: wire.OutPoint{Hash: chainhash.Hash{byte(index)}},
+			Value:    value,
+			KeyBytes: receiver,
+			Sequence: htlc.SuccessSequence(),
+			WitnessFunc: func(sig []byte) (wire.TxWitness, error) {
+				return htlc.GenSuccessWitness(sig, preimage)
+			},
+			AddInputEstimate: htlc.AddSuccessToEstimator,
+			Destinations: []loopdb.SweepDestination{
+				{Addr: destAddr, Weight: 1},
+			},
+			Fee:    1000,
+			MaxFee: 10000,
+		},
+		height: 600,
+		state: loopdb.SwapStateData{
+			State: loopdb.StateInitiated,
+		},
+	}
+}
+
+// TestSweepBatcher tests that the sweep batcher combines multiple sweep
+// requests into a single tx.
+func TestSweepBatcher(t *testing.T) {
+	defer test.Guard(t)()
+
+	c := newBatcherTestContext(t)
+	defer c.stop()
+
+	destAddr := test.GetDestAddr(t, 0)
+	req1 := testSweepRequest(t, 1, 100000, destAddr)
+	req2 := testSweepRequest(t, 3, 200000, destAddr)
+	c.store.loopOutUpdates[req1.swapHash] = nil
+	c.store.loopOutUpdates[req2.swapHash] = nil
+
+	// Add both requests to the same batch and fire the batch timer.
+	c.addRequest(req1)
+	c.addRequest(req2)
+	c.timer <- time.Now()
+
+	// Both htlcs are signed in a single call.
+	signReq := <-c.lnd.SignOutputRawChannel
+	require.Len(t, signReq.SignDescriptors, 2)
+	for _, signDesc := range signReq.SignDescriptors {
+		require.NotEmpty(t, signDesc.Output.PkScript)
+	}
+
+	// Both swaps record the batch before it is published.
+	state1 := c.store.assertLoopOutState(loopdb.StatePreimageRevealed)
+	state2 := c.store.assertLoopOutState(loopdb.StatePreimageRevealed)
+
+	batchTx := <-c.lnd.TxPublishChannel
+	require.Len(t, batchTx.TxIn, 2)
+	require.Len(t, batchTx.TxOut, 1)
+	require.Equal(t, int32(600), int32(batchTx.LockTime))
+
+	batchTxHash := batchTx.TxHash()
+	require.Equal(t, &batchTxHash, state1.SweepTxHash)
+	require.Equal(t, &batchTxHash, state2.SweepTxHash)
+
+	// The swaps receive the batch tx and their fees.
+	result1 := <-req1.resultChan
+	result2 := <-req2.resultChan
+	require.NoError(t, result1.err)
+	require.NoError(t, result2.err)
+	require.Equal(t, batchTxHash, result1.txHash)
+	require.Equal(t, batchTxHash, result2.txHash)
+	require.False(t, result1.updateTime.IsZero())
+	require.False(t, result2.updateTime.IsZero())
+
+	// The fee of the batch is split between the swaps, and is lower than
+	// the fee that the swaps would have paid individually.
+	batchFee := result1.fee + result2.fee
+	require.Equal(t, batchFee, result1.batchFee)
+	require.Equal(t, batchFee, result2.batchFee)
+	require.Equal(t, batchFee, state1.SweepFee+state2.SweepFee)
+	require.Less(t, int64(result1.fee), int64(1000))
+	require.Less(t, int64(result2.fee), int64(1000))
+
+	require.Equal(
+		t, int64(300000-batchFee), batchTx.TxOut[0].Value,
+	)
+}
+
+// TestSweepBatcherAbort tests that a batch is neither recorded nor published
+// if it can't be recorded for all of its swaps.
+func TestSweepBatcherAbort(t *testing.T) {
+	defer test.Guard(t)()
+
+	c := newBatcherTestContext(t)
+	defer c.stop()
+
+	// Only the first swap is known to the store.
+	req1 := testSweepRequest(t, 1, 100000, test.GetDestAddr(t, 0))
+	req2 := testSweepRequest(t, 3, 200000, test.GetDestAddr(t, 1))
+	c.store.loopOutUpdates[req1.swapHash] = nil
+
+	c.addRequest(req1)
+	c.addRequest(req2)
+	c.timer <- time.Now()
+
+	// Every destination address gets its own output.
+	signReq := <-c.lnd.SignOutputRawChannel
+	require.Len(t, signReq.Tx.TxOut, 2)
+
+	require.Error(t, (<-req1.resultChan).err)
+	require.Error(t, (<-req2.resultChan).err)
+	require.Empty(t, c.store.loopOutUpdates[req1.swapHash])
+
+	select {
+	case <-c.lnd.TxPublishChannel:
+		t.Fatal("aborted batch published")
+	default:
+	}
+}
+
+// TestSweepBatcherReplacement tests that a batch that replaces previous sweeps
+// pays their total fee plus the incremental relay fee, and that it isn't
+// created if that exceeds the maximum fee of an input.
+func TestSweepBatcherReplacement(t *testing.T) {
+	defer test.Guard(t)()
+
+	c := newBatcherTestContext(t)
+	defer c.stop()
+
+	// Both swaps were swept in a previous batch. The third swap joins the
+	// new batch, which therefore replaces the previous one.
+	prevTxHash := chainhash.Hash{1, 2, 3}
+	destAddr := test.GetDestAddr(t, 0)
+	reqs := []*sweepRequest{
+		testSweepRequest(t, 1, 100000, destAddr),
+		testSweepRequest(t, 3, 200000, destAddr),
+		testSweepRequest(t, 5, 300000, destAddr),
+	}
+	for _, req := range reqs[:2] {
+		req.input.PrevTxHash = &prevTxHash
+		req.input.PrevTxFee = 5000
+		req.state.State = loopdb.StatePreimageRevealed
+		req.state.SweepTxHash = &prevTxHash
+		req.state.SweepFee = 2500
+	}
+
+	for _, req := range reqs {
+		c.store.loopOutUpdates[req.swapHash] = nil
+		c.addRequest(req)
+	}
+	c.timer <- time.Now()
+
+	<-c.lnd.SignOutputRawChannel
+	for range reqs {
+		c.store.assertLoopOutState(loopdb.StatePreimageRevealed)
+	}
+	batchTx := <-c.lnd.TxPublishChannel
+
+	var batchFee btcutil.Amount
+	for _, req := range reqs {
+		result := <-req.resultChan
+		require.NoError(t, result.err)
+
+		batchFee += result.fee
+	}
+	require.Equal(t, int64(600000-batchFee), batchTx.TxOut[0].Value)
+
+	// The batch pays at least the total fee of the replaced batch plus
+	// the incremental relay fee for its own size.
+	weight := 3 * 4 * int64(batchTx.SerializeSizeStripped())
+	require.GreaterOrEqual(
+		t, int64(batchFee),
+		int64(5000+sweep.IncrementalRelayFee(weight/3)),
+	)
+
+	// If the replacement exceeds the maximum fee of an input, the batch
+	// fails without being recorded.
+	for _, req := range reqs {
+		req.input.MaxFee = 1000
+		c.addRequest(req)
+	}
+	c.timer <- time.Now()
+
+	for _, req := range reqs {
+		require.Equal(t, sweep.ErrBatchFeeTooLow, (<-req.resultChan).err)
+	}
+}
+
+// TestSweepBatcherPublishFailure tests that the recorded batch is rolled back
+// if it can't be published, while the swaps stay marked as having revealed
+// their preimage.
+func TestSweepBatcherPublishFailure(t *testing.T) {
+	defer test.Guard(t)()
+
+	c := newBatcherTestContext(t)
+	defer c.stop()
+
+	publishErr := errors.New("publish failed")
+	c.lnd.SetPublishErr(publishErr)
+
+	req := testSweepRequest(t, 1, 100000, test.GetDestAddr(t, 0))
+	c.store.loopOutUpdates[req.swapHash] = nil
+
+	c.addRequest(req)
+	c.timer <- time.Now()
+
+	<-c.lnd.SignOutputRawChannel
+
+	state := c.store.assertLoopOutState(loopdb.StatePreimageRevealed)
+	require.NotNil(t, state.SweepTxHash)
+
+	state = c.store.assertLoopOutState(loopdb.StatePreimageRevealed)
+	require.Nil(t, state.SweepTxHash)
+	require.Zero(t, state.SweepFee)
+
+	result := <-req.resultChan
+	require.Equal(t, publishErr, result.err)
+	require.False(t, result.updateTime.IsZero())
+}
+
+// TestBatchSweepFees tests that the total fee of a batch is restored from the
+// fee shares of its pending swaps.
+func TestBatchSweepFees(t *testing.T) {
+	batchTxHash := chainhash.Hash{1}
+	sweepTxHash := chainhash.Hash{2}
+
+	newSwap := func(state loopdb.SwapState, txHash *chainhash.Hash,
+		fee btcutil.Amount) *loopdb.LoopOut {
+
+		return &loopdb.LoopOut{
+			Loop: loopdb.Loop{
+				Events: []*loopdb.LoopEvent{{
+					SwapStateData: loopdb.SwapStateData{
+						State:       state,
+						SweepTxHash: txHash,
+						SweepFee:    fee,
+					},
+				}},
+			},
+		}
+	}
+
+	fees := batchSweepFees([]*loopdb.LoopOut{
+		newSwap(loopdb.StatePreimageRevealed, &batchTxHash, 100),
+		newSwap(loopdb.StatePreimageRevealed, &batchTxHash, 200),
+		newSwap(loopdb.StateSuccess, &batchTxHash, 400),
+		newSwap(loopdb.StatePreimageRevealed, &sweepTxHash, 800),
+		newSwap(loopdb.StateInitiated, nil, 0),
+	})
+	require.Equal(t, map[chainhash.Hash]btcutil.Amount{
+		batchTxHash: 300,
+	}, fees)
+}
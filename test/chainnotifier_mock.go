@@ -132,17 +132,21 @@ func (c *mockChainNotifier) RegisterConfirmationsNtfn(ctx context.Context,
 
 				// Whichever conf notifier catches the confirmation
 				// will forward it to all matching subscibers.
-				if bytes.Equal(m.Tx.TxOut[0].PkScript, r.PkScript) {
+				if txHasPkScript(m.Tx, r.PkScript) {
 					// Unregister the "notifier".
 					c.confRegistrations = append(
 						c.confRegistrations[:i], c.confRegistrations[i+1:]...,
 					)
 					i--
 
-					select {
-					case r.ConfChan <- m:
-					case <-ctx.Done():
-					}
+					// The conf channel is buffered and
+					// every registration is notified only
+					// once, so this never blocks. We don't
+					// select on our own context, because a
+					// notifier that was canceled would
+					// otherwise drop the confirmation of
+					// another subscriber.
+					r.ConfChan <- m
 				}
 			}
 			c.Unlock()
@@ -158,3 +162,15 @@ func (c *mockChainNotifier) RegisterConfirmationsNtfn(ctx context.Context,
 
 	return reg.ConfChan, errChan, nil
 }
+
+// txHasPkScript returns true if any of the outputs of the tx pays to the given
+// pk script.
+func txHasPkScript(tx *wire.MsgTx, pkScript []byte) bool {
+	for _, out := range tx.TxOut {
+		if bytes.Equal(out.PkScript, pkScript) {
+			return true
+		}
+	}
+
+	return false
+}
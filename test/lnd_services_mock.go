@@ -11,6 +11,7 @@ import (
 	"github.com/lightninglabs/lndclient"
 	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 	"github.com/lightningnetwork/lnd/zpay32"
 )
@@ -159,6 +160,10 @@ type LndMockServices struct {
 	Transactions []lndclient.Transaction
 	Sweeps       []string
 
+	// Utxos is the set of outputs that is returned by the wallet kit's
+	// ListUnspent call.
+	Utxos []*lnwallet.Utxo
+
 	// Invoices is a set of invoices that have been created by the mock,
 	// keyed by hash string.
 	Invoices map[lntypes.Hash]*lndclient.Invoice
@@ -170,6 +175,10 @@ type LndMockServices struct {
 
 	WaitForFinished func()
 
+	// publishErr is returned by the wallet kit's PublishTransaction call
+	// if set.
+	publishErr error
+
 	lock sync.Mutex
 }
 
@@ -185,6 +194,14 @@ func (s *LndMockServices) NotifyHeight(height int32) error {
 	return nil
 }
 
+// SetPublishErr sets the error that is returned by the wallet kit's
+// PublishTransaction call. Txes are only published if it is nil.
+func (s *LndMockServices) SetPublishErr(err error) {
+	s.lock.Lock()
+	s.publishErr = err
+	s.lock.Unlock()
+}
+
 // AddRelevantTx marks the given transaction as relevant.
 func (s *LndMockServices) AddTx(tx *wire.MsgTx) {
 	s.lock.Lock()
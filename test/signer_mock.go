@@ -3,7 +3,6 @@ package test
 import (
 	"bytes"
 	"context"
-	"fmt"
 
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcd/wire"
@@ -24,7 +23,10 @@ func (s *mockSigner) SignOutputRaw(ctx context.Context, tx *wire.MsgTx,
 		SignDescriptors: signDescriptors,
 	}
 
-	rawSigs := [][]byte{{1, 2, 3}}
+	rawSigs := make([][]byte, len(signDescriptors))
+	for i := range signDescriptors {
+		rawSigs[i] = []byte{1, 2, 3}
+	}
 
 	return rawSigs, nil
 }
@@ -32,7 +34,14 @@ func (s *mockSigner) SignOutputRaw(ctx context.Context, tx *wire.MsgTx,
 func (s *mockSigner) ComputeInputScript(ctx context.Context, tx *wire.MsgTx,
 	signDescriptors []*lndclient.SignDescriptor) ([]*input.Script, error) {
 
-	return nil, fmt.Errorf("unimplemented")
+	scripts := make([]*input.Script, len(signDescriptors))
+	for i := range signDescriptors {
+		scripts[i] = &input.Script{
+			Witness: wire.TxWitness{{1, 2, 3}, {4, 5, 6}},
+		}
+	}
+
+	return scripts, nil
 }
 
 func (s *mockSigner) SignMessage(ctx context.Context, msg []byte,
@@ -27,7 +27,10 @@ var _ lndclient.WalletKitClient = (*mockWalletKit)(nil)
 func (m *mockWalletKit) ListUnspent(ctx context.Context, minConfs,
 	maxConfs int32) ([]*lnwallet.Utxo, error) {
 
-	return nil, nil
+	utxos := make([]*lnwallet.Utxo, len(m.lnd.Utxos))
+	copy(utxos, m.lnd.Utxos)
+
+	return utxos, nil
 }
 
 func (m *mockWalletKit) LeaseOutput(ctx context.Context, lockID wtxmgr.LockID,
@@ -83,6 +86,14 @@ func (m *mockWalletKit) NextAddr(ctx context.Context) (btcutil.Address, error) {
 func (m *mockWalletKit) PublishTransaction(ctx context.Context, tx *wire.MsgTx,
 	_ string) error {
 
+	m.lnd.lock.Lock()
+	publishErr := m.lnd.publishErr
+	m.lnd.lock.Unlock()
+
+	if publishErr != nil {
+		return publishErr
+	}
+
 	m.lnd.AddTx(tx)
 	m.lnd.TxPublishChannel <- tx
 	return nil